@@ -15,6 +15,7 @@ Supports two modes:
 import json
 import os
 import re
+import shutil
 import subprocess
 import sys
 import time
@@ -75,6 +76,19 @@ def get_stories(prd: dict) -> list:
     return prd.get("stories") or prd.get("tasks") or []
 
 
+def resume_from(prd: dict, task_id: str) -> dict:
+    """Mark every story before task_id (in PRD order) as passed.
+
+    Used to rerun a task from a specific story; the operator has already
+    checked that the id exists in the PRD.
+    """
+    for story in get_stories(prd):
+        if story.get("id") == task_id:
+            break
+        story["passes"] = True
+    return prd
+
+
 def get_next_task(prd: dict) -> dict | None:
     """Find the highest-priority incomplete task from the PRD."""
     stories = get_stories(prd)
@@ -232,6 +246,30 @@ def run_quality_gates(quality_gates: list[dict]) -> tuple[bool, list[dict]]:
     return all_passed, results
 
 
+def find_missing_gate_commands(quality_gates: list[dict]) -> tuple[list[str], bool]:
+    """Return gate commands not found in the image, and whether any of them
+    belongs to a gate that blocks the task on failure."""
+    missing = []
+    blocking = False
+    for gate in quality_gates:
+        command = gate.get("command") or []
+        if not command:
+            continue
+        executable = command[0]
+        if os.sep in executable:
+            path = os.path.join(WORKSPACE_DIR, executable)
+            found = os.access(path, os.X_OK)
+        else:
+            found = shutil.which(executable) is not None
+        if found:
+            continue
+        if executable not in missing:
+            missing.append(executable)
+        if gate.get("failurePolicy", "Fail") != "Ignore":
+            blocking = True
+    return missing, blocking
+
+
 def update_prd_task_status(prd: dict, task_id: str, passed: bool) -> dict:
     """Update the status of a task in the PRD."""
     stories = get_stories(prd)
@@ -505,8 +543,13 @@ def finalize_git(git_config: dict, prd: dict, task_name: str) -> dict:
                 logger.info("Push successful")
                 result["pushed"] = True
 
+        # A rerun pushes to the branch of the PR opened by a previous run
+        if git_config.get("reusePR") and result.get("pushed"):
+            result["pullRequestUrl"] = git_config.get("pullRequestUrl")
+            logger.info(f"Updated existing PR: {result['pullRequestUrl']}")
+
         # Create PR if enabled (default: true)
-        if git_config.get("createPR", True) and result.get("pushed"):
+        elif git_config.get("createPR", True) and result.get("pushed"):
             logger.info("Creating pull request...")
             pr_url = create_pull_request(git_config, prd, task_name)
             if pr_url:
@@ -560,6 +603,18 @@ def create_pull_request(git_config: dict, prd: dict, task_name: str) -> str | No
         if base_branch:
             cmd.extend(["--base", base_branch])
 
+        # Request reviewers and assignees if configured
+        reviewers = git_config.get("reviewers") or []
+        if reviewers:
+            cmd.extend(["--reviewer", ",".join(reviewers)])
+        assignees = git_config.get("assignees") or []
+        if assignees:
+            cmd.extend(["--assignee", ",".join(assignees)])
+
+        # Apply labels if configured
+        for label in git_config.get("prLabels") or []:
+            cmd.extend(["--label", label])
+
         logger.info(f"Running: {' '.join(cmd[:4])}...")  # Don't log full body
         result = subprocess.run(
             cmd,
@@ -583,6 +638,13 @@ def create_pull_request(git_config: dict, prd: dict, task_name: str) -> str | No
         return None
 
 
+def worker_invoke_url(worker_endpoint: str) -> str:
+    """Build the worker /invoke URL from a bare host:port or a full base URL."""
+    if "://" in worker_endpoint:
+        return f"{worker_endpoint.rstrip('/')}/invoke"
+    return f"http://{worker_endpoint}/invoke"
+
+
 def dispatch_to_worker_with_endpoint(
     task: dict, context: str, worker_endpoint: str, timeout_seconds: float = 600.0
 ) -> dict:
@@ -617,7 +679,7 @@ ID: {task_id}
     try:
         with httpx.Client(timeout=timeout_seconds) as client:  # per-task iteration timeout
             response = client.post(
-                f"http://{worker_endpoint}/invoke",
+                worker_invoke_url(worker_endpoint),
                 json={"query": worker_query, "metadata": {"taskId": task_id}},
             )
             response.raise_for_status()
@@ -643,6 +705,18 @@ ID: {task_id}
         }
 
 
+# Sequence number of the last milestone marker printed
+_event_seq = 0
+
+
+def emit_event(event_type: str, message: str, **fields) -> None:
+    """Print a milestone marker the controller records as a Kubernetes Event."""
+    global _event_seq
+    _event_seq += 1
+    marker = {"seq": _event_seq, "type": event_type, "message": message, **fields}
+    print("ORCHESTRATOR_EVENT:" + json.dumps(marker), flush=True)
+
+
 def run_job_mode():
     """Run orchestrator as a Job - full loop until completion."""
     logger.info("=" * 60)
@@ -671,6 +745,15 @@ def run_job_mode():
     quality_gates = config.get("qualityGates", [])
     limits = config.get("limits", {})
     context = config.get("context", "")
+    resume_from_task_id = config.get("resumeFromTaskId")
+    if resume_from_task_id:
+        prd = resume_from(prd, resume_from_task_id)
+
+    # Additional named documents (e.g. requirements, design) are appended to
+    # the context shared with the worker
+    sources = config.get("sources") or {}
+    for name, content in sources.items():
+        context += f"\n\n## {name}\n{content}"
 
     max_iterations = limits.get("maxIterations", 100)
     max_consecutive_failures = limits.get("maxConsecutiveFailures", 3)
@@ -683,8 +766,26 @@ def run_job_mode():
     logger.info(f"Max iterations: {max_iterations}")
     logger.info(f"Iteration timeout: {iteration_timeout_seconds}s")
     logger.info(f"Quality gates: {len(quality_gates)}")
+    logger.info(f"Additional sources: {', '.join(sources) or 'none'}")
+    logger.info(f"Resume from task: {resume_from_task_id or 'start'}")
     logger.info(f"Git configured: {git_config is not None}")
 
+    # Pre-flight: report gate commands missing from the image instead of
+    # failing every task on them
+    missing_commands = []
+    if config.get("checkGateCommands") and quality_gates:
+        missing_commands, blocking = find_missing_gate_commands(quality_gates)
+        if missing_commands:
+            logger.warning(f"Quality gate commands not found: {', '.join(missing_commands)}")
+        if blocking:
+            print("ORCHESTRATOR_RESULT:" + json.dumps({
+                "passed": False,
+                "totalTasks": len(prd.get("stories", [])),
+                "error": f"Quality gate commands not found: {', '.join(missing_commands)}",
+                "missingCommands": missing_commands,
+            }))
+            sys.exit(1)
+
     # Initialize tracking
     iteration = 0
     consecutive_failures = 0
@@ -711,6 +812,8 @@ def run_job_mode():
         task_id = task.get("id", "unknown")
         task_title = task.get("title", "Unknown Task")
         logger.info(f"Processing: {task_id} - {task_title}")
+        emit_event("IterationStarted", f"Iteration {iteration}: processing {task_id} - {task_title}",
+                   iteration=iteration, taskId=task_id)
 
         # Dispatch to worker
         worker_result = dispatch_to_worker_with_endpoint(
@@ -721,6 +824,7 @@ def run_job_mode():
             consecutive_failures += 1
             error_msg = worker_result.get("error", "Unknown error")
             logger.error(f"Worker dispatch failed: {error_msg}")
+            emit_event("TaskFailed", f"Task {task_id} failed: {error_msg}", iteration=iteration, taskId=task_id)
             all_learnings.append(f"Iteration {iteration}: Task {task_id} failed - {error_msg}")
 
             if consecutive_failures >= max_consecutive_failures:
@@ -740,6 +844,9 @@ def run_job_mode():
             gates_passed, gate_results = run_quality_gates(quality_gates)
             if not gates_passed:
                 logger.warning("Quality gates failed - marking task as not passed")
+                failed_gates = [g.get("name", "?") for g in gate_results if not g.get("passed")]
+                emit_event("QualityGateFailed", f"Task {task_id}: quality gates failed: {', '.join(failed_gates)}",
+                           iteration=iteration, taskId=task_id)
                 task_passed = False
                 learnings += f"\nQuality gates failed: {gate_results}"
 
@@ -748,9 +855,11 @@ def run_job_mode():
             consecutive_failures = 0
             prd = update_prd_task_status(prd, task_id, True)
             logger.info(f"Task {task_id} PASSED")
+            emit_event("TaskPassed", f"Task {task_id} passed", iteration=iteration, taskId=task_id)
         else:
             consecutive_failures += 1
             logger.warning(f"Task {task_id} FAILED (consecutive failures: {consecutive_failures})")
+            emit_event("TaskFailed", f"Task {task_id} failed", iteration=iteration, taskId=task_id)
 
             if consecutive_failures >= max_consecutive_failures:
                 logger.error(f"Max consecutive failures ({max_consecutive_failures}) reached")
@@ -790,6 +899,8 @@ def run_job_mode():
         "prd": prd,
         "learnings": "\n".join(all_learnings[-10:]),  # Last 10 learnings
     }
+    if missing_commands:
+        final_result["missingCommands"] = missing_commands
 
     # Git finalization if all tasks complete and git is configured
     if git_config and all_complete:
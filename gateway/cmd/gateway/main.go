@@ -27,16 +27,64 @@ func main() {
 		metricsAddr    string
 		routesFile     string
 		requestTimeout time.Duration
+		maxReqTimeout  time.Duration
+		strictTimeout  bool
 		mcpEnabled     bool
 		mcpNamespace   string
+		maxRespBytes   int64
+		maxInFlight    int
+		zone           string
+		prewarm        bool
+		prewarmWorkers int
+		debugHeaders   bool
+		adminToken     string
+		authTokenFile  string
+		errorBuffer    int
+		resultTTL      time.Duration
+		resultEntries  int
+		asyncJobs      int
+		forwardHeaders string
+		tenantHeader   string
+		rlRetries      int
+		rlMaxWait      time.Duration
+		mcpPartial     bool
+		mcpToolsTTL    time.Duration
+		mcpProgress    time.Duration
+		mcpIdle        time.Duration
+		mcpKeepAlive   time.Duration
+		mcpCompressMin int
 	)
 
 	flag.StringVar(&addr, "addr", ":8080", "HTTP listen address")
 	flag.StringVar(&metricsAddr, "metrics-addr", ":9090", "Metrics listen address")
 	flag.StringVar(&routesFile, "routes-file", "/etc/gateway/routes.json", "Path to routes configuration file")
 	flag.DurationVar(&requestTimeout, "request-timeout", 5*time.Minute, "Request timeout for agent calls")
+	flag.DurationVar(&maxReqTimeout, "max-request-timeout", 0, "Longest timeout a client may ask for with the X-Request-Timeout header (0 = request-timeout)")
+	flag.BoolVar(&strictTimeout, "strict-request-timeout", false, "Reject invalid X-Request-Timeout values with 400 instead of ignoring them")
 	flag.BoolVar(&mcpEnabled, "mcp-enabled", true, "Enable MCP protocol endpoints")
 	flag.StringVar(&mcpNamespace, "mcp-namespace", "", "Namespace to watch for agents (empty = all namespaces)")
+	flag.Int64Var(&maxRespBytes, "max-response-bytes", routes.DefaultMaxResponseBytes, "Maximum size of an agent response body in bytes")
+	flag.IntVar(&maxInFlight, "max-inflight", 0, "Maximum concurrent API requests across all routes (0 = unlimited)")
+	flag.StringVar(&zone, "zone", os.Getenv("GATEWAY_ZONE"), "Topology zone of this gateway, for locality-aware routing")
+	flag.BoolVar(&prewarm, "prewarm-connections", false, "Pre-dial ready backends whenever routes load")
+	flag.IntVar(&prewarmWorkers, "prewarm-concurrency", 8, "Maximum concurrent backend dials when pre-warming connections")
+	flag.BoolVar(&debugHeaders, "debug-routing-headers", false, "Honor routing override headers (X-Route-Strategy, X-Route-Backend, X-Route-Exclude) from clients; do not enable for untrusted traffic")
+	flag.StringVar(&adminToken, "admin-token", os.Getenv("GATEWAY_ADMIN_TOKEN"), "Bearer token for diagnostics endpoints such as /v1/errors (empty = disabled)")
+	flag.StringVar(&authTokenFile, "auth-token-file", "", "File holding the bearer token required on /v1/invoke, /v1/agents, /v1/routes and /mcp endpoints; reloaded when it changes (empty = no authentication)")
+	flag.IntVar(&errorBuffer, "error-buffer-size", api.DefaultErrorBufferSize, "Number of recent request errors kept for /v1/errors")
+	flag.DurationVar(&resultTTL, "async-result-ttl", api.DefaultResultTTL, "How long an async invoke result is kept before it expires")
+	flag.IntVar(&resultEntries, "async-result-max-entries", api.DefaultResultStoreSize, "Maximum async invoke results kept; the oldest is evicted when full")
+	flag.IntVar(&asyncJobs, "async-max-running", api.DefaultMaxAsyncJobs, "Maximum async invokes running at once; further ?async=true submissions get 503")
+	flag.StringVar(&forwardHeaders, "forward-headers", "", "Comma-separated client request headers copied onto agent requests; a trailing * matches a prefix, e.g. X-Trace-* (empty = none)")
+	flag.StringVar(&tenantHeader, "tenant-header", "", "Request header supplying the tenant ID when the invoke body has none, e.g. X-Tenant-ID (empty = body only)")
+	flag.IntVar(&rlRetries, "agent-rate-limit-retries", 0, "Times a call an agent rejects with 429 and Retry-After is retried within the request deadline (0 = return the 429 to the caller)")
+	flag.DurationVar(&rlMaxWait, "agent-rate-limit-max-wait", routes.DefaultRateLimitMaxWait, "Longest Retry-After the gateway waits out before retrying a rate-limited agent call (0 = no cap)")
+	flag.BoolVar(&mcpPartial, "mcp-partial-results", false, "Return the output a streaming agent sent before an MCP tools/call timeout, marked partial, instead of a bare timeout error")
+	flag.DurationVar(&mcpProgress, "mcp-progress-interval", mcp.DefaultProgressInterval, "How often SSE tools/call requests carrying a progress token get a notifications/progress heartbeat while the agent works (0 = disabled)")
+	flag.DurationVar(&mcpToolsTTL, "mcp-tools-list-cache-ttl", mcp.DefaultToolsListCacheTTL, "How long MCP tools/list results are cached; agent changes invalidate the cache early (0 = disabled)")
+	flag.DurationVar(&mcpIdle, "mcp-session-idle-timeout", mcp.DefaultSessionIdleTimeout, "How long an MCP SSE session may go without a client message or successful stream write before it is closed (0 = never)")
+	flag.DurationVar(&mcpKeepAlive, "mcp-sse-keepalive-interval", mcp.DefaultSSEKeepAliveInterval, "How often open MCP SSE streams get a ping event; keep it below the idle timeout of proxies in front of the gateway")
+	flag.IntVar(&mcpCompressMin, "mcp-sse-compress-threshold", 0, "JSON size in bytes from which MCP SSE messages are sent gzipped to sessions opened with ?compress=gzip (0 = disabled)")
 	flag.Parse()
 
 	// Initialize logger
@@ -57,11 +105,38 @@ func main() {
 
 	// Create handler
 	handler := api.NewHandler(table, requestTimeout)
+	handler.SetMaxResponseBytes(maxRespBytes)
+	handler.SetMaxRequestTimeout(maxReqTimeout)
+	handler.SetStrictRequestTimeout(strictTimeout)
+	handler.SetMaxInFlight(maxInFlight)
+	handler.SetZone(zone)
+	handler.SetDebugHeaders(debugHeaders)
+	handler.SetAdminToken(adminToken)
+	handler.SetErrorBufferSize(errorBuffer)
+	handler.SetResultStore(resultTTL, resultEntries)
+	handler.SetMaxAsyncJobs(asyncJobs)
+	handler.SetForwardHeaders(routes.ParseHeaderAllowlist(forwardHeaders))
+	handler.SetTenantHeader(tenantHeader)
+	rateLimit := routes.RateLimitPolicy{Retries: rlRetries, MaxWait: rlMaxWait}
+	handler.SetRateLimitPolicy(rateLimit)
+	if prewarm {
+		handler.SetPrewarmConcurrency(prewarmWorkers)
+	}
 	handler.UpdateDefaults()
+	go prewarmBackends(logger, handler)
 
 	// Setup file watcher for hot-reload
 	go watchRoutesFile(logger, routesFile, table, handler)
 
+	if authTokenFile != "" {
+		token, err := api.ReadTokenFile(authTokenFile)
+		if err != nil {
+			logger.Fatalf("Failed to read auth token: %v", err)
+		}
+		_ = handler.SetAuthToken(token)
+		go watchAuthTokenFile(logger, authTokenFile, handler)
+	}
+
 	// Create HTTP mux
 	mux := http.NewServeMux()
 
@@ -82,6 +157,7 @@ func main() {
 			watcher, _ = k8s.NewAgentWatcher(logger, mcpNamespace, func() {
 				if mcpHandler != nil {
 					mcpHandler.NotifyToolsListChanged()
+					mcpHandler.NotifyPromptsListChanged()
 				}
 			})
 
@@ -94,6 +170,16 @@ func main() {
 			} else {
 				// Re-create handler with working watcher
 				mcpHandler = mcp.NewHandler(logger, watcher)
+				mcpHandler.SetMaxResponseBytes(maxRespBytes)
+				mcpHandler.SetPartialResults(mcpPartial)
+				mcpHandler.SetToolsListCacheTTL(mcpToolsTTL)
+				mcpHandler.SetProgressInterval(mcpProgress)
+				mcpHandler.SetForwardHeaders(routes.ParseHeaderAllowlist(forwardHeaders))
+				mcpHandler.SetRateLimitPolicy(rateLimit)
+				mcpHandler.SetSessionIdleTimeout(mcpIdle)
+				mcpHandler.SetSSEKeepAliveInterval(mcpKeepAlive)
+				mcpHandler.SetSSECompressThreshold(mcpCompressMin)
+				go mcpHandler.RunSessionJanitor(ctx)
 
 				// Register MCP routes
 				mux.HandleFunc("/mcp", mcpHandler.HandleHTTP)    // HTTP transport (recommended)
@@ -107,9 +193,9 @@ func main() {
 	// Create main server
 	server := &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      handler.Authenticate(mux),
 		ReadTimeout:  30 * time.Second,
-		WriteTimeout: requestTimeout + 10*time.Second,
+		WriteTimeout: max(requestTimeout, maxReqTimeout) + 10*time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
 
@@ -151,6 +237,9 @@ func main() {
 
 	logger.Info("Shutting down servers...")
 
+	// Shed new invocations while in-flight ones finish
+	handler.Drain()
+
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -167,6 +256,34 @@ func main() {
 }
 
 func watchRoutesFile(logger *zap.SugaredLogger, path string, table *routes.Table, handler *api.Handler) {
+	watchFile(logger, path, func() {
+		logger.Info("Routes file changed, reloading...")
+		if err := table.LoadFromFile(path); err != nil {
+			logger.Errorf("Failed to reload routes: %v", err)
+			return
+		}
+		handler.UpdateDefaults()
+		logger.Info("Routes reloaded successfully")
+		go prewarmBackends(logger, handler)
+	})
+}
+
+// watchAuthTokenFile reloads the auth token when its file changes. A token
+// that cannot be read is logged and the previous one stays in force.
+func watchAuthTokenFile(logger *zap.SugaredLogger, path string, handler *api.Handler) {
+	watchFile(logger, path, func() {
+		token, err := api.ReadTokenFile(path)
+		if err != nil {
+			logger.Errorf("Failed to reload auth token: %v", err)
+			return
+		}
+		_ = handler.SetAuthToken(token)
+		logger.Info("Auth token reloaded")
+	})
+}
+
+// watchFile calls reload whenever the file at path is written or created.
+func watchFile(logger *zap.SugaredLogger, path string, reload func()) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		logger.Errorf("Failed to create file watcher: %v", err)
@@ -197,17 +314,9 @@ func watchRoutesFile(logger *zap.SugaredLogger, path string, table *routes.Table
 
 			// Reload on write or create
 			if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
-				logger.Info("Routes file changed, reloading...")
-
 				// Small delay to ensure file is fully written
 				time.Sleep(100 * time.Millisecond)
-
-				if err := table.LoadFromFile(path); err != nil {
-					logger.Errorf("Failed to reload routes: %v", err)
-				} else {
-					handler.UpdateDefaults()
-					logger.Info("Routes reloaded successfully")
-				}
+				reload()
 			}
 
 		case err, ok := <-watcher.Errors:
@@ -218,3 +327,9 @@ func watchRoutesFile(logger *zap.SugaredLogger, path string, table *routes.Table
 		}
 	}
 }
+
+func prewarmBackends(logger *zap.SugaredLogger, handler *api.Handler) {
+	if n := handler.Prewarm(context.Background()); n > 0 {
+		logger.Infof("Pre-warmed connections to %d backends", n)
+	}
+}
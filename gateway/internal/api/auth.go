@@ -0,0 +1,66 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ReadTokenFile reads a bearer token from path, ignoring surrounding
+// whitespace. An empty file is an error, so a truncated file never turns
+// authentication off.
+func ReadTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+// SetAuthToken sets the bearer token Authenticate requires. It is safe to
+// call while serving requests, e.g. when the token file changes. An empty
+// token is rejected; use no token file to leave endpoints open.
+func (h *Handler) SetAuthToken(token string) error {
+	if token == "" {
+		return errors.New("auth token must not be empty")
+	}
+	h.authToken.Store(token)
+	return nil
+}
+
+// Authenticate wraps next so that requests must carry the auth token as
+// an Authorization: Bearer header. Health checks and the diagnostics and
+// admin endpoints, which check the admin token themselves, are exempt. Until a
+// token is set every request passes through.
+func (h *Handler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected, _ := h.authToken.Load().(string)
+		if expected == "" || authExempt(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			h.writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authExempt reports whether path is served without the auth token.
+func authExempt(path string) bool {
+	switch path {
+	case "/healthz", "/v1/errors", "/v1/config":
+		return true
+	}
+	return strings.HasPrefix(path, breakersPathPrefix)
+}
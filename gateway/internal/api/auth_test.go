@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuthenticate(t *testing.T) {
+	h := NewHandler(nil, 0)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	protected := h.Authenticate(ok)
+
+	status := func(path, authorization string) int {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		protected.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Without a token, endpoints stay open
+	if code := status("/v1/invoke", ""); code != http.StatusOK {
+		t.Fatalf("expected open endpoint without a token, got %d", code)
+	}
+
+	if err := h.SetAuthToken(""); err == nil {
+		t.Error("expected an empty token to be rejected")
+	}
+	if err := h.SetAuthToken("s3cret"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		want          int
+	}{
+		{name: "missing token", path: "/v1/invoke", want: http.StatusUnauthorized},
+		{name: "wrong token", path: "/mcp", authorization: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "wrong scheme", path: "/mcp/sse", authorization: "Basic s3cret", want: http.StatusUnauthorized},
+		{name: "valid token", path: "/mcp/message", authorization: "Bearer s3cret", want: http.StatusOK},
+		{name: "health check", path: "/healthz", want: http.StatusOK},
+		{name: "diagnostics use the admin token", path: "/v1/errors", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status(tt.path, tt.authorization); code != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, code)
+			}
+		})
+	}
+
+	// A reloaded token replaces the old one
+	_ = h.SetAuthToken("rotated")
+	if code := status("/v1/invoke", "Bearer s3cret"); code != http.StatusUnauthorized {
+		t.Errorf("expected old token to be rejected, got %d", code)
+	}
+	if code := status("/v1/invoke", "Bearer rotated"); code != http.StatusOK {
+		t.Errorf("expected new token to be accepted, got %d", code)
+	}
+}
+
+func TestReadTokenFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token")
+
+	if err := os.WriteFile(path, []byte("  s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := ReadTokenFile(path); err != nil || token != "s3cret" {
+		t.Errorf("expected s3cret, got %q (%v)", token, err)
+	}
+
+	if err := os.WriteFile(path, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadTokenFile(path); err == nil {
+		t.Error("expected an error for an empty token file")
+	}
+	if _, err := ReadTokenFile(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error for a missing token file")
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// breakersPathPrefix prefixes the admin endpoints that reset circuit
+// breakers: POST /v1/admin/breakers/{route}/reset for one route and
+// POST /v1/admin/breakers/reset for all of them.
+const breakersPathPrefix = "/v1/admin/breakers/"
+
+// BreakerStatus is a route's circuit breaker after a reset.
+type BreakerStatus struct {
+	Route   string `json:"route"`
+	State   string `json:"state"`
+	Active  int32  `json:"active"`
+	Waiting int32  `json:"waiting"`
+}
+
+// handleResetBreakers closes tripped circuit breakers without a restart.
+// Like the diagnostics endpoints it requires the admin token.
+func (h *Handler) handleResetBreakers(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	target := strings.TrimPrefix(r.URL.Path, breakersPathPrefix)
+	if target == "reset" {
+		statuses := []BreakerStatus{}
+		for _, route := range h.breakers.ResetAll() {
+			statuses = append(statuses, h.breakerStatus(route))
+		}
+		h.writeJSON(w, http.StatusOK, map[string]interface{}{"breakers": statuses})
+		return
+	}
+
+	route, ok := strings.CutSuffix(target, "/reset")
+	if !ok || route == "" || strings.Contains(route, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	if !h.breakers.Reset(route) {
+		h.writeError(w, http.StatusNotFound, fmt.Sprintf("no circuit breaker for route %q", route))
+		return
+	}
+	h.writeJSON(w, http.StatusOK, h.breakerStatus(route))
+}
+
+func (h *Handler) breakerStatus(route string) BreakerStatus {
+	breaker := h.breakers.Get(route)
+	stats := breaker.Stats()
+	return BreakerStatus{
+		Route:   route,
+		State:   breaker.State().String(),
+		Active:  stats.Active,
+		Waiting: stats.Waiting,
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultErrorBufferSize is how many recent errors GET /v1/errors retains.
+const DefaultErrorBufferSize = 100
+
+// ErrorRecord describes one failed gateway request.
+type ErrorRecord struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Agent      string    `json:"agent,omitempty"`
+	Route      string    `json:"route,omitempty"`
+	ErrorType  string    `json:"errorType"`
+	StatusCode int       `json:"statusCode"`
+	Message    string    `json:"message"`
+}
+
+// ErrorBuffer is a fixed-size, thread-safe ring buffer of recent errors.
+// Once full, each new record overwrites the oldest.
+type ErrorBuffer struct {
+	mu      sync.Mutex
+	records []ErrorRecord
+	next    int
+	full    bool
+}
+
+// NewErrorBuffer creates a buffer holding the last size records.
+// Non-positive sizes use DefaultErrorBufferSize.
+func NewErrorBuffer(size int) *ErrorBuffer {
+	if size <= 0 {
+		size = DefaultErrorBufferSize
+	}
+	return &ErrorBuffer{records: make([]ErrorRecord, size)}
+}
+
+// Add stores a record, evicting the oldest when the buffer is full.
+func (b *ErrorBuffer) Add(rec ErrorRecord) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.records[b.next] = rec
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// Recent returns the buffered records, newest first.
+func (b *ErrorBuffer) Recent() []ErrorRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := b.next
+	if b.full {
+		n = len(b.records)
+	}
+	out := make([]ErrorRecord, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, b.records[(b.next-i+len(b.records))%len(b.records)])
+	}
+	return out
+}
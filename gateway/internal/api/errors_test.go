@@ -0,0 +1,55 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestErrorBuffer(t *testing.T) {
+	b := NewErrorBuffer(3)
+	if got := b.Recent(); len(got) != 0 {
+		t.Fatalf("expected empty buffer, got %v", got)
+	}
+
+	for i := 1; i <= 2; i++ {
+		b.Add(ErrorRecord{Message: fmt.Sprintf("err-%d", i)})
+	}
+	assertMessages(t, b.Recent(), "err-2", "err-1")
+
+	// Overflow evicts the oldest records
+	for i := 3; i <= 5; i++ {
+		b.Add(ErrorRecord{Message: fmt.Sprintf("err-%d", i)})
+	}
+	assertMessages(t, b.Recent(), "err-5", "err-4", "err-3")
+}
+
+func TestErrorBuffer_Concurrent(t *testing.T) {
+	b := NewErrorBuffer(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Add(ErrorRecord{Message: "boom"})
+			_ = b.Recent()
+		}()
+	}
+	wg.Wait()
+
+	if got := len(b.Recent()); got != 10 {
+		t.Errorf("expected buffer capped at 10, got %d", got)
+	}
+}
+
+func assertMessages(t *testing.T, records []ErrorRecord, want ...string) {
+	t.Helper()
+	if len(records) != len(want) {
+		t.Fatalf("expected %d records, got %d: %v", len(want), len(records), records)
+	}
+	for i, msg := range want {
+		if records[i].Message != msg {
+			t.Errorf("record %d: expected %q, got %q", i, msg, records[i].Message)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/circuit"
+	"github.com/jarsater/mcp-fabric/gateway/internal/metrics"
+	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
+)
+
+// maxBackendAttempts bounds how many backends of a rule one request is sent
+// to when the earlier ones fail.
+const maxBackendAttempts = 3
+
+// agentStatusError reports an error response from an agent.
+type agentStatusError struct {
+	status int
+	body   string
+}
+
+func (e *agentStatusError) Error() string {
+	return fmt.Sprintf("agent returned %d: %s", e.status, e.body)
+}
+
+// errBackendSaturated is returned when a backend reached its concurrency
+// cap between selection and forwarding and no other backend could take
+// the request instead.
+var errBackendSaturated = errors.New("all backends are at their concurrency limit")
+
+// shouldFailover reports whether a failed agent call may be sent to another
+// backend: connection errors and 5xx responses are, while rate limiting,
+// client errors, oversized responses and a passed deadline are not.
+func shouldFailover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var statusErr *agentStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.status >= http.StatusInternalServerError
+	}
+	var rateLimited *routes.RateLimitedError
+	return !errors.As(err, &rateLimited) && !errors.Is(err, routes.ErrResponseTooLarge)
+}
+
+// failover describes where a request may go when its backend fails.
+type failover struct {
+	breaker  *circuit.Breaker
+	route    string
+	backends []routes.CompiledRouteBackend
+	strategy routes.SelectionStrategy
+	key      string
+	exclude  []string
+}
+
+// forwardWithFailover forwards req to backend and, while it fails with a
+// connection error or a 5xx response, to another ready backend of the rule
+// not tried yet, up to maxBackendAttempts backends. The route's circuit
+// breaker slot held by the caller is released and re-acquired for each
+// further backend; held reports whether a slot is still held on return,
+// since failover stops if none can be re-acquired. A backend found at its
+// concurrency cap when the request is about to be sent is skipped for
+// another one without counting as an attempt. It returns the backend whose
+// response was used or, on failure, the last one tried.
+func (h *Handler) forwardWithFailover(ctx context.Context, f failover, backend *routes.CompiledRouteBackend, req *InvokeRequest, header http.Header) (result interface{}, used *routes.CompiledRouteBackend, held bool, err error) {
+	tried := make([]routes.CompiledRouteBackend, 0, maxBackendAttempts)
+	attempts := 0
+	for {
+		done, ok := h.selector.TryTrack(backend)
+		if !ok {
+			// Other requests took its last slot since it was selected
+			tried = append(tried, *backend)
+			next := h.nextBackend(f, tried)
+			if next == nil {
+				if err == nil {
+					err = errBackendSaturated
+				}
+				return result, backend, true, err
+			}
+			backend = next
+			continue
+		}
+		result, err = h.forwardToAgent(ctx, backend, req, header)
+		done()
+		attempts++
+		if err == nil || attempts >= maxBackendAttempts || !shouldFailover(ctx, err) {
+			return result, backend, true, err
+		}
+		tried = append(tried, *backend)
+
+		next := h.nextBackend(f, tried)
+		if next == nil {
+			return result, backend, true, err
+		}
+		metrics.RecordRequestError(backend.AgentName, f.route, "failover")
+
+		f.breaker.Release()
+		if f.breaker.Acquire(ctx) != nil {
+			return result, backend, false, err
+		}
+		backend = next
+		metrics.RecordBackendForward(backend.AgentName, backend.Namespace)
+	}
+}
+
+// nextBackend selects the backend to fail over to from those not yet tried,
+// not excluded, below their concurrency cap and not ejected, or nil if none
+// is left. Unlike the first selection, failover never falls back to an
+// excluded backend.
+func (h *Handler) nextBackend(f failover, tried []routes.CompiledRouteBackend) *routes.CompiledRouteBackend {
+	remaining := make([]routes.CompiledRouteBackend, 0, len(f.backends))
+	for _, b := range h.selector.Healthy(h.selector.Available(f.backends)) {
+		if !containsEndpoint(tried, b.Endpoint) && !slices.ContainsFunc(f.exclude, b.HasName) {
+			remaining = append(remaining, b)
+		}
+	}
+	if len(remaining) == 0 {
+		return nil
+	}
+	return h.selector.Select(remaining, f.strategy, f.key, f.exclude)
+}
+
+func containsEndpoint(backends []routes.CompiledRouteBackend, endpoint string) bool {
+	for _, b := range backends {
+		if b.Endpoint == endpoint {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
+)
+
+// downBackend returns a backend whose server has already shut down, so
+// connections to it are refused.
+func downBackend(name string) routes.CompiledRouteBackend {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return routes.CompiledRouteBackend{
+		AgentName: name,
+		Namespace: "default",
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Weight:    100,
+		Ready:     true,
+	}
+}
+
+// statusBackend answers every call with status, counting its calls.
+func statusBackend(t *testing.T, name string, status int, calls *atomic.Int32) routes.CompiledRouteBackend {
+	return newStubBackend(t, name, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, name+" failed", status)
+	})
+}
+
+// roundRobinConfig returns singleRuleConfig with round-robin selection, so
+// the first request goes to the first backend.
+func roundRobinConfig(backends ...routes.CompiledRouteBackend) *routes.RouteConfig {
+	config := singleRuleConfig(backends...)
+	config.Rules[0].SelectionStrategy = "round-robin"
+	return config
+}
+
+func TestHandleInvoke_FailsOverToNextBackend(t *testing.T) {
+	tests := []struct {
+		name  string
+		first func(t *testing.T, calls *atomic.Int32) routes.CompiledRouteBackend
+	}{
+		{name: "connection refused", first: func(t *testing.T, _ *atomic.Int32) routes.CompiledRouteBackend { return downBackend("down") }},
+		{name: "500", first: func(t *testing.T, calls *atomic.Int32) routes.CompiledRouteBackend {
+			return statusBackend(t, "broken", http.StatusInternalServerError, calls)
+		}},
+		{name: "503", first: func(t *testing.T, calls *atomic.Int32) routes.CompiledRouteBackend {
+			return statusBackend(t, "broken", http.StatusServiceUnavailable, calls)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var firstCalls, healthyCalls atomic.Int32
+			first := tt.first(t, &firstCalls)
+			healthy := countingBackend(t, "healthy", 0, &healthyCalls)
+			h := newTestHandler(t, roundRobinConfig(first, healthy))
+
+			rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+			if rec.Code != http.StatusOK || !resp.Success {
+				t.Fatalf("expected success after failover, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if resp.Agent != "healthy" || healthyCalls.Load() != 1 {
+				t.Errorf("expected the second backend to answer once, got agent %q after %d calls", resp.Agent, healthyCalls.Load())
+			}
+			if active := h.breakers.Get("echo-rule").Stats().Active; active != 0 {
+				t.Errorf("expected the breaker slot released, got %d active", active)
+			}
+		})
+	}
+}
+
+func TestHandleInvoke_NoFailoverOnClientErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		wantCode int
+	}{
+		{name: "bad request", status: http.StatusBadRequest, wantCode: http.StatusBadGateway},
+		{name: "rate limited", status: http.StatusTooManyRequests, wantCode: http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var firstCalls, otherCalls atomic.Int32
+			h := newTestHandler(t, roundRobinConfig(
+				statusBackend(t, "picky", tt.status, &firstCalls),
+				countingBackend(t, "other", 0, &otherCalls),
+			))
+
+			rec, _ := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+			if firstCalls.Load() != 1 || otherCalls.Load() != 0 {
+				t.Errorf("expected no failover, got %d and %d calls", firstCalls.Load(), otherCalls.Load())
+			}
+		})
+	}
+}
+
+func TestHandleInvoke_FailoverIsBounded(t *testing.T) {
+	var calls atomic.Int32
+	backends := make([]routes.CompiledRouteBackend, 5)
+	for i := range backends {
+		backends[i] = statusBackend(t, "broken", http.StatusBadGateway, &calls)
+	}
+	h := newTestHandler(t, roundRobinConfig(backends...))
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+	if rec.Code != http.StatusBadGateway || !strings.Contains(resp.Error, "agent returned 502") {
+		t.Fatalf("expected the last agent error, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if calls.Load() != maxBackendAttempts {
+		t.Errorf("expected %d backends tried, got %d", maxBackendAttempts, calls.Load())
+	}
+	if active := h.breakers.Get("echo-rule").Stats().Active; active != 0 {
+		t.Errorf("expected the breaker slot released, got %d active", active)
+	}
+}
+
+func TestHandleInvoke_PinnedBackendDoesNotFailOver(t *testing.T) {
+	var otherCalls atomic.Int32
+	down := downBackend("down")
+	h := newTestHandler(t, roundRobinConfig(down, countingBackend(t, "other", 0, &otherCalls)))
+	h.SetDebugHeaders(true)
+
+	rec, _ := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, map[string]string{BackendPinHeader: "down"})
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502 from the pinned backend, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if otherCalls.Load() != 0 {
+		t.Errorf("expected no failover from a pinned backend, got %d calls", otherCalls.Load())
+	}
+}
+
+func TestHandleInvoke_NoFailoverToExcludedBackend(t *testing.T) {
+	var excludedCalls atomic.Int32
+	down := downBackend("down")
+	h := newTestHandler(t, roundRobinConfig(down, countingBackend(t, "excluded", 0, &excludedCalls)))
+	h.SetDebugHeaders(true)
+
+	// The only untried backend after "down" fails is excluded
+	rec, _ := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, map[string]string{BackendExcludeHeader: "excluded"})
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502 from the failed backend, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if excludedCalls.Load() != 0 {
+		t.Errorf("expected no failover to an excluded backend, got %d calls", excludedCalls.Load())
+	}
+}
+
+func TestShouldFailover_DeadlinePassed(t *testing.T) {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if shouldFailover(ctx, &agentStatusError{status: http.StatusServiceUnavailable}) {
+		t.Error("expected no failover once the request deadline has passed")
+	}
+}
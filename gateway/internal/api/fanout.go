@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/metrics"
+	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
+)
+
+// FanOutResult is one backend's outcome for a fan-out rule.
+type FanOutResult struct {
+	Agent     string      `json:"agent"`
+	Namespace string      `json:"namespace"`
+	Success   bool        `json:"success"`
+	Result    interface{} `json:"result,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	LatencyMs int64       `json:"latencyMs"`
+
+	err error
+}
+
+// handleFanOut serves a request matched by a fan-out rule and returns the
+// response status. It succeeds if any backend does; the per-backend
+// outcomes are returned in Results either way. The fan-out as a whole is
+// one outcome for the route's circuit breaker, failed only if every
+// backend failed.
+func (h *Handler) handleFanOut(ctx context.Context, w http.ResponseWriter, r *http.Request, match *routes.MatchResult, backends []routes.CompiledRouteBackend, req *InvokeRequest, start time.Time, clientDeadline bool) int {
+	routeName := match.RuleName
+
+	// A fan-out holds one circuit breaker slot for all its calls
+	breaker := h.breakers.Get(routeName)
+	if err := breaker.Acquire(ctx); err != nil {
+		h.requestError(w, http.StatusServiceUnavailable, "", routeName, breakerErrorType(routeName, err), err.Error())
+		return http.StatusServiceUnavailable
+	}
+	defer breaker.Release()
+
+	results := h.fanOut(ctx, backends, int(match.FanOutConcurrency), req, withCorrelationID(req, r.Header))
+
+	resp := InvokeResponse{
+		Success:       false,
+		CorrelationID: req.CorrelationID,
+		LatencyMs:     time.Since(start).Milliseconds(),
+		Results:       results,
+	}
+	for _, result := range results {
+		if result.Success {
+			resp.Success = true
+			break
+		}
+	}
+
+	if !resp.Success {
+		status, errorType := http.StatusBadGateway, "agent_error"
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			status, errorType = http.StatusGatewayTimeout, "deadline_exceeded"
+		}
+		recordBreakerOutcome(ctx, breaker, clientDeadline, status, fanOutError(results))
+		resp.Error = "all fan-out backends failed"
+		h.recordError(status, "", routeName, errorType, resp.Error)
+		h.writeJSON(w, status, resp)
+		return status
+	}
+
+	recordBreakerOutcome(ctx, breaker, clientDeadline, http.StatusOK, nil)
+	h.writeJSON(w, http.StatusOK, resp)
+	return http.StatusOK
+}
+
+// fanOutError returns the error a failed fan-out is recorded with:
+// errBackendSaturated if no backend could take the request, since that is
+// not the agents' doing, or the first backend's error otherwise.
+func fanOutError(results []FanOutResult) error {
+	for _, result := range results {
+		if !errors.Is(result.err, errBackendSaturated) {
+			return result.err
+		}
+	}
+	return errBackendSaturated
+}
+
+// fanOut forwards req to every backend, at most concurrency at a time
+// (0 = all at once), and returns their results in backend order. Backends
+// still waiting for a slot when ctx ends are reported as failed.
+func (h *Handler) fanOut(ctx context.Context, backends []routes.CompiledRouteBackend, concurrency int, req *InvokeRequest, header http.Header) []FanOutResult {
+	if concurrency <= 0 || concurrency > len(backends) {
+		concurrency = len(backends)
+	}
+
+	results := make([]FanOutResult, len(backends))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range backends {
+		backend := &backends[i]
+		results[i] = FanOutResult{Agent: backend.AgentName, Namespace: backend.Namespace}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				results[i].err = ctx.Err()
+				results[i].Error = "agent error: " + results[i].err.Error()
+				return
+			}
+
+			done, ok := h.selector.TryTrack(backend)
+			if !ok {
+				results[i].err = errBackendSaturated
+				results[i].Error = "agent error: " + errBackendSaturated.Error()
+				return
+			}
+			metrics.RecordBackendForward(backend.AgentName, backend.Namespace)
+			start := time.Now()
+			result, err := h.forwardToAgent(ctx, backend, req, header)
+			done()
+
+			results[i].LatencyMs = time.Since(start).Milliseconds()
+			if err != nil {
+				results[i].err = err
+				results[i].Error = "agent error: " + err.Error()
+				return
+			}
+			results[i].Success = true
+			results[i].Result = result
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
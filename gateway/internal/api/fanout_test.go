@@ -0,0 +1,151 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
+)
+
+func fanOutConfig(concurrency int32, backends ...routes.CompiledRouteBackend) *routes.RouteConfig {
+	return &routes.RouteConfig{
+		Rules: []routes.CompiledRouteRule{{
+			Name:              "survey",
+			Match:             routes.CompiledRouteMatch{Agent: "survey"},
+			Backends:          backends,
+			Mode:              routes.RouteModeFanOut,
+			FanOutConcurrency: concurrency,
+		}},
+	}
+}
+
+func TestHandleInvoke_FanOut(t *testing.T) {
+	backends := namedBackends(t, "alpha", "beta")
+	backends = append(backends, newStubBackend(t, "broken", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	h := newTestHandler(t, fanOutConfig(0, backends...))
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "survey", Query: "ping", CorrelationID: "c1"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !resp.Success || resp.CorrelationID != "c1" {
+		t.Errorf("expected success with correlation ID, got %+v", resp)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", resp.Results)
+	}
+
+	for i, want := range []string{"alpha", "beta"} {
+		got := resp.Results[i]
+		if got.Agent != want || !got.Success {
+			t.Errorf("expected successful result from %s, got %+v", want, got)
+		}
+		if result, _ := got.Result.(map[string]interface{}); result["result"] != want {
+			t.Errorf("expected %s's result, got %v", want, got.Result)
+		}
+	}
+	if broken := resp.Results[2]; broken.Agent != "broken" || broken.Success || broken.Error == "" {
+		t.Errorf("expected failed result from broken, got %+v", broken)
+	}
+}
+
+func TestHandleInvoke_FanOutAllFail(t *testing.T) {
+	fail := func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}
+	h := newTestHandler(t, fanOutConfig(0, newStubBackend(t, "a", fail), newStubBackend(t, "b", fail)))
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "survey", Query: "ping"})
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if resp.Success || len(resp.Results) != 2 {
+		t.Errorf("expected failure with per-agent results, got %+v", resp)
+	}
+}
+
+func TestHandleInvoke_FanOutConcurrency(t *testing.T) {
+	var active, peak atomic.Int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"result":"ok"}`))
+	}
+	var backends []routes.CompiledRouteBackend
+	for _, name := range []string{"a", "b", "c", "d"} {
+		backends = append(backends, newStubBackend(t, name, handler))
+	}
+	h := newTestHandler(t, fanOutConfig(2, backends...))
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "survey", Query: "ping"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	for _, result := range resp.Results {
+		if !result.Success {
+			t.Errorf("expected every backend to succeed, got %+v", result)
+		}
+	}
+	if got := peak.Load(); got != 2 {
+		t.Errorf("expected at most 2 concurrent calls, peak was %d", got)
+	}
+}
+
+func TestHandleInvoke_FanOutDeadline(t *testing.T) {
+	backends := namedBackends(t, "fast")
+	backends = append(backends, newStubBackend(t, "slow", func(w http.ResponseWriter, r *http.Request) {
+		// Drain the body so the server notices the client disconnecting.
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			_, _ = w.Write([]byte(`{"result":"late"}`))
+		}
+	}))
+	h := newTestHandler(t, fanOutConfig(0, backends...))
+
+	start := time.Now()
+	rec, resp := invokeWithHeaders(t, h, InvokeRequest{Agent: "survey", Query: "ping"}, map[string]string{
+		RequestDeadlineHeader: "100ms",
+	})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the deadline to bound the fan-out, took %s", elapsed)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with a partial result, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !resp.Results[0].Success || resp.Results[1].Success {
+		t.Errorf("expected fast to succeed and slow to fail, got %+v", resp.Results)
+	}
+}
+
+func TestHandleInvoke_FanOutFailuresOpenCircuit(t *testing.T) {
+	var calls atomic.Int32
+	config := fanOutConfig(0, statusBackend(t, "broken", http.StatusInternalServerError, &calls))
+	config.Defaults = &routes.RouteDefaultConfig{ErrorThresholdPercent: 50, MinimumRequests: 2, CooldownMs: 60000}
+	h := newTestHandler(t, config)
+
+	for i := 0; i < 2; i++ {
+		if rec, _ := invoke(t, h, InvokeRequest{Agent: "survey", Query: "ping"}); rec.Code != http.StatusBadGateway {
+			t.Fatalf("expected 502 when every backend fails, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if rec, _ := invoke(t, h, InvokeRequest{Agent: "survey", Query: "ping"}); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 from the open circuit, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected the agent not called while the circuit is open, got %d calls", got)
+	}
+}
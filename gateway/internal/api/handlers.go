@@ -3,12 +3,18 @@ package api
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jarsater/mcp-fabric/gateway/internal/circuit"
@@ -16,6 +22,42 @@ import (
 	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
 )
 
+// StrategyOverrideHeader lets a trusted caller force the selection strategy
+// for one request. It is honored only when debug headers are enabled.
+const StrategyOverrideHeader = "X-Route-Strategy"
+
+// BackendPinHeader lets a trusted caller send one request to a named
+// backend ("agent" or "namespace/agent") of the matched rule. It is honored
+// only when debug headers are enabled.
+const BackendPinHeader = "X-Route-Backend"
+
+// BackendExcludeHeader lets a trusted caller keep one request away from a
+// comma-separated list of backends ("agent" or "namespace/agent") of the
+// matched rule. If every backend is excluded, selection ignores the list.
+// It is honored only when debug headers are enabled.
+const BackendExcludeHeader = "X-Route-Exclude"
+
+// RequestDeadlineHeader carries the caller's own deadline, either as an
+// RFC 3339 timestamp or as a relative duration such as "1500ms". The gateway
+// stops working on the request once it passes, but never waits longer than
+// its configured request timeout.
+const RequestDeadlineHeader = "X-Request-Deadline"
+
+// RequestTimeoutHeader overrides the request timeout for one request with a
+// Go duration such as "90s", capped by the gateway's maximum request timeout.
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// CorrelationIDHeader carries the request's correlation ID. It is read when
+// the request body has none and always sent to agents.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// TraceparentHeader carries the caller's W3C trace context. Its trace ID is
+// attached to the request duration metric as an exemplar.
+const TraceparentHeader = "traceparent"
+
+// prewarmTimeout bounds each connection pre-warming request.
+const prewarmTimeout = 5 * time.Second
+
 // InvokeRequest is the request body for POST /v1/invoke.
 type InvokeRequest struct {
 	Agent         string                 `json:"agent,omitempty"`
@@ -36,6 +78,8 @@ type InvokeResponse struct {
 	CorrelationID string                 `json:"correlationId,omitempty"`
 	LatencyMs     int64                  `json:"latencyMs,omitempty"`
 	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	// Results holds each backend's outcome for fan-out rules.
+	Results []FanOutResult `json:"results,omitempty"`
 }
 
 // Handler handles HTTP requests for the agent gateway.
@@ -45,6 +89,46 @@ type Handler struct {
 	breakers   *circuit.BreakerManager
 	httpClient *http.Client
 	reqTimeout time.Duration
+	// maxReqTimeout caps RequestTimeoutHeader (0 = reqTimeout).
+	maxReqTimeout time.Duration
+	// strictTimeouts rejects invalid RequestTimeoutHeader values with 400
+	// instead of ignoring them.
+	strictTimeouts bool
+	maxRespLen     int64
+	zone           string
+
+	// debugHeaders enables per-request routing overrides via headers.
+	debugHeaders bool
+
+	// forwardHeaders lists client headers copied onto agent requests.
+	forwardHeaders routes.HeaderAllowlist
+
+	// tenantHeader supplies the tenant ID when the request body has none.
+	tenantHeader string
+
+	// rateLimit decides whether agent 429 responses are retried.
+	rateLimit routes.RateLimitPolicy
+
+	// maxInFlight caps concurrent requests across all routes (0 = unlimited).
+	maxInFlight int64
+	inFlight    atomic.Int64
+
+	// prewarmWorkers bounds concurrent pre-warm dials (0 = disabled).
+	prewarmWorkers int
+
+	// recentErrors backs GET /v1/errors, which requires adminToken.
+	recentErrors *ErrorBuffer
+	adminToken   string
+
+	// authToken (a string) is required by Authenticate once set.
+	authToken atomic.Value
+
+	// results holds async invoke results until they are collected.
+	results *ResultStore
+
+	// maxAsyncJobs caps async invokes running at once.
+	maxAsyncJobs int64
+	asyncRunning atomic.Int64
 }
 
 // NewHandler creates a new API handler.
@@ -60,38 +144,295 @@ func NewHandler(table *routes.Table, reqTimeout time.Duration) *Handler {
 		httpClient: &http.Client{
 			Timeout: reqTimeout,
 		},
-		reqTimeout: reqTimeout,
+		reqTimeout:   reqTimeout,
+		maxRespLen:   routes.DefaultMaxResponseBytes,
+		recentErrors: NewErrorBuffer(DefaultErrorBufferSize),
+		results:      NewResultStore(DefaultResultTTL, DefaultResultStoreSize),
+		maxAsyncJobs: DefaultMaxAsyncJobs,
+	}
+}
+
+// SetMaxResponseBytes sets the maximum accepted agent response size.
+// Non-positive values restore the default.
+func (h *Handler) SetMaxResponseBytes(n int64) {
+	if n <= 0 {
+		n = routes.DefaultMaxResponseBytes
+	}
+	h.maxRespLen = n
+}
+
+// SetMaxInFlight sets the global limit on concurrent requests, applied
+// before per-route circuit breakers. Zero disables the limit.
+func (h *Handler) SetMaxInFlight(n int) {
+	if n < 0 {
+		n = 0
+	}
+	h.maxInFlight = int64(n)
+}
+
+// SetPrewarmConcurrency enables connection pre-warming on route loads,
+// dialing at most n backends at a time. Zero disables pre-warming.
+func (h *Handler) SetPrewarmConcurrency(n int) {
+	if n < 0 {
+		n = 0
+	}
+	h.prewarmWorkers = n
+}
+
+// Prewarm primes the HTTP transport's idle connection pool by requesting
+// /healthz from each ready backend in the current route table. It returns
+// the number of backends that answered. It is a no-op unless pre-warming
+// is enabled.
+func (h *Handler) Prewarm(ctx context.Context) int {
+	if h.prewarmWorkers <= 0 {
+		return 0
+	}
+
+	endpoints := readyEndpoints(h.table.GetConfig())
+	if len(endpoints) == 0 {
+		return 0
+	}
+
+	jobs := make(chan string)
+	var (
+		wg     sync.WaitGroup
+		warmed atomic.Int64
+	)
+	for i := 0; i < min(h.prewarmWorkers, len(endpoints)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for endpoint := range jobs {
+				if h.prewarmEndpoint(ctx, endpoint) == nil {
+					warmed.Add(1)
+				}
+			}
+		}()
+	}
+	for _, endpoint := range endpoints {
+		jobs <- endpoint
+	}
+	close(jobs)
+	wg.Wait()
+
+	return int(warmed.Load())
+}
+
+func (h *Handler) prewarmEndpoint(ctx context.Context, endpoint string) error {
+	ctx, cancel := context.WithTimeout(ctx, prewarmTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, backendURL(endpoint, "/healthz"), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	// Drain the body so the connection goes back to the idle pool
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// readyEndpoints returns the distinct endpoints of ready backends.
+func readyEndpoints(config *routes.RouteConfig) []string {
+	if config == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var endpoints []string
+	add := func(b routes.CompiledRouteBackend) {
+		if !b.Ready || b.Endpoint == "" || seen[b.Endpoint] {
+			return
+		}
+		seen[b.Endpoint] = true
+		endpoints = append(endpoints, b.Endpoint)
+	}
+	for _, rule := range config.Rules {
+		for _, b := range rule.Backends {
+			add(b)
+		}
+	}
+	if config.Defaults != nil && config.Defaults.Backend != nil {
+		add(*config.Defaults.Backend)
 	}
+	return endpoints
+}
+
+// SetDebugHeaders enables routing override headers such as
+// X-Route-Strategy and X-Route-Backend. They bypass configured routing, so keep them disabled
+// unless callers are trusted.
+func (h *Handler) SetDebugHeaders(enabled bool) {
+	h.debugHeaders = enabled
+}
+
+// SetForwardHeaders sets the client request headers passed through to
+// agents. Nothing is forwarded by default, so auth headers never leak.
+func (h *Handler) SetForwardHeaders(allow routes.HeaderAllowlist) {
+	h.forwardHeaders = allow
+}
+
+// SetTenantHeader sets the request header, typically set by an auth proxy,
+// that supplies the tenant ID when the request body does not. The body
+// field always wins. An empty name disables header extraction.
+func (h *Handler) SetTenantHeader(name string) {
+	h.tenantHeader = name
+}
+
+// SetRateLimitPolicy sets how agent 429 responses are handled. By default
+// they are returned to the caller as 429 with the agent's Retry-After.
+func (h *Handler) SetRateLimitPolicy(policy routes.RateLimitPolicy) {
+	h.rateLimit = policy
+}
+
+// SetErrorBufferSize sets how many recent errors GET /v1/errors retains,
+// discarding any already recorded. Call it before serving requests.
+func (h *Handler) SetErrorBufferSize(n int) {
+	h.recentErrors = NewErrorBuffer(n)
+}
+
+// SetResultStore sets how long async invoke results are kept and how many
+// are kept at most, discarding any already stored. Call it before serving
+// requests.
+func (h *Handler) SetResultStore(ttl time.Duration, maxEntries int) {
+	h.results = NewResultStore(ttl, maxEntries)
+}
+
+// SetMaxRequestTimeout sets the longest timeout a caller may ask for with
+// RequestTimeoutHeader. Zero caps it at the request timeout, so the header
+// can only shorten requests.
+func (h *Handler) SetMaxRequestTimeout(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	h.maxReqTimeout = d
+	h.httpClient.Timeout = h.maxRequestTimeout()
+}
+
+// SetStrictRequestTimeout makes invalid RequestTimeoutHeader values fail the
+// request with 400. By default they are ignored.
+func (h *Handler) SetStrictRequestTimeout(strict bool) {
+	h.strictTimeouts = strict
+}
+
+// maxRequestTimeout returns the cap on RequestTimeoutHeader.
+func (h *Handler) maxRequestTimeout() time.Duration {
+	return max(h.maxReqTimeout, h.reqTimeout)
+}
+
+// requestTimeout returns the timeout for an invoke request: the caller's
+// RequestTimeoutHeader capped by the maximum, or else the request timeout.
+// An invalid header is reported and the request timeout returned.
+func (h *Handler) requestTimeout(r *http.Request) (time.Duration, error) {
+	value := r.Header.Get(RequestTimeoutHeader)
+	if value == "" {
+		return h.reqTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return h.reqTimeout, err
+	}
+	if timeout <= 0 {
+		return h.reqTimeout, fmt.Errorf("timeout %s is not positive", value)
+	}
+	return min(timeout, h.maxRequestTimeout()), nil
+}
+
+// SetAdminToken sets the bearer token required by diagnostics endpoints.
+// With no token those endpoints are disabled.
+func (h *Handler) SetAdminToken(token string) {
+	h.adminToken = token
+}
+
+// SetZone sets the topology zone the gateway runs in, used to prefer
+// same-zone backends on routes with locality-aware selection.
+func (h *Handler) SetZone(zone string) {
+	h.zone = zone
 }
 
 // UpdateDefaults updates circuit breaker defaults from route config.
 func (h *Handler) UpdateDefaults() {
-	defaults := h.table.GetDefaults()
-	if defaults == nil {
-		return
+	if defaults := h.table.GetDefaults(); defaults != nil {
+		h.breakers.UpdateConfig(circuit.Config{
+			MaxConcurrent: defaults.MaxConcurrent,
+			MaxQueueSize:  defaults.MaxQueueSize,
+			QueueTimeout:  time.Duration(defaults.QueueTimeoutMs) * time.Millisecond,
+
+			ErrorThresholdPercent: defaults.ErrorThresholdPercent,
+			MinimumRequests:       defaults.MinimumRequests,
+			ErrorWindow:           time.Duration(defaults.ErrorWindowMs) * time.Millisecond,
+			Cooldown:              time.Duration(defaults.CooldownMs) * time.Millisecond,
+		})
+
+		if defaults.RequestTimeoutMs > 0 {
+			h.reqTimeout = time.Duration(defaults.RequestTimeoutMs) * time.Millisecond
+			h.httpClient.Timeout = h.maxRequestTimeout()
+		}
 	}
 
-	h.breakers.UpdateConfig(circuit.Config{
-		MaxConcurrent: defaults.MaxConcurrent,
-		MaxQueueSize:  defaults.MaxQueueSize,
-		QueueTimeout:  time.Duration(defaults.QueueTimeoutMs) * time.Millisecond,
-	})
+	h.breakers.SetQueueTimeouts(ruleQueueTimeouts(h.table.GetConfig()))
+}
+
+// Drain stops admitting new invocations, which fail with 503 "draining",
+// while invocations already holding a circuit breaker slot run to completion.
+// Call it before shutting down the HTTP server.
+func (h *Handler) Drain() {
+	h.breakers.Drain()
+}
 
-	if defaults.RequestTimeoutMs > 0 {
-		h.reqTimeout = time.Duration(defaults.RequestTimeoutMs) * time.Millisecond
-		h.httpClient.Timeout = h.reqTimeout
+// ruleQueueTimeouts collects the circuit breaker queue timeouts set on
+// individual rules, keyed by rule name.
+func ruleQueueTimeouts(config *routes.RouteConfig) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	if config == nil {
+		return timeouts
+	}
+	for _, rule := range config.Rules {
+		if rule.QueueTimeoutMs > 0 {
+			timeouts[rule.Name] = time.Duration(rule.QueueTimeoutMs) * time.Millisecond
+		}
 	}
+	return timeouts
 }
 
 // ServeHTTP implements http.Handler.
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Health checks bypass the in-flight limit
+	if r.URL.Path != "/healthz" && h.maxInFlight > 0 {
+		if h.inFlight.Add(1) > h.maxInFlight {
+			h.inFlight.Add(-1)
+			metrics.RecordInflightRejection()
+			h.recentErrors.Add(ErrorRecord{
+				Timestamp:  time.Now(),
+				ErrorType:  "inflight_limit",
+				StatusCode: http.StatusServiceUnavailable,
+				Message:    "gateway overloaded: too many requests in flight",
+			})
+			h.writeError(w, http.StatusServiceUnavailable, "gateway overloaded: too many requests in flight")
+			return
+		}
+		defer h.inFlight.Add(-1)
+	}
+
 	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/invoke" && isAsyncInvoke(r):
+		h.handleAsyncInvoke(w, r)
 	case r.Method == http.MethodPost && r.URL.Path == "/v1/invoke":
 		h.handleInvoke(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, jobsPathPrefix):
+		h.handleGetJob(w, r)
 	case r.Method == http.MethodGet && r.URL.Path == "/v1/agents":
 		h.handleListAgents(w, r)
 	case r.Method == http.MethodGet && r.URL.Path == "/v1/routes":
 		h.handleListRoutes(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/errors":
+		h.handleListErrors(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/config":
+		h.handleGetConfig(w, r)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, breakersPathPrefix):
+		h.handleResetBreakers(w, r)
 	case r.Method == http.MethodGet && r.URL.Path == "/healthz":
 		h.handleHealthz(w, r)
 	default:
@@ -104,20 +445,65 @@ func (h *Handler) handleInvoke(w http.ResponseWriter, r *http.Request) {
 	var agentName, routeName string
 	var statusCode = http.StatusOK
 
+	// Link the request duration to the caller's trace, if any
+	ctx := metrics.ContextWithTraceID(r.Context(), traceID(r.Header))
+
 	// Ensure metrics are recorded on exit
 	defer func() {
 		duration := time.Since(start).Seconds()
-		metrics.RecordRequest(agentName, routeName, strconv.Itoa(statusCode), duration)
+		metrics.RecordRequest(ctx, agentName, routeName, strconv.Itoa(statusCode), duration)
 	}()
 
+	// Honor the caller's timeout, capped by the maximum request timeout.
+	// Expiry of a deadline the caller set is not held against the agents.
+	var clientDeadline bool
+	timeout, timeoutErr := h.requestTimeout(r)
+	if timeoutErr != nil && h.strictTimeouts {
+		statusCode = http.StatusBadRequest
+		h.requestError(w, statusCode, agentName, routeName, "invalid_request", fmt.Sprintf("invalid %s header: %v", RequestTimeoutHeader, timeoutErr))
+		return
+	}
+	if timeout != h.reqTimeout {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		clientDeadline = true
+	}
+
+	// Honor the caller's deadline, capped by the request timeout
+	if value := r.Header.Get(RequestDeadlineHeader); value != "" {
+		deadline, err := parseRequestDeadline(value, start)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			h.requestError(w, statusCode, agentName, routeName, "invalid_request", fmt.Sprintf("invalid %s header: %v", RequestDeadlineHeader, err))
+			return
+		}
+		if !deadline.After(start) {
+			statusCode = http.StatusGatewayTimeout
+			h.requestError(w, statusCode, agentName, routeName, "deadline_exceeded", "request deadline already passed")
+			return
+		}
+		if limit := start.Add(timeout); deadline.Before(limit) {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, deadline)
+			defer cancel()
+			clientDeadline = true
+		}
+	}
+
 	// Parse request
 	var req InvokeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		statusCode = http.StatusBadRequest
-		metrics.RecordRequestError(agentName, routeName, "invalid_request")
-		h.writeError(w, statusCode, "invalid request body: "+err.Error())
+		h.requestError(w, statusCode, agentName, routeName, "invalid_request", "invalid request body: "+err.Error())
 		return
 	}
+	if req.TenantID == "" && h.tenantHeader != "" {
+		req.TenantID = r.Header.Get(h.tenantHeader)
+	}
+	if req.CorrelationID == "" {
+		req.CorrelationID = r.Header.Get(CorrelationIDHeader)
+	}
 
 	// Match route
 	matchResult := h.table.Match(routes.MatchRequest{
@@ -132,68 +518,154 @@ func (h *Handler) handleInvoke(w http.ResponseWriter, r *http.Request) {
 		defaults := h.table.GetDefaults()
 		if defaults != nil && defaults.RejectUnmatched {
 			statusCode = http.StatusBadRequest
-			metrics.RecordRequestError(agentName, routeName, "no_route_match")
-			h.writeError(w, statusCode, "no matching route found")
+			h.requestError(w, statusCode, agentName, routeName, "no_route_match", "no matching route found")
 			return
 		}
 		statusCode = http.StatusNotFound
-		metrics.RecordRequestError(agentName, routeName, "no_agent")
-		h.writeError(w, statusCode, "no available agent for this request")
+		h.requestError(w, statusCode, agentName, routeName, "no_agent", "no available agent for this request")
 		return
 	}
 
 	routeName = matchResult.RuleName
 	metrics.RecordRouteMatch(routeName, matchResult.RuleName)
 
+	// Skip backends already at their concurrency cap
+	candidates := h.selector.Available(matchResult.Backends)
+	if len(candidates) == 0 {
+		statusCode = http.StatusServiceUnavailable
+		h.requestError(w, statusCode, agentName, routeName, "backend_saturated", "all backends are at their concurrency limit")
+		return
+	}
+
+	// Skip backends ejected after consecutive failures
+	candidates = h.selector.Healthy(candidates)
+
+	// Fan-out rules call every available backend instead of selecting one
+	if matchResult.Mode == routes.RouteModeFanOut {
+		statusCode = h.handleFanOut(ctx, w, r, matchResult, candidates, &req, start, clientDeadline)
+		return
+	}
+
+	// Prefer same-zone backends when the route opts in
+	if defaults := h.table.GetDefaults(); defaults != nil && defaults.LocalityAware {
+		candidates = routes.PreferZone(candidates, h.zone)
+	}
+
 	// Select backend
-	var backend *routes.CompiledRouteBackend
-	if req.TenantID != "" || req.CorrelationID != "" {
-		// Use consistent hashing for sticky sessions
-		hashKey := req.TenantID + ":" + req.CorrelationID
-		backend = h.selector.Select(matchResult.Backends, routes.StrategyConsistentHash, hashKey)
-	} else {
-		backend = h.selector.Select(matchResult.Backends, routes.StrategyWeightedRandom, "")
+	strategy := h.selectionStrategy(matchResult)
+	if h.debugHeaders {
+		if name := r.Header.Get(StrategyOverrideHeader); name != "" {
+			override, ok := routes.ParseStrategy(name)
+			if !ok {
+				statusCode = http.StatusBadRequest
+				h.requestError(w, statusCode, agentName, routeName, "invalid_request", fmt.Sprintf("invalid %s header: %q", StrategyOverrideHeader, name))
+				return
+			}
+			strategy = override
+		}
+	}
+	var exclude []string
+	if h.debugHeaders {
+		if value := r.Header.Get(BackendExcludeHeader); value != "" {
+			for _, name := range strings.Split(value, ",") {
+				name = strings.TrimSpace(name)
+				if name == "" {
+					continue
+				}
+				if pinnedBackend(matchResult.Backends, name) == nil {
+					statusCode = http.StatusBadRequest
+					h.requestError(w, statusCode, agentName, routeName, "invalid_request", fmt.Sprintf("%s %q is not a ready backend of rule %s", BackendExcludeHeader, name, matchResult.RuleName))
+					return
+				}
+				exclude = append(exclude, name)
+			}
+		}
+	}
+	key := selectionKey(strategy, matchResult, &req)
+	backend := h.selector.Select(candidates, strategy, key, exclude)
+
+	// A pinned backend bypasses selection entirely
+	if h.debugHeaders {
+		if name := r.Header.Get(BackendPinHeader); name != "" {
+			backend = pinnedBackend(matchResult.Backends, name)
+			if backend == nil {
+				statusCode = http.StatusBadRequest
+				h.requestError(w, statusCode, agentName, routeName, "invalid_request", fmt.Sprintf("%s %q is not a ready backend of rule %s", BackendPinHeader, name, matchResult.RuleName))
+				return
+			}
+		}
 	}
 
 	if backend == nil {
 		statusCode = http.StatusServiceUnavailable
-		metrics.RecordRequestError(agentName, routeName, "no_backend")
-		h.writeError(w, statusCode, "no backend available")
+		h.requestError(w, statusCode, agentName, routeName, "no_backend", "no backend available")
 		return
 	}
 
 	agentName = backend.AgentName
 
+	// Generated only now, so that it doesn't affect backend selection
+	header := withCorrelationID(&req, r.Header)
+
 	// Acquire circuit breaker slot
 	breaker := h.breakers.Get(matchResult.RuleName)
-	if err := breaker.Acquire(r.Context()); err != nil {
+	if err := breaker.Acquire(ctx); err != nil {
 		statusCode = http.StatusServiceUnavailable
-		var errorType string
-		switch err {
-		case circuit.ErrQueueFull:
-			errorType = "queue_full"
-			metrics.RecordCircuitBreakerRejection(routeName, "queue_full")
-		case circuit.ErrQueueTimeout:
-			errorType = "queue_timeout"
-			metrics.RecordCircuitBreakerRejection(routeName, "timeout")
-		default:
-			errorType = "circuit_breaker"
-		}
-		metrics.RecordRequestError(agentName, routeName, errorType)
-		h.writeError(w, statusCode, err.Error())
+		h.requestError(w, statusCode, agentName, routeName, breakerErrorType(routeName, err), err.Error())
 		return
 	}
-	defer breaker.Release()
+	held := true
+	defer func() {
+		if held {
+			breaker.Release()
+		}
+	}()
+
+	// Feed the outcome to the breaker's error rate
+	var err error
+	defer func() {
+		recordBreakerOutcome(ctx, breaker, clientDeadline, statusCode, err)
+	}()
 
 	// Record backend forward
 	metrics.RecordBackendForward(agentName, backend.Namespace)
 
-	// Forward request to agent
-	result, err := h.forwardToAgent(r.Context(), backend, &req)
+	// Relay the agent's output as it arrives if the client asks for a stream
+	if flusher, ok := w.(http.Flusher); ok && acceptsEventStream(r) {
+		statusCode, err = h.streamInvoke(ctx, w, flusher, backend, routeName, &req, header, start)
+		return
+	}
+
+	// Forward request to agent, hedging to a second backend if the rule asks
+	// or failing over to another one if it fails. A pinned backend is only
+	// ever sent the request.
+	var result interface{}
+	switch {
+	case h.debugHeaders && r.Header.Get(BackendPinHeader) != "":
+		if done, ok := h.selector.TryTrack(backend); ok {
+			result, err = h.forwardToAgent(ctx, backend, &req, header)
+			done()
+		} else {
+			err = errBackendSaturated
+		}
+	case matchResult.HedgeAfterMs > 0:
+		hedgeAfter := time.Duration(matchResult.HedgeAfterMs) * time.Millisecond
+		result, backend, err = h.forwardHedged(ctx, breaker, routeName, backend, candidates, exclude, hedgeAfter, &req, header)
+		agentName = backend.AgentName
+	default:
+		f := failover{
+			breaker:  breaker,
+			route:    routeName,
+			backends: matchResult.Backends,
+			strategy: strategy,
+			key:      key,
+			exclude:  exclude,
+		}
+		result, backend, held, err = h.forwardWithFailover(ctx, f, backend, &req, header)
+		agentName = backend.AgentName
+	}
 	if err != nil {
-		statusCode = http.StatusBadGateway
-		metrics.RecordRequestError(agentName, routeName, "agent_error")
-		h.writeError(w, statusCode, "agent error: "+err.Error())
+		statusCode = h.agentError(ctx, w, err, agentName, routeName)
 		return
 	}
 
@@ -209,63 +681,312 @@ func (h *Handler) handleInvoke(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, statusCode, resp)
 }
 
-func (h *Handler) forwardToAgent(ctx context.Context, backend *routes.CompiledRouteBackend, req *InvokeRequest) (interface{}, error) {
-	// Build request to agent
-	agentReq := map[string]interface{}{
+// recordBreakerOutcome feeds the outcome of a request that held a slot of
+// breaker to its error rate. Only outcomes the agents caused count: a
+// caller hanging up, a deadline the caller set expiring and the gateway's
+// own saturation say nothing about them and are not recorded.
+func recordBreakerOutcome(ctx context.Context, breaker *circuit.Breaker, clientDeadline bool, status int, err error) {
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+	case clientDeadline && errors.Is(ctx.Err(), context.DeadlineExceeded):
+	case errors.Is(err, errBackendSaturated):
+	default:
+		breaker.Record(status >= http.StatusInternalServerError)
+	}
+}
+
+// agentError writes the response for a failed agent call and returns its
+// status.
+func (h *Handler) agentError(ctx context.Context, w http.ResponseWriter, err error, agent, route string) int {
+	statusCode, errorType := agentErrorStatus(ctx, err)
+	var rateLimited *routes.RateLimitedError
+	if errors.As(err, &rateLimited) && rateLimited.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(rateLimited.RetryAfterSeconds(), 10))
+	}
+	h.requestError(w, statusCode, agent, route, errorType, "agent error: "+err.Error())
+	return statusCode
+}
+
+// agentErrorStatus returns the status and error type a failed agent call
+// is reported with.
+func agentErrorStatus(ctx context.Context, err error) (int, string) {
+	var rateLimited *routes.RateLimitedError
+	switch {
+	case errors.As(err, &rateLimited):
+		return http.StatusTooManyRequests, "agent_rate_limited"
+	case errors.Is(err, routes.ErrResponseTooLarge):
+		return http.StatusBadGateway, "response_too_large"
+	case errors.Is(err, errBackendSaturated):
+		return http.StatusServiceUnavailable, "backend_saturated"
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "deadline_exceeded"
+	default:
+		return http.StatusBadGateway, "agent_error"
+	}
+}
+
+func (h *Handler) forwardToAgent(ctx context.Context, backend *routes.CompiledRouteBackend, req *InvokeRequest, header http.Header) (interface{}, error) {
+	body, err := agentRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bound retries by the request timeout
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.reqTimeout)
+		defer cancel()
+	}
+
+	retry := h.table.GetDefaults().RetryPolicy()
+	rateLimited, retries := 0, 0
+	for {
+		status, retryAfter, respBody, err := h.postInvoke(ctx, backend, body, header)
+		if err != nil {
+			if retryableError(err) && h.retryTransient(ctx, retry, &retries, backend) {
+				continue
+			}
+			return nil, err
+		}
+
+		if status == http.StatusTooManyRequests {
+			rateLimited++
+			delay, ok := routes.ParseRetryAfter(retryAfter, time.Now())
+			if ok && h.rateLimit.Wait(ctx, rateLimited, delay) {
+				continue
+			}
+			return nil, &routes.RateLimitedError{Agent: backend.AgentName, RetryAfter: delay}
+		}
+		if routes.RetryableStatus(status) && h.retryTransient(ctx, retry, &retries, backend) {
+			continue
+		}
+		if status >= 400 {
+			return nil, &agentStatusError{status: status, body: string(respBody)}
+		}
+
+		// Parse response
+		var result interface{}
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			// Return raw response if not JSON
+			return string(respBody), nil
+		}
+		return result, nil
+	}
+}
+
+// retryableError reports whether a failed agent call may be retried: any
+// transport error, but not a response that was too large to accept.
+func retryableError(err error) bool {
+	return !errors.Is(err, routes.ErrResponseTooLarge)
+}
+
+// retryTransient waits out the backoff before retrying a call to backend
+// that failed transiently, counting the retry in retries. It reports false
+// when the policy allows no further retry in time.
+func (h *Handler) retryTransient(ctx context.Context, policy routes.RetryPolicy, retries *int, backend *routes.CompiledRouteBackend) bool {
+	if !policy.Wait(ctx, *retries+1) {
+		return false
+	}
+	*retries++
+	metrics.RecordRetry(backend.AgentName)
+	return true
+}
+
+// agentRequestBody builds the /invoke request body sent to an agent.
+func agentRequestBody(req *InvokeRequest) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
 		"query":         req.Query,
 		"input":         req.Input,
 		"metadata":      req.Metadata,
 		"correlationId": req.CorrelationID,
 		"tenantId":      req.TenantID,
-	}
+	})
+}
 
-	body, err := json.Marshal(agentReq)
+// postInvoke sends one /invoke request to a backend and returns the status,
+// Retry-After header and body of its response.
+func (h *Handler) postInvoke(ctx context.Context, backend *routes.CompiledRouteBackend, body []byte, header http.Header) (int, string, []byte, error) {
+	resp, err := h.openInvoke(ctx, backend, body, header)
 	if err != nil {
-		return nil, err
+		return 0, "", nil, err
 	}
+	defer func() { _ = resp.Body.Close() }()
 
-	// Ensure endpoint uses FQDN format (trailing dot) to avoid search domain issues
-	endpoint := backend.Endpoint
-	if strings.Contains(endpoint, ".svc.cluster.local") && !strings.HasSuffix(strings.Split(endpoint, ":")[0], ".") {
-		parts := strings.SplitN(endpoint, ":", 2)
-		if len(parts) == 2 {
-			endpoint = parts[0] + ".:" + parts[1]
-		}
+	respBody, err := routes.ReadLimited(resp.Body, h.maxRespLen)
+	if err != nil {
+		return 0, "", nil, err
 	}
+	return resp.StatusCode, resp.Header.Get("Retry-After"), respBody, nil
+}
 
-	// Create HTTP request
-	url := fmt.Sprintf("http://%s/invoke", endpoint)
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+// openInvoke sends one /invoke request to a backend and returns its
+// response with the body unread. The caller must close the body.
+func (h *Handler) openInvoke(ctx context.Context, backend *routes.CompiledRouteBackend, body []byte, header http.Header) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, backendURL(backend.Endpoint, "/invoke"), bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
+	h.headerAllowlist().Copy(httpReq.Header, header)
 	httpReq.Header.Set("Content-Type", "application/json")
-
-	// Execute
+	if id := header.Get(CorrelationIDHeader); id != "" {
+		httpReq.Header.Set(CorrelationIDHeader, id)
+	}
+	done := metrics.TrackAgentInflight(backend.AgentName)
 	resp, err := h.httpClient.Do(httpReq)
+	h.recordOutcome(ctx, backend, resp, err)
 	if err != nil {
+		done()
 		return nil, err
 	}
-	defer func() { _ = resp.Body.Close() }()
+	// The forward lasts until the caller has read the response
+	resp.Body = &inflightBody{ReadCloser: resp.Body, done: done}
+	return resp, nil
+}
+
+// inflightBody ends an agent's in-flight count when the response body is
+// closed.
+type inflightBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *inflightBody) Close() error {
+	b.once.Do(b.done)
+	return b.ReadCloser.Close()
+}
+
+// withCorrelationID gives req a generated correlation ID if the caller sent
+// none and returns a copy of the client headers carrying it, so that every
+// agent call made for req sends it in CorrelationIDHeader.
+func withCorrelationID(req *InvokeRequest, header http.Header) http.Header {
+	if req.CorrelationID == "" {
+		req.CorrelationID = newCorrelationID()
+	}
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(CorrelationIDHeader, req.CorrelationID)
+	return header
+}
+
+// newCorrelationID returns a random 128-bit ID in hex.
+func newCorrelationID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// headerAllowlist returns the client headers forwarded to agents: those of
+// the gateway's allowlist and of the route defaults.
+func (h *Handler) headerAllowlist() routes.HeaderAllowlist {
+	defaults := h.table.GetDefaults()
+	if defaults == nil || len(defaults.ForwardHeaders) == 0 {
+		return h.forwardHeaders
+	}
+	allow := make(routes.HeaderAllowlist, 0, len(h.forwardHeaders)+len(defaults.ForwardHeaders))
+	allow = append(allow, h.forwardHeaders...)
+	return append(allow, defaults.ForwardHeaders...)
+}
+
+// breakerErrorType returns the error type for a circuit breaker Acquire
+// failure, recording queue rejections.
+func breakerErrorType(route string, err error) string {
+	switch err {
+	case circuit.ErrQueueFull:
+		metrics.RecordCircuitBreakerRejection(route, "queue_full")
+		return "queue_full"
+	case circuit.ErrQueueTimeout:
+		metrics.RecordCircuitBreakerRejection(route, "timeout")
+		return "queue_timeout"
+	case circuit.ErrDraining:
+		return "draining"
+	case circuit.ErrCircuitOpen:
+		return "circuit_open"
+	default:
+		return "circuit_breaker"
+	}
+}
+
+// selectionStrategy returns the backend selection strategy for a matched
+// request: the rule's strategy, else the route default, else weighted
+// random. Request fields such as the tenant never change it.
+func (h *Handler) selectionStrategy(match *routes.MatchResult) routes.SelectionStrategy {
+	name := match.SelectionStrategy
+	if name == "" {
+		if defaults := h.table.GetDefaults(); defaults != nil {
+			name = defaults.SelectionStrategy
+		}
+	}
 
-	// Read response
-	respBody, err := io.ReadAll(resp.Body)
+	strategy, _ := routes.ParseStrategy(name)
+	return strategy
+}
+
+// selectionKey returns the key a strategy selects by: the tenant and
+// correlation ID for consistent hashing, the rule for round-robin.
+func selectionKey(strategy routes.SelectionStrategy, match *routes.MatchResult, req *InvokeRequest) string {
+	switch strategy {
+	case routes.StrategyConsistentHash:
+		return req.TenantID + ":" + req.CorrelationID
+	case routes.StrategyRoundRobin:
+		return match.RuleName
+	default:
+		return ""
+	}
+}
+
+// parseRequestDeadline parses a RequestDeadlineHeader value relative to now.
+func parseRequestDeadline(value string, now time.Time) (time.Time, error) {
+	if deadline, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return deadline, nil
+	}
+	d, err := time.ParseDuration(value)
 	if err != nil {
-		return nil, err
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 timestamp nor a duration", value)
 	}
+	if d <= 0 {
+		return time.Time{}, fmt.Errorf("duration %q must be positive", value)
+	}
+	return now.Add(d), nil
+}
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("agent returned %d: %s", resp.StatusCode, string(respBody))
+// traceID returns the trace ID of a W3C traceparent header
+// ("version-traceid-parentid-flags"), or "" if it is missing or malformed.
+func traceID(header http.Header) string {
+	parts := strings.Split(strings.TrimSpace(header.Get(TraceparentHeader)), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 {
+		return ""
+	}
+	id := strings.ToLower(parts[1])
+	if strings.Trim(id, "0") == "" || strings.Trim(id, "0123456789abcdef") != "" {
+		return ""
 	}
+	return id
+}
 
-	// Parse response
-	var result interface{}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		// Return raw response if not JSON
-		return string(respBody), nil
+// pinnedBackend finds the backend named by a BackendPinHeader or
+// BackendExcludeHeader value.
+func pinnedBackend(backends []routes.CompiledRouteBackend, name string) *routes.CompiledRouteBackend {
+	for i := range backends {
+		if backends[i].HasName(name) {
+			return &backends[i]
+		}
 	}
+	return nil
+}
 
-	return result, nil
+// backendURL builds the URL for path on a backend endpoint.
+func backendURL(endpoint, path string) string {
+	// Ensure endpoint uses FQDN format (trailing dot) to avoid search domain issues
+	if strings.Contains(endpoint, ".svc.cluster.local") && !strings.HasSuffix(strings.Split(endpoint, ":")[0], ".") {
+		parts := strings.SplitN(endpoint, ":", 2)
+		if len(parts) == 2 {
+			endpoint = parts[0] + ".:" + parts[1]
+		}
+	}
+	return fmt.Sprintf("http://%s%s", endpoint, path)
 }
 
 func (h *Handler) handleListAgents(w http.ResponseWriter, r *http.Request) {
@@ -312,6 +1033,63 @@ func (h *Handler) handleHealthz(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
+func (h *Handler) handleListErrors(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"errors": h.recentErrors.Recent(),
+	})
+}
+
+// handleGetConfig returns the full compiled route configuration the gateway
+// is currently using, so it can be compared with what the operator compiled.
+func (h *Handler) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"config":     h.table.GetConfig(),
+		"configHash": h.table.ConfigHash(),
+	})
+}
+
+// authorizeAdmin checks the request's bearer token against the admin
+// token, writing an error response and returning false if it doesn't match.
+func (h *Handler) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if h.adminToken == "" {
+		h.writeError(w, http.StatusForbidden, "diagnostics endpoints are disabled: no admin token configured")
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.writeError(w, http.StatusUnauthorized, "unauthorized")
+		return false
+	}
+	return true
+}
+
+// requestError records a failed invoke request in metrics and the recent
+// errors buffer, then writes the error response.
+func (h *Handler) requestError(w http.ResponseWriter, status int, agent, route, errorType, message string) {
+	h.recordError(status, agent, route, errorType, message)
+	h.writeError(w, status, message)
+}
+
+// recordError counts a failed request and keeps it for GET /v1/errors.
+func (h *Handler) recordError(status int, agent, route, errorType, message string) {
+	metrics.RecordRequestError(agent, route, errorType)
+	h.recentErrors.Add(ErrorRecord{
+		Timestamp:  time.Now(),
+		Agent:      agent,
+		Route:      route,
+		ErrorType:  errorType,
+		StatusCode: status,
+		Message:    message,
+	})
+}
+
 func (h *Handler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -0,0 +1,1555 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/circuit"
+	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
+)
+
+// newStubBackend starts an HTTP server acting as an agent /invoke endpoint
+// and returns a ready backend pointing at it.
+func newStubBackend(t *testing.T, name string, handler http.HandlerFunc) routes.CompiledRouteBackend {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return routes.CompiledRouteBackend{
+		AgentName: name,
+		Namespace: "default",
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Weight:    100,
+		Ready:     true,
+	}
+}
+
+// newTestHandler creates a handler whose route table contains a single rule
+// with the given backends.
+func newTestHandler(t *testing.T, config *routes.RouteConfig) *Handler {
+	t.Helper()
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("failed to marshal route config: %v", err)
+	}
+	table := routes.NewTable()
+	if err := table.LoadFromJSON(data); err != nil {
+		t.Fatalf("failed to load route config: %v", err)
+	}
+	h := NewHandler(table, time.Minute)
+	h.UpdateDefaults()
+	return h
+}
+
+// singleRuleConfig returns a route config with one rule matching the agent
+// name "echo" and the given backends.
+func singleRuleConfig(backends ...routes.CompiledRouteBackend) *routes.RouteConfig {
+	return &routes.RouteConfig{
+		Rules: []routes.CompiledRouteRule{{
+			Name:     "echo-rule",
+			Match:    routes.CompiledRouteMatch{Agent: "echo"},
+			Backends: backends,
+		}},
+	}
+}
+
+func invoke(t *testing.T, h http.Handler, req InvokeRequest) (*httptest.ResponseRecorder, InvokeResponse) {
+	t.Helper()
+	return invokeWithHeaders(t, h, req, nil)
+}
+
+func invokeWithHeaders(t *testing.T, h http.Handler, req InvokeRequest, headers map[string]string) (*httptest.ResponseRecorder, InvokeResponse) {
+	t.Helper()
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	httpReq := httptest.NewRequest(http.MethodPost, "/v1/invoke", bytes.NewReader(body))
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httpReq)
+
+	var resp InvokeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return rec, resp
+}
+
+func TestHandleInvoke_Success(t *testing.T) {
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":"pong"}`))
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !resp.Success || resp.Agent != "echo" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestHandleInvoke_ForwardHeaders(t *testing.T) {
+	forwarded := make(chan http.Header, 1)
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		forwarded <- r.Header.Clone()
+		_, _ = w.Write([]byte(`{"result":"pong"}`))
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+	h.SetForwardHeaders(routes.ParseHeaderAllowlist("X-Tenant, x-trace-*"))
+
+	rec, _ := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, map[string]string{
+		"X-Tenant":      "acme",
+		"X-Trace-Id":    "abc123",
+		"X-Other":       "dropped",
+		"Authorization": "Bearer secret",
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	got := <-forwarded
+	if got.Get("X-Tenant") != "acme" || got.Get("X-Trace-Id") != "abc123" {
+		t.Errorf("expected allowlisted headers forwarded, got %v", got)
+	}
+	for _, name := range []string{"X-Other", "Authorization"} {
+		if got.Get(name) != "" {
+			t.Errorf("expected %s not to be forwarded, got %q", name, got.Get(name))
+		}
+	}
+	if got.Get("Content-Type") != "application/json" {
+		t.Errorf("expected JSON content type, got %q", got.Get("Content-Type"))
+	}
+}
+
+func TestHandleInvoke_ForwardHeadersDefaultNone(t *testing.T) {
+	forwarded := make(chan http.Header, 1)
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		forwarded <- r.Header.Clone()
+		_, _ = w.Write([]byte(`{"result":"pong"}`))
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+
+	invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, map[string]string{
+		"X-Tenant":      "acme",
+		"Authorization": "Bearer secret",
+	})
+
+	got := <-forwarded
+	if got.Get("X-Tenant") != "" || got.Get("Authorization") != "" {
+		t.Errorf("expected no client headers forwarded by default, got %v", got)
+	}
+}
+
+func TestHandleInvoke_RouteForwardHeaders(t *testing.T) {
+	forwarded := make(chan http.Header, 1)
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		forwarded <- r.Header.Clone()
+		_, _ = w.Write([]byte(`{"result":"pong"}`))
+	})
+	config := singleRuleConfig(backend)
+	config.Defaults = &routes.RouteDefaultConfig{ForwardHeaders: routes.HeaderAllowlist{"X-Request-ID", "*"}}
+	h := newTestHandler(t, config)
+	h.SetForwardHeaders(routes.HeaderAllowlist{"X-Tenant"})
+
+	invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, map[string]string{
+		"X-Tenant":      "acme",
+		"X-Request-ID":  "req-1",
+		"Traceparent":   "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		"Authorization": "Bearer secret",
+		"Cookie":        "session=secret",
+	})
+
+	got := <-forwarded
+	if got.Get("X-Tenant") != "acme" || got.Get("X-Request-ID") != "req-1" || got.Get("Traceparent") == "" {
+		t.Errorf("expected headers of both allowlists forwarded, got %v", got)
+	}
+	for _, name := range []string{"Authorization", "Cookie"} {
+		if got.Get(name) != "" {
+			t.Errorf("expected %s not to match a wildcard, got %q", name, got.Get(name))
+		}
+	}
+}
+
+func TestHandleInvoke_PropagatesCorrelationID(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		headers map[string]string
+		want    string
+	}{
+		{name: "from the body", body: "body-id", want: "body-id"},
+		{name: "from the header", headers: map[string]string{CorrelationIDHeader: "header-id"}, want: "header-id"},
+		{name: "generated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forwarded := make(chan http.Header, 1)
+			backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+				forwarded <- r.Header.Clone()
+				_, _ = w.Write([]byte(`{"result":"pong"}`))
+			})
+			h := newTestHandler(t, singleRuleConfig(backend))
+
+			_, resp := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping", CorrelationID: tt.body}, tt.headers)
+
+			got := (<-forwarded).Get(CorrelationIDHeader)
+			if got == "" || got != resp.CorrelationID {
+				t.Fatalf("expected the response's correlation ID %q sent to the agent, got %q", resp.CorrelationID, got)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("expected correlation ID %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// rateLimitedBackend answers the first n calls with 429 and the given
+// Retry-After, then succeeds.
+func rateLimitedBackend(t *testing.T, n int32, retryAfter string, calls *atomic.Int32) routes.CompiledRouteBackend {
+	return newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= n {
+			w.Header().Set("Retry-After", retryAfter)
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":"pong"}`))
+	})
+}
+
+func TestHandleInvoke_RateLimitedReturnsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	h := newTestHandler(t, singleRuleConfig(rateLimitedBackend(t, 1, "7", &calls)))
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Retry-After"); got != "7" {
+		t.Errorf("expected Retry-After 7, got %q", got)
+	}
+	if !strings.Contains(resp.Error, "rate limited") {
+		t.Errorf("expected rate limited error, got %q", resp.Error)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected no retry by default, got %d calls", calls.Load())
+	}
+}
+
+func TestHandleInvoke_RateLimitedRetry(t *testing.T) {
+	var calls atomic.Int32
+	h := newTestHandler(t, singleRuleConfig(rateLimitedBackend(t, 1, "0", &calls)))
+	h.SetRateLimitPolicy(routes.RateLimitPolicy{Retries: 2, MaxWait: time.Second})
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 after retry, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !resp.Success {
+		t.Errorf("expected success, got %+v", resp)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 calls, got %d", calls.Load())
+	}
+}
+
+func TestHandleInvoke_RateLimitedRetryNotAttempted(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		policy     routes.RateLimitPolicy
+		deadline   string
+		wantCalls  int32
+	}{
+		{name: "wait exceeds max", retryAfter: "30", policy: routes.RateLimitPolicy{Retries: 1, MaxWait: time.Second}, wantCalls: 1},
+		{name: "wait exceeds deadline", retryAfter: "5", policy: routes.RateLimitPolicy{Retries: 1}, deadline: "1s", wantCalls: 1},
+		{name: "retries exhausted", retryAfter: "0", policy: routes.RateLimitPolicy{Retries: 1}, wantCalls: 2},
+		{name: "no retry-after", retryAfter: "", policy: routes.RateLimitPolicy{Retries: 1}, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			h := newTestHandler(t, singleRuleConfig(rateLimitedBackend(t, 5, tt.retryAfter, &calls)))
+			h.SetRateLimitPolicy(tt.policy)
+
+			var headers map[string]string
+			if tt.deadline != "" {
+				headers = map[string]string{RequestDeadlineHeader: tt.deadline}
+			}
+			start := time.Now()
+			rec, _ := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, headers)
+			if rec.Code != http.StatusTooManyRequests {
+				t.Fatalf("expected 429, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("expected an immediate 429, took %s", elapsed)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, calls.Load())
+			}
+		})
+	}
+}
+
+// flakyBackend returns a backend that fails its first n calls with fail and
+// then answers successfully.
+func flakyBackend(t *testing.T, n int32, fail http.HandlerFunc, calls *atomic.Int32) routes.CompiledRouteBackend {
+	return newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= n {
+			fail(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":"pong"}`))
+	})
+}
+
+// retryConfig returns singleRuleConfig with route default retries enabled.
+func retryConfig(maxRetries int32, backend routes.CompiledRouteBackend) *routes.RouteConfig {
+	config := singleRuleConfig(backend)
+	config.Defaults = &routes.RouteDefaultConfig{MaxRetries: maxRetries, RetryBackoffMs: 1}
+	return config
+}
+
+// resetConnection drops the connection without sending a response.
+func resetConnection(w http.ResponseWriter, r *http.Request) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		_ = conn.Close()
+	}
+}
+
+func TestHandleInvoke_RetriesTransientFailures(t *testing.T) {
+	tests := []struct {
+		name string
+		fail http.HandlerFunc
+	}{
+		{name: "connection reset", fail: resetConnection},
+		{name: "502", fail: func(w http.ResponseWriter, r *http.Request) { http.Error(w, "bad gateway", http.StatusBadGateway) }},
+		{name: "503", fail: func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}},
+		{name: "504", fail: func(w http.ResponseWriter, r *http.Request) { http.Error(w, "timeout", http.StatusGatewayTimeout) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			h := newTestHandler(t, retryConfig(2, flakyBackend(t, 2, tt.fail, &calls)))
+
+			rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+			if rec.Code != http.StatusOK || !resp.Success {
+				t.Fatalf("expected success after retries, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if calls.Load() != 3 {
+				t.Errorf("expected 3 calls, got %d", calls.Load())
+			}
+		})
+	}
+}
+
+func TestHandleInvoke_RetryNotAttempted(t *testing.T) {
+	unavailable := func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}
+	tests := []struct {
+		name      string
+		config    func(routes.CompiledRouteBackend) *routes.RouteConfig
+		fail      http.HandlerFunc
+		wantCode  int
+		wantCalls int32
+	}{
+		{name: "retries disabled", config: func(b routes.CompiledRouteBackend) *routes.RouteConfig { return singleRuleConfig(b) }, fail: unavailable, wantCode: http.StatusBadGateway, wantCalls: 1},
+		{name: "retries exhausted", config: func(b routes.CompiledRouteBackend) *routes.RouteConfig { return retryConfig(1, b) }, fail: unavailable, wantCode: http.StatusBadGateway, wantCalls: 2},
+		{name: "non-transient status", config: func(b routes.CompiledRouteBackend) *routes.RouteConfig { return retryConfig(3, b) },
+			fail: func(w http.ResponseWriter, r *http.Request) { http.Error(w, "boom", http.StatusInternalServerError) }, wantCode: http.StatusBadGateway, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			h := newTestHandler(t, tt.config(flakyBackend(t, 5, tt.fail, &calls)))
+
+			rec, _ := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, calls.Load())
+			}
+		})
+	}
+}
+
+func TestHandleInvoke_RetryRespectsDeadline(t *testing.T) {
+	var calls atomic.Int32
+	config := retryConfig(3, flakyBackend(t, 5, resetConnection, &calls))
+	config.Defaults.RetryBackoffMs = 5000
+	h := newTestHandler(t, config)
+
+	start := time.Now()
+	rec, _ := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, map[string]string{RequestDeadlineHeader: "1s"})
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected no backoff past the deadline, took %s", elapsed)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 call, got %d", calls.Load())
+	}
+}
+
+func TestHandleInvoke_TenantHeader(t *testing.T) {
+	backends := namedBackends(t, "echo-acme", "echo-shared")
+	config := &routes.RouteConfig{
+		Rules: []routes.CompiledRouteRule{
+			{
+				Name:     "acme",
+				Priority: 10,
+				Match:    routes.CompiledRouteMatch{TenantID: "acme"},
+				Backends: backends[:1],
+			},
+			{
+				Name:     "shared",
+				Match:    routes.CompiledRouteMatch{IntentRegex: ".*"},
+				Backends: backends[1:],
+			},
+		},
+	}
+	h := newTestHandler(t, config)
+	h.SetTenantHeader("X-Tenant-ID")
+
+	tests := []struct {
+		name    string
+		req     InvokeRequest
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "header supplies tenant",
+			req:     InvokeRequest{Query: "ping"},
+			headers: map[string]string{"X-Tenant-ID": "acme"},
+			want:    "echo-acme",
+		},
+		{
+			name:    "body tenant wins over header",
+			req:     InvokeRequest{Query: "ping", TenantID: "globex"},
+			headers: map[string]string{"X-Tenant-ID": "acme"},
+			want:    "echo-shared",
+		},
+		{
+			name: "no tenant",
+			req:  InvokeRequest{Query: "ping"},
+			want: "echo-shared",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, resp := invokeWithHeaders(t, h, tt.req, tt.headers)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if resp.Agent != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, resp.Agent)
+			}
+		})
+	}
+}
+
+func TestHandleInvoke_TenantHeaderDisabled(t *testing.T) {
+	backends := namedBackends(t, "echo-acme", "echo-shared")
+	config := &routes.RouteConfig{
+		Rules: []routes.CompiledRouteRule{
+			{Name: "acme", Priority: 10, Match: routes.CompiledRouteMatch{TenantID: "acme"}, Backends: backends[:1]},
+			{Name: "shared", Match: routes.CompiledRouteMatch{IntentRegex: ".*"}, Backends: backends[1:]},
+		},
+	}
+	h := newTestHandler(t, config)
+
+	_, resp := invokeWithHeaders(t, h, InvokeRequest{Query: "ping"}, map[string]string{"X-Tenant-ID": "acme"})
+	if resp.Agent != "echo-shared" {
+		t.Errorf("expected tenant header to be ignored by default, got %s", resp.Agent)
+	}
+}
+
+func TestHandleInvoke_ResponseTooLarge(t *testing.T) {
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bytes.Repeat([]byte("x"), 2048))
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+	h.SetMaxResponseBytes(1024)
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(resp.Error, "response too large") {
+		t.Errorf("expected response too large error, got %q", resp.Error)
+	}
+}
+
+func TestHandleInvoke_ResponseAtLimit(t *testing.T) {
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bytes.Repeat([]byte("x"), 1024))
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+	h.SetMaxResponseBytes(1024)
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if s, ok := resp.Result.(string); !ok || len(s) != 1024 {
+		t.Errorf("expected 1024-byte raw result, got %v", resp.Result)
+	}
+}
+
+func TestServeHTTP_MaxInFlight(t *testing.T) {
+	release := make(chan struct{})
+	entered := make(chan struct{}, 1)
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+		_, _ = w.Write([]byte(`{"result":"done"}`))
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+	h.SetMaxInFlight(1)
+
+	// Occupy the only slot
+	done := make(chan int)
+	go func() {
+		rec, _ := invoke(t, h, InvokeRequest{Agent: "echo", Query: "first"})
+		done <- rec.Code
+	}()
+	<-entered
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "second"})
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rec.Code)
+	}
+	if !strings.Contains(resp.Error, "too many requests in flight") {
+		t.Errorf("unexpected error: %q", resp.Error)
+	}
+
+	// Health checks are never rejected
+	healthRec := httptest.NewRecorder()
+	h.ServeHTTP(healthRec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if healthRec.Code != http.StatusOK {
+		t.Errorf("expected healthz 200, got %d", healthRec.Code)
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("expected first request to succeed, got %d", code)
+	}
+
+	// Slot is released once the first request completes
+	rec, _ = invoke(t, h, InvokeRequest{Agent: "echo", Query: "third"})
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 after slot release, got %d", rec.Code)
+	}
+}
+
+func TestHandleInvoke_LocalityAware(t *testing.T) {
+	local := newStubBackend(t, "echo-local", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":"local"}`))
+	})
+	local.Zone = "eu-west-1a"
+	remote := newStubBackend(t, "echo-remote", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":"remote"}`))
+	})
+	remote.Zone = "eu-west-1b"
+
+	config := singleRuleConfig(local, remote)
+	config.Defaults = &routes.RouteDefaultConfig{MaxConcurrent: 10, LocalityAware: true}
+	h := newTestHandler(t, config)
+	h.SetZone("eu-west-1b")
+
+	for i := 0; i < 20; i++ {
+		rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if resp.Agent != "echo-remote" {
+			t.Fatalf("expected same-zone backend echo-remote, got %s", resp.Agent)
+		}
+	}
+}
+
+func TestPrewarm_DialsReadyBackends(t *testing.T) {
+	var hits atomic.Int64
+	healthz := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			hits.Add(1)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}
+	a := newStubBackend(t, "echo-a", healthz)
+	b := newStubBackend(t, "echo-b", healthz)
+	notReady := newStubBackend(t, "echo-c", healthz)
+	notReady.Ready = false
+
+	config := singleRuleConfig(a, b, notReady)
+	config.Defaults = &routes.RouteDefaultConfig{MaxConcurrent: 10, Backend: &a}
+	h := newTestHandler(t, config)
+
+	if n := h.Prewarm(context.Background()); n != 0 {
+		t.Fatalf("expected no pre-warming when disabled, got %d", n)
+	}
+
+	h.SetPrewarmConcurrency(1)
+	if n := h.Prewarm(context.Background()); n != 2 {
+		t.Errorf("expected 2 backends warmed, got %d", n)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("expected 2 pre-dial requests, got %d", hits.Load())
+	}
+}
+
+// namedBackends starts stub backends that answer with their own name.
+func namedBackends(t *testing.T, names ...string) []routes.CompiledRouteBackend {
+	t.Helper()
+	backends := make([]routes.CompiledRouteBackend, 0, len(names))
+	for _, name := range names {
+		result := `{"result":"` + name + `"}`
+		backends = append(backends, newStubBackend(t, name, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(result))
+		}))
+	}
+	return backends
+}
+
+func invokedAgents(t *testing.T, h http.Handler, n int, req func(i int) InvokeRequest) []string {
+	t.Helper()
+	agents := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		rec, resp := invoke(t, h, req(i))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		agents = append(agents, resp.Agent)
+	}
+	return agents
+}
+
+func TestHandleInvoke_SelectionStrategy_WeightedRandom(t *testing.T) {
+	backends := namedBackends(t, "echo-a", "echo-b")
+	backends[1].Weight = 0
+	config := singleRuleConfig(backends...)
+	config.Rules[0].SelectionStrategy = routes.StrategyNameWeightedRandom
+	h := newTestHandler(t, config)
+
+	agents := invokedAgents(t, h, 20, func(i int) InvokeRequest {
+		return InvokeRequest{Agent: "echo", Query: "ping", CorrelationID: fmt.Sprintf("req-%d", i)}
+	})
+	for _, agent := range agents {
+		if agent != "echo-a" {
+			t.Fatalf("expected weight to select echo-a only, got %v", agents)
+		}
+	}
+}
+
+func TestHandleInvoke_SelectionStrategy_IgnoresTenantWithoutConfig(t *testing.T) {
+	backends := namedBackends(t, "echo-a", "echo-b")
+	backends[1].Weight = 0
+	h := newTestHandler(t, singleRuleConfig(backends...))
+
+	// Without a configured strategy, weighted random applies even to
+	// requests carrying a tenant and correlation ID
+	agents := invokedAgents(t, h, 20, func(i int) InvokeRequest {
+		return InvokeRequest{Agent: "echo", Query: "ping", TenantID: fmt.Sprintf("tenant-%d", i), CorrelationID: "session-1"}
+	})
+	for _, agent := range agents {
+		if agent != "echo-a" {
+			t.Fatalf("expected weight to select echo-a only, got %v", agents)
+		}
+	}
+
+	match := &routes.MatchResult{}
+	if got := h.selectionStrategy(match); got != routes.StrategyWeightedRandom {
+		t.Errorf("selectionStrategy() = %v, want %v", got, routes.StrategyWeightedRandom)
+	}
+}
+
+func TestHandleInvoke_SelectionStrategy_ConsistentHash(t *testing.T) {
+	config := singleRuleConfig(namedBackends(t, "echo-a", "echo-b", "echo-c")...)
+	config.Defaults = &routes.RouteDefaultConfig{MaxConcurrent: 10, SelectionStrategy: routes.StrategyNameConsistentHash}
+	h := newTestHandler(t, config)
+
+	agents := invokedAgents(t, h, 10, func(int) InvokeRequest {
+		return InvokeRequest{Agent: "echo", Query: "ping", TenantID: "acme", CorrelationID: "session-1"}
+	})
+	for _, agent := range agents {
+		if agent != agents[0] {
+			t.Fatalf("expected all requests pinned to one backend, got %v", agents)
+		}
+	}
+}
+
+func TestHandleInvoke_SelectionStrategy_RoundRobin(t *testing.T) {
+	config := singleRuleConfig(namedBackends(t, "echo-a", "echo-b", "echo-c")...)
+	// The rule's strategy overrides the route default
+	config.Defaults = &routes.RouteDefaultConfig{MaxConcurrent: 10, SelectionStrategy: routes.StrategyNameConsistentHash}
+	config.Rules[0].SelectionStrategy = routes.StrategyNameRoundRobin
+	h := newTestHandler(t, config)
+
+	agents := invokedAgents(t, h, 6, func(int) InvokeRequest {
+		return InvokeRequest{Agent: "echo", Query: "ping", CorrelationID: "session-1"}
+	})
+	want := []string{"echo-a", "echo-b", "echo-c", "echo-a", "echo-b", "echo-c"}
+	if strings.Join(agents, ",") != strings.Join(want, ",") {
+		t.Errorf("expected rotation %v, got %v", want, agents)
+	}
+}
+
+func TestHandleInvoke_SelectionStrategy_LeastConnections(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	busy := newStubBackend(t, "echo-busy", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte(`{"result":"busy"}`))
+	})
+	idle := namedBackends(t, "echo-idle")[0]
+
+	config := singleRuleConfig(busy, idle)
+	config.Defaults = &routes.RouteDefaultConfig{MaxConcurrent: 10, SelectionStrategy: routes.StrategyNameLeastConnections}
+	h := newTestHandler(t, config)
+
+	// With no requests in flight the first backend wins the tie
+	firstDone := make(chan string)
+	go func() {
+		body, _ := json.Marshal(InvokeRequest{Agent: "echo", Query: "ping"})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/invoke", bytes.NewReader(body)))
+		var resp InvokeResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		firstDone <- resp.Agent
+	}()
+	<-started
+
+	agents := invokedAgents(t, h, 3, func(int) InvokeRequest {
+		return InvokeRequest{Agent: "echo", Query: "ping"}
+	})
+	close(release)
+
+	if first := <-firstDone; first != "echo-busy" {
+		t.Errorf("expected first request on echo-busy, got %s", first)
+	}
+	for _, agent := range agents {
+		if agent != "echo-idle" {
+			t.Fatalf("expected requests to avoid the busy backend, got %v", agents)
+		}
+	}
+}
+
+func TestHandleInvoke_SkipsSaturatedBackend(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	busy := newStubBackend(t, "echo-busy", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte(`{"result":"busy"}`))
+	})
+	busy.MaxConcurrentRequests = 1
+	idle := namedBackends(t, "echo-idle")[0]
+
+	config := singleRuleConfig(busy, idle)
+	config.Defaults = &routes.RouteDefaultConfig{MaxConcurrent: 10, SelectionStrategy: routes.StrategyNameRoundRobin}
+	h := newTestHandler(t, config)
+
+	// Round-robin sends the first request to the capped backend and holds it
+	firstDone := make(chan string)
+	go func() {
+		body, _ := json.Marshal(InvokeRequest{Agent: "echo", Query: "ping"})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/invoke", bytes.NewReader(body)))
+		var resp InvokeResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		firstDone <- resp.Agent
+	}()
+	<-started
+
+	agents := invokedAgents(t, h, 4, func(int) InvokeRequest {
+		return InvokeRequest{Agent: "echo", Query: "ping"}
+	})
+	close(release)
+
+	if first := <-firstDone; first != "echo-busy" {
+		t.Errorf("expected first request on echo-busy, got %s", first)
+	}
+	for _, agent := range agents {
+		if agent != "echo-idle" {
+			t.Fatalf("expected requests to skip the saturated backend, got %v", agents)
+		}
+	}
+}
+
+func TestHandleInvoke_AllBackendsSaturated(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	busy := newStubBackend(t, "echo-busy", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte(`{"result":"busy"}`))
+	})
+	busy.MaxConcurrentRequests = 1
+
+	h := newTestHandler(t, singleRuleConfig(busy))
+
+	firstDone := make(chan int)
+	go func() {
+		body, _ := json.Marshal(InvokeRequest{Agent: "echo", Query: "ping"})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/invoke", bytes.NewReader(body)))
+		firstDone <- rec.Code
+	}()
+	<-started
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+	close(release)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(resp.Error, "concurrency limit") {
+		t.Errorf("unexpected error: %q", resp.Error)
+	}
+	if code := <-firstDone; code != http.StatusOK {
+		t.Errorf("expected in-flight request to succeed, got %d", code)
+	}
+}
+
+func TestHandleInvoke_ConcurrencyCapUnderParallelLoad(t *testing.T) {
+	var current, peak atomic.Int32
+	capped := newStubBackend(t, "echo-capped", func(w http.ResponseWriter, r *http.Request) {
+		n := current.Add(1)
+		defer current.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		_, _ = io.Copy(io.Discard, r.Body)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"result":"ok"}`))
+	})
+	capped.MaxConcurrentRequests = 1
+
+	const parallel = 5
+	config := singleRuleConfig(capped)
+	config.Defaults = &routes.RouteDefaultConfig{MaxConcurrent: parallel, MaxQueueSize: parallel, QueueTimeoutMs: 5000}
+	h := newTestHandler(t, config)
+
+	// Fill the route's breaker so every request passes the up-front
+	// capacity check and then waits in its queue
+	breaker := h.breakers.Get("echo-rule")
+	for i := 0; i < parallel; i++ {
+		if err := breaker.Acquire(context.Background()); err != nil {
+			t.Fatalf("failed to fill the breaker: %v", err)
+		}
+	}
+
+	codes := make(chan int, parallel)
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/invoke", strings.NewReader(`{"agent":"echo","query":"ping"}`)))
+			codes <- rec.Code
+		}()
+	}
+	for deadline := time.Now().Add(5 * time.Second); breaker.Stats().Waiting < parallel; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d queued requests, got %d", parallel, breaker.Stats().Waiting)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Let them all forward at once
+	for i := 0; i < parallel; i++ {
+		breaker.Release()
+	}
+	wg.Wait()
+	close(codes)
+
+	if got := peak.Load(); got != 1 {
+		t.Errorf("expected at most 1 request in flight to the capped backend, got %d", got)
+	}
+	var ok int
+	for code := range codes {
+		switch code {
+		case http.StatusOK:
+			ok++
+		case http.StatusServiceUnavailable:
+		default:
+			t.Errorf("expected 200 or 503, got %d", code)
+		}
+	}
+	if ok == 0 {
+		t.Error("expected at least one request to reach the backend")
+	}
+}
+
+func TestHandleInvoke_StrategyOverrideHeader(t *testing.T) {
+	config := singleRuleConfig(namedBackends(t, "echo-a", "echo-b")...)
+	config.Rules[0].SelectionStrategy = routes.StrategyNameRoundRobin
+	override := map[string]string{StrategyOverrideHeader: routes.StrategyNameConsistentHash}
+	req := InvokeRequest{Agent: "echo", Query: "ping", CorrelationID: "session-1"}
+
+	t.Run("ignored when disabled", func(t *testing.T) {
+		h := newTestHandler(t, config)
+		var agents []string
+		for i := 0; i < 4; i++ {
+			_, resp := invokeWithHeaders(t, h, req, override)
+			agents = append(agents, resp.Agent)
+		}
+		if strings.Join(agents, ",") != "echo-a,echo-b,echo-a,echo-b" {
+			t.Errorf("expected configured round-robin, got %v", agents)
+		}
+	})
+
+	t.Run("honored when enabled", func(t *testing.T) {
+		h := newTestHandler(t, config)
+		h.SetDebugHeaders(true)
+		var agents []string
+		for i := 0; i < 4; i++ {
+			rec, resp := invokeWithHeaders(t, h, req, override)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			agents = append(agents, resp.Agent)
+		}
+		for _, agent := range agents {
+			if agent != agents[0] {
+				t.Fatalf("expected consistent-hash override to pin one backend, got %v", agents)
+			}
+		}
+	})
+
+	t.Run("invalid value rejected", func(t *testing.T) {
+		h := newTestHandler(t, config)
+		h.SetDebugHeaders(true)
+		rec, resp := invokeWithHeaders(t, h, req, map[string]string{StrategyOverrideHeader: "fastest"})
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if !strings.Contains(resp.Error, StrategyOverrideHeader) {
+			t.Errorf("expected error naming the header, got %q", resp.Error)
+		}
+	})
+}
+
+func TestHandleInvoke_BackendPinHeader(t *testing.T) {
+	backends := namedBackends(t, "echo-a", "echo-b", "echo-c")
+	backends[2].Ready = false
+	config := singleRuleConfig(backends...)
+	config.Rules[0].SelectionStrategy = routes.StrategyNameRoundRobin
+	req := InvokeRequest{Agent: "echo", Query: "ping"}
+
+	tests := []struct {
+		name      string
+		enabled   bool
+		pin       string
+		wantCode  int
+		wantAgent string
+	}{
+		{name: "pinned by name", enabled: true, pin: "echo-b", wantCode: http.StatusOK, wantAgent: "echo-b"},
+		{name: "pinned by namespace and name", enabled: true, pin: "default/echo-b", wantCode: http.StatusOK, wantAgent: "echo-b"},
+		{name: "backend not in rule", enabled: true, pin: "other", wantCode: http.StatusBadRequest},
+		{name: "backend not ready", enabled: true, pin: "echo-c", wantCode: http.StatusBadRequest},
+		{name: "ignored when disabled", enabled: false, pin: "echo-b", wantCode: http.StatusOK, wantAgent: "echo-a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t, config)
+			h.SetDebugHeaders(tt.enabled)
+
+			rec, resp := invokeWithHeaders(t, h, req, map[string]string{BackendPinHeader: tt.pin})
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				if !strings.Contains(resp.Error, BackendPinHeader) {
+					t.Errorf("expected error naming the header, got %q", resp.Error)
+				}
+				return
+			}
+			if resp.Agent != tt.wantAgent {
+				t.Errorf("expected %s, got %s", tt.wantAgent, resp.Agent)
+			}
+		})
+	}
+}
+
+func TestHandleInvoke_BackendExcludeHeader(t *testing.T) {
+	config := singleRuleConfig(namedBackends(t, "echo-a", "echo-b", "echo-c")...)
+	config.Rules[0].SelectionStrategy = routes.StrategyNameRoundRobin
+	req := InvokeRequest{Agent: "echo", Query: "ping"}
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		exclude    string
+		wantCode   int
+		wantAgents string
+	}{
+		{name: "excluded by name", enabled: true, exclude: "echo-a", wantCode: http.StatusOK, wantAgents: "echo-b,echo-c,echo-b"},
+		{name: "excluded by namespace and name", enabled: true, exclude: "default/echo-a, echo-c", wantCode: http.StatusOK, wantAgents: "echo-b,echo-b,echo-b"},
+		{name: "all excluded falls back", enabled: true, exclude: "echo-a,echo-b,echo-c", wantCode: http.StatusOK, wantAgents: "echo-a,echo-b,echo-c"},
+		{name: "backend not in rule", enabled: true, exclude: "echo-a,other", wantCode: http.StatusBadRequest},
+		{name: "ignored when disabled", enabled: false, exclude: "echo-a", wantCode: http.StatusOK, wantAgents: "echo-a,echo-b,echo-c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t, config)
+			h.SetDebugHeaders(tt.enabled)
+			header := map[string]string{BackendExcludeHeader: tt.exclude}
+
+			if tt.wantCode != http.StatusOK {
+				rec, resp := invokeWithHeaders(t, h, req, header)
+				if rec.Code != tt.wantCode {
+					t.Fatalf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+				}
+				if !strings.Contains(resp.Error, BackendExcludeHeader) || !strings.Contains(resp.Error, "other") {
+					t.Errorf("expected error naming the header and backend, got %q", resp.Error)
+				}
+				return
+			}
+
+			var agents []string
+			for i := 0; i < 3; i++ {
+				rec, resp := invokeWithHeaders(t, h, req, header)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+				}
+				agents = append(agents, resp.Agent)
+			}
+			if got := strings.Join(agents, ","); got != tt.wantAgents {
+				t.Errorf("expected %s, got %s", tt.wantAgents, got)
+			}
+		})
+	}
+}
+
+func TestHandleListErrors(t *testing.T) {
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+	h.SetAdminToken("s3cret")
+
+	if rec, _ := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"}); rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %d", rec.Code)
+	}
+	if rec, _ := invoke(t, h, InvokeRequest{Agent: "missing", Query: "ping"}); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+
+	listErrors := func(auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/errors", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := listErrors(""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", rec.Code)
+	}
+	if rec := listErrors("Bearer wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with wrong token, got %d", rec.Code)
+	}
+
+	rec := listErrors("Bearer s3cret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Errors []ErrorRecord `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode errors: %v", err)
+	}
+	if len(body.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %+v", body.Errors)
+	}
+	if body.Errors[0].ErrorType != "no_agent" || body.Errors[0].StatusCode != http.StatusNotFound {
+		t.Errorf("expected newest error no_agent/404, got %+v", body.Errors[0])
+	}
+	if body.Errors[1].ErrorType != "agent_error" || body.Errors[1].Agent != "echo" || body.Errors[1].Route != "echo-rule" {
+		t.Errorf("expected agent_error from echo on echo-rule, got %+v", body.Errors[1])
+	}
+	if !strings.Contains(body.Errors[1].Message, "boom") {
+		t.Errorf("expected agent error message, got %q", body.Errors[1].Message)
+	}
+}
+
+func TestHandleListErrors_DisabledWithoutToken(t *testing.T) {
+	h := newTestHandler(t, singleRuleConfig())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/errors", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 when no admin token is configured, got %d", rec.Code)
+	}
+}
+
+func TestHandleGetConfig(t *testing.T) {
+	config := singleRuleConfig(routes.CompiledRouteBackend{
+		AgentName: "echo",
+		Namespace: "default",
+		Endpoint:  "echo.default.svc:8080",
+		Weight:    100,
+		Ready:     true,
+	})
+	config.Rules[0].QueueTimeoutMs = 500
+	config.Defaults = &routes.RouteDefaultConfig{MaxConcurrent: 10, MaxQueueSize: 5, QueueTimeoutMs: 1000}
+	h := newTestHandler(t, config)
+	h.SetAdminToken("s3cret")
+
+	unauthorized := httptest.NewRecorder()
+	h.ServeHTTP(unauthorized, httptest.NewRequest(http.MethodGet, "/v1/config", nil))
+	if unauthorized.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", unauthorized.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/config", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Config     routes.RouteConfig `json:"config"`
+		ConfigHash string             `json:"configHash"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	if !reflect.DeepEqual(body.Config, *h.table.GetConfig()) {
+		t.Errorf("expected loaded config %+v, got %+v", *h.table.GetConfig(), body.Config)
+	}
+	if body.ConfigHash == "" || body.ConfigHash != h.table.ConfigHash() {
+		t.Errorf("expected config hash %q, got %q", h.table.ConfigHash(), body.ConfigHash)
+	}
+}
+
+func TestHandleInvoke_RequestDeadlineHeader(t *testing.T) {
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		// Drain the body so the server notices the client disconnecting.
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			_, _ = w.Write([]byte(`{"result":"late"}`))
+		}
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+
+	start := time.Now()
+	rec, resp := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, map[string]string{
+		RequestDeadlineHeader: "50ms",
+	})
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the deadline to cut the forward short, took %v", elapsed)
+	}
+	if resp.Success {
+		t.Errorf("expected failure, got %+v", resp)
+	}
+}
+
+func TestHandleInvoke_InvalidRequestDeadlineHeader(t *testing.T) {
+	h := newTestHandler(t, singleRuleConfig(namedBackends(t, "echo")...))
+
+	tests := map[string]int{
+		"soon":                 http.StatusBadRequest,
+		"-1s":                  http.StatusBadRequest,
+		"2000-01-01T00:00:00Z": http.StatusGatewayTimeout,
+	}
+	for value, want := range tests {
+		rec, _ := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, map[string]string{
+			RequestDeadlineHeader: value,
+		})
+		if rec.Code != want {
+			t.Errorf("%s %q: expected %d, got %d", RequestDeadlineHeader, value, want, rec.Code)
+		}
+	}
+}
+
+// slowBackend answers only after 5s or once the caller gives up.
+func slowBackend(t *testing.T) routes.CompiledRouteBackend {
+	return newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			_, _ = w.Write([]byte(`{"result":"late"}`))
+		}
+	})
+}
+
+func TestHandleInvoke_RequestTimeoutHeader(t *testing.T) {
+	h := newTestHandler(t, singleRuleConfig(slowBackend(t)))
+
+	start := time.Now()
+	rec, _ := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, map[string]string{
+		RequestTimeoutHeader: "50ms",
+	})
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the timeout to cut the forward short, took %v", elapsed)
+	}
+}
+
+func TestHandleInvoke_RequestTimeoutHeaderClamped(t *testing.T) {
+	config := singleRuleConfig(slowBackend(t))
+	config.Defaults = &routes.RouteDefaultConfig{RequestTimeoutMs: 50}
+	h := newTestHandler(t, config)
+
+	start := time.Now()
+	rec, _ := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, map[string]string{
+		RequestTimeoutHeader: "1m",
+	})
+
+	if rec.Code == http.StatusOK {
+		t.Fatalf("expected the request to time out, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the timeout capped at the request timeout, took %v", elapsed)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	h := NewHandler(routes.NewTable(), time.Minute)
+	h.SetMaxRequestTimeout(2 * time.Minute)
+
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: time.Minute},
+		{value: "10s", want: 10 * time.Second},
+		{value: "90s", want: 90 * time.Second},
+		{value: "1h", want: 2 * time.Minute},
+		{value: "soon", want: time.Minute, wantErr: true},
+		{value: "-1s", want: time.Minute, wantErr: true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/v1/invoke", nil)
+		if tt.value != "" {
+			r.Header.Set(RequestTimeoutHeader, tt.value)
+		}
+		got, err := h.requestTimeout(r)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected %v (error %v), got %v (%v)", tt.value, tt.want, tt.wantErr, got, err)
+		}
+	}
+	if h.httpClient.Timeout != 2*time.Minute {
+		t.Errorf("expected the client timeout raised to the maximum, got %v", h.httpClient.Timeout)
+	}
+}
+
+func TestHandleInvoke_InvalidRequestTimeoutHeader(t *testing.T) {
+	h := newTestHandler(t, singleRuleConfig(namedBackends(t, "echo")...))
+	headers := map[string]string{RequestTimeoutHeader: "soon"}
+
+	if rec, _ := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, headers); rec.Code != http.StatusOK {
+		t.Errorf("expected an invalid timeout ignored, got %d", rec.Code)
+	}
+
+	h.SetStrictRequestTimeout(true)
+	if rec, _ := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, headers); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 in strict mode, got %d", rec.Code)
+	}
+}
+
+func TestParseRequestDeadline(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "1500ms", want: now.Add(1500 * time.Millisecond)},
+		{value: "2s", want: now.Add(2 * time.Second)},
+		{value: "2024-01-15T10:30:05Z", want: now.Add(5 * time.Second)},
+		{value: "2024-01-15T10:30:00.25Z", want: now.Add(250 * time.Millisecond)},
+		{value: "0s", wantErr: true},
+		{value: "-1s", wantErr: true},
+		{value: "tomorrow", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseRequestDeadline(tt.value, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTraceID(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", want: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{value: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-00", want: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{value: "00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01"},
+		{value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{value: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{value: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(TraceparentHeader, tt.value)
+			if got := traceID(header); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHandleInvoke_Draining(t *testing.T) {
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":"ok"}`))
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+
+	if rec, _ := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"}); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 before draining, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	h.Drain()
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while draining, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if resp.Error != circuit.ErrDraining.Error() {
+		t.Errorf("expected draining error, got %q", resp.Error)
+	}
+}
+
+func TestHandleInvoke_CircuitOpensOnFailures(t *testing.T) {
+	var calls atomic.Int32
+	config := singleRuleConfig(statusBackend(t, "failing", http.StatusInternalServerError, &calls))
+	config.Defaults = &routes.RouteDefaultConfig{ErrorThresholdPercent: 50, MinimumRequests: 2, CooldownMs: 60000}
+	h := newTestHandler(t, config)
+
+	for i := 0; i < 2; i++ {
+		if rec, _ := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"}); rec.Code != http.StatusBadGateway {
+			t.Fatalf("expected the agent's failure, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(resp.Error, "circuit breaker open") {
+		t.Fatalf("expected 503 from the open circuit, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected the agent not called while the circuit is open, got %d calls", got)
+	}
+	if !strings.Contains(scrapeMetrics(t), `mcpfabric_circuit_breaker_state{route="echo-rule"} 1`) {
+		t.Error("expected the circuit breaker state recorded as open")
+	}
+}
+
+func TestHandleInvoke_ClientDeadlineDoesNotOpenCircuit(t *testing.T) {
+	config := singleRuleConfig(slowBackend(t))
+	config.Defaults = &routes.RouteDefaultConfig{ErrorThresholdPercent: 50, MinimumRequests: 2, CooldownMs: 60000}
+	h := newTestHandler(t, config)
+
+	for _, header := range []map[string]string{
+		{RequestTimeoutHeader: "10ms"},
+		{RequestDeadlineHeader: "10ms"},
+		{RequestTimeoutHeader: "10ms"},
+	} {
+		if rec, _ := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, header); rec.Code != http.StatusGatewayTimeout {
+			t.Fatalf("expected 504, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if state := h.breakers.Get("echo-rule").State(); state != circuit.StateClosed {
+		t.Errorf("expected the caller's own deadlines not to open the circuit, got %s", state)
+	}
+}
+
+func TestRecordBreakerOutcome(t *testing.T) {
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		clientDeadline bool
+		status         int
+		err            error
+		wantRecorded   bool
+	}{
+		{name: "agent failure", ctx: context.Background(), status: http.StatusBadGateway, err: &agentStatusError{status: 500}, wantRecorded: true},
+		{name: "route timeout", ctx: expired, status: http.StatusGatewayTimeout, err: context.DeadlineExceeded, wantRecorded: true},
+		{name: "client deadline", ctx: expired, clientDeadline: true, status: http.StatusGatewayTimeout, err: context.DeadlineExceeded},
+		{name: "client hung up", ctx: cancelled, status: http.StatusBadGateway, err: context.Canceled},
+		{name: "backend saturated", ctx: context.Background(), status: http.StatusServiceUnavailable, err: errBackendSaturated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker := circuit.New("route", circuit.Config{MaxConcurrent: 1, ErrorThresholdPercent: 100, MinimumRequests: 1, Cooldown: time.Minute})
+			recordBreakerOutcome(tt.ctx, breaker, tt.clientDeadline, tt.status, tt.err)
+			if recorded := breaker.State() == circuit.StateOpen; recorded != tt.wantRecorded {
+				t.Errorf("failure recorded = %v, want %v", recorded, tt.wantRecorded)
+			}
+		})
+	}
+}
+
+func TestHandleResetBreakers(t *testing.T) {
+	var calls atomic.Int32
+	config := singleRuleConfig(statusBackend(t, "failing", http.StatusInternalServerError, &calls))
+	config.Defaults = &routes.RouteDefaultConfig{ErrorThresholdPercent: 50, MinimumRequests: 2, CooldownMs: 60000}
+	h := newTestHandler(t, config)
+	h.SetAdminToken("s3cret")
+
+	for i := 0; i < 3; i++ {
+		_, _ = invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+	}
+	if state := h.breakers.Get("echo-rule").State(); state != circuit.StateOpen {
+		t.Fatalf("expected the circuit open, got %s", state)
+	}
+
+	reset := func(path, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := reset("/v1/admin/breakers/echo-rule/reset", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", rec.Code)
+	}
+	if rec := reset("/v1/admin/breakers/missing/reset", "Bearer s3cret"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a route without a breaker, got %d", rec.Code)
+	}
+
+	rec := reset("/v1/admin/breakers/echo-rule/reset", "Bearer s3cret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var status BreakerStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to decode breaker status: %v", err)
+	}
+	if status.Route != "echo-rule" || status.State != "closed" {
+		t.Errorf("expected echo-rule closed, got %+v", status)
+	}
+	if !strings.Contains(scrapeMetrics(t), `mcpfabric_circuit_breaker_state{route="echo-rule"} 0`) {
+		t.Error("expected the circuit breaker state recorded as closed")
+	}
+
+	// The agent is called again once the circuit is closed
+	if rec, _ := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"}); rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected the agent's failure after the reset, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("expected the agent called after the reset, got %d calls", got)
+	}
+
+	rec = reset("/v1/admin/breakers/reset", "Bearer s3cret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var all struct {
+		Breakers []BreakerStatus `json:"breakers"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &all); err != nil {
+		t.Fatalf("failed to decode breaker statuses: %v", err)
+	}
+	if len(all.Breakers) != 1 || all.Breakers[0].Route != "echo-rule" || all.Breakers[0].State != "closed" {
+		t.Errorf("expected echo-rule closed, got %+v", all.Breakers)
+	}
+}
+
+func TestHandleInvoke_AgentInflightGauge(t *testing.T) {
+	arrived := make(chan struct{})
+	release := make(chan struct{})
+	backend := newStubBackend(t, "inflight", func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		_, _ = w.Write([]byte(`{"result":"ok"}`))
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/v1/invoke", strings.NewReader(`{"agent":"echo","query":"ping"}`))
+			h.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+	}
+	<-arrived
+	<-arrived
+
+	if !strings.Contains(scrapeMetrics(t), `mcpfabric_gateway_agent_inflight{agent="inflight"} 2`) {
+		t.Error("expected both concurrent forwards counted as in flight")
+	}
+
+	close(release)
+	wg.Wait()
+	if !strings.Contains(scrapeMetrics(t), `mcpfabric_gateway_agent_inflight{agent="inflight"} 0`) {
+		t.Error("expected no forwards in flight once both completed")
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/circuit"
+	"github.com/jarsater/mcp-fabric/gateway/internal/metrics"
+	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
+)
+
+// hedgeResult is the outcome of one request of a hedged forward.
+type hedgeResult struct {
+	backend *routes.CompiledRouteBackend
+	result  interface{}
+	err     error
+}
+
+// forwardHedged forwards req to backend and, if it has not answered after
+// delay, also to another of candidates not in exclude. The first successful response wins
+// and the other request is cancelled; if both fail, the last error is
+// returned. It returns the backend whose response is used.
+//
+// A request is hedged at most once, and only when another backend is
+// below its concurrency cap and the route's circuit breaker has a slot
+// free without queueing, so hedging never more than doubles a request and
+// never takes capacity from requests waiting for it. If backend itself
+// reached its concurrency cap since it was selected, errBackendSaturated is
+// returned.
+func (h *Handler) forwardHedged(ctx context.Context, breaker *circuit.Breaker, route string, backend *routes.CompiledRouteBackend, candidates []routes.CompiledRouteBackend, exclude []string, delay time.Duration, req *InvokeRequest, header http.Header) (interface{}, *routes.CompiledRouteBackend, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered so the losing request never blocks after we return
+	results := make(chan hedgeResult, 2)
+	call := func(b *routes.CompiledRouteBackend, done, release func()) {
+		result, err := h.forwardToAgent(ctx, b, req, header)
+		done()
+		release()
+		results <- hedgeResult{backend: b, result: result, err: err}
+	}
+	primaryDone, ok := h.selector.TryTrack(backend)
+	if !ok {
+		return nil, backend, errBackendSaturated
+	}
+	go call(backend, primaryDone, func() {})
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var hedge *routes.CompiledRouteBackend
+	pending := 1
+	for {
+		select {
+		case <-timer.C:
+			hedge = h.hedgeBackend(backend, candidates, exclude)
+			if hedge == nil || !breaker.TryAcquire() {
+				hedge = nil
+				continue
+			}
+			hedgeDone, ok := h.selector.TryTrack(hedge)
+			if !ok {
+				breaker.Release()
+				hedge = nil
+				continue
+			}
+			metrics.RecordBackendForward(hedge.AgentName, hedge.Namespace)
+			pending++
+			go call(hedge, hedgeDone, breaker.Release)
+
+		case r := <-results:
+			pending--
+			if r.err != nil && pending > 0 {
+				continue
+			}
+			if hedge != nil {
+				winner := "primary"
+				if r.backend == hedge {
+					winner = "hedge"
+				}
+				metrics.RecordHedgedRequest(route, winner)
+			}
+			return r.result, r.backend, r.err
+		}
+	}
+}
+
+// hedgeBackend picks the backend for a hedge request: the candidate other
+// than primary with the fewest requests in flight, skipping excluded
+// backends and those at their concurrency cap. It returns nil if there is
+// none.
+func (h *Handler) hedgeBackend(primary *routes.CompiledRouteBackend, candidates []routes.CompiledRouteBackend, exclude []string) *routes.CompiledRouteBackend {
+	others := make([]routes.CompiledRouteBackend, 0, len(candidates))
+	for _, b := range h.selector.Available(candidates) {
+		if b.Endpoint != primary.Endpoint && !slices.ContainsFunc(exclude, b.HasName) {
+			others = append(others, b)
+		}
+	}
+	if len(others) == 0 {
+		return nil
+	}
+	return h.selector.SelectLeastConnections(others)
+}
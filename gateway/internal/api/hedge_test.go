@@ -0,0 +1,134 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
+)
+
+// hedgeConfig returns a single-rule config hedging after hedgeAfter. It
+// selects round-robin, so a new handler's first request goes to the first
+// backend.
+func hedgeConfig(hedgeAfter time.Duration, backends ...routes.CompiledRouteBackend) *routes.RouteConfig {
+	config := singleRuleConfig(backends...)
+	config.Rules[0].HedgeAfterMs = hedgeAfter.Milliseconds()
+	config.Rules[0].SelectionStrategy = routes.StrategyNameRoundRobin
+	return config
+}
+
+// countingBackend answers after delay, counting its calls.
+func countingBackend(t *testing.T, name string, delay time.Duration, calls *atomic.Int32) routes.CompiledRouteBackend {
+	return newStubBackend(t, name, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = io.Copy(io.Discard, r.Body)
+		time.Sleep(delay)
+		_, _ = w.Write([]byte(`{"result":"` + name + `"}`))
+	})
+}
+
+func TestHandleInvoke_HedgesSlowPrimary(t *testing.T) {
+	aborted := make(chan struct{}, 1)
+	slow := newStubBackend(t, "slow", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+			aborted <- struct{}{}
+		case <-time.After(5 * time.Second):
+			_, _ = w.Write([]byte(`{"result":"slow"}`))
+		}
+	})
+	var fastCalls atomic.Int32
+	fast := countingBackend(t, "fast", 0, &fastCalls)
+	h := newTestHandler(t, hedgeConfig(20*time.Millisecond, slow, fast))
+
+	start := time.Now()
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+
+	if rec.Code != http.StatusOK || resp.Agent != "fast" {
+		t.Fatalf("expected the hedge to answer, got %d: %+v", rec.Code, resp)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the hedge to cut latency, took %v", elapsed)
+	}
+	if n := fastCalls.Load(); n != 1 {
+		t.Errorf("expected one hedge request, got %d", n)
+	}
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Error("expected the slow primary request to be cancelled")
+	}
+}
+
+func TestHandleInvoke_NoHedgeWhenPrimaryIsFast(t *testing.T) {
+	var primaryCalls, otherCalls atomic.Int32
+	primary := countingBackend(t, "primary", 0, &primaryCalls)
+	other := countingBackend(t, "other", 0, &otherCalls)
+	h := newTestHandler(t, hedgeConfig(time.Second, primary, other))
+
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+
+	if rec.Code != http.StatusOK || resp.Agent != "primary" {
+		t.Fatalf("expected the primary to answer, got %d: %+v", rec.Code, resp)
+	}
+	if n := otherCalls.Load(); n != 0 {
+		t.Errorf("expected no hedge request, got %d", n)
+	}
+}
+
+func TestHandleInvoke_HedgeRespectsCircuitBreaker(t *testing.T) {
+	var primaryCalls, otherCalls atomic.Int32
+	primary := countingBackend(t, "primary", 100*time.Millisecond, &primaryCalls)
+	other := countingBackend(t, "other", 0, &otherCalls)
+	config := hedgeConfig(10*time.Millisecond, primary, other)
+	config.Defaults = &routes.RouteDefaultConfig{MaxConcurrent: 1, MaxQueueSize: 1, QueueTimeoutMs: 1000}
+	h := newTestHandler(t, config)
+
+	// The primary holds the route's only breaker slot, so no hedge is sent
+	rec, resp := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+
+	if rec.Code != http.StatusOK || resp.Agent != "primary" {
+		t.Fatalf("expected the primary to answer, got %d: %+v", rec.Code, resp)
+	}
+	if n := otherCalls.Load(); n != 0 {
+		t.Errorf("expected no hedge request without a free breaker slot, got %d", n)
+	}
+}
+
+func TestHandleInvoke_NoHedgeForPinnedBackend(t *testing.T) {
+	var pinnedCalls, otherCalls atomic.Int32
+	other := countingBackend(t, "other", 0, &otherCalls)
+	pinned := countingBackend(t, "pinned", 100*time.Millisecond, &pinnedCalls)
+	h := newTestHandler(t, hedgeConfig(10*time.Millisecond, other, pinned))
+	h.SetDebugHeaders(true)
+
+	rec, resp := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, map[string]string{BackendPinHeader: "pinned"})
+
+	if rec.Code != http.StatusOK || resp.Agent != "pinned" {
+		t.Fatalf("expected the pinned backend to answer, got %d: %+v", rec.Code, resp)
+	}
+	if n := otherCalls.Load(); n != 0 {
+		t.Errorf("expected no hedge request for a pinned backend, got %d", n)
+	}
+}
+
+func TestHandleInvoke_HedgeSkipsExcludedBackend(t *testing.T) {
+	var primaryCalls, excludedCalls atomic.Int32
+	primary := countingBackend(t, "primary", 100*time.Millisecond, &primaryCalls)
+	excluded := countingBackend(t, "excluded", 0, &excludedCalls)
+	h := newTestHandler(t, hedgeConfig(10*time.Millisecond, primary, excluded))
+	h.SetDebugHeaders(true)
+
+	rec, resp := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "ping"}, map[string]string{BackendExcludeHeader: "excluded"})
+
+	if rec.Code != http.StatusOK || resp.Agent != "primary" {
+		t.Fatalf("expected the primary to answer, got %d: %+v", rec.Code, resp)
+	}
+	if n := excludedCalls.Load(); n != 0 {
+		t.Errorf("expected no hedge request to the excluded backend, got %d", n)
+	}
+}
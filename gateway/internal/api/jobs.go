@@ -0,0 +1,147 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultMaxAsyncJobs is how many async invokes may run at once.
+const DefaultMaxAsyncJobs = 100
+
+// jobsPathPrefix prefixes the path polled for an async invoke job.
+const jobsPathPrefix = "/v1/jobs/"
+
+// Async job states.
+const (
+	JobPending   = "pending"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// AsyncJob is the state of an async invoke, as returned by GET /v1/jobs/{id}.
+type AsyncJob struct {
+	JobID       string     `json:"jobId"`
+	Status      string     `json:"status"`
+	SubmittedAt time.Time  `json:"submittedAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	// StatusCode and Response are what a synchronous invoke would have
+	// returned, once the job is no longer pending.
+	StatusCode int             `json:"statusCode,omitempty"`
+	Response   *InvokeResponse `json:"response,omitempty"`
+}
+
+// SetMaxAsyncJobs sets how many async invokes may run at once; further
+// submissions are rejected with 503. Non-positive values restore the default.
+func (h *Handler) SetMaxAsyncJobs(n int) {
+	if n <= 0 {
+		n = DefaultMaxAsyncJobs
+	}
+	h.maxAsyncJobs = int64(n)
+}
+
+// isAsyncInvoke reports whether an invoke request asks to run as a job.
+func isAsyncInvoke(r *http.Request) bool {
+	return r.URL.Query().Get("async") == "true"
+}
+
+// handleAsyncInvoke accepts an invoke request as a job, answering 202 with
+// its ID at once, and runs it in the background like a synchronous invoke,
+// through route selection and the route's circuit breaker.
+func (h *Handler) handleAsyncInvoke(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, &InvokeRequest{})
+	}
+	if err != nil {
+		h.requestError(w, http.StatusBadRequest, "", "", "invalid_request", "invalid request body: "+err.Error())
+		return
+	}
+
+	if h.asyncRunning.Add(1) > h.maxAsyncJobs {
+		h.asyncRunning.Add(-1)
+		h.requestError(w, http.StatusServiceUnavailable, "", "", "async_limit", "too many async jobs running")
+		return
+	}
+
+	job := AsyncJob{JobID: newCorrelationID(), Status: JobPending, SubmittedAt: time.Now()}
+	h.results.Put(job.JobID, job)
+
+	// The job outlives the submitting request, so it must not be canceled
+	// with it; runAsyncInvoke bounds it by the request timeout
+	req := r.Clone(context.WithoutCancel(r.Context()))
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.Header.Del("Accept")
+	go h.runAsyncInvoke(job, req)
+
+	w.Header().Set("Location", jobsPathPrefix+job.JobID)
+	h.writeJSON(w, http.StatusAccepted, job)
+}
+
+// runAsyncInvoke runs an async invoke and stores its outcome.
+func (h *Handler) runAsyncInvoke(job AsyncJob, r *http.Request) {
+	defer h.asyncRunning.Add(-1)
+
+	timeout, _ := h.requestTimeout(r)
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	rec := &jobResponseWriter{header: make(http.Header)}
+	h.handleInvoke(rec, r.WithContext(ctx))
+
+	var resp InvokeResponse
+	if err := json.Unmarshal(rec.body.Bytes(), &resp); err != nil {
+		resp = InvokeResponse{Error: strings.TrimSpace(rec.body.String())}
+	}
+	completed := time.Now()
+	job.CompletedAt = &completed
+	job.StatusCode = rec.status
+	job.Response = &resp
+	job.Status = JobCompleted
+	if rec.status != http.StatusOK {
+		job.Status = JobFailed
+	}
+	h.results.Put(job.JobID, job)
+}
+
+// handleGetJob returns the state of an async invoke job: 404 for an unknown
+// ID and 410 once its result has expired or been evicted.
+func (h *Handler) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, jobsPathPrefix)
+	job, err := h.results.Get(id)
+	switch {
+	case errors.Is(err, errResultGone):
+		h.writeError(w, http.StatusGone, err.Error())
+	case err != nil:
+		h.writeError(w, http.StatusNotFound, err.Error())
+	default:
+		h.writeJSON(w, http.StatusOK, job)
+	}
+}
+
+// jobResponseWriter captures the response of an async invoke.
+type jobResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *jobResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *jobResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *jobResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// submitAsync posts an async invoke request for the echo agent.
+func submitAsync(t *testing.T, h http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/v1/invoke?async=true", bytes.NewReader([]byte(body)))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// getJob polls a job once.
+func getJob(t *testing.T, h http.Handler, id string) (*httptest.ResponseRecorder, AsyncJob) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, jobsPathPrefix+id, nil))
+	var job AsyncJob
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+			t.Fatalf("failed to decode job %q: %v", rec.Body.String(), err)
+		}
+	}
+	return rec, job
+}
+
+// awaitJob polls a job until it is no longer pending.
+func awaitJob(t *testing.T, h http.Handler, id string) AsyncJob {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		rec, job := getJob(t, h, id)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200 polling job, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if job.Status != JobPending {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the job to finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAsyncInvoke_SubmitPollComplete(t *testing.T) {
+	release := make(chan struct{})
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write([]byte(`{"result":"pong"}`))
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+
+	rec := submitAsync(t, h, `{"agent":"echo","query":"ping","correlationId":"c-1"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var submitted AsyncJob
+	if err := json.Unmarshal(rec.Body.Bytes(), &submitted); err != nil || submitted.JobID == "" {
+		t.Fatalf("expected a job handle, got %q", rec.Body.String())
+	}
+	if rec.Header().Get("Location") != jobsPathPrefix+submitted.JobID {
+		t.Errorf("expected Location of the job, got %q", rec.Header().Get("Location"))
+	}
+
+	// The job runs through the route's circuit breaker
+	if _, job := getJob(t, h, submitted.JobID); job.Status != JobPending {
+		t.Errorf("expected a pending job while the agent works, got %q", job.Status)
+	}
+	deadline := time.Now().Add(time.Second)
+	for h.breakers.Get("echo-rule").Stats().Active != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the job to hold a breaker slot")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(release)
+
+	job := awaitJob(t, h, submitted.JobID)
+	if job.Status != JobCompleted || job.StatusCode != http.StatusOK || job.CompletedAt == nil {
+		t.Fatalf("expected a completed job, got %+v", job)
+	}
+	if job.Response == nil || !job.Response.Success || job.Response.Agent != "echo" || job.Response.CorrelationID != "c-1" {
+		t.Errorf("unexpected job response: %+v", job.Response)
+	}
+	if h.asyncRunning.Load() != 0 {
+		t.Errorf("expected no async jobs running, got %d", h.asyncRunning.Load())
+	}
+}
+
+func TestAsyncInvoke_FailedJob(t *testing.T) {
+	h := newTestHandler(t, singleRuleConfig(newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad input", http.StatusBadRequest)
+	})))
+
+	rec := submitAsync(t, h, `{"agent":"echo","query":"ping"}`)
+	var submitted AsyncJob
+	_ = json.Unmarshal(rec.Body.Bytes(), &submitted)
+
+	job := awaitJob(t, h, submitted.JobID)
+	if job.Status != JobFailed || job.StatusCode != http.StatusBadGateway || job.Response == nil || job.Response.Success {
+		t.Errorf("expected a failed job with the synchronous error, got %+v", job)
+	}
+}
+
+func TestAsyncInvoke_Rejections(t *testing.T) {
+	h := newTestHandler(t, singleRuleConfig(newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {})))
+
+	if rec := submitAsync(t, h, `{not json`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid body, got %d", rec.Code)
+	}
+
+	h.SetMaxAsyncJobs(1)
+	h.asyncRunning.Store(1)
+	if rec := submitAsync(t, h, `{"agent":"echo","query":"ping"}`); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 at the async job limit, got %d", rec.Code)
+	}
+	if h.asyncRunning.Load() != 1 {
+		t.Errorf("expected the rejected job not to count, got %d running", h.asyncRunning.Load())
+	}
+}
+
+func TestGetJob_UnknownAndExpired(t *testing.T) {
+	h := newTestHandler(t, singleRuleConfig())
+	h.SetResultStore(time.Minute, 1)
+	h.results.Put("old", AsyncJob{JobID: "old"})
+	h.results.Put("new", AsyncJob{JobID: "new"})
+
+	if rec, _ := getJob(t, h, "never-issued"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown job, got %d", rec.Code)
+	}
+	if rec, _ := getJob(t, h, "old"); rec.Code != http.StatusGone {
+		t.Errorf("expected 410 for an evicted job, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/metrics"
+	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
+)
+
+// recordOutcome feeds the result of one agent call to outlier detection:
+// connection errors and 5xx responses count as failures. Calls the caller
+// gave up on say nothing about the backend and are not recorded.
+func (h *Handler) recordOutcome(ctx context.Context, backend *routes.CompiledRouteBackend, resp *http.Response, err error) {
+	policy := h.table.GetDefaults().OutlierPolicy()
+	if !policy.Enabled() || ctx.Err() != nil {
+		return
+	}
+
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+	if h.selector.RecordResult(backend, failed, policy) {
+		// Clear the gauge once the window passes, even if no request
+		// comes along to re-admit the backend
+		agent, endpoint := backend.AgentName, backend.Endpoint
+		time.AfterFunc(policy.Ejection, func() {
+			if !h.selector.Ejected(endpoint) {
+				metrics.SetBackendEjected(agent, false)
+			}
+		})
+	}
+	metrics.SetBackendEjected(backend.AgentName, h.selector.Ejected(backend.Endpoint))
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/metrics"
+	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
+)
+
+// scrapeMetrics returns the gateway metrics exposition.
+func scrapeMetrics(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rec.Body.String()
+}
+
+func TestHandleInvoke_EjectsFailingBackend(t *testing.T) {
+	var flakyCalls, healthyCalls atomic.Int32
+	config := roundRobinConfig(
+		statusBackend(t, "flaky", http.StatusInternalServerError, &flakyCalls),
+		countingBackend(t, "healthy", 0, &healthyCalls),
+	)
+	config.Defaults = &routes.RouteDefaultConfig{OutlierConsecutiveErrors: 2, OutlierEjectionMs: 100}
+	h := newTestHandler(t, config)
+
+	for i := 0; i < 10; i++ {
+		if rec, _ := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"}); rec.Code != http.StatusOK {
+			t.Fatalf("expected failover to the healthy backend, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if got := flakyCalls.Load(); got != 2 {
+		t.Errorf("expected the failing backend skipped after 2 failures, got %d calls", got)
+	}
+	if !strings.Contains(scrapeMetrics(t), `mcpfabric_gateway_backend_ejected{agent="flaky"} 1`) {
+		t.Error("expected the ejected gauge set")
+	}
+
+	// After the window the backend is tried again
+	time.Sleep(150 * time.Millisecond)
+	if !strings.Contains(scrapeMetrics(t), `mcpfabric_gateway_backend_ejected{agent="flaky"} 0`) {
+		t.Error("expected the ejected gauge cleared after the window")
+	}
+	for i := 0; i < 4; i++ {
+		invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"})
+	}
+	if got := flakyCalls.Load(); got <= 2 {
+		t.Errorf("expected the backend re-admitted after the ejection window, got %d calls", got)
+	}
+}
+
+func TestHandleInvoke_AllBackendsEjected(t *testing.T) {
+	var calls atomic.Int32
+	config := singleRuleConfig(statusBackend(t, "only", http.StatusServiceUnavailable, &calls))
+	config.Defaults = &routes.RouteDefaultConfig{OutlierConsecutiveErrors: 1, OutlierEjectionMs: 60000}
+	h := newTestHandler(t, config)
+
+	for i := 0; i < 3; i++ {
+		if rec, _ := invoke(t, h, InvokeRequest{Agent: "echo", Query: "ping"}); rec.Code != http.StatusBadGateway {
+			t.Fatalf("expected the agent's failure, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("expected the only backend still called while ejected, got %d calls", got)
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultResultTTL is how long an async invoke result is kept.
+	DefaultResultTTL = 15 * time.Minute
+
+	// DefaultResultStoreSize is how many async invoke results are kept.
+	DefaultResultStoreSize = 1000
+)
+
+var (
+	// errResultNotFound reports an ID the store has never seen (404).
+	errResultNotFound = errors.New("result not found")
+
+	// errResultGone reports an ID whose result expired or was evicted (410).
+	errResultGone = errors.New("result expired or evicted")
+)
+
+// ResultStore is a bounded, thread-safe store of async invoke results keyed
+// by ID. Results expire after the TTL, and once the store is full each new
+// result evicts the oldest. The IDs of removed results are remembered, up to
+// the store size, so lookups can tell them apart from IDs never issued.
+type ResultStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	order   *list.List // *storedResult, oldest first
+	entries map[string]*list.Element
+
+	// tombstones holds the IDs of removed results, oldest first.
+	tombstones []string
+	removed    map[string]struct{}
+
+	now func() time.Time
+}
+
+type storedResult struct {
+	id      string
+	value   interface{}
+	expires time.Time
+}
+
+// NewResultStore creates a store keeping at most size results for ttl each.
+// Non-positive values use DefaultResultStoreSize and DefaultResultTTL.
+func NewResultStore(ttl time.Duration, size int) *ResultStore {
+	if ttl <= 0 {
+		ttl = DefaultResultTTL
+	}
+	if size <= 0 {
+		size = DefaultResultStoreSize
+	}
+	return &ResultStore{
+		ttl:     ttl,
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		removed: make(map[string]struct{}),
+		now:     time.Now,
+	}
+}
+
+// Put stores value under id, replacing and renewing any result already
+// stored there. The oldest result is evicted when the store is full.
+func (s *ResultStore) Put(id string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.expire(now)
+
+	if el, ok := s.entries[id]; ok {
+		s.order.Remove(el)
+	} else if s.order.Len() >= s.size {
+		s.remove(s.order.Front())
+	}
+	s.entries[id] = s.order.PushBack(&storedResult{id: id, value: value, expires: now.Add(s.ttl)})
+}
+
+// Get returns the result stored under id. It fails with errResultGone if
+// the result expired or was evicted, and errResultNotFound otherwise.
+func (s *ResultStore) Get(id string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(s.now())
+
+	if el, ok := s.entries[id]; ok {
+		return el.Value.(*storedResult).value, nil
+	}
+	if _, ok := s.removed[id]; ok {
+		return nil, errResultGone
+	}
+	return nil, errResultNotFound
+}
+
+// Len returns the number of stored results.
+func (s *ResultStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// expire removes results whose TTL has passed. Results are ordered by when
+// they were stored, so expired ones are always at the front.
+func (s *ResultStore) expire(now time.Time) {
+	for el := s.order.Front(); el != nil && !now.Before(el.Value.(*storedResult).expires); el = s.order.Front() {
+		s.remove(el)
+	}
+}
+
+// remove drops a result and records a tombstone for its ID, forgetting the
+// oldest tombstone once as many are kept as results.
+func (s *ResultStore) remove(el *list.Element) {
+	id := s.order.Remove(el).(*storedResult).id
+	delete(s.entries, id)
+
+	if len(s.tombstones) >= s.size {
+		delete(s.removed, s.tombstones[0])
+		s.tombstones = s.tombstones[1:]
+	}
+	s.tombstones = append(s.tombstones, id)
+	s.removed[id] = struct{}{}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestResultStore returns a store whose clock is advanced by the
+// returned function.
+func newTestResultStore(ttl time.Duration, size int) (*ResultStore, func(time.Duration)) {
+	s := NewResultStore(ttl, size)
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	return s, func(d time.Duration) { now = now.Add(d) }
+}
+
+func assertResult(t *testing.T, s *ResultStore, id string, want interface{}, wantErr error) {
+	t.Helper()
+	got, err := s.Get(id)
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("%s: expected error %v, got %v", id, wantErr, err)
+	}
+	if got != want {
+		t.Errorf("%s: expected %v, got %v", id, want, got)
+	}
+}
+
+func TestResultStore_Expiry(t *testing.T) {
+	s, advance := newTestResultStore(time.Minute, 10)
+	s.Put("a", "first")
+	advance(30 * time.Second)
+	s.Put("b", "second")
+
+	assertResult(t, s, "a", "first", nil)
+	assertResult(t, s, "missing", nil, errResultNotFound)
+
+	advance(30 * time.Second)
+	assertResult(t, s, "a", nil, errResultGone)
+	assertResult(t, s, "b", "second", nil)
+
+	// Replacing a result renews its TTL
+	s.Put("b", "updated")
+	advance(45 * time.Second)
+	assertResult(t, s, "b", "updated", nil)
+
+	advance(15 * time.Second)
+	assertResult(t, s, "b", nil, errResultGone)
+	if n := s.Len(); n != 0 {
+		t.Errorf("expected an empty store, got %d results", n)
+	}
+}
+
+func TestResultStore_Eviction(t *testing.T) {
+	s, _ := newTestResultStore(time.Hour, 2)
+	s.Put("a", 1)
+	s.Put("b", 2)
+	s.Put("a", 10) // replacing does not evict
+	s.Put("c", 3)  // evicts b, now the oldest
+
+	assertResult(t, s, "a", 10, nil)
+	assertResult(t, s, "b", nil, errResultGone)
+	assertResult(t, s, "c", 3, nil)
+
+	// Tombstones are bounded by the store size too
+	s.Put("d", 4)
+	s.Put("e", 5)
+	s.Put("f", 6)
+	assertResult(t, s, "b", nil, errResultNotFound)
+	assertResult(t, s, "c", nil, errResultGone)
+	assertResult(t, s, "d", nil, errResultGone)
+}
+
+func TestResultStore_Concurrent(t *testing.T) {
+	s := NewResultStore(time.Hour, 10)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("job-%d", i)
+			s.Put(id, i)
+			_, _ = s.Get(id)
+		}(i)
+	}
+	wg.Wait()
+
+	if n := s.Len(); n != 10 {
+		t.Errorf("expected store capped at 10, got %d", n)
+	}
+}
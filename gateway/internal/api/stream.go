@@ -0,0 +1,165 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
+)
+
+// streamChunkSize is the most agent output relayed in one SSE data event.
+const streamChunkSize = 32 * 1024
+
+// acceptsEventStream reports whether the client asked for a streamed
+// response with Accept: text/event-stream.
+func acceptsEventStream(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, accepted := range strings.Split(accept, ",") {
+			accepted, _, _ = strings.Cut(accepted, ";")
+			if strings.EqualFold(strings.TrimSpace(accepted), "text/event-stream") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// streamInvoke forwards req to backend and relays the agent's response body
+// to the client as server-sent events while it arrives: a "message" event
+// per chunk read, then a "done" event with the invoke metadata, or an
+// "error" event if the agent fails mid-stream. Failures before the agent
+// answers are returned as a JSON error like a buffered invoke. It returns
+// the status to record for the request, which for a stream that fails
+// after it started is the error's status rather than the 200 sent, and the
+// error the request failed with, if any.
+func (h *Handler) streamInvoke(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, backend *routes.CompiledRouteBackend, route string, req *InvokeRequest, header http.Header, start time.Time) (int, error) {
+	agent := backend.AgentName
+
+	body, err := agentRequestBody(req)
+	if err != nil {
+		return h.agentError(ctx, w, err, agent, route), err
+	}
+
+	// Bound the stream and retries by the request timeout
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.reqTimeout)
+		defer cancel()
+	}
+
+	done, ok := h.selector.TryTrack(backend)
+	if !ok {
+		return h.agentError(ctx, w, errBackendSaturated, agent, route), errBackendSaturated
+	}
+	defer done()
+
+	// Retries happen only until the agent answers; once events have been
+	// written a failure ends the stream.
+	retry := h.table.GetDefaults().RetryPolicy()
+	rateLimited, retries := 0, 0
+	var resp *http.Response
+	for {
+		resp, err = h.openInvoke(ctx, backend, body, header)
+		if err != nil {
+			if h.retryTransient(ctx, retry, &retries, backend) {
+				continue
+			}
+			return h.agentError(ctx, w, err, agent, route), err
+		}
+		if routes.RetryableStatus(resp.StatusCode) && h.retryTransient(ctx, retry, &retries, backend) {
+			_ = resp.Body.Close()
+			continue
+		}
+		if resp.StatusCode != http.StatusTooManyRequests {
+			break
+		}
+		_ = resp.Body.Close()
+		rateLimited++
+		delay, ok := routes.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if ok && h.rateLimit.Wait(ctx, rateLimited, delay) {
+			continue
+		}
+		err = &routes.RateLimitedError{Agent: agent, RetryAfter: delay}
+		return h.agentError(ctx, w, err, agent, route), err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		respBody, err := routes.ReadLimited(resp.Body, h.maxRespLen)
+		if err == nil {
+			err = &agentStatusError{status: resp.StatusCode, body: string(respBody)}
+		}
+		return h.agentError(ctx, w, err, agent, route), err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	buf := make([]byte, streamChunkSize)
+	var total int64
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			total += int64(n)
+			if total > h.maxRespLen {
+				err := routes.TooLargeError(h.maxRespLen)
+				return h.streamError(ctx, w, flusher, err, agent, route), err
+			}
+			writeSSEEvent(w, "message", buf[:n])
+			flusher.Flush()
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return h.streamError(ctx, w, flusher, readErr, agent, route), readErr
+		}
+	}
+
+	summary, _ := json.Marshal(InvokeResponse{
+		Success:       true,
+		Agent:         agent,
+		CorrelationID: req.CorrelationID,
+		LatencyMs:     time.Since(start).Milliseconds(),
+	})
+	writeSSEEvent(w, "done", summary)
+	flusher.Flush()
+	return http.StatusOK, nil
+}
+
+// streamError ends a stream that failed after it started with an "error"
+// event, records the failure and returns its status.
+func (h *Handler) streamError(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, err error, agent, route string) int {
+	status, errorType := agentErrorStatus(ctx, err)
+	message := "agent error: " + err.Error()
+	h.recordError(status, agent, route, errorType, message)
+
+	data, _ := json.Marshal(InvokeResponse{Success: false, Error: message, Agent: agent})
+	writeSSEEvent(w, "error", data)
+	flusher.Flush()
+	return status
+}
+
+// writeSSEEvent writes one server-sent event, splitting data into one
+// "data:" line per line so clients can rejoin it with newlines.
+func writeSSEEvent(w io.Writer, event string, data []byte) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "event: %s\n", event)
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		b.WriteString("data: ")
+		b.Write(bytes.TrimSuffix(line, []byte("\r")))
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	_, _ = w.Write(b.Bytes())
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sseEvent is one server-sent event read from a stream.
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readSSE sends events read from body until it ends.
+func readSSE(body io.Reader) <-chan sseEvent {
+	events := make(chan sseEvent)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(body)
+		var event sseEvent
+		var data []string
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = append(data, strings.TrimPrefix(line, "data: "))
+			case line == "":
+				event.data = strings.Join(data, "\n")
+				events <- event
+				event, data = sseEvent{}, nil
+			}
+		}
+	}()
+	return events
+}
+
+// postStream sends an invoke request for the echo agent asking for a stream.
+func postStream(t *testing.T, url string) *http.Response {
+	t.Helper()
+	body, _ := json.Marshal(InvokeRequest{Agent: "echo", Query: "count", CorrelationID: "c-1"})
+	req, _ := http.NewRequest(http.MethodPost, url+"/v1/invoke", bytes.NewReader(body))
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func nextEvent(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("stream ended early")
+		}
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return sseEvent{}
+}
+
+func TestHandleInvoke_StreamsEventStream(t *testing.T) {
+	release := make(chan struct{})
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("one\ntwo"))
+		w.(http.Flusher).Flush()
+		<-release
+		_, _ = w.Write([]byte("three"))
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+
+	resp := postStream(t, server.URL)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	events := readSSE(resp.Body)
+
+	// The first chunk arrives while the agent is still working
+	if event := nextEvent(t, events); event.name != "message" || event.data != "one\ntwo" {
+		t.Fatalf("expected the first chunk, got %+v", event)
+	}
+	if active := h.breakers.Get("echo-rule").Stats().Active; active != 1 {
+		t.Errorf("expected the breaker slot held while streaming, got %d active", active)
+	}
+	close(release)
+
+	if event := nextEvent(t, events); event.name != "message" || event.data != "three" {
+		t.Fatalf("expected the second chunk, got %+v", event)
+	}
+	done := nextEvent(t, events)
+	var summary InvokeResponse
+	if err := json.Unmarshal([]byte(done.data), &summary); err != nil || done.name != "done" {
+		t.Fatalf("expected a done event, got %+v", done)
+	}
+	if !summary.Success || summary.Agent != "echo" || summary.CorrelationID != "c-1" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	// The slot is released once the handler returns after the stream
+	deadline := time.Now().Add(time.Second)
+	for h.breakers.Get("echo-rule").Stats().Active != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the breaker slot to be released after the stream")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHandleInvoke_StreamFailsMidway(t *testing.T) {
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		// Promise more than is sent, so the gateway sees an unexpected EOF
+		w.Header().Set("Content-Length", "100")
+		_, _ = w.Write([]byte("partial"))
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+
+	events := readSSE(postStream(t, server.URL).Body)
+
+	if event := nextEvent(t, events); event.name != "message" || event.data != "partial" {
+		t.Fatalf("expected the partial chunk, got %+v", event)
+	}
+	if event := nextEvent(t, events); event.name != "error" || !strings.Contains(event.data, `"success":false`) {
+		t.Fatalf("expected an error event, got %+v", event)
+	}
+	if records := h.recentErrors.Recent(); len(records) != 1 || records[0].StatusCode != http.StatusBadGateway {
+		t.Errorf("expected the failure recorded with its final status, got %+v", records)
+	}
+}
+
+func TestHandleInvoke_StreamAgentErrorIsJSON(t *testing.T) {
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	h := newTestHandler(t, singleRuleConfig(backend))
+
+	rec, resp := invokeWithHeaders(t, h, InvokeRequest{Agent: "echo", Query: "hi"}, map[string]string{"Accept": "text/event-stream"})
+
+	if rec.Code != http.StatusBadGateway || resp.Success || !strings.Contains(resp.Error, "agent returned 500") {
+		t.Errorf("expected a JSON 502 before the stream starts, got %d: %+v", rec.Code, resp)
+	}
+}
+
+func TestHandleInvoke_StreamRetriesOnlyBeforeStarting(t *testing.T) {
+	var calls atomic.Int32
+	backend := newStubBackend(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		default:
+			// Fails after part of the body has been relayed
+			w.Header().Set("Content-Length", "100")
+			_, _ = w.Write([]byte("partial"))
+		}
+	})
+	h := newTestHandler(t, retryConfig(3, backend))
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+
+	events := readSSE(postStream(t, server.URL).Body)
+
+	if event := nextEvent(t, events); event.name != "message" || event.data != "partial" {
+		t.Fatalf("expected the partial chunk after a retry, got %+v", event)
+	}
+	if event := nextEvent(t, events); event.name != "error" {
+		t.Fatalf("expected an error event, got %+v", event)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected one retry before the stream started and none after, got %d calls", calls.Load())
+	}
+}
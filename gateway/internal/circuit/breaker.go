@@ -3,6 +3,7 @@ package circuit
 import (
 	"context"
 	"errors"
+	"slices"
 	"sync"
 	"time"
 
@@ -10,25 +11,43 @@ import (
 )
 
 var (
-	// ErrCircuitOpen is returned when the circuit breaker is open.
-	ErrCircuitOpen = errors.New("circuit breaker open: too many concurrent requests")
+	// ErrCircuitOpen is returned while the circuit breaker is open after
+	// too many failed requests.
+	ErrCircuitOpen = errors.New("circuit breaker open: too many recent failures")
 	// ErrQueueFull is returned when the queue is full.
 	ErrQueueFull = errors.New("queue full: cannot accept more requests")
 	// ErrQueueTimeout is returned when waiting in queue times out.
 	ErrQueueTimeout = errors.New("queue timeout: waited too long for capacity")
+	// ErrDraining is returned once the breaker is draining for shutdown.
+	ErrDraining = errors.New("draining: not accepting new requests")
 )
 
-// Breaker implements a simple concurrency-limiting circuit breaker.
+// Breaker implements a concurrency-limiting circuit breaker that can also
+// open after too many failed requests.
 type Breaker struct {
-	route         string
-	maxConcurrent int32
-	maxQueue      int32
-	queueTimeout  time.Duration
+	route          string
+	maxConcurrent  int32
+	maxQueue       int32
+	queueTimeout   time.Duration
+	errorThreshold int32
+	minRequests    int32
+	cooldown       time.Duration
 
 	mu       sync.Mutex
 	active   int32
 	waiting  int32
 	waitChan chan struct{}
+
+	// drained is closed by Drain to reject new and queued requests.
+	drained  chan struct{}
+	draining bool
+
+	// Failure state; see state.go.
+	state        State
+	changedAt    time.Time
+	window       rollingWindow
+	probing      bool
+	probeStarted time.Time
 }
 
 // Config holds circuit breaker configuration.
@@ -36,6 +55,19 @@ type Config struct {
 	MaxConcurrent int32
 	MaxQueueSize  int32
 	QueueTimeout  time.Duration
+
+	// ErrorThresholdPercent is the share of failed requests within
+	// ErrorWindow, from 1 to 100, that opens the breaker. Zero never opens
+	// it on failures.
+	ErrorThresholdPercent int32
+	// MinimumRequests is how many requests ErrorWindow must hold before
+	// the error rate is considered.
+	MinimumRequests int32
+	// ErrorWindow is the rolling window the error rate is measured over.
+	ErrorWindow time.Duration
+	// Cooldown is how long the breaker stays open before letting a probe
+	// request through.
+	Cooldown time.Duration
 }
 
 // DefaultConfig returns sensible default configuration.
@@ -58,13 +90,28 @@ func New(route string, cfg Config) *Breaker {
 	if cfg.QueueTimeout <= 0 {
 		cfg.QueueTimeout = 30 * time.Second
 	}
+	cfg.ErrorThresholdPercent = min(max(cfg.ErrorThresholdPercent, 0), 100)
+	if cfg.MinimumRequests <= 0 {
+		cfg.MinimumRequests = DefaultMinimumRequests
+	}
+	if cfg.ErrorWindow <= 0 {
+		cfg.ErrorWindow = DefaultErrorWindow
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = DefaultCooldown
+	}
 
 	return &Breaker{
-		route:         route,
-		maxConcurrent: cfg.MaxConcurrent,
-		maxQueue:      cfg.MaxQueueSize,
-		queueTimeout:  cfg.QueueTimeout,
-		waitChan:      make(chan struct{}, cfg.MaxConcurrent+cfg.MaxQueueSize),
+		route:          route,
+		maxConcurrent:  cfg.MaxConcurrent,
+		maxQueue:       cfg.MaxQueueSize,
+		queueTimeout:   cfg.QueueTimeout,
+		errorThreshold: cfg.ErrorThresholdPercent,
+		minRequests:    cfg.MinimumRequests,
+		cooldown:       cfg.Cooldown,
+		window:         rollingWindow{span: cfg.ErrorWindow},
+		waitChan:       make(chan struct{}, cfg.MaxConcurrent+cfg.MaxQueueSize),
+		drained:        make(chan struct{}),
 	}
 }
 
@@ -73,6 +120,18 @@ func New(route string, cfg Config) *Breaker {
 func (b *Breaker) Acquire(ctx context.Context) error {
 	b.mu.Lock()
 
+	if b.draining {
+		b.mu.Unlock()
+		metrics.RecordCircuitBreakerRejection(b.route, "draining")
+		return ErrDraining
+	}
+
+	if err := b.admit(); err != nil {
+		b.mu.Unlock()
+		metrics.RecordCircuitBreakerRejection(b.route, "open")
+		return err
+	}
+
 	// Check if we have capacity
 	if b.active < b.maxConcurrent {
 		b.active++
@@ -83,6 +142,7 @@ func (b *Breaker) Acquire(ctx context.Context) error {
 
 	// Check if we can queue
 	if b.waiting >= b.maxQueue {
+		b.abandonProbe()
 		b.mu.Unlock()
 		metrics.RecordCircuitBreakerRejection(b.route, "queue_full")
 		return ErrQueueFull
@@ -91,26 +151,37 @@ func (b *Breaker) Acquire(ctx context.Context) error {
 	// Queue this request
 	b.waiting++
 	b.updateMetrics()
+	queueTimeout := b.queueTimeout
 	b.mu.Unlock()
 
 	// Wait for capacity
-	timer := time.NewTimer(b.queueTimeout)
+	timer := time.NewTimer(queueTimeout)
 	defer timer.Stop()
 
 	select {
 	case <-ctx.Done():
 		b.mu.Lock()
 		b.waiting--
+		b.abandonProbe()
 		b.updateMetrics()
 		b.mu.Unlock()
 		return ctx.Err()
 	case <-timer.C:
 		b.mu.Lock()
 		b.waiting--
+		b.abandonProbe()
 		b.updateMetrics()
 		b.mu.Unlock()
 		metrics.RecordCircuitBreakerRejection(b.route, "timeout")
 		return ErrQueueTimeout
+	case <-b.drained:
+		b.mu.Lock()
+		b.waiting--
+		b.abandonProbe()
+		b.updateMetrics()
+		b.mu.Unlock()
+		metrics.RecordCircuitBreakerRejection(b.route, "draining")
+		return ErrDraining
 	case <-b.waitChan:
 		b.mu.Lock()
 		b.waiting--
@@ -121,6 +192,44 @@ func (b *Breaker) Acquire(ctx context.Context) error {
 	}
 }
 
+// TryAcquire takes a slot only if one is free right away, never queueing.
+// It is meant for optional work, such as hedge requests, that should not
+// compete with queued requests for capacity, so it also fails unless the
+// breaker is closed.
+func (b *Breaker) TryAcquire() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.draining || b.state != StateClosed || b.waiting > 0 || b.active >= b.maxConcurrent {
+		return false
+	}
+	b.active++
+	b.updateMetrics()
+	return true
+}
+
+// Drain makes Acquire fail with ErrDraining, including for requests already
+// queued, while requests holding a slot finish and Release normally.
+func (b *Breaker) Drain() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.draining {
+		b.draining = true
+		close(b.drained)
+	}
+}
+
+// setQueueTimeout changes how long later requests wait for capacity.
+func (b *Breaker) setQueueTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	b.mu.Lock()
+	b.queueTimeout = timeout
+	b.mu.Unlock()
+}
+
 // updateMetrics updates the Prometheus metrics for this breaker.
 // Must be called while holding the lock.
 func (b *Breaker) updateMetrics() {
@@ -146,10 +255,11 @@ func (b *Breaker) Release() {
 
 // Stats returns current breaker statistics.
 type Stats struct {
-	Active      int32
-	Waiting     int32
-	MaxCapacity int32
-	MaxQueue    int32
+	Active       int32
+	Waiting      int32
+	MaxCapacity  int32
+	MaxQueue     int32
+	QueueTimeout time.Duration
 }
 
 // Stats returns current statistics.
@@ -158,10 +268,11 @@ func (b *Breaker) Stats() Stats {
 	defer b.mu.Unlock()
 
 	return Stats{
-		Active:      b.active,
-		Waiting:     b.waiting,
-		MaxCapacity: b.maxConcurrent,
-		MaxQueue:    b.maxQueue,
+		Active:       b.active,
+		Waiting:      b.waiting,
+		MaxCapacity:  b.maxConcurrent,
+		MaxQueue:     b.maxQueue,
+		QueueTimeout: b.queueTimeout,
 	}
 }
 
@@ -170,6 +281,12 @@ type BreakerManager struct {
 	mu       sync.RWMutex
 	breakers map[string]*Breaker
 	defaults Config
+
+	// queueTimeouts overrides defaults.QueueTimeout per route.
+	queueTimeouts map[string]time.Duration
+
+	// draining makes breakers created after Drain start out draining.
+	draining bool
 }
 
 // NewManager creates a new breaker manager.
@@ -198,14 +315,79 @@ func (m *BreakerManager) Get(route string) *Breaker {
 		return b
 	}
 
-	b = New(route, m.defaults)
+	b = New(route, m.config(route))
+	if m.draining {
+		b.Drain()
+	}
 	m.breakers[route] = b
 	return b
 }
 
+// config returns the breaker config for a route. Must be called while
+// holding the lock.
+func (m *BreakerManager) config(route string) Config {
+	cfg := m.defaults
+	if timeout := m.queueTimeouts[route]; timeout > 0 {
+		cfg.QueueTimeout = timeout
+	}
+	return cfg
+}
+
 // UpdateConfig updates the default config for new breakers.
 func (m *BreakerManager) UpdateConfig(cfg Config) {
 	m.mu.Lock()
 	m.defaults = cfg
 	m.mu.Unlock()
 }
+
+// SetQueueTimeouts sets per-route queue timeouts that override the default.
+// Existing breakers are updated in place; routes missing from timeouts fall
+// back to the default queue timeout.
+func (m *BreakerManager) SetQueueTimeouts(timeouts map[string]time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queueTimeouts = timeouts
+	for route, b := range m.breakers {
+		b.setQueueTimeout(m.config(route).QueueTimeout)
+	}
+}
+
+// Reset resets the breaker for route, reporting false if the route has
+// no breaker yet.
+func (m *BreakerManager) Reset(route string) bool {
+	m.mu.RLock()
+	b, ok := m.breakers[route]
+	m.mu.RUnlock()
+
+	if ok {
+		b.Reset()
+	}
+	return ok
+}
+
+// ResetAll resets every breaker and returns their routes, sorted.
+func (m *BreakerManager) ResetAll() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	routes := make([]string, 0, len(m.breakers))
+	for route, b := range m.breakers {
+		b.Reset()
+		routes = append(routes, route)
+	}
+	slices.Sort(routes)
+	return routes
+}
+
+// Drain drains every breaker, current and future, so new requests are shed
+// during shutdown while in-flight requests complete.
+func (m *BreakerManager) Drain() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.draining = true
+	for _, b := range m.breakers {
+		b.Drain()
+	}
+}
@@ -0,0 +1,303 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// saturate takes the breaker's only slot so the next Acquire has to queue.
+func saturate(t *testing.T, b *Breaker) {
+	t.Helper()
+	if err := b.Acquire(context.Background()); err != nil {
+		t.Fatalf("failed to acquire first slot: %v", err)
+	}
+	t.Cleanup(b.Release)
+}
+
+func TestBreakerManager_PerRouteQueueTimeout(t *testing.T) {
+	m := NewManager(Config{MaxConcurrent: 1, MaxQueueSize: 1, QueueTimeout: time.Minute})
+	m.SetQueueTimeouts(map[string]time.Duration{"interactive": 20 * time.Millisecond})
+
+	if got := m.Get("interactive").Stats().QueueTimeout; got != 20*time.Millisecond {
+		t.Errorf("expected per-route queue timeout 20ms, got %v", got)
+	}
+	if got := m.Get("batch").Stats().QueueTimeout; got != time.Minute {
+		t.Errorf("expected default queue timeout 1m, got %v", got)
+	}
+
+	b := m.Get("interactive")
+	saturate(t, b)
+	start := time.Now()
+	if err := b.Acquire(context.Background()); !errors.Is(err, ErrQueueTimeout) {
+		t.Fatalf("expected ErrQueueTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the per-route timeout to fail fast, waited %v", elapsed)
+	}
+}
+
+func TestBreakerManager_SetQueueTimeoutsUpdatesExistingBreakers(t *testing.T) {
+	m := NewManager(Config{MaxConcurrent: 1, MaxQueueSize: 1, QueueTimeout: time.Minute})
+	b := m.Get("interactive")
+
+	m.SetQueueTimeouts(map[string]time.Duration{"interactive": 20 * time.Millisecond})
+	if got := b.Stats().QueueTimeout; got != 20*time.Millisecond {
+		t.Errorf("expected existing breaker to pick up 20ms, got %v", got)
+	}
+
+	// Dropping the override reverts to the default.
+	m.SetQueueTimeouts(nil)
+	if got := b.Stats().QueueTimeout; got != time.Minute {
+		t.Errorf("expected existing breaker to revert to 1m, got %v", got)
+	}
+}
+
+func TestBreakerManager_Drain(t *testing.T) {
+	m := NewManager(Config{MaxConcurrent: 1, MaxQueueSize: 1, QueueTimeout: time.Minute})
+	b := m.Get("interactive")
+	if err := b.Acquire(context.Background()); err != nil {
+		t.Fatalf("failed to acquire slot: %v", err)
+	}
+
+	// A request queued behind the active one is shed on drain.
+	queued := make(chan error, 1)
+	go func() { queued <- b.Acquire(context.Background()) }()
+	for b.Stats().Waiting == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	m.Drain()
+
+	if err := <-queued; !errors.Is(err, ErrDraining) {
+		t.Errorf("expected queued acquire to fail with ErrDraining, got %v", err)
+	}
+	if err := b.Acquire(context.Background()); !errors.Is(err, ErrDraining) {
+		t.Errorf("expected new acquire to fail with ErrDraining, got %v", err)
+	}
+	if err := m.Get("batch").Acquire(context.Background()); !errors.Is(err, ErrDraining) {
+		t.Errorf("expected breakers created after Drain to be draining, got %v", err)
+	}
+
+	// The in-flight request still releases its slot.
+	b.Release()
+	if stats := b.Stats(); stats.Active != 0 || stats.Waiting != 0 {
+		t.Errorf("expected no active or waiting requests after release, got %+v", stats)
+	}
+}
+
+func TestBreaker_TryAcquire(t *testing.T) {
+	b := New("hedge", Config{MaxConcurrent: 1, MaxQueueSize: 1, QueueTimeout: time.Minute})
+
+	if !b.TryAcquire() {
+		t.Fatal("expected a free slot to be taken")
+	}
+	if b.TryAcquire() {
+		t.Error("expected no slot while the breaker is full")
+	}
+	if got := b.Stats().Waiting; got != 0 {
+		t.Errorf("expected TryAcquire never to queue, got %d waiting", got)
+	}
+
+	b.Release()
+	b.Drain()
+	if b.TryAcquire() {
+		t.Error("expected no slot while draining")
+	}
+}
+
+// failureBreaker returns a breaker that opens at a 50% error rate over at
+// least 4 requests and probes again after 50ms.
+func failureBreaker() *Breaker {
+	return New("failing", Config{
+		MaxConcurrent:         10,
+		ErrorThresholdPercent: 50,
+		MinimumRequests:       4,
+		ErrorWindow:           time.Minute,
+		Cooldown:              50 * time.Millisecond,
+	})
+}
+
+// call acquires a slot, records the outcome and releases it.
+func call(t *testing.T, b *Breaker, failed bool) error {
+	t.Helper()
+	if err := b.Acquire(context.Background()); err != nil {
+		return err
+	}
+	b.Record(failed)
+	b.Release()
+	return nil
+}
+
+// trip opens the breaker with failed requests.
+func trip(t *testing.T, b *Breaker) {
+	t.Helper()
+	for i := 0; i < 4; i++ {
+		if err := call(t, b, true); err != nil {
+			t.Fatalf("unexpected error before tripping: %v", err)
+		}
+	}
+	if b.State() != StateOpen {
+		t.Fatalf("expected the breaker open, got %s", b.State())
+	}
+}
+
+func TestBreaker_Trips(t *testing.T) {
+	b := failureBreaker()
+
+	// Below the minimum request count the error rate is not considered
+	for i := 0; i < 3; i++ {
+		_ = call(t, b, true)
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("expected the breaker closed below the minimum requests, got %s", b.State())
+	}
+
+	_ = call(t, b, false)
+	if b.State() != StateOpen {
+		t.Fatalf("expected a 75%% error rate to open the breaker, got %s", b.State())
+	}
+	if err := b.Acquire(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+	if b.TryAcquire() {
+		t.Error("expected no slot while open")
+	}
+}
+
+func TestBreaker_StaysClosedBelowThreshold(t *testing.T) {
+	b := failureBreaker()
+	for i := 0; i < 20; i++ {
+		_ = call(t, b, (i+1)%3 == 0)
+	}
+	if b.State() != StateClosed {
+		t.Errorf("expected a 33%% error rate to keep the breaker closed, got %s", b.State())
+	}
+}
+
+func TestBreaker_ProbeSuccessCloses(t *testing.T) {
+	b := failureBreaker()
+	trip(t, b)
+
+	time.Sleep(60 * time.Millisecond)
+	if err := b.Acquire(context.Background()); err != nil {
+		t.Fatalf("expected a probe admitted after the cooldown: %v", err)
+	}
+	if b.State() != StateHalfOpen {
+		t.Fatalf("expected the breaker half-open, got %s", b.State())
+	}
+	if err := b.Acquire(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected requests rejected while the probe runs, got %v", err)
+	}
+
+	b.Record(false)
+	b.Release()
+	if b.State() != StateClosed {
+		t.Fatalf("expected a successful probe to close the breaker, got %s", b.State())
+	}
+
+	// The failures that opened it no longer count
+	_ = call(t, b, true)
+	if b.State() != StateClosed {
+		t.Errorf("expected a fresh error window after closing, got %s", b.State())
+	}
+}
+
+func TestBreaker_ProbeFailureReopens(t *testing.T) {
+	b := failureBreaker()
+	trip(t, b)
+
+	time.Sleep(60 * time.Millisecond)
+	if err := call(t, b, true); err != nil {
+		t.Fatalf("expected a probe admitted after the cooldown: %v", err)
+	}
+	if b.State() != StateOpen {
+		t.Fatalf("expected a failed probe to reopen the breaker, got %s", b.State())
+	}
+	if err := b.Acquire(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected a new cooldown after the failed probe, got %v", err)
+	}
+}
+
+func TestBreaker_AbandonedProbe(t *testing.T) {
+	b := New("abandoned", Config{
+		MaxConcurrent:         1,
+		MaxQueueSize:          0,
+		ErrorThresholdPercent: 50,
+		MinimumRequests:       1,
+		Cooldown:              50 * time.Millisecond,
+	})
+	_ = call(t, b, true)
+
+	// The probe finds no free slot, so the next request may probe instead
+	time.Sleep(60 * time.Millisecond)
+	b.mu.Lock()
+	b.active = 1
+	b.mu.Unlock()
+	if err := b.Acquire(context.Background()); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	b.mu.Lock()
+	b.active = 0
+	b.mu.Unlock()
+	if err := call(t, b, false); err != nil {
+		t.Fatalf("expected another probe admitted, got %v", err)
+	}
+	if b.State() != StateClosed {
+		t.Errorf("expected the breaker closed, got %s", b.State())
+	}
+}
+
+func TestBreaker_NoErrorThreshold(t *testing.T) {
+	b := New("plain", Config{MaxConcurrent: 10})
+	for i := 0; i < 50; i++ {
+		if err := call(t, b, true); err != nil {
+			t.Fatalf("expected failures ignored without an error threshold, got %v", err)
+		}
+	}
+	if b.State() != StateClosed {
+		t.Errorf("expected the breaker closed, got %s", b.State())
+	}
+}
+
+func TestBreaker_Reset(t *testing.T) {
+	b := failureBreaker()
+	trip(t, b)
+
+	b.Reset()
+	if b.State() != StateClosed {
+		t.Fatalf("expected the breaker closed after a reset, got %s", b.State())
+	}
+	if err := call(t, b, true); err != nil {
+		t.Fatalf("expected requests admitted after a reset, got %v", err)
+	}
+	// The failures that opened it no longer count
+	if b.State() != StateClosed {
+		t.Errorf("expected a fresh error window after a reset, got %s", b.State())
+	}
+}
+
+func TestBreakerManager_Reset(t *testing.T) {
+	m := NewManager(Config{MaxConcurrent: 10, ErrorThresholdPercent: 50, MinimumRequests: 4, Cooldown: time.Minute})
+	trip(t, m.Get("b"))
+	trip(t, m.Get("a"))
+
+	if m.Reset("missing") {
+		t.Error("expected no reset for a route without a breaker")
+	}
+	if !m.Reset("a") || m.Get("a").State() != StateClosed {
+		t.Fatalf("expected route a closed after a reset, got %s", m.Get("a").State())
+	}
+	if m.Get("b").State() != StateOpen {
+		t.Fatalf("expected route b left open, got %s", m.Get("b").State())
+	}
+
+	if got := m.ResetAll(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("expected routes a and b reset, got %v", got)
+	}
+	if m.Get("b").State() != StateClosed {
+		t.Errorf("expected route b closed after resetting all, got %s", m.Get("b").State())
+	}
+}
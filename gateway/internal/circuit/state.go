@@ -0,0 +1,191 @@
+package circuit
+
+import (
+	"time"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/metrics"
+)
+
+// Defaults for failure-based tripping, used when ErrorThresholdPercent is
+// set without the other settings.
+const (
+	DefaultMinimumRequests = 10
+	DefaultErrorWindow     = time.Minute
+	DefaultCooldown        = 30 * time.Second
+)
+
+// windowBuckets is how many buckets the error window is split into.
+const windowBuckets = 10
+
+// State is the failure state of a breaker.
+type State int
+
+const (
+	// StateClosed admits requests.
+	StateClosed State = iota
+	// StateOpen rejects requests until the cooldown passes.
+	StateOpen
+	// StateHalfOpen admits a single probe request, whose outcome closes or
+	// reopens the breaker.
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// bucket counts the outcomes of one slice of the error window.
+type bucket struct {
+	start    time.Time
+	requests int32
+	failures int32
+}
+
+// rollingWindow counts request outcomes over the last span, in buckets.
+type rollingWindow struct {
+	span    time.Duration
+	buckets [windowBuckets]bucket
+}
+
+func (w *rollingWindow) width() time.Duration {
+	return max(w.span/windowBuckets, time.Millisecond)
+}
+
+// add counts an outcome at now.
+func (w *rollingWindow) add(now time.Time, failed bool) {
+	width := w.width()
+	start := now.Truncate(width)
+	b := &w.buckets[int(start.UnixNano()/int64(width))%windowBuckets]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	b.requests++
+	if failed {
+		b.failures++
+	}
+}
+
+// totals returns the requests and failures counted within the span
+// ending at now.
+func (w *rollingWindow) totals(now time.Time) (requests, failures int32) {
+	oldest := now.Add(-w.span)
+	for _, b := range w.buckets {
+		if b.start.After(oldest) {
+			requests += b.requests
+			failures += b.failures
+		}
+	}
+	return requests, failures
+}
+
+func (w *rollingWindow) reset() {
+	w.buckets = [windowBuckets]bucket{}
+}
+
+// admit checks the failure state before a request takes a slot: an open
+// breaker rejects until its cooldown passes and then lets one probe
+// through as half-open. Must be called while holding the lock.
+func (b *Breaker) admit() error {
+	if b.errorThreshold <= 0 {
+		return nil
+	}
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.changedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.setState(StateHalfOpen)
+	case StateHalfOpen:
+		// A probe that never reported back must not wedge the breaker
+		if b.probing && time.Since(b.probeStarted) < b.cooldown {
+			return ErrCircuitOpen
+		}
+	default:
+		return nil
+	}
+	b.probing = true
+	b.probeStarted = time.Now()
+	return nil
+}
+
+// abandonProbe lets another request probe when the probe admitted by
+// admit did not get a slot. Must be called while holding the lock.
+func (b *Breaker) abandonProbe() {
+	if b.state == StateHalfOpen {
+		b.probing = false
+	}
+}
+
+// Record reports the outcome of a request that held a slot. A closed
+// breaker opens once failures reach the error threshold within the error
+// window; a half-open one closes on a successful probe and reopens on a
+// failed one. Outcomes are ignored while the breaker is open, and always
+// when no error threshold is configured.
+func (b *Breaker) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.errorThreshold <= 0 {
+		return
+	}
+
+	switch b.state {
+	case StateHalfOpen:
+		if !b.probing {
+			return
+		}
+		b.probing = false
+		if failed {
+			b.setState(StateOpen)
+		} else {
+			b.window.reset()
+			b.setState(StateClosed)
+		}
+	case StateClosed:
+		now := time.Now()
+		b.window.add(now, failed)
+		requests, failures := b.window.totals(now)
+		if requests >= b.minRequests && failures*100 >= b.errorThreshold*requests {
+			b.window.reset()
+			b.setState(StateOpen)
+		}
+	}
+}
+
+// Reset closes the breaker and clears its error window, discarding any
+// probe in flight. Requests holding or waiting for a slot are unaffected.
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.window.reset()
+	b.probing = false
+	b.setState(StateClosed)
+}
+
+// State returns the breaker's failure state.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// setState moves the breaker to state, recording the transition. Must be
+// called while holding the lock.
+func (b *Breaker) setState(state State) {
+	if state == b.state {
+		return
+	}
+	metrics.RecordCircuitBreakerStateChange(b.route, b.state.String(), state.String())
+	metrics.SetCircuitBreakerState(b.route, int(state))
+	b.state = state
+	b.changedAt = time.Now()
+}
@@ -1,9 +1,12 @@
 package k8s
 
+import "time"
+
 // Agent represents a simplified Agent CRD for the gateway.
 type Agent struct {
 	Name      string
 	Namespace string
+	Labels    map[string]string
 	Spec      AgentSpec
 	Status    AgentStatus
 }
@@ -12,6 +15,26 @@ type Agent struct {
 type AgentSpec struct {
 	Prompt string
 	Tools  []AgentTool
+	// Resources are the agent's spec.mcpResources.
+	Resources []AgentResource
+	Prompts   []AgentPrompt
+	// RequestTimeout is the agent's policy.requestTimeout (zero if unset).
+	RequestTimeout time.Duration
+	// MaxCallsPerMinute is the agent's policy.maxCallsPerMinute (zero if
+	// unset, meaning unlimited).
+	MaxCallsPerMinute int
+	// InvokeMapping is the agent's spec.invokeMapping (zero if unset).
+	InvokeMapping AgentInvokeMapping
+}
+
+// AgentInvokeMapping controls how MCP tool calls are mapped onto the
+// agent's /invoke request.
+type AgentInvokeMapping struct {
+	// QueryField names the argument used as the query; empty means the
+	// default search order.
+	QueryField string
+	// PassRawArguments sends the arguments as the request body as-is.
+	PassRawArguments bool
 }
 
 // AgentTool declares an MCP tool exposed by an agent.
@@ -19,6 +42,43 @@ type AgentTool struct {
 	Name        string                 `json:"name"`
 	Description string                 `json:"description"`
 	InputSchema map[string]interface{} `json:"inputSchema,omitempty"`
+	// DefaultArguments are merged into calls of the tool; client arguments
+	// win.
+	DefaultArguments map[string]string `json:"defaultArguments,omitempty"`
+	// Annotations are the tool's MCP behavior hints, nil if none are set.
+	Annotations *AgentToolAnnotations `json:"annotations,omitempty"`
+}
+
+// AgentToolAnnotations are MCP hints about a tool's behavior. Nil hints
+// are unset.
+type AgentToolAnnotations struct {
+	ReadOnlyHint    *bool `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool `json:"openWorldHint,omitempty"`
+}
+
+// AgentResource declares an MCP resource exposed by an agent.
+type AgentResource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// AgentPrompt declares an MCP prompt template exposed by an agent.
+type AgentPrompt struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description,omitempty"`
+	Template    string                `json:"template"`
+	Arguments   []AgentPromptArgument `json:"arguments,omitempty"`
+}
+
+// AgentPromptArgument declares an argument of an AgentPrompt.
+type AgentPromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
 }
 
 // AgentStatus contains the agent status.
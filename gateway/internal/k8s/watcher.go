@@ -160,6 +160,7 @@ func (w *AgentWatcher) unstructuredToAgent(u *unstructured.Unstructured) *Agent
 	agent := &Agent{
 		Name:      u.GetName(),
 		Namespace: u.GetNamespace(),
+		Labels:    u.GetLabels(),
 	}
 
 	// Extract spec
@@ -173,19 +174,76 @@ func (w *AgentWatcher) unstructuredToAgent(u *unstructured.Unstructured) *Agent
 		agent.Spec.Prompt = prompt
 	}
 
+	// Get request timeout and call rate limit from policy
+	if policy, ok := spec["policy"].(map[string]interface{}); ok {
+		if d, err := time.ParseDuration(getString(policy, "requestTimeout")); err == nil {
+			agent.Spec.RequestTimeout = d
+		}
+		switch n := policy["maxCallsPerMinute"].(type) {
+		case int64:
+			agent.Spec.MaxCallsPerMinute = int(n)
+		case float64:
+			agent.Spec.MaxCallsPerMinute = int(n)
+		}
+	}
+
+	// Get invoke mapping
+	if mapping, ok := spec["invokeMapping"].(map[string]interface{}); ok {
+		passRaw, _ := mapping["passRawArguments"].(bool)
+		agent.Spec.InvokeMapping = AgentInvokeMapping{
+			QueryField:       getString(mapping, "queryField"),
+			PassRawArguments: passRaw,
+		}
+	}
+
 	// Get tools
 	if tools, ok := spec["tools"].([]interface{}); ok {
 		for _, t := range tools {
 			if toolMap, ok := t.(map[string]interface{}); ok {
-				tool := AgentTool{
-					Name:        getString(toolMap, "name"),
-					Description: getString(toolMap, "description"),
-				}
-				if schema, ok := toolMap["inputSchema"].(map[string]interface{}); ok {
-					tool.InputSchema = schema
+				agent.Spec.Tools = append(agent.Spec.Tools, parseTool(toolMap))
+			}
+		}
+	}
+
+	// Get MCP resources
+	if resources, ok := spec["mcpResources"].([]interface{}); ok {
+		for _, r := range resources {
+			if resourceMap, ok := r.(map[string]interface{}); ok {
+				agent.Spec.Resources = append(agent.Spec.Resources, AgentResource{
+					URI:         getString(resourceMap, "uri"),
+					Name:        getString(resourceMap, "name"),
+					Description: getString(resourceMap, "description"),
+					MimeType:    getString(resourceMap, "mimeType"),
+				})
+			}
+		}
+	}
+
+	// Get MCP prompts
+	if prompts, ok := spec["prompts"].([]interface{}); ok {
+		for _, p := range prompts {
+			promptMap, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			prompt := AgentPrompt{
+				Name:        getString(promptMap, "name"),
+				Description: getString(promptMap, "description"),
+				Template:    getString(promptMap, "template"),
+			}
+			if args, ok := promptMap["arguments"].([]interface{}); ok {
+				for _, a := range args {
+					if argMap, ok := a.(map[string]interface{}); ok {
+						required, _ := argMap["required"].(bool)
+						prompt.Arguments = append(prompt.Arguments, AgentPromptArgument{
+							Name:        getString(argMap, "name"),
+							Description: getString(argMap, "description"),
+							Required:    required,
+						})
+					}
 				}
-				agent.Spec.Tools = append(agent.Spec.Tools, tool)
 			}
+			agent.Spec.Prompts = append(agent.Spec.Prompts, prompt)
 		}
 	}
 
@@ -209,14 +267,7 @@ func (w *AgentWatcher) unstructuredToAgent(u *unstructured.Unstructured) *Agent
 	if tools, ok := status["availableTools"].([]interface{}); ok {
 		for _, t := range tools {
 			if toolMap, ok := t.(map[string]interface{}); ok {
-				tool := AgentTool{
-					Name:        getString(toolMap, "name"),
-					Description: getString(toolMap, "description"),
-				}
-				if schema, ok := toolMap["inputSchema"].(map[string]interface{}); ok {
-					tool.InputSchema = schema
-				}
-				agent.Status.AvailableTools = append(agent.Status.AvailableTools, tool)
+				agent.Status.AvailableTools = append(agent.Status.AvailableTools, parseTool(toolMap))
 			}
 		}
 	}
@@ -224,6 +275,45 @@ func (w *AgentWatcher) unstructuredToAgent(u *unstructured.Unstructured) *Agent
 	return agent
 }
 
+// parseTool converts an AgentTool from spec.tools or status.availableTools.
+func parseTool(toolMap map[string]interface{}) AgentTool {
+	tool := AgentTool{
+		Name:        getString(toolMap, "name"),
+		Description: getString(toolMap, "description"),
+	}
+	if schema, ok := toolMap["inputSchema"].(map[string]interface{}); ok {
+		tool.InputSchema = schema
+	}
+	if defaults, ok := toolMap["defaultArguments"].(map[string]interface{}); ok {
+		tool.DefaultArguments = make(map[string]string, len(defaults))
+		for key, value := range defaults {
+			if s, ok := value.(string); ok {
+				tool.DefaultArguments[key] = s
+			}
+		}
+	}
+	if annotations, ok := toolMap["annotations"].(map[string]interface{}); ok {
+		a := AgentToolAnnotations{
+			ReadOnlyHint:    getBool(annotations, "readOnlyHint"),
+			DestructiveHint: getBool(annotations, "destructiveHint"),
+			IdempotentHint:  getBool(annotations, "idempotentHint"),
+			OpenWorldHint:   getBool(annotations, "openWorldHint"),
+		}
+		if a != (AgentToolAnnotations{}) {
+			tool.Annotations = &a
+		}
+	}
+	return tool
+}
+
+// getBool returns the boolean at key, or nil if it is missing.
+func getBool(m map[string]interface{}, key string) *bool {
+	if v, ok := m[key].(bool); ok {
+		return &v
+	}
+	return nil
+}
+
 func getString(m map[string]interface{}, key string) string {
 	if v, ok := m[key].(string); ok {
 		return v
@@ -231,6 +321,11 @@ func getString(m map[string]interface{}, key string) string {
 	return ""
 }
 
+// Store adds or replaces an agent in the cache.
+func (w *AgentWatcher) Store(agent *Agent) {
+	w.agents.Store(w.agentKey(agent), agent)
+}
+
 // List returns all cached agents.
 func (w *AgentWatcher) List() []*Agent {
 	var agents []*Agent
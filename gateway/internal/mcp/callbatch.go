@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+const (
+	// maxBatchCalls caps the number of calls in one tools/callBatch.
+	maxBatchCalls = 50
+
+	// batchConcurrency is how many calls of a tools/callBatch run at once.
+	batchConcurrency = 4
+)
+
+// callToolBatch handles tools/callBatch for both transports: each call runs
+// like a tools/call over HTTP, at most batchConcurrency at a time, and the
+// results are returned in request order. A failed call does not fail the
+// batch; its entry carries the error instead.
+func (h *Handler) callToolBatch(ctx context.Context, req *Request, header http.Header) (*CallToolBatchResult, *Error) {
+	paramsJSON, err := json.Marshal(req.Params)
+	if err != nil {
+		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Invalid params", Data: err.Error()}
+	}
+
+	var params CallToolBatchParams
+	if err := json.Unmarshal(paramsJSON, &params); err != nil {
+		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Invalid params", Data: err.Error()}
+	}
+	if len(params.Calls) == 0 {
+		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Invalid params", Data: "calls is required"}
+	}
+	if len(params.Calls) > maxBatchCalls {
+		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Invalid params", Data: fmt.Sprintf("at most %d calls per batch", maxBatchCalls)}
+	}
+
+	results := make([]CallToolBatchEntry, len(params.Calls))
+	slots := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for i := range params.Calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			// Calls have no ID of their own: notifications/cancelled names
+			// the batch request, which cancels all of them
+			call := &Request{JSONRPC: "2.0", Method: "tools/call", Params: params.Calls[i]}
+			resp := h.callToolResponse(ctx, call, header, nil)
+			if resp.Error != nil {
+				results[i].Error = resp.Error
+				return
+			}
+			results[i].Result, _ = resp.Result.(*CallToolResult)
+		}()
+	}
+	wg.Wait()
+
+	return &CallToolBatchResult{Results: results}, nil
+}
+
+func (h *Handler) handleCallToolBatch(ctx context.Context, sess *session, req *Request, header http.Header) {
+	ctx, done := h.trackCall(ctx, sseCallScope(sess.id), req.ID)
+	defer done()
+
+	result, rpcErr := h.callToolBatch(ctx, req, header)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		// The client asked us to stop; it expects no response
+		return
+	}
+	if rpcErr != nil {
+		h.sendSSEMessage(sess, Response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr})
+		return
+	}
+	h.sendResult(sess, req.ID, result)
+}
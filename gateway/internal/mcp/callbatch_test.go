@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInitialize_AdvertisesToolCallBatch(t *testing.T) {
+	h := newTestHandler()
+
+	var result InitializeResult
+	rpcHTTP(t, h, "initialize", InitializeParams{ProtocolVersion: latestProtocolVersion}, &result)
+
+	if _, ok := result.Capabilities.Experimental["tools/callBatch"]; !ok {
+		t.Errorf("expected tools/callBatch in experimental capabilities, got %v", result.Capabilities.Experimental)
+	}
+}
+
+func TestToolCallBatch(t *testing.T) {
+	echo := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("answer from " + name))
+		}
+	}
+	h := newTestHandler(newStubAgent(t, "alpha", echo("alpha")), newStubAgent(t, "beta", echo("beta")))
+	params := CallToolBatchParams{Calls: []CallToolParams{
+		{Name: "beta", Arguments: map[string]interface{}{"query": "one"}},
+		{Name: "missing", Arguments: map[string]interface{}{"query": "two"}},
+		{Name: "alpha", Arguments: map[string]interface{}{"query": "three"}},
+	}}
+	check := func(t *testing.T, result CallToolBatchResult) {
+		t.Helper()
+		if len(result.Results) != 3 {
+			t.Fatalf("expected 3 results, got %+v", result.Results)
+		}
+		for i, want := range map[int]string{0: "answer from beta", 2: "answer from alpha"} {
+			entry := result.Results[i]
+			if entry.Error != nil || entry.Result == nil || len(entry.Result.Content) == 0 || entry.Result.Content[0].Text != want {
+				t.Errorf("result %d: expected %q, got %+v", i, want, entry)
+			}
+		}
+		if entry := result.Results[1]; entry.Error == nil || entry.Result != nil {
+			t.Errorf("expected an error for the unknown agent, got %+v", entry)
+		}
+	}
+
+	t.Run("http", func(t *testing.T) {
+		var result CallToolBatchResult
+		if resp := rpcHTTP(t, h, "tools/callBatch", params, &result); resp.Error != nil {
+			t.Fatalf("unexpected error: %+v", resp.Error)
+		}
+		check(t, result)
+	})
+
+	t.Run("sse", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		sess := &session{id: 1, initialized: true, writer: rec, flusher: rec, done: make(chan struct{})}
+		h.handleCallToolBatch(context.Background(), sess, &Request{JSONRPC: "2.0", ID: 4, Method: "tools/callBatch", Params: params}, nil)
+
+		messages := sseMessages(t, rec.Body.String())
+		if len(messages) != 1 || messages[0]["id"] != float64(4) {
+			t.Fatalf("expected one response, got %v", messages)
+		}
+		raw, _ := json.Marshal(messages[0]["result"])
+		var result CallToolBatchResult
+		if err := json.Unmarshal(raw, &result); err != nil {
+			t.Fatalf("failed to decode result: %v", err)
+		}
+		check(t, result)
+	})
+}
+
+func TestToolCallBatch_BoundedConcurrency(t *testing.T) {
+	var active, peak atomic.Int32
+	agent := newStubAgent(t, "slow", func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	})
+	h := newTestHandler(agent)
+
+	var params CallToolBatchParams
+	for i := 0; i < 3*batchConcurrency; i++ {
+		params.Calls = append(params.Calls, CallToolParams{Name: "slow", Arguments: map[string]interface{}{"query": fmt.Sprint(i)}})
+	}
+	var result CallToolBatchResult
+	if resp := rpcHTTP(t, h, "tools/callBatch", params, &result); resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	if len(result.Results) != len(params.Calls) {
+		t.Fatalf("expected %d results, got %d", len(params.Calls), len(result.Results))
+	}
+	if got := peak.Load(); got > batchConcurrency {
+		t.Errorf("expected at most %d concurrent calls, peak was %d", batchConcurrency, got)
+	}
+}
+
+func TestToolCallBatch_InvalidParams(t *testing.T) {
+	h := newTestHandler()
+	tooMany := CallToolBatchParams{Calls: make([]CallToolParams, maxBatchCalls+1)}
+
+	for _, params := range []CallToolBatchParams{{}, tooMany} {
+		resp := rpcHTTP(t, h, "tools/callBatch", params, nil)
+		if resp.Error == nil || resp.Error.Code != ErrCodeInvalidParams {
+			t.Errorf("expected invalid params for %d calls, got %+v", len(params.Calls), resp)
+		}
+		if data, _ := resp.Error.Data.(string); !strings.Contains(data, "calls") {
+			t.Errorf("expected the error to mention calls, got %v", resp.Error.Data)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// SessionIDHeader carries the session ID the HTTP transport assigns on
+// initialize. Clients send it back so that their calls can be cancelled.
+const SessionIDHeader = "Mcp-Session-Id"
+
+// callKey identifies an in-flight tools/call for notifications/cancelled.
+// Request IDs are only unique per client, so calls are scoped by session.
+type callKey struct {
+	scope string // sseCallScope or httpCallScope
+	id    string // JSON encoding of the request ID, so 1 and "1" differ
+}
+
+func newCallKey(scope string, id interface{}) (callKey, bool) {
+	if scope == "" || id == nil {
+		return callKey{}, false
+	}
+	data, err := json.Marshal(id)
+	if err != nil {
+		return callKey{}, false
+	}
+	return callKey{scope: scope, id: string(data)}, true
+}
+
+// sseCallScope returns the cancellation scope of an SSE session's calls.
+func sseCallScope(session uint64) string {
+	return "sse/" + strconv.FormatUint(session, 10)
+}
+
+// httpCallScope returns the cancellation scope of HTTP transport calls: the
+// client's SessionIDHeader. Calls without one cannot be cancelled, since
+// they could not be told apart from other clients' calls with the same ID.
+func httpCallScope(header http.Header) string {
+	if id := header.Get(SessionIDHeader); id != "" {
+		return "http/" + id
+	}
+	return ""
+}
+
+// newHTTPSessionID returns a random 128-bit session ID in hex, so that one
+// client cannot guess another's.
+func newHTTPSessionID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// trackCall returns a copy of ctx that a notifications/cancelled naming id
+// in the same scope cancels, and a function to call once the call
+// completes. Calls without a scope are not cancellable.
+func (h *Handler) trackCall(ctx context.Context, scope string, id interface{}) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	key, ok := newCallKey(scope, id)
+	if !ok {
+		return ctx, cancel
+	}
+
+	// A later call reusing the ID replaces this entry; CompareAndDelete
+	// keeps this call from removing it.
+	entry := &cancel
+	h.calls.Store(key, entry)
+	return ctx, func() {
+		h.calls.CompareAndDelete(key, entry)
+		cancel()
+	}
+}
+
+// cancelCall handles notifications/cancelled by cancelling the named
+// in-flight call of the scope. Unknown or finished requests are ignored,
+// as the notification may race with the response.
+func (h *Handler) cancelCall(scope string, req *Request) {
+	var params CancelledParams
+	if data, err := json.Marshal(req.Params); err == nil {
+		// Decoded like request IDs, so large integer IDs still match
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		_ = dec.Decode(&params)
+	}
+
+	key, ok := newCallKey(scope, params.RequestID)
+	if !ok {
+		return
+	}
+	if entry, found := h.calls.LoadAndDelete(key); found {
+		h.logger.Debugf("[MCP] Cancelling request %s: %s", key.id, params.Reason)
+		(*entry.(*context.CancelFunc))()
+	}
+}
@@ -0,0 +1,183 @@
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cancellableAgent returns a handler that signals started once a call
+// arrives, then blocks like slowAgent and signals aborted if the gateway
+// gave up on the call.
+func cancellableAgent(started, aborted chan<- struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		slowAgent(w, r)
+		if r.Context().Err() != nil {
+			aborted <- struct{}{}
+		}
+	}
+}
+
+// waitFor fails the test if ch does not receive within a second.
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+// postSession posts a JSON-RPC message over the HTTP transport as the
+// client holding sessionID, returning the recorded response.
+func postSession(h *Handler, sessionID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	if sessionID != "" {
+		req.Header.Set(SessionIDHeader, sessionID)
+	}
+	rec := httptest.NewRecorder()
+	h.HandleHTTP(rec, req)
+	return rec
+}
+
+// initializeSession initializes over the HTTP transport and returns the
+// session ID the gateway assigned.
+func initializeSession(t *testing.T, h *Handler) string {
+	t.Helper()
+	rec := postSession(h, "", `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`)
+	id := rec.Header().Get(SessionIDHeader)
+	if id == "" {
+		t.Fatalf("expected initialize to assign a session ID, headers %v", rec.Header())
+	}
+	return id
+}
+
+const slowCall = `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","arguments":{"query":"hello"}}}`
+
+func TestHandleHTTP_CancelToolCall(t *testing.T) {
+	started, aborted := make(chan struct{}, 1), make(chan struct{}, 1)
+	h := newTestHandler(newStubAgent(t, "slow", cancellableAgent(started, aborted)))
+	sessionID := initializeSession(t, h)
+
+	done := make(chan string, 1)
+	go func() {
+		done <- postSession(h, sessionID, slowCall).Body.String()
+	}()
+	waitFor(t, started, "the agent call")
+
+	postSession(h, sessionID, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1,"reason":"user aborted"}}`)
+
+	select {
+	case body := <-done:
+		if !strings.Contains(body, fmt.Sprintf(`"code":%d`, ErrCodeRequestCancelled)) {
+			t.Errorf("expected request cancelled error, got %s", body)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the cancelled call to return")
+	}
+	waitFor(t, aborted, "the agent request to be aborted")
+
+	h.calls.Range(func(key, _ interface{}) bool {
+		t.Errorf("expected no in-flight calls, found %v", key)
+		return true
+	})
+}
+
+func TestHandleHTTP_CancelIsScopedToSession(t *testing.T) {
+	started, aborted := make(chan struct{}, 2), make(chan struct{}, 2)
+	h := newTestHandler(newStubAgent(t, "slow", cancellableAgent(started, aborted)))
+	clientA, clientB := initializeSession(t, h), initializeSession(t, h)
+	if clientA == clientB {
+		t.Fatalf("expected distinct session IDs, got %q twice", clientA)
+	}
+
+	doneA, doneB := make(chan string, 1), make(chan string, 1)
+	go func() { doneA <- postSession(h, clientA, slowCall).Body.String() }()
+	go func() { doneB <- postSession(h, clientB, slowCall).Body.String() }()
+	waitFor(t, started, "client A's agent call")
+	waitFor(t, started, "client B's agent call")
+
+	// Both calls use ID 1; B's cancel must only reach B's call
+	postSession(h, clientB, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`)
+	select {
+	case <-doneB:
+	case <-time.After(time.Second):
+		t.Fatal("expected client B's call to be cancelled")
+	}
+	select {
+	case body := <-doneA:
+		t.Fatalf("client A's call ended on client B's cancel: %s", body)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	postSession(h, clientA, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`)
+	select {
+	case <-doneA:
+	case <-time.After(time.Second):
+		t.Fatal("expected client A's call to be cancelled")
+	}
+}
+
+func TestHandleHTTP_CallWithoutSessionIsNotCancellable(t *testing.T) {
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	h := newTestHandler(newStubAgent(t, "slow", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":"done"}`))
+	}))
+
+	done := make(chan string, 1)
+	go func() { done <- postSession(h, "", slowCall).Body.String() }()
+	waitFor(t, started, "the agent call")
+
+	h.calls.Range(func(key, _ interface{}) bool {
+		t.Errorf("expected a call without a session not to be registered, found %v", key)
+		return true
+	})
+	postSession(h, "", `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`)
+	select {
+	case body := <-done:
+		t.Fatalf("call without a session was cancelled: %s", body)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if body := <-done; strings.Contains(body, `"error"`) {
+		t.Errorf("expected the call to complete, got %s", body)
+	}
+}
+
+func TestHandleMessage_CancelToolCall(t *testing.T) {
+	started, aborted := make(chan struct{}, 1), make(chan struct{}, 1)
+	h := newTestHandler(newStubAgent(t, "slow", cancellableAgent(started, aborted)))
+	rec := httptest.NewRecorder()
+	sess := &session{id: 7, initialized: true, writer: rec, flusher: rec, done: make(chan struct{})}
+	h.sessions.Store(sess.id, sess)
+
+	message := func(body string) {
+		h.HandleMessage(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, fmt.Sprintf("/mcp/message?sessionId=%d", sess.id), strings.NewReader(body)))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		message(`{"jsonrpc":"2.0","id":"call-1","method":"tools/call","params":{"name":"slow","arguments":{"query":"hello"}}}`)
+	}()
+	waitFor(t, started, "the agent call")
+
+	// Same ID on another session must not cancel the call
+	h.cancelCall(sseCallScope(8), &Request{Params: CancelledParams{RequestID: "call-1"}})
+	message(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"call-1"}}`)
+
+	waitFor(t, done, "the cancelled call to return")
+	waitFor(t, aborted, "the agent request to be aborted")
+
+	if body := rec.Body.String(); strings.Contains(body, "call-1") {
+		t.Errorf("expected no response to a cancelled call, got %q", body)
+	}
+}
@@ -0,0 +1,39 @@
+package mcp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+)
+
+// sseCompressParam is the /mcp/sse query parameter with which a client
+// opts into compressed message events: ?compress=gzip.
+const sseCompressParam = "compress"
+
+// sseCompressedEvent is the SSE event carrying a base64-encoded, gzipped
+// JSON-RPC message in place of a "message" event.
+const sseCompressedEvent = "message-gzip"
+
+// SetSSECompressThreshold sets the JSON size in bytes from which messages
+// on SSE sessions that opted in with ?compress=gzip are sent compressed.
+// Zero disables compression.
+func (h *Handler) SetSSECompressThreshold(n int) {
+	h.sseCompressMin = n
+}
+
+// compressSSEPayload returns payload gzipped and base64-encoded, reporting
+// false if that would not make it smaller.
+func compressSSEPayload(payload []byte) (string, bool) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		return "", false
+	}
+	if err := zw.Close(); err != nil {
+		return "", false
+	}
+	if base64.StdEncoding.EncodedLen(buf.Len()) >= len(payload) {
+		return "", false
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), true
+}
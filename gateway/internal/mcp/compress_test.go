@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// sseEvent returns the event name and joined data of the only event in body.
+func sseEvent(t *testing.T, body string) (string, string) {
+	t.Helper()
+	var event string
+	var data []string
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			if event != "" {
+				t.Fatalf("expected one event, got %q", body)
+			}
+			event = name
+		} else if d, ok := strings.CutPrefix(line, "data: "); ok {
+			data = append(data, d)
+		}
+	}
+	return event, strings.Join(data, "\n")
+}
+
+func TestSendSSEMessage_Compression(t *testing.T) {
+	large := Notification{JSONRPC: "2.0", Method: "notifications/message", Params: map[string]string{
+		"text": strings.Repeat("all work and no play ", 200),
+	}}
+	small := Notification{JSONRPC: "2.0", Method: "notifications/message"}
+
+	tests := []struct {
+		name     string
+		compress bool
+		message  Notification
+		wantGzip bool
+	}{
+		{name: "large payload on opted-in session", compress: true, message: large, wantGzip: true},
+		{name: "small payload stays plain", compress: true, message: small},
+		{name: "session without opt-in stays plain", message: large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler()
+			h.SetSSECompressThreshold(1024)
+			rec := httptest.NewRecorder()
+			sess := &session{id: 1, writer: rec, flusher: rec, done: make(chan struct{}), compress: tt.compress}
+
+			h.sendSSEMessage(sess, tt.message)
+
+			event, data := sseEvent(t, rec.Body.String())
+			if !tt.wantGzip {
+				if event != "message" || !strings.Contains(data, `"method":"notifications/message"`) {
+					t.Errorf("expected a plain message event, got %q: %.80s", event, data)
+				}
+				return
+			}
+
+			if event != sseCompressedEvent {
+				t.Fatalf("expected a %s event, got %q", sseCompressedEvent, event)
+			}
+			if strings.Contains(data, "\n") {
+				t.Error("expected compressed data on a single line")
+			}
+			raw, err := base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				t.Fatalf("expected base64 data: %v", err)
+			}
+			zr, err := gzip.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("expected gzip data: %v", err)
+			}
+			decoded, err := io.ReadAll(zr)
+			if err != nil {
+				t.Fatalf("failed to decompress: %v", err)
+			}
+			if !strings.Contains(string(decoded), "all work and no play") || len(data) >= len(decoded) {
+				t.Errorf("expected the compressed message to be smaller than %d bytes, got %d", len(decoded), len(data))
+			}
+		})
+	}
+}
@@ -5,32 +5,96 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"go.uber.org/zap"
 
 	"github.com/jarsater/mcp-fabric/gateway/internal/k8s"
 	"github.com/jarsater/mcp-fabric/gateway/internal/metrics"
+	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
 )
 
 const (
-	protocolVersion = "2024-11-05"
-	serverName      = "mcp-fabric-gateway"
-	serverVersion   = "1.0.0"
+	// latestProtocolVersion is offered to clients requesting a protocol
+	// version the gateway does not support.
+	latestProtocolVersion = "2025-03-26"
+	serverName            = "mcp-fabric-gateway"
+	serverVersion         = "1.0.0"
+
+	// defaultAgentTimeout applies when an agent has no policy.requestTimeout.
+	defaultAgentTimeout = 5 * time.Minute
+
+	// DefaultToolsListCacheTTL is how long a built tools/list result is
+	// reused before agents are listed again.
+	DefaultToolsListCacheTTL = 5 * time.Second
+
+	// DefaultProgressInterval is how often an SSE client that sent a
+	// progress token hears that its tools/call is still running.
+	DefaultProgressInterval = 10 * time.Second
+
+	// ToolGroupLabel opts an agent into a logical tool group: agents sharing
+	// the label value are advertised once under that name, and calls are
+	// spread across them. The value must not contain "_".
+	ToolGroupLabel = "fabric.jarsater.ai/mcp-tool-group"
+
+	// ToolWeightLabel sets an agent's share of its tool group's calls
+	// (defaults to 100).
+	ToolWeightLabel = "fabric.jarsater.ai/mcp-tool-weight"
+
+	// ToolVariantLabel names the variant an agent serves within its tool
+	// group, e.g. "canary". Group calls are recorded per variant so prompt or
+	// model experiments can be compared; weights set the canary's share.
+	ToolVariantLabel = "fabric.jarsater.ai/mcp-tool-variant"
+
+	defaultToolWeight  = 100
+	defaultToolVariant = "stable"
 )
 
+// supportedProtocolVersions lists the MCP protocol versions the gateway
+// speaks, newest first.
+var supportedProtocolVersions = []string{latestProtocolVersion, "2024-11-05"}
+
 // Handler handles MCP protocol requests.
 type Handler struct {
 	logger         *zap.SugaredLogger
 	watcher        *k8s.AgentWatcher
+	selector       *routes.Selector // spreads calls across a tool group
 	httpClient     *http.Client
-	sessions       sync.Map // sessionID -> *session
+	agentTimeout   time.Duration // fallback when the agent sets no timeout
+	maxRespLen     int64
+	partialResults bool                   // return output streamed before a timeout
+	progressEvery  time.Duration          // SSE tools/call heartbeat interval, 0 = off
+	forwardHeaders routes.HeaderAllowlist // client headers copied onto agent calls
+	rateLimit      routes.RateLimitPolicy // retries of agent 429 responses
+	sessions       sync.Map               // sessionID -> *session
+	sessionIdle    time.Duration          // SSE sessions silent this long are reaped, 0 = never
+	keepAlive      time.Duration          // SSE ping interval
+	sseCompressMin int                    // JSON size from which opted-in SSE messages are gzipped, 0 = never
+	calls          sync.Map               // callKey -> *context.CancelFunc of an in-flight tools/call
+	callLimits     *callLimiter           // per-agent policy.maxCallsPerMinute buckets
+
+	// toolsMu guards the cached tools/list result, which is dropped when
+	// agents change and otherwise reused for toolsTTL.
+	toolsMu         sync.Mutex
+	toolsTTL        time.Duration
+	toolsCache      *ListToolsResult
+	toolsCachedAt   time.Time
+	toolsGen        uint64       // bumped whenever the cached tools list is dropped
+	toolRefs        atomic.Value // map[string]toolRef of the last built tools list
+	toolRefsGen     uint64       // toolsGen when toolRefs was built
+	toolRefsBuiltAt time.Time
+
 	sessionID      atomic.Uint64
 	sseConnections atomic.Int32 // track active SSE connections for metrics
 }
@@ -42,17 +106,151 @@ type session struct {
 	flusher     http.Flusher
 	done        chan struct{}
 	mu          sync.Mutex
+	lastActive  atomic.Int64 // unix nanoseconds of the last client message or event written
+	end         sync.Once
+	compress    bool // client opted into message-gzip events
 }
 
 // NewHandler creates a new MCP handler.
 func NewHandler(logger *zap.SugaredLogger, watcher *k8s.AgentWatcher) *Handler {
 	return &Handler{
-		logger:  logger,
-		watcher: watcher,
-		httpClient: &http.Client{
-			Timeout: 5 * time.Minute,
+		logger:   logger,
+		watcher:  watcher,
+		selector: routes.NewSelector(),
+		// Timeouts are applied per call via context, see timeoutFor.
+		httpClient:    &http.Client{},
+		agentTimeout:  defaultAgentTimeout,
+		maxRespLen:    routes.DefaultMaxResponseBytes,
+		toolsTTL:      DefaultToolsListCacheTTL,
+		progressEvery: DefaultProgressInterval,
+		sessionIdle:   DefaultSessionIdleTimeout,
+		keepAlive:     DefaultSSEKeepAliveInterval,
+		callLimits:    newCallLimiter(),
+	}
+}
+
+// SetMaxResponseBytes sets the maximum accepted agent response size.
+// Non-positive values restore the default.
+func (h *Handler) SetMaxResponseBytes(n int64) {
+	if n <= 0 {
+		n = routes.DefaultMaxResponseBytes
+	}
+	h.maxRespLen = n
+}
+
+// SetToolsListCacheTTL sets how long a tools/list result is served from
+// cache. Zero disables caching.
+func (h *Handler) SetToolsListCacheTTL(ttl time.Duration) {
+	h.toolsMu.Lock()
+	defer h.toolsMu.Unlock()
+	h.toolsTTL = ttl
+	h.toolsCache = nil
+	h.toolsGen++
+}
+
+// SetForwardHeaders sets the client request headers passed through to
+// agents on tools/call. Nothing is forwarded by default.
+func (h *Handler) SetForwardHeaders(allow routes.HeaderAllowlist) {
+	h.forwardHeaders = allow
+}
+
+// SetRateLimitPolicy sets how agent 429 responses to tools/call are
+// handled. By default they fail the call with an agent rate limited error
+// carrying the agent's Retry-After.
+func (h *Handler) SetRateLimitPolicy(policy routes.RateLimitPolicy) {
+	h.rateLimit = policy
+}
+
+// SetPartialResults controls whether a tools/call that times out after the
+// agent started streaming its response returns the output received so far,
+// as an error result marked partial, instead of a bare timeout error.
+func (h *Handler) SetPartialResults(enabled bool) {
+	h.partialResults = enabled
+}
+
+// SetProgressInterval sets how often an SSE tools/call that carries a
+// progress token gets a notifications/progress heartbeat while the agent
+// works. Zero disables the heartbeat.
+func (h *Handler) SetProgressInterval(interval time.Duration) {
+	h.progressEvery = interval
+}
+
+// agentTimeoutError is returned when an agent does not respond in time.
+type agentTimeoutError struct {
+	agent   string
+	timeout time.Duration
+	// partial is the response body received before the timeout, when
+	// partial results are enabled.
+	partial []byte
+}
+
+func (e *agentTimeoutError) Error() string {
+	return fmt.Sprintf("agent %s timed out after %s", e.agent, e.timeout)
+}
+
+// rpcError converts the timeout into a JSON-RPC error.
+func (e *agentTimeoutError) rpcError() *Error {
+	return &Error{
+		Code:    ErrCodeAgentTimeout,
+		Message: fmt.Sprintf("agent timed out after %s", e.timeout),
+		Data: map[string]interface{}{
+			"agent":     e.agent,
+			"timeout":   e.timeout.String(),
+			"timeoutMs": e.timeout.Milliseconds(),
+		},
+	}
+}
+
+// partialResult returns the output received before the timeout as an error
+// tool result, or nil if nothing was captured.
+func (e *agentTimeoutError) partialResult() *CallToolResult {
+	if len(e.partial) == 0 {
+		return nil
+	}
+	return &CallToolResult{
+		Content: []Content{
+			{Type: "text", Text: string(e.partial)},
+			{Type: "text", Text: fmt.Sprintf("[partial result: agent %s timed out after %s]", e.agent, e.timeout)},
 		},
+		IsError: true,
+	}
+}
+
+// rateLimitedRPCError converts an agent 429 into a JSON-RPC error telling
+// the client when to retry.
+func rateLimitedRPCError(e *routes.RateLimitedError) *Error {
+	data := map[string]interface{}{"agent": e.Agent}
+	if e.RetryAfter > 0 {
+		data["retryAfter"] = e.RetryAfter.String()
+		data["retryAfterMs"] = e.RetryAfter.Milliseconds()
+	}
+	return &Error{
+		Code:    ErrCodeAgentRateLimited,
+		Message: "agent rate limited",
+		Data:    data,
+	}
+}
+
+// timeoutFor returns the call timeout for an agent.
+func (h *Handler) timeoutFor(agent *k8s.Agent) time.Duration {
+	if agent.Spec.RequestTimeout > 0 {
+		return agent.Spec.RequestTimeout
 	}
+	return h.agentTimeout
+}
+
+// withAgentTimeout bounds ctx by an agent call's timeout. It also returns
+// the timeout an agentTimeoutError should report: the caller's remaining
+// time instead when ctx already has an earlier deadline, since that is the
+// one that will expire.
+func withAgentTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc, time.Duration) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < timeout {
+			timeout = max(remaining.Round(time.Millisecond), 0)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, timeout
 }
 
 // HandleSSE handles the SSE connection endpoint (GET /mcp/sse).
@@ -67,20 +265,19 @@ func (h *Handler) HandleSSE(w http.ResponseWriter, r *http.Request) {
 	// Create session
 	sessionID := h.sessionID.Add(1)
 	sess := &session{
-		id:      sessionID,
-		writer:  w,
-		flusher: flusher,
-		done:    make(chan struct{}),
+		id:       sessionID,
+		writer:   w,
+		flusher:  flusher,
+		done:     make(chan struct{}),
+		compress: r.URL.Query().Get(sseCompressParam) == "gzip",
 	}
+	sess.touch()
 	h.sessions.Store(sessionID, sess)
 
 	// Track active SSE connections
 	activeCount := h.sseConnections.Add(1)
 	metrics.SetMCPConnectionsActive("sse", int(activeCount))
-	defer func() {
-		activeCount := h.sseConnections.Add(-1)
-		metrics.SetMCPConnectionsActive("sse", int(activeCount))
-	}()
+	defer h.endSession(sess, "client disconnected")
 
 	h.logger.Infof("MCP SSE session started: %d", sessionID)
 
@@ -94,20 +291,20 @@ func (h *Handler) HandleSSE(w http.ResponseWriter, r *http.Request) {
 	endpointURL := fmt.Sprintf("/mcp/message?sessionId=%d", sessionID)
 	h.sendSSEEvent(sess, "endpoint", endpointURL)
 
-	// Keep connection alive
-	ticker := time.NewTicker(30 * time.Second)
+	// Keep connection alive until the client leaves or the session is reaped
+	ticker := time.NewTicker(h.keepAlive)
 	defer ticker.Stop()
 
-	select {
-	case <-r.Context().Done():
-	case <-sess.done:
-	case <-ticker.C:
-		// Send ping to keep connection alive
-		h.sendSSEEvent(sess, "ping", "")
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sess.done:
+			return
+		case <-ticker.C:
+			h.sendSSEEvent(sess, "ping", "")
+		}
 	}
-
-	h.sessions.Delete(sessionID)
-	h.logger.Infof("MCP SSE session ended: %d", sessionID)
 }
 
 // HandleMessage handles incoming MCP messages (POST /mcp/message) for SSE transport.
@@ -138,6 +335,7 @@ func (h *Handler) HandleMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	sess := sessVal.(*session)
+	sess.touch()
 
 	// Parse request
 	var req Request
@@ -161,11 +359,23 @@ func (h *Handler) HandleMessage(w http.ResponseWriter, r *http.Request) {
 	case "initialized":
 		// Notification, no response needed
 		sess.initialized = true
+	case "notifications/cancelled":
+		h.cancelCall(sseCallScope(sess.id), &req)
 	case "tools/list":
 		metrics.RecordMCPToolsList()
 		h.handleListTools(sess, &req)
 	case "tools/call":
-		h.handleCallTool(r.Context(), sess, &req)
+		h.handleCallTool(r.Context(), sess, &req, r.Header)
+	case "tools/callBatch":
+		h.handleCallToolBatch(r.Context(), sess, &req, r.Header)
+	case "resources/list":
+		h.handleListResources(sess, &req)
+	case "resources/read":
+		h.handleReadResource(r.Context(), sess, &req, r.Header)
+	case "prompts/list":
+		h.handleListPrompts(sess, &req)
+	case "prompts/get":
+		h.handleGetPrompt(sess, &req)
 	case "ping":
 		h.sendResult(sess, req.ID, map[string]interface{}{})
 	default:
@@ -185,49 +395,147 @@ func (h *Handler) HandleHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.writeHTTPError(w, nil, ErrCodeParse, "Parse error", err.Error())
+		return
+	}
+	if trimmed := bytes.TrimLeft(body, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '[' {
+		h.handleBatchHTTP(w, r, body)
+		return
+	}
+
 	// Parse request
 	var req Request
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&req); err != nil {
 		h.writeHTTPError(w, nil, ErrCodeParse, "Parse error", err.Error())
 		return
 	}
 
 	h.logger.Debugf("MCP HTTP request: method=%s id=%v", req.Method, req.ID)
 
+	if req.Method == "initialize" {
+		w.Header().Set(SessionIDHeader, newHTTPSessionID())
+	}
+
 	// Record MCP request metrics
 	defer func() {
 		metrics.RecordMCPRequest(req.Method, "http", time.Since(start).Seconds())
 	}()
 
-	// Handle request and write response directly
-	var resp Response
-	resp.JSONRPC = "2.0"
-	resp.ID = req.ID
+	if req.Method == "tools/call" {
+		if flusher, ok := w.(http.Flusher); ok {
+			switch {
+			case acceptsMediaType(r, "text/event-stream"):
+				// Answered like an SSE session, without registering one
+				sess := &session{writer: w, flusher: flusher}
+				h.streamCallToolHTTP(w, r, &req, "text/event-stream", func(msg interface{}) {
+					h.sendSSEMessage(sess, msg)
+				})
+				return
+			case acceptsMediaType(r, "application/x-ndjson"):
+				enc := json.NewEncoder(w)
+				h.streamCallToolHTTP(w, r, &req, "application/x-ndjson", func(msg interface{}) {
+					_ = enc.Encode(msg)
+					flusher.Flush()
+				})
+				return
+			}
+		}
+	}
+
+	resp := h.dispatchHTTP(r, &req)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// handleBatchHTTP answers a JSON-RPC batch. Its requests are dispatched in
+// order and answered together in one array; notifications get no element,
+// and a batch of only notifications is answered with 202 Accepted.
+func (h *Handler) handleBatchHTTP(w http.ResponseWriter, r *http.Request, body []byte) {
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		h.writeHTTPError(w, nil, ErrCodeParse, "Parse error", err.Error())
+		return
+	}
+	if len(batch) == 0 {
+		h.writeHTTPError(w, nil, ErrCodeInvalidRequest, "Invalid Request", "empty batch")
+		return
+	}
+
+	h.logger.Debugf("MCP HTTP batch: requests=%d", len(batch))
+
+	responses := make([]Response, 0, len(batch))
+	for _, raw := range batch {
+		var req Request
+		if err := json.Unmarshal(raw, &req); err != nil {
+			responses = append(responses, Response{
+				JSONRPC: "2.0",
+				Error:   &Error{Code: ErrCodeInvalidRequest, Message: "Invalid Request", Data: err.Error()},
+			})
+			continue
+		}
+
+		if req.Method == "initialize" && w.Header().Get(SessionIDHeader) == "" {
+			w.Header().Set(SessionIDHeader, newHTTPSessionID())
+		}
+		start := time.Now()
+		resp := h.dispatchHTTP(r, &req)
+		metrics.RecordMCPRequest(req.Method, "http", time.Since(start).Seconds())
+		if req.ID != nil {
+			responses = append(responses, resp)
+		}
+	}
+
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(responses)
+}
+
+// dispatchHTTP handles one request of the HTTP transport and returns its
+// response.
+func (h *Handler) dispatchHTTP(r *http.Request, req *Request) Response {
+	resp := Response{JSONRPC: "2.0", ID: req.ID}
 
 	switch req.Method {
 	case "initialize":
-		resp.Result = InitializeResult{
-			ProtocolVersion: protocolVersion,
-			Capabilities: Capabilities{
-				Tools: &ToolsCapability{
-					ListChanged: true,
-				},
-			},
-			ServerInfo: Implementation{
-				Name:    serverName,
-				Version: serverVersion,
-			},
-		}
+		resp.Result = h.initializeResult(req)
 	case "initialized":
 		// Notification, just acknowledge
 		resp.Result = map[string]interface{}{}
+	case "notifications/cancelled":
+		h.cancelCall(httpCallScope(r.Header), req)
+		resp.Result = map[string]interface{}{}
 	case "tools/list":
 		metrics.RecordMCPToolsList()
-		resp.Result = h.buildToolsList()
+		resp.Result = h.cachedToolsList()
 	case "tools/call":
-		result, err := h.handleCallToolHTTP(r.Context(), &req)
-		if err != nil {
-			resp.Error = &Error{Code: ErrCodeInternal, Message: err.Error()}
+		resp = h.callToolResponse(r.Context(), req, r.Header, nil)
+	case "tools/callBatch":
+		ctx, done := h.trackCall(r.Context(), httpCallScope(r.Header), req.ID)
+		defer done()
+		if result, rpcErr := h.callToolBatch(ctx, req, r.Header); rpcErr != nil {
+			resp.Error = rpcErr
+		} else {
+			resp.Result = result
+		}
+	case "resources/list":
+		resp.Result = h.buildResourcesList()
+	case "resources/read":
+		if result, rpcErr := h.readResource(r.Context(), req, r.Header); rpcErr != nil {
+			resp.Error = rpcErr
+		} else {
+			resp.Result = result
+		}
+	case "prompts/list":
+		resp.Result = h.buildPromptsList()
+	case "prompts/get":
+		if result, rpcErr := h.getPrompt(req); rpcErr != nil {
+			resp.Error = rpcErr
 		} else {
 			resp.Result = result
 		}
@@ -237,45 +545,386 @@ func (h *Handler) HandleHTTP(w http.ResponseWriter, r *http.Request) {
 		resp.Error = &Error{Code: ErrCodeMethodNotFound, Message: "Method not found", Data: req.Method}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(resp)
+	return resp
+}
+
+// cachedToolsList returns the tools list, rebuilding it only when the cache
+// is empty or older than the TTL. Concurrent callers share one rebuild.
+func (h *Handler) cachedToolsList() ListToolsResult {
+	h.toolsMu.Lock()
+	defer h.toolsMu.Unlock()
+	if h.toolsCache != nil && time.Since(h.toolsCachedAt) < h.toolsTTL {
+		return *h.toolsCache
+	}
+	result := h.buildToolsList()
+	if h.toolsTTL > 0 {
+		h.toolsCache = &result
+		h.toolsCachedAt = time.Now()
+	}
+	return result
+}
+
+// invalidateToolsList drops the cached tools list.
+func (h *Handler) invalidateToolsList() {
+	h.toolsMu.Lock()
+	defer h.toolsMu.Unlock()
+	h.toolsCache = nil
+	h.toolsGen++
+}
+
+// refreshToolRefs rebuilds the tools list after a tools/call name was not
+// found in the last one, unless that one is still current: built since the
+// cached list was last dropped and, with caching enabled, within the TTL.
+// Unknown names thus cannot force a rebuild on every call.
+func (h *Handler) refreshToolRefs() {
+	h.toolsMu.Lock()
+	defer h.toolsMu.Unlock()
+	built := h.toolRefs.Load() != nil && h.toolRefsGen == h.toolsGen
+	if built && (h.toolsTTL <= 0 || time.Since(h.toolRefsBuiltAt) < h.toolsTTL) {
+		return
+	}
+	h.buildToolsList()
+}
+
+// toolRef is what a listed tool name calls: an agent name or tool group
+// prefix, and the agent's tool (empty for an agent without tools).
+type toolRef struct {
+	prefix string
+	tool   string
 }
 
+// buildToolsList lists the tools of every ready agent and records which
+// agent and tool each name calls, so tools/call resolves names exactly.
+// Callers hold toolsMu.
 func (h *Handler) buildToolsList() ListToolsResult {
 	agents := h.watcher.ListReady()
+	sortAgents(agents)
 
+	// Agents in a tool group share a prefix, so their tools are listed once
 	var tools []Tool
+	refs := make(map[string]toolRef)
+	add := func(tool Tool, ref toolRef) {
+		if existing, ok := refs[tool.Name]; ok {
+			if existing == ref {
+				return
+			}
+			// "code" + "review_pr" and "code_review" + "pr" generate the
+			// same name; the later tool gets a double underscore instead
+			alt := ref.prefix + "__" + ref.tool
+			if _, taken := refs[alt]; taken || ref.tool == "" {
+				h.logger.Warnf("[MCP] Skipping tool %q of %s: name already taken", tool.Name, ref.prefix)
+				return
+			}
+			tool.Name = alt
+		}
+		if !validToolName(tool.Name) {
+			h.logger.Warnf("[MCP] Skipping tool %q: names must match %s", tool.Name, toolNamePattern)
+			return
+		}
+		refs[tool.Name] = ref
+		tools = append(tools, tool)
+	}
 	for _, agent := range agents {
-		agentTools := agent.Status.AvailableTools
-		if len(agentTools) == 0 {
-			agentTools = agent.Spec.Tools
+		prefix := agent.Name
+		if group := agent.Labels[ToolGroupLabel]; group != "" {
+			prefix = group
 		}
 
-		if len(agentTools) > 0 {
+		if agentTools := agentTools(agent); len(agentTools) > 0 {
 			for _, t := range agentTools {
 				inputSchema := t.InputSchema
 				if inputSchema == nil {
 					inputSchema = defaultInputSchema()
 				}
-				tools = append(tools, Tool{
-					Name:        fmt.Sprintf("%s_%s", agent.Name, t.Name),
+				add(Tool{
+					Name:        fmt.Sprintf("%s_%s", prefix, t.Name),
 					Description: t.Description,
 					InputSchema: inputSchema,
-				})
+					Annotations: (*ToolAnnotations)(t.Annotations),
+				}, toolRef{prefix: prefix, tool: t.Name})
 			}
 		} else {
-			tools = append(tools, Tool{
-				Name:        agent.Name,
+			add(Tool{
+				Name:        prefix,
 				Description: extractDescription(agent.Spec.Prompt),
 				InputSchema: defaultInputSchema(),
-			})
+			}, toolRef{prefix: prefix})
 		}
 	}
 
+	h.toolRefs.Store(refs)
+	h.toolRefsGen = h.toolsGen
+	h.toolRefsBuiltAt = time.Now()
 	return ListToolsResult{Tools: tools}
 }
 
-func (h *Handler) handleCallToolHTTP(ctx context.Context, req *Request) (*CallToolResult, error) {
+// resolveToolName returns the agent name or tool group prefix and the tool
+// a tools/call name refers to. Names are looked up in the last built tools
+// list, rebuilt on a miss if agents may have changed since (see
+// refreshToolRefs). Names that are not listed, such as those of agents that
+// are not ready, are split at the first "_".
+func (h *Handler) resolveToolName(name string) (prefix, tool string) {
+	lookup := func() (toolRef, bool) {
+		refs, _ := h.toolRefs.Load().(map[string]toolRef)
+		ref, ok := refs[name]
+		return ref, ok
+	}
+	ref, ok := lookup()
+	if !ok {
+		h.refreshToolRefs()
+		ref, ok = lookup()
+	}
+	if ok {
+		return ref.prefix, ref.tool
+	}
+
+	if idx := strings.Index(name, "_"); idx > 0 {
+		return name[:idx], name[idx+1:]
+	}
+	return name, ""
+}
+
+// toolNamePattern is the character set and length MCP clients accept for
+// tool names. Agent names may contain dots, so not every agent qualifies.
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// validToolName reports whether clients accept name as a tool name.
+func validToolName(name string) bool {
+	return toolNamePattern.MatchString(name)
+}
+
+// agentTools returns the tools an agent exposes: the available tools it
+// reports, or the ones declared in its spec until it does.
+func agentTools(agent *k8s.Agent) []k8s.AgentTool {
+	if len(agent.Status.AvailableTools) > 0 {
+		return agent.Status.AvailableTools
+	}
+	return agent.Spec.Tools
+}
+
+// withDefaultArguments returns args merged over the default arguments of
+// the agent's tool, so values sent by the client win.
+func withDefaultArguments(agent *k8s.Agent, toolName string, args map[string]interface{}) map[string]interface{} {
+	for _, tool := range agentTools(agent) {
+		if tool.Name != toolName || len(tool.DefaultArguments) == 0 {
+			continue
+		}
+		merged := make(map[string]interface{}, len(tool.DefaultArguments)+len(args))
+		for key, value := range tool.DefaultArguments {
+			merged[key] = value
+		}
+		for key, value := range args {
+			merged[key] = value
+		}
+		return merged
+	}
+	return args
+}
+
+// resolveAgent returns the agent serving a tool name prefix. A prefix naming
+// a tool group picks one of the group's ready agents by weight.
+func (h *Handler) resolveAgent(name string) (*k8s.Agent, bool) {
+	var members []*k8s.Agent
+	for _, agent := range h.watcher.ListReady() {
+		if agent.Labels[ToolGroupLabel] == name {
+			members = append(members, agent)
+		}
+	}
+	if len(members) == 0 {
+		return h.watcher.GetByName(name)
+	}
+	sortAgents(members)
+
+	backends := make([]routes.CompiledRouteBackend, len(members))
+	for i, agent := range members {
+		backends[i] = routes.CompiledRouteBackend{
+			AgentName: agent.Name,
+			Namespace: agent.Namespace,
+			Endpoint:  agent.Status.Endpoint,
+			Weight:    toolWeight(agent),
+			Ready:     true,
+		}
+	}
+	chosen := h.selector.SelectWeighted(backends)
+	return h.watcher.Get(chosen.Namespace, chosen.AgentName)
+}
+
+// toolWeight returns the agent's ToolWeightLabel, or the default if it is
+// missing or invalid.
+func toolWeight(agent *k8s.Agent) int32 {
+	weight, err := strconv.ParseInt(agent.Labels[ToolWeightLabel], 10, 32)
+	if err != nil || weight < 0 {
+		return defaultToolWeight
+	}
+	return int32(weight)
+}
+
+// recordVariantCall records the outcome of a call to an agent in a tool
+// group under the agent's variant. Calls outside a group are not recorded.
+func recordVariantCall(agent *k8s.Agent, err error, start time.Time) {
+	group := agent.Labels[ToolGroupLabel]
+	if group == "" {
+		return
+	}
+	variant := agent.Labels[ToolVariantLabel]
+	if variant == "" {
+		variant = defaultToolVariant
+	}
+	outcome := "success"
+	var timeoutErr *agentTimeoutError
+	switch {
+	case errors.As(err, &timeoutErr):
+		outcome = "timeout"
+	case err != nil:
+		outcome = "error"
+	}
+	metrics.RecordMCPToolVariantCall(group, variant, outcome, time.Since(start).Seconds())
+}
+
+// sortAgents orders agents by namespace and name so tool lists are stable.
+func sortAgents(agents []*k8s.Agent) {
+	sort.Slice(agents, func(i, j int) bool {
+		if agents[i].Namespace != agents[j].Namespace {
+			return agents[i].Namespace < agents[j].Namespace
+		}
+		return agents[i].Name < agents[j].Name
+	})
+}
+
+// callToolResponse runs a tools/call for the HTTP transport and builds its
+// JSON-RPC response. onChunk, if set, receives the agent's response as it
+// streams in.
+func (h *Handler) callToolResponse(ctx context.Context, req *Request, header http.Header, onChunk func([]byte)) Response {
+	resp := Response{JSONRPC: "2.0", ID: req.ID}
+
+	ctx, done := h.trackCall(ctx, httpCallScope(header), req.ID)
+	defer done()
+
+	result, err := h.handleCallToolHTTP(ctx, req, header, onChunk)
+	var invalidArgs *invalidArgumentsError
+	var timeoutErr *agentTimeoutError
+	var rateLimited *routes.RateLimitedError
+	var callLimited *callRateLimitedError
+	if errors.Is(ctx.Err(), context.Canceled) {
+		resp.Error = &Error{Code: ErrCodeRequestCancelled, Message: "Request cancelled"}
+	} else if errors.As(err, &invalidArgs) {
+		resp.Error = invalidArgs.rpcError()
+	} else if errors.As(err, &callLimited) {
+		resp.Error = callLimited.rpcError()
+	} else if errors.As(err, &timeoutErr) {
+		resp.Error = timeoutErr.rpcError()
+	} else if errors.As(err, &rateLimited) {
+		resp.Error = rateLimitedRPCError(rateLimited)
+	} else if err != nil {
+		resp.Error = &Error{Code: ErrCodeInternal, Message: err.Error()}
+	} else {
+		resp.Result = result
+	}
+	return resp
+}
+
+// acceptsMediaType reports whether the client lists mediaType in its Accept
+// header, as streaming HTTP clients do for text/event-stream or
+// application/x-ndjson.
+func acceptsMediaType(r *http.Request, mediaType string) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, accepted := range strings.Split(accept, ",") {
+			accepted, _, _ = strings.Cut(accepted, ";")
+			if strings.EqualFold(strings.TrimSpace(accepted), mediaType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// streamCallToolHTTP answers a tools/call as a stream of contentType
+// messages written by send. Each piece of the agent's response is sent as a
+// notifications/progress message as it arrives, followed by the JSON-RPC
+// response carrying the full result.
+func (h *Handler) streamCallToolHTTP(w http.ResponseWriter, r *http.Request, req *Request, contentType string, send func(msg interface{})) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	_ = http.NewResponseController(w).Flush()
+
+	token := progressToken(req)
+	var received int64
+	onChunk := func(chunk []byte) {
+		received += int64(len(chunk))
+		send(Notification{
+			JSONRPC: "2.0",
+			Method:  "notifications/progress",
+			Params: ProgressParams{
+				ProgressToken: token,
+				Progress:      received,
+				Message:       string(chunk),
+			},
+		})
+	}
+
+	send(h.callToolResponse(r.Context(), req, r.Header, onChunk))
+}
+
+// progressToken returns the progress token the client sent in the request's
+// _meta, or the request ID if it sent none.
+func progressToken(req *Request) interface{} {
+	var params CallToolParams
+	if data, err := json.Marshal(req.Params); err == nil {
+		_ = json.Unmarshal(data, &params)
+	}
+	if params.Meta != nil && params.Meta.ProgressToken != nil {
+		return params.Meta.ProgressToken
+	}
+	return req.ID
+}
+
+// startProgress sends sess a notifications/progress heartbeat with an
+// increasing progress value every progress interval, so a client waiting on
+// a slow agent does not consider the call stalled. Nothing is sent without
+// a progress token. The returned function stops the heartbeat; no progress
+// is sent once it returns.
+func (h *Handler) startProgress(sess *session, meta *RequestMeta) (stop func()) {
+	if meta == nil || meta.ProgressToken == nil || h.progressEvery <= 0 {
+		return func() {}
+	}
+
+	quit := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(h.progressEvery)
+		defer ticker.Stop()
+
+		var progress int64
+		for {
+			select {
+			case <-quit:
+				return
+			case <-sess.done:
+				return
+			case <-ticker.C:
+				progress++
+				h.sendSSEMessage(sess, Notification{
+					JSONRPC: "2.0",
+					Method:  "notifications/progress",
+					Params: ProgressParams{
+						ProgressToken: meta.ProgressToken,
+						Progress:      progress,
+						Message:       "waiting for agent",
+					},
+				})
+			}
+		}
+	}()
+
+	return func() {
+		close(quit)
+		<-stopped
+	}
+}
+
+func (h *Handler) handleCallToolHTTP(ctx context.Context, req *Request, header http.Header, onChunk func([]byte)) (*CallToolResult, error) {
 	paramsJSON, err := json.Marshal(req.Params)
 	if err != nil {
 		return nil, fmt.Errorf("invalid params: %w", err)
@@ -288,20 +937,14 @@ func (h *Handler) handleCallToolHTTP(ctx context.Context, req *Request) (*CallTo
 
 	h.logger.Debugf("[MCP] Tool call: %s with args: %v", params.Name, params.Arguments)
 
-	// Extract agent name from tool name
-	agentName := params.Name
-	toolName := ""
-	if idx := strings.Index(params.Name, "_"); idx > 0 {
-		agentName = params.Name[:idx]
-		toolName = params.Name[idx+1:]
-	}
+	agentName, toolName := h.resolveToolName(params.Name)
 
 	// Record tool call metric
 	metrics.RecordMCPToolsCall(agentName, toolName)
 
 	h.logger.Debugf("[MCP] Resolved agent=%s tool=%s", agentName, toolName)
 
-	agent, found := h.watcher.GetByName(agentName)
+	agent, found := h.resolveAgent(agentName)
 	if !found {
 		h.logger.Warnf("[MCP] Agent not found: %s", agentName)
 		return nil, fmt.Errorf("agent not found: %s", agentName)
@@ -314,40 +957,45 @@ func (h *Handler) handleCallToolHTTP(ctx context.Context, req *Request) (*CallTo
 
 	h.logger.Debugf("[MCP] Agent %s is ready, endpoint=%s", agentName, agent.Status.Endpoint)
 
-	// Build query from arguments
-	query := ""
-	for _, key := range []string{"query", "question", "request", "description"} {
-		if q, ok := params.Arguments[key].(string); ok && q != "" {
-			query = q
-			break
-		}
+	params.Arguments = withDefaultArguments(agent, toolName, params.Arguments)
+	if err := validateToolArguments(agent, params.Name, toolName, params.Arguments); err != nil {
+		h.logger.Debugf("[MCP] Rejected call to %s: %v", params.Name, err)
+		return nil, err
 	}
-
-	if query == "" {
-		parts := make([]string, 0)
-		for k, v := range params.Arguments {
-			if s, ok := v.(string); ok && s != "" {
-				parts = append(parts, fmt.Sprintf("%s: %s", k, s))
-			}
-		}
-		query = strings.Join(parts, "\n")
+	if err := h.checkCallRate(agent); err != nil {
+		return nil, err
 	}
 
+	query := agentQuery(agent, params.Arguments)
+
 	h.logger.Debugf("[MCP] Forwarding to agent %s: query=%q", agentName, truncate(query, 100))
 
-	result, err := h.forwardToAgent(ctx, agent, query, params.Arguments)
+	start := time.Now()
+	result, err := h.forwardToAgentStream(ctx, agent, query, params.Arguments, header, onChunk)
+	recordVariantCall(agent, err, start)
 	if err != nil {
 		h.logger.Errorf("[MCP] Error from agent %s: %v", agentName, err)
+		var timeoutErr *agentTimeoutError
+		var rateLimited *routes.RateLimitedError
+		if errors.As(err, &timeoutErr) {
+			if partial := timeoutErr.partialResult(); partial != nil {
+				return partial, nil
+			}
+			return nil, err
+		}
+		if errors.As(err, &rateLimited) {
+			return nil, err
+		}
 		return &CallToolResult{
 			Content: []Content{{Type: "text", Text: fmt.Sprintf("Error: %v", err)}},
 			IsError: true,
 		}, nil
 	}
 
-	h.logger.Debugf("[MCP] Success from agent %s: response=%q", agentName, truncate(result, 200))
+	h.logger.Debugf("[MCP] Success from agent %s: %d content block(s)", agentName, len(result))
 
 	return &CallToolResult{
-		Content: []Content{{Type: "text", Text: result}},
+		Content: result,
 	}, nil
 }
 
@@ -374,59 +1022,64 @@ func (h *Handler) writeHTTPError(w http.ResponseWriter, id interface{}, code int
 }
 
 func (h *Handler) handleInitialize(sess *session, req *Request) {
-	result := InitializeResult{
-		ProtocolVersion: protocolVersion,
+	h.sendResult(sess, req.ID, h.initializeResult(req))
+}
+
+// initializeResult answers an initialize request for both transports,
+// agreeing on the protocol version the client asked for.
+func (h *Handler) initializeResult(req *Request) InitializeResult {
+	var params InitializeParams
+	if data, err := json.Marshal(req.Params); err == nil {
+		_ = json.Unmarshal(data, &params)
+	}
+
+	version, ok := negotiateProtocolVersion(params.ProtocolVersion)
+	if !ok {
+		h.logger.Warnf("[MCP] Client %q requested unsupported protocol version %q, offering %s",
+			params.ClientInfo.Name, params.ProtocolVersion, version)
+	}
+
+	return InitializeResult{
+		ProtocolVersion: version,
 		Capabilities: Capabilities{
 			Tools: &ToolsCapability{
 				ListChanged: true,
 			},
+			Resources: &ResourcesCapability{},
+			Prompts: &PromptsCapability{
+				ListChanged: true,
+			},
+			Experimental: map[string]interface{}{
+				"tools/callBatch": map[string]interface{}{
+					"maxCalls":       maxBatchCalls,
+					"maxConcurrency": batchConcurrency,
+				},
+			},
 		},
 		ServerInfo: Implementation{
 			Name:    serverName,
 			Version: serverVersion,
 		},
 	}
-	h.sendResult(sess, req.ID, result)
 }
 
-func (h *Handler) handleListTools(sess *session, req *Request) {
-	agents := h.watcher.ListReady()
-
-	var tools []Tool
-	for _, agent := range agents {
-		// Use available tools from status if present, otherwise generate from spec
-		agentTools := agent.Status.AvailableTools
-		if len(agentTools) == 0 {
-			agentTools = agent.Spec.Tools
-		}
-
-		if len(agentTools) > 0 {
-			// Agent has explicit tools defined
-			for _, t := range agentTools {
-				inputSchema := t.InputSchema
-				if inputSchema == nil {
-					inputSchema = defaultInputSchema()
-				}
-				tools = append(tools, Tool{
-					Name:        fmt.Sprintf("%s_%s", agent.Name, t.Name),
-					Description: t.Description,
-					InputSchema: inputSchema,
-				})
-			}
-		} else {
-			// Generate default tool from agent name + prompt
-			tools = append(tools, Tool{
-				Name:        agent.Name,
-				Description: extractDescription(agent.Spec.Prompt),
-				InputSchema: defaultInputSchema(),
-			})
+// negotiateProtocolVersion returns the requested protocol version if the
+// gateway supports it, and otherwise the latest version it supports with
+// ok set to false.
+func negotiateProtocolVersion(requested string) (version string, ok bool) {
+	for _, v := range supportedProtocolVersions {
+		if v == requested {
+			return v, true
 		}
 	}
+	return latestProtocolVersion, false
+}
 
-	h.sendResult(sess, req.ID, ListToolsResult{Tools: tools})
+func (h *Handler) handleListTools(sess *session, req *Request) {
+	h.sendResult(sess, req.ID, h.cachedToolsList())
 }
 
-func (h *Handler) handleCallTool(ctx context.Context, sess *session, req *Request) {
+func (h *Handler) handleCallTool(ctx context.Context, sess *session, req *Request, header http.Header) {
 	// Parse params
 	paramsJSON, err := json.Marshal(req.Params)
 	if err != nil {
@@ -440,19 +1093,13 @@ func (h *Handler) handleCallTool(ctx context.Context, sess *session, req *Reques
 		return
 	}
 
-	// Extract agent name from tool name (format: agentname_toolname or just agentname)
-	agentName := params.Name
-	toolName := ""
-	if idx := strings.Index(params.Name, "_"); idx > 0 {
-		agentName = params.Name[:idx]
-		toolName = params.Name[idx+1:]
-	}
+	agentName, toolName := h.resolveToolName(params.Name)
 
 	// Record tool call metric
 	metrics.RecordMCPToolsCall(agentName, toolName)
 
 	// Find agent
-	agent, found := h.watcher.GetByName(agentName)
+	agent, found := h.resolveAgent(agentName)
 	if !found {
 		h.sendError(sess, req.ID, ErrCodeInvalidParams, "Agent not found", agentName)
 		return
@@ -463,31 +1110,47 @@ func (h *Handler) handleCallTool(ctx context.Context, sess *session, req *Reques
 		return
 	}
 
-	// Build query from arguments
-	query := ""
-	if q, ok := params.Arguments["query"].(string); ok {
-		query = q
-	} else if q, ok := params.Arguments["question"].(string); ok {
-		query = q
-	} else if q, ok := params.Arguments["request"].(string); ok {
-		query = q
-	} else if q, ok := params.Arguments["description"].(string); ok {
-		query = q
-	}
-
-	if query == "" {
-		// Try to construct query from all arguments
-		parts := make([]string, 0)
-		for k, v := range params.Arguments {
-			if s, ok := v.(string); ok && s != "" {
-				parts = append(parts, fmt.Sprintf("%s: %s", k, s))
-			}
-		}
-		query = strings.Join(parts, "\n")
+	params.Arguments = withDefaultArguments(agent, toolName, params.Arguments)
+	var invalidArgs *invalidArgumentsError
+	if err := validateToolArguments(agent, params.Name, toolName, params.Arguments); errors.As(err, &invalidArgs) {
+		h.sendSSEMessage(sess, Response{JSONRPC: "2.0", ID: req.ID, Error: invalidArgs.rpcError()})
+		return
+	}
+	var callLimited *callRateLimitedError
+	if err := h.checkCallRate(agent); errors.As(err, &callLimited) {
+		h.sendSSEMessage(sess, Response{JSONRPC: "2.0", ID: req.ID, Error: callLimited.rpcError()})
+		return
 	}
 
+	query := agentQuery(agent, params.Arguments)
+
 	// Forward to agent
-	result, err := h.forwardToAgent(ctx, agent, query, params.Arguments)
+	ctx, done := h.trackCall(ctx, sseCallScope(sess.id), req.ID)
+	defer done()
+	stopProgress := h.startProgress(sess, params.Meta)
+	start := time.Now()
+	result, err := h.forwardToAgent(ctx, agent, query, params.Arguments, header)
+	stopProgress()
+	recordVariantCall(agent, err, start)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		// The client asked us to stop; it expects no response
+		h.logger.Debugf("[MCP] Tool call %v cancelled", req.ID)
+		return
+	}
+	var timeoutErr *agentTimeoutError
+	if errors.As(err, &timeoutErr) {
+		if partial := timeoutErr.partialResult(); partial != nil {
+			h.sendResult(sess, req.ID, partial)
+			return
+		}
+		h.sendSSEMessage(sess, Response{JSONRPC: "2.0", ID: req.ID, Error: timeoutErr.rpcError()})
+		return
+	}
+	var rateLimited *routes.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		h.sendSSEMessage(sess, Response{JSONRPC: "2.0", ID: req.ID, Error: rateLimitedRPCError(rateLimited)})
+		return
+	}
 	if err != nil {
 		h.sendResult(sess, req.ID, CallToolResult{
 			Content: []Content{{Type: "text", Text: fmt.Sprintf("Error: %v", err)}},
@@ -497,90 +1160,353 @@ func (h *Handler) handleCallTool(ctx context.Context, sess *session, req *Reques
 	}
 
 	h.sendResult(sess, req.ID, CallToolResult{
-		Content: []Content{{Type: "text", Text: result}},
+		Content: result,
 	})
 }
 
-func (h *Handler) forwardToAgent(ctx context.Context, agent *k8s.Agent, query string, args map[string]interface{}) (string, error) {
+func (h *Handler) forwardToAgent(ctx context.Context, agent *k8s.Agent, query string, args map[string]interface{}, header http.Header) ([]Content, error) {
+	return h.forwardToAgentStream(ctx, agent, query, args, header, nil)
+}
+
+// forwardToAgentStream forwards a tool call like forwardToAgent, passing
+// each piece of a successful response body to onChunk, if set, as it
+// arrives from the agent.
+func (h *Handler) forwardToAgentStream(ctx context.Context, agent *k8s.Agent, query string, args map[string]interface{}, header http.Header, onChunk func([]byte)) ([]Content, error) {
 	// Build request to agent
-	agentReq := map[string]interface{}{
+	var agentReq interface{} = map[string]interface{}{
 		"query":    query,
 		"input":    args,
 		"metadata": map[string]interface{}{"source": "mcp"},
 	}
+	if agent.Spec.InvokeMapping.PassRawArguments {
+		if args == nil {
+			args = map[string]interface{}{}
+		}
+		agentReq = args
+	}
 
 	body, err := json.Marshal(agentReq)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Create HTTP request - ensure FQDN format to avoid DNS search domain issues
-	endpoint := agent.Status.Endpoint
-	if strings.Contains(endpoint, ".svc.cluster.local") && !strings.HasSuffix(strings.Split(endpoint, ":")[0], ".") {
-		parts := strings.SplitN(endpoint, ":", 2)
-		if len(parts) == 2 {
-			endpoint = parts[0] + ".:" + parts[1]
-		}
-	}
-	url := fmt.Sprintf("http://%s/invoke", endpoint)
+	url := agentURL(agent, "/invoke")
 	h.logger.Debugf("[AGENT] >> POST %s", url)
 	h.logger.Debugf("[AGENT] >> Body: %s", truncate(string(body), 500))
 
+	ctx, cancel, timeout := withAgentTimeout(ctx, h.timeoutFor(agent))
+	defer cancel()
+
+	// Rate-limited calls are retried within the same timeout
+	for attempt := 1; ; attempt++ {
+		status, retryAfter, respBody, err := h.postAgent(ctx, agent, url, body, header, timeout, onChunk)
+		if err != nil {
+			return nil, err
+		}
+
+		if status == http.StatusTooManyRequests {
+			delay, ok := routes.ParseRetryAfter(retryAfter, time.Now())
+			if ok && h.rateLimit.Wait(ctx, attempt, delay) {
+				h.logger.Debugf("[AGENT] %s rate limited, retrying after %s", agent.Name, delay)
+				continue
+			}
+			return nil, &routes.RateLimitedError{Agent: agent.Name, RetryAfter: delay}
+		}
+		if status >= 400 {
+			return nil, fmt.Errorf("agent returned %d: %s", status, string(respBody))
+		}
+
+		return agentContent(respBody), nil
+	}
+}
+
+// defaultQueryFields are the arguments searched, in order, for the query
+// sent to an agent without a configured query field.
+var defaultQueryFields = []string{"query", "question", "request", "description"}
+
+// agentQuery builds the query sent to an agent from tool-call arguments:
+// the agent's configured query field, or the first default field that is
+// set, or else every string argument as "key: value" lines.
+func agentQuery(agent *k8s.Agent, args map[string]interface{}) string {
+	fields := defaultQueryFields
+	if field := agent.Spec.InvokeMapping.QueryField; field != "" {
+		fields = []string{field}
+	}
+	for _, key := range fields {
+		if q, ok := args[key].(string); ok && q != "" {
+			return q
+		}
+	}
+
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if s, ok := args[k].(string); ok && s != "" {
+			parts = append(parts, fmt.Sprintf("%s: %s", k, s))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// postAgent sends one request to an agent and returns the status,
+// Retry-After header and body of its response. ctx must carry the timeout
+// returned by withAgentTimeout; expiring it is reported as an
+// agentTimeoutError. onChunk, if set, receives a successful response body
+// as it is read.
+func (h *Handler) postAgent(ctx context.Context, agent *k8s.Agent, url string, body []byte, header http.Header, timeout time.Duration, onChunk func([]byte)) (int, string, []byte, error) {
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
-		return "", err
+		return 0, "", nil, err
 	}
+	h.forwardHeaders.Copy(httpReq.Header, header)
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	// Execute
+	defer metrics.TrackAgentInflight(agent.Name)()
 	startTime := time.Now()
 	resp, err := h.httpClient.Do(httpReq)
 	if err != nil {
 		h.logger.Errorf("[AGENT] << Error after %v: %v", time.Since(startTime), err)
-		return "", err
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return 0, "", nil, &agentTimeoutError{agent: agent.Name, timeout: timeout}
+		}
+		return 0, "", nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	// Read response
-	respBody, err := io.ReadAll(resp.Body)
+	var respBody []byte
+	if onChunk != nil && resp.StatusCode < 400 {
+		respBody, err = readChunks(resp.Body, h.maxRespLen, onChunk)
+	} else {
+		respBody, err = routes.ReadLimited(resp.Body, h.maxRespLen)
+	}
 	if err != nil {
-		return "", err
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			timeoutErr := &agentTimeoutError{agent: agent.Name, timeout: timeout}
+			if h.partialResults && resp.StatusCode < 400 {
+				timeoutErr.partial = respBody
+			}
+			return 0, "", nil, timeoutErr
+		}
+		return 0, "", nil, err
 	}
 
 	h.logger.Debugf("[AGENT] << %d after %v", resp.StatusCode, time.Since(startTime))
 	h.logger.Debugf("[AGENT] << Body: %s", truncate(string(respBody), 500))
 
-	if resp.StatusCode >= 400 {
-		return "", fmt.Errorf("agent returned %d: %s", resp.StatusCode, string(respBody))
+	return resp.StatusCode, resp.Header.Get("Retry-After"), respBody, nil
+}
+
+// agentURL returns the URL of path on the agent, writing cluster-local
+// endpoints as FQDNs to avoid DNS search domain issues.
+func agentURL(agent *k8s.Agent, path string) string {
+	endpoint := agent.Status.Endpoint
+	if strings.Contains(endpoint, ".svc.cluster.local") && !strings.HasSuffix(strings.Split(endpoint, ":")[0], ".") {
+		parts := strings.SplitN(endpoint, ":", 2)
+		if len(parts) == 2 {
+			endpoint = parts[0] + ".:" + parts[1]
+		}
+	}
+	return fmt.Sprintf("http://%s%s", endpoint, path)
+}
+
+// readChunks reads r like routes.ReadLimited, passing each piece of the body to
+// onChunk as it arrives. A UTF-8 sequence split across reads is held back
+// until it is complete.
+func readChunks(r io.Reader, max int64, onChunk func([]byte)) ([]byte, error) {
+	var body []byte
+	sent := 0
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if int64(len(body)+n) > max {
+				return nil, routes.TooLargeError(max)
+			}
+			body = append(body, buf[:n]...)
+			if end := completeUTF8(body); end > sent {
+				onChunk(body[sent:end])
+				sent = end
+			}
+		}
+		if err == io.EOF {
+			if sent < len(body) {
+				onChunk(body[sent:])
+			}
+			return body, nil
+		}
+		if err != nil {
+			return body, err
+		}
+	}
+}
+
+// completeUTF8 returns the length of b without a trailing incomplete UTF-8
+// sequence.
+func completeUTF8(b []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+		c := b[len(b)-i]
+		if c < utf8.RuneSelf {
+			return len(b)
+		}
+		if utf8.RuneStart(c) {
+			if !utf8.FullRune(b[len(b)-i:]) {
+				return len(b) - i
+			}
+			return len(b)
+		}
+	}
+	return len(b)
+}
+
+// agentContent converts an agent response body into MCP content blocks.
+// Responses carrying a "content" array (at the top level or under
+// "result") or a single typed part with a "contentType" are converted;
+// anything else becomes a text block.
+func agentContent(respBody []byte) []Content {
+	var envelope struct {
+		ContentType string          `json:"contentType"`
+		Content     json.RawMessage `json:"content"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(respBody, &envelope); err == nil {
+		if envelope.ContentType != "" {
+			if c, ok := parseContentPart(respBody); ok {
+				return []Content{c}
+			}
+		}
+		if content, ok := parseContentBlocks(envelope.Content); ok {
+			return content
+		}
+		var nested struct {
+			Content json.RawMessage `json:"content"`
+		}
+		if err := json.Unmarshal(envelope.Result, &nested); err == nil {
+			if content, ok := parseContentBlocks(nested.Content); ok {
+				return content
+			}
+		}
+	}
+
+	return []Content{{Type: "text", Text: extractText(respBody)}}
+}
+
+// parseContentBlocks decodes raw as a content array, reporting false unless
+// it is non-empty and every part is well-formed. Parts may be MCP content
+// blocks or typed parts with a "contentType".
+func parseContentBlocks(raw json.RawMessage) ([]Content, bool) {
+	if len(raw) == 0 {
+		return nil, false
+	}
+	var parts []json.RawMessage
+	if err := json.Unmarshal(raw, &parts); err != nil || len(parts) == 0 {
+		return nil, false
+	}
+	content := make([]Content, 0, len(parts))
+	for _, part := range parts {
+		c, ok := parseContentPart(part)
+		if !ok {
+			return nil, false
+		}
+		content = append(content, c)
+	}
+	return content, true
+}
+
+// inlineResourceURI names resources an agent returns without a "uri".
+const inlineResourceURI = "agent://result"
+
+// parseContentPart decodes one part of an agent response. A part is either
+// an MCP content block or a typed part such as
+//
+//	{"contentType": "image/png", "data": "<base64>"}
+//
+// whose payload is in "data" or "content". Images become image blocks,
+// text/* becomes a text block, and any other type becomes an embedded
+// resource: JSON payloads as text, string payloads as a base64 blob.
+func parseContentPart(raw json.RawMessage) (Content, bool) {
+	var part struct {
+		Content
+		ContentType string          `json:"contentType"`
+		Payload     json.RawMessage `json:"content"`
+		URI         string          `json:"uri"`
+	}
+	if err := json.Unmarshal(raw, &part); err != nil {
+		return Content{}, false
+	}
+	if part.ContentType == "" {
+		return part.Content, part.Content.valid()
 	}
 
+	payload := part.Payload
+	if part.Data != "" {
+		payload, _ = json.Marshal(part.Data)
+	}
+	var str string
+	isString := json.Unmarshal(payload, &str) == nil
+
+	mimeType := part.ContentType
+	var c Content
+	switch {
+	case strings.HasPrefix(mimeType, "image/") && isString:
+		c = Content{Type: "image", Data: str, MimeType: mimeType}
+	case strings.HasPrefix(mimeType, "text/") && isString:
+		c = Content{Type: "text", Text: str}
+	case len(payload) > 0 && string(payload) != "null":
+		uri := part.URI
+		if uri == "" {
+			uri = inlineResourceURI
+		}
+		resource := &ResourceContents{URI: uri, MimeType: mimeType}
+		if isString && !isJSONMimeType(mimeType) {
+			resource.Blob = str
+		} else {
+			resource.Text = string(payload)
+		}
+		c = Content{Type: "resource", Resource: resource}
+	}
+	return c, c.valid()
+}
+
+// isJSONMimeType reports whether mimeType is JSON or a +json suffix type.
+func isJSONMimeType(mimeType string) bool {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	return mimeType == "application/json" || strings.HasSuffix(mimeType, "+json")
+}
+
+// extractText returns the textual result of an agent response.
+func extractText(respBody []byte) string {
 	// Try to extract result from JSON response
 	var result map[string]interface{}
 	if err := json.Unmarshal(respBody, &result); err == nil {
 		// Check for common result field names
 		if r, ok := result["result"]; ok {
 			if s, ok := r.(string); ok {
-				return s, nil
+				return s
 			}
 			// Marshal back to JSON
 			resultJSON, _ := json.MarshalIndent(r, "", "  ")
-			return string(resultJSON), nil
+			return string(resultJSON)
 		}
 		if r, ok := result["response"]; ok {
 			if s, ok := r.(string); ok {
-				return s, nil
+				return s
 			}
 		}
 		if r, ok := result["output"]; ok {
 			if s, ok := r.(string); ok {
-				return s, nil
+				return s
 			}
 		}
-		// Return entire response as JSON
-		return string(respBody), nil
 	}
 
-	return string(respBody), nil
+	// Return entire response as-is
+	return string(respBody)
 }
 
 func (h *Handler) sendResult(sess *session, id interface{}, result interface{}) {
@@ -611,39 +1537,86 @@ func (h *Handler) sendSSEMessage(sess *session, data interface{}) {
 		h.logger.Errorf("Failed to marshal SSE message: %v", err)
 		return
 	}
+	if sess.compress && h.sseCompressMin > 0 && len(jsonData) >= h.sseCompressMin {
+		if compressed, ok := compressSSEPayload(jsonData); ok {
+			h.sendSSEEvent(sess, sseCompressedEvent, compressed)
+			return
+		}
+	}
 	h.sendSSEEvent(sess, "message", string(jsonData))
 }
 
+// sendSSEEvent writes an event to the session's stream. A failed write
+// means the client is gone, so it ends the session.
 func (h *Handler) sendSSEEvent(sess *session, event, data string) {
+	if err := writeSessionEvent(sess, event, data); err != nil {
+		h.endSession(sess, "write failed: "+err.Error())
+	}
+}
+
+// writeSessionEvent writes one event to the session's stream. A successful
+// write counts as activity, since the client is still reading the stream.
+func writeSessionEvent(sess *session, event, data string) error {
 	sess.mu.Lock()
 	defer sess.mu.Unlock()
 
+	// The stream is gone once the session ends
+	select {
+	case <-sess.done:
+		return nil
+	default:
+	}
+
+	var err error
+	write := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(sess.writer, format, args...)
+		}
+	}
+
 	// Write event type
-	_, _ = fmt.Fprintf(sess.writer, "event: %s\n", event)
+	write("event: %s\n", event)
 
 	// Write data (handle multi-line)
 	if data != "" {
 		scanner := bufio.NewScanner(strings.NewReader(data))
 		for scanner.Scan() {
-			_, _ = fmt.Fprintf(sess.writer, "data: %s\n", scanner.Text())
+			write("data: %s\n", scanner.Text())
 		}
 	} else {
-		_, _ = fmt.Fprint(sess.writer, "data: \n")
+		write("data: \n")
 	}
 
 	// End event
-	_, _ = fmt.Fprint(sess.writer, "\n")
+	write("\n")
+	if err != nil {
+		return err
+	}
 	sess.flusher.Flush()
+	sess.touch()
+	return nil
 }
 
-// NotifyToolsListChanged sends a notification that the tools list has changed.
+// NotifyToolsListChanged drops the cached tools list and notifies sessions
+// that it changed.
 func (h *Handler) NotifyToolsListChanged() {
+	h.invalidateToolsList()
+	h.notifySessions("notifications/tools/list_changed")
+}
+
+// NotifyPromptsListChanged notifies sessions that the prompts list changed.
+func (h *Handler) NotifyPromptsListChanged() {
+	h.notifySessions("notifications/prompts/list_changed")
+}
+
+// notifySessions sends a notification to every initialized session.
+func (h *Handler) notifySessions(method string) {
 	h.sessions.Range(func(key, value interface{}) bool {
 		sess := value.(*session)
 		if sess.initialized {
 			notification := Notification{
 				JSONRPC: "2.0",
-				Method:  "notifications/tools/list_changed",
+				Method:  method,
 			}
 			h.sendSSEMessage(sess, notification)
 		}
@@ -0,0 +1,1287 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/k8s"
+	"github.com/jarsater/mcp-fabric/gateway/internal/metrics"
+	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
+)
+
+// newTestHandler creates a handler whose watcher serves the given agents.
+func newTestHandler(agents ...*k8s.Agent) *Handler {
+	watcher := &k8s.AgentWatcher{}
+	for _, agent := range agents {
+		watcher.Store(agent)
+	}
+	return NewHandler(zap.NewNop().Sugar(), watcher)
+}
+
+// newStubAgent starts an HTTP server acting as an agent /invoke endpoint and
+// returns a ready agent pointing at it.
+func newStubAgent(t *testing.T, name string, handler http.HandlerFunc) *k8s.Agent {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &k8s.Agent{
+		Name:      name,
+		Namespace: "default",
+		Status: k8s.AgentStatus{
+			Ready:    true,
+			Endpoint: strings.TrimPrefix(server.URL, "http://"),
+		},
+	}
+}
+
+// slowAgent returns a handler that blocks until the client goes away.
+func slowAgent(w http.ResponseWriter, r *http.Request) {
+	// Drain the body so the server notices the client disconnecting.
+	_, _ = io.Copy(io.Discard, r.Body)
+	select {
+	case <-r.Context().Done():
+	case <-time.After(5 * time.Second):
+	}
+}
+
+func callToolHTTP(t *testing.T, h *Handler, name string, args map[string]interface{}) Response {
+	t.Helper()
+	body, err := json.Marshal(Request{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  CallToolParams{Name: name, Arguments: args},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.HandleHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body)))
+
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return resp
+}
+
+func TestInitialize_NegotiatesProtocolVersion(t *testing.T) {
+	tests := []struct {
+		requested string
+		want      string
+	}{
+		{requested: "2025-03-26", want: "2025-03-26"},
+		{requested: "2024-11-05", want: "2024-11-05"},
+		{requested: "2099-01-01", want: latestProtocolVersion},
+		{requested: "", want: latestProtocolVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.requested, func(t *testing.T) {
+			h := newTestHandler()
+			params := InitializeParams{ProtocolVersion: tt.requested}
+
+			var result InitializeResult
+			rpcHTTP(t, h, "initialize", params, &result)
+			if result.ProtocolVersion != tt.want {
+				t.Errorf("HTTP: expected %q, got %q", tt.want, result.ProtocolVersion)
+			}
+
+			rec := httptest.NewRecorder()
+			sess := &session{id: 1, writer: rec, flusher: rec, done: make(chan struct{})}
+			h.handleInitialize(sess, &Request{JSONRPC: "2.0", ID: 1, Method: "initialize", Params: params})
+			if !strings.Contains(rec.Body.String(), `"protocolVersion":"`+tt.want+`"`) {
+				t.Errorf("SSE: expected %q, got %s", tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleHTTP_Batch(t *testing.T) {
+	h := newTestHandler(&k8s.Agent{
+		Name:      "echo",
+		Namespace: "default",
+		Status:    k8s.AgentStatus{Ready: true, Endpoint: "echo:8080"},
+	})
+
+	body := `[
+		{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}},
+		{"jsonrpc":"2.0","method":"notifications/initialized"},
+		{"jsonrpc":"2.0","id":"list","method":"tools/list"},
+		{"jsonrpc":"2.0","id":3,"method":"bogus"}
+	]`
+	rec := httptest.NewRecorder()
+	h.HandleHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body)))
+
+	var responses []struct {
+		ID     interface{}     `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &responses); err != nil {
+		t.Fatalf("expected a response array, got %q: %v", rec.Body.String(), err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("expected 3 responses (none for the notification), got %s", rec.Body.String())
+	}
+
+	if responses[0].ID != float64(1) || !strings.Contains(string(responses[0].Result), `"protocolVersion":"2025-03-26"`) {
+		t.Errorf("expected initialize result for id 1, got %+v", responses[0])
+	}
+	if responses[1].ID != "list" || !strings.Contains(string(responses[1].Result), `"name":"echo"`) {
+		t.Errorf("expected tools/list result for id list, got %+v", responses[1])
+	}
+	if responses[2].ID != float64(3) || responses[2].Error == nil || responses[2].Error.Code != ErrCodeMethodNotFound {
+		t.Errorf("expected method not found for id 3, got %+v", responses[2])
+	}
+}
+
+func TestHandleHTTP_BatchEdgeCases(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "only notifications", body: `[{"jsonrpc":"2.0","method":"notifications/initialized"}]`, wantCode: http.StatusAccepted},
+		{name: "empty batch", body: ` []`, wantCode: http.StatusOK, wantBody: `"code":-32600`},
+		{name: "invalid element", body: `[1]`, wantCode: http.StatusOK, wantBody: `[{"jsonrpc":"2.0","id":null,"error":{"code":-32600`},
+		{name: "malformed batch", body: `[{"jsonrpc":`, wantCode: http.StatusOK, wantBody: `"code":-32700`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler()
+			rec := httptest.NewRecorder()
+			h.HandleHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+			if tt.wantBody == "" && rec.Body.Len() != 0 {
+				t.Errorf("expected an empty body, got %q", rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("expected body containing %s, got %q", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleHTTP_PreservesRequestID(t *testing.T) {
+	for _, id := range []string{`7`, `9007199254740993`, `-12`, `"req-1"`, `"42"`, `null`} {
+		t.Run(id, func(t *testing.T) {
+			h := newTestHandler()
+			body := `{"jsonrpc":"2.0","id":` + id + `,"method":"ping"}`
+			rec := httptest.NewRecorder()
+			h.HandleHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body)))
+
+			want := `{"jsonrpc":"2.0","id":` + id + `,"result":{}}`
+			if got := strings.TrimSpace(rec.Body.String()); got != want {
+				t.Errorf("expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestCancelCall_LargeIntegerID(t *testing.T) {
+	h := newTestHandler()
+	var call, cancel Request
+	if err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","id":9007199254740993,"method":"tools/call"}`), &call); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":9007199254740993}}`), &cancel); err != nil {
+		t.Fatal(err)
+	}
+
+	// An ID that differs only beyond float64 precision must not match
+	ctxOther, doneOther := h.trackCall(context.Background(), sseCallScope(1), json.Number("9007199254740992"))
+	defer doneOther()
+	ctx, done := h.trackCall(context.Background(), sseCallScope(1), call.ID)
+	defer done()
+
+	h.cancelCall(sseCallScope(1), &cancel)
+	if ctx.Err() == nil {
+		t.Error("expected the call to be cancelled")
+	}
+	if ctxOther.Err() != nil {
+		t.Error("expected the call with a neighbouring ID to keep running")
+	}
+}
+
+func TestHandleHTTP_AgentTimeout(t *testing.T) {
+	agent := newStubAgent(t, "slow", slowAgent)
+	agent.Spec.RequestTimeout = 50 * time.Millisecond
+	h := newTestHandler(agent)
+
+	resp := callToolHTTP(t, h, "slow", map[string]interface{}{"query": "hello"})
+
+	if resp.Error == nil {
+		t.Fatalf("expected error response, got result %v", resp.Result)
+	}
+	if resp.Error.Code != ErrCodeAgentTimeout {
+		t.Errorf("expected code %d, got %d", ErrCodeAgentTimeout, resp.Error.Code)
+	}
+	if resp.Error.Message != "agent timed out after 50ms" {
+		t.Errorf("unexpected message: %q", resp.Error.Message)
+	}
+	data, ok := resp.Error.Data.(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected map data, got %T", resp.Error.Data)
+	}
+	if data["timeoutMs"] != float64(50) {
+		t.Errorf("expected timeoutMs 50, got %v", data["timeoutMs"])
+	}
+	if data["agent"] != "slow" {
+		t.Errorf("expected agent slow, got %v", data["agent"])
+	}
+}
+
+func TestForwardToAgent_CallerDeadlineReported(t *testing.T) {
+	agent := newStubAgent(t, "slow", slowAgent)
+	agent.Spec.RequestTimeout = 5 * time.Second
+	h := newTestHandler(agent)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := h.forwardToAgent(ctx, agent, "hello", nil, nil)
+
+	var timeoutErr *agentTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected an agent timeout error, got %v", err)
+	}
+	// The caller's shorter deadline fired, not the agent's 5s timeout
+	if timeoutErr.timeout <= 0 || timeoutErr.timeout > 50*time.Millisecond {
+		t.Errorf("expected the caller's 50ms deadline to be reported, got %s", timeoutErr.timeout)
+	}
+}
+
+// streamThenStall returns a handler that streams part of a response and then
+// blocks until the client goes away.
+func streamThenStall(partial string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(partial))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func TestHandleHTTP_PartialResultOnTimeout(t *testing.T) {
+	agent := newStubAgent(t, "slow", streamThenStall("step 1 done\nstep 2 done\n"))
+	agent.Spec.RequestTimeout = 50 * time.Millisecond
+	h := newTestHandler(agent)
+	h.SetPartialResults(true)
+
+	resp := callToolHTTP(t, h, "slow", map[string]interface{}{"query": "hello"})
+
+	if resp.Error != nil {
+		t.Fatalf("expected partial tool result, got error %+v", resp.Error)
+	}
+	raw, _ := json.Marshal(resp.Result)
+	var result CallToolResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected partial result to be marked isError, got %s", raw)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("expected output and note blocks, got %s", raw)
+	}
+	if result.Content[0].Text != "step 1 done\nstep 2 done\n" {
+		t.Errorf("expected streamed output preserved, got %q", result.Content[0].Text)
+	}
+	if !strings.Contains(result.Content[1].Text, "partial result") {
+		t.Errorf("expected partial note, got %q", result.Content[1].Text)
+	}
+}
+
+func TestHandleHTTP_PartialResultsDisabled(t *testing.T) {
+	agent := newStubAgent(t, "slow", streamThenStall("step 1 done\n"))
+	agent.Spec.RequestTimeout = 50 * time.Millisecond
+	h := newTestHandler(agent)
+
+	resp := callToolHTTP(t, h, "slow", map[string]interface{}{"query": "hello"})
+
+	if resp.Error == nil || resp.Error.Code != ErrCodeAgentTimeout {
+		t.Fatalf("expected agent timeout error, got %+v", resp)
+	}
+}
+
+func TestHandleHTTP_DefaultAgentTimeout(t *testing.T) {
+	agent := newStubAgent(t, "slow", slowAgent)
+	h := newTestHandler(agent)
+	h.agentTimeout = 20 * time.Millisecond
+
+	resp := callToolHTTP(t, h, "slow", map[string]interface{}{"query": "hello"})
+
+	if resp.Error == nil || resp.Error.Code != ErrCodeAgentTimeout {
+		t.Fatalf("expected agent timeout error, got %+v", resp)
+	}
+}
+
+func TestHandleHTTP_AgentErrorIsToolResult(t *testing.T) {
+	agent := newStubAgent(t, "broken", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	h := newTestHandler(agent)
+
+	resp := callToolHTTP(t, h, "broken", map[string]interface{}{"query": "hello"})
+
+	if resp.Error != nil {
+		t.Fatalf("expected tool result, got error %+v", resp.Error)
+	}
+	result, ok := resp.Result.(map[string]interface{})
+	if !ok || result["isError"] != true {
+		t.Errorf("expected isError result, got %v", resp.Result)
+	}
+}
+
+func TestHandleHTTP_ForwardHeaders(t *testing.T) {
+	forwarded := make(chan http.Header, 1)
+	agent := newStubAgent(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		forwarded <- r.Header.Clone()
+		_, _ = w.Write([]byte(`{"result":"pong"}`))
+	})
+	h := newTestHandler(agent)
+	h.SetForwardHeaders(routes.HeaderAllowlist{"X-Tenant"})
+
+	body, err := json.Marshal(Request{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  CallToolParams{Name: "echo", Arguments: map[string]interface{}{"query": "ping"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body))
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Set("Authorization", "Bearer secret")
+	h.HandleHTTP(httptest.NewRecorder(), req)
+
+	got := <-forwarded
+	if got.Get("X-Tenant") != "acme" {
+		t.Errorf("expected X-Tenant forwarded, got %v", got)
+	}
+	if got.Get("Authorization") != "" {
+		t.Errorf("expected Authorization not to be forwarded, got %q", got.Get("Authorization"))
+	}
+}
+
+func TestToolCall_DefaultArguments(t *testing.T) {
+	inputs := make(chan map[string]interface{}, 1)
+	agent := newStubAgent(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input map[string]interface{} `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		inputs <- body.Input
+		_, _ = w.Write([]byte(`{"result":"ok"}`))
+	})
+	agent.Spec.Tools = []k8s.AgentTool{{
+		Name:             "lookup",
+		DefaultArguments: map[string]string{"tenant": "acme", "locale": "en"},
+	}}
+	h := newTestHandler(agent)
+	args := map[string]interface{}{"query": "q", "locale": "sv"}
+	want := map[string]interface{}{"query": "q", "tenant": "acme", "locale": "sv"}
+
+	t.Run("http", func(t *testing.T) {
+		if resp := callToolHTTP(t, h, "echo_lookup", args); resp.Error != nil {
+			t.Fatalf("unexpected error: %+v", resp.Error)
+		}
+		if got := <-inputs; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("sse", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		sess := &session{id: 1, initialized: true, writer: rec, flusher: rec, done: make(chan struct{})}
+		h.handleCallTool(context.Background(), sess, &Request{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "tools/call",
+			Params:  CallToolParams{Name: "echo_lookup", Arguments: args},
+		}, nil)
+		if got := <-inputs; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	if args["tenant"] != nil {
+		t.Errorf("expected the client's arguments to be left untouched, got %v", args)
+	}
+}
+
+func TestToolCall_InvokeMapping(t *testing.T) {
+	bodies := make(chan map[string]interface{}, 1)
+	agent := newStubAgent(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies <- body
+		_, _ = w.Write([]byte(`{"result":"ok"}`))
+	})
+	h := newTestHandler(agent)
+	args := map[string]interface{}{"query": "ignored", "prompt": "summarize", "limit": float64(3)}
+
+	tests := []struct {
+		name    string
+		mapping k8s.AgentInvokeMapping
+		want    map[string]interface{}
+	}{
+		{
+			name: "default",
+			want: map[string]interface{}{"query": "ignored", "input": args, "metadata": map[string]interface{}{"source": "mcp"}},
+		},
+		{
+			name:    "query field",
+			mapping: k8s.AgentInvokeMapping{QueryField: "prompt"},
+			want:    map[string]interface{}{"query": "summarize", "input": args, "metadata": map[string]interface{}{"source": "mcp"}},
+		},
+		{
+			name:    "missing query field",
+			mapping: k8s.AgentInvokeMapping{QueryField: "topic"},
+			want:    map[string]interface{}{"query": "prompt: summarize\nquery: ignored", "input": args, "metadata": map[string]interface{}{"source": "mcp"}},
+		},
+		{
+			name:    "raw arguments",
+			mapping: k8s.AgentInvokeMapping{PassRawArguments: true},
+			want:    args,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent.Spec.InvokeMapping = tt.mapping
+			if resp := callToolHTTP(t, h, "echo", args); resp.Error != nil {
+				t.Fatalf("unexpected error: %+v", resp.Error)
+			}
+			if got := <-bodies; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHandleHTTP_AgentRateLimited(t *testing.T) {
+	var calls atomic.Int32
+	agent := newStubAgent(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":"pong"}`))
+	})
+	h := newTestHandler(agent)
+	params := CallToolParams{Name: "echo", Arguments: map[string]interface{}{"query": "ping"}}
+
+	resp := rpcHTTP(t, h, "tools/call", params, nil)
+	if resp.Error == nil || resp.Error.Code != ErrCodeAgentRateLimited {
+		t.Fatalf("expected rate limited error, got %+v", resp)
+	}
+	data, _ := resp.Error.Data.(map[string]interface{})
+	if data["retryAfterMs"] != float64(3000) {
+		t.Errorf("expected retryAfterMs 3000, got %v", data["retryAfterMs"])
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected no retry by default, got %d calls", calls.Load())
+	}
+}
+
+func TestHandleHTTP_AgentRateLimitedRetry(t *testing.T) {
+	var calls atomic.Int32
+	agent := newStubAgent(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":"pong"}`))
+	})
+	h := newTestHandler(agent)
+	h.SetRateLimitPolicy(routes.RateLimitPolicy{Retries: 1, MaxWait: time.Second})
+
+	var result CallToolResult
+	resp := rpcHTTP(t, h, "tools/call", CallToolParams{Name: "echo", Arguments: map[string]interface{}{"query": "ping"}}, &result)
+	if resp.Error != nil || result.IsError {
+		t.Fatalf("expected success after retry, got %+v / %+v", resp.Error, result)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 calls, got %d", calls.Load())
+	}
+}
+
+// sseMessages decodes the data of each event in an SSE response body.
+func sseMessages(t *testing.T, body string) []map[string]interface{} {
+	t.Helper()
+	var messages []map[string]interface{}
+	for _, event := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		for _, line := range strings.Split(event, "\n") {
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				var msg map[string]interface{}
+				if err := json.Unmarshal([]byte(data), &msg); err != nil {
+					t.Fatalf("failed to decode event data %q: %v", data, err)
+				}
+				messages = append(messages, msg)
+			}
+		}
+	}
+	return messages
+}
+
+func TestHandleHTTP_StreamsToolCall(t *testing.T) {
+	agent := newStubAgent(t, "writer", func(w http.ResponseWriter, r *http.Request) {
+		for _, chunk := range []string{"first ", "second ", "third"} {
+			_, _ = w.Write([]byte(chunk))
+			w.(http.Flusher).Flush()
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+	h := newTestHandler(agent)
+
+	body, err := json.Marshal(Request{
+		JSONRPC: "2.0",
+		ID:      7,
+		Method:  "tools/call",
+		Params: map[string]interface{}{
+			"name":      "writer",
+			"arguments": map[string]interface{}{"query": "go"},
+			"_meta":     map[string]interface{}{"progressToken": "tok"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body))
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	rec := httptest.NewRecorder()
+	h.HandleHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %q: %s", ct, rec.Body.String())
+	}
+	messages := sseMessages(t, rec.Body.String())
+	if len(messages) < 2 {
+		t.Fatalf("expected progress notifications and a response, got %v", messages)
+	}
+
+	var streamed string
+	for _, msg := range messages[:len(messages)-1] {
+		if msg["method"] != "notifications/progress" {
+			t.Fatalf("expected progress notification, got %v", msg)
+		}
+		params, _ := msg["params"].(map[string]interface{})
+		if params["progressToken"] != "tok" {
+			t.Errorf("expected progress token tok, got %v", params["progressToken"])
+		}
+		streamed += params["message"].(string)
+	}
+	if streamed != "first second third" {
+		t.Errorf("expected the streamed text in order, got %q", streamed)
+	}
+
+	final := messages[len(messages)-1]
+	if final["id"] != float64(7) || final["error"] != nil {
+		t.Fatalf("expected the JSON-RPC response last, got %v", final)
+	}
+	raw, _ := json.Marshal(final["result"])
+	var result CallToolResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if len(result.Content) != 1 || result.Content[0].Text != "first second third" {
+		t.Errorf("expected the full result, got %s", raw)
+	}
+}
+
+func TestHandleHTTP_StreamsToolCallAsNDJSON(t *testing.T) {
+	agent := newStubAgent(t, "writer", func(w http.ResponseWriter, r *http.Request) {
+		for _, chunk := range []string{"one\n", "two"} {
+			_, _ = w.Write([]byte(chunk))
+			w.(http.Flusher).Flush()
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+	h := newTestHandler(agent)
+
+	body, err := json.Marshal(Request{
+		JSONRPC: "2.0",
+		ID:      3,
+		Method:  "tools/call",
+		Params:  CallToolParams{Name: "writer", Arguments: map[string]interface{}{"query": "go"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body))
+	req.Header.Set("Accept", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	h.HandleHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("expected ndjson, got %q: %s", ct, rec.Body.String())
+	}
+	out := rec.Body.String()
+	if !strings.HasSuffix(out, "\n") {
+		t.Fatalf("expected newline-terminated output, got %q", out)
+	}
+
+	// One JSON object per line: progress notifications, then the response
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected progress lines and a final response, got %q", out)
+	}
+	var streamed string
+	for i, line := range lines {
+		var msg map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("line %d is not a JSON object: %q", i, line)
+		}
+		if i < len(lines)-1 {
+			params, _ := msg["params"].(map[string]interface{})
+			if msg["method"] != "notifications/progress" || params["progressToken"] != float64(3) {
+				t.Fatalf("expected progress notification keyed by the request ID, got %v", msg)
+			}
+			streamed += params["message"].(string)
+			continue
+		}
+		if msg["id"] != float64(3) || msg["result"] == nil {
+			t.Errorf("expected the final result last, got %v", msg)
+		}
+	}
+	if streamed != "one\ntwo" {
+		t.Errorf("expected the streamed text in order, got %q", streamed)
+	}
+}
+
+func TestHandleHTTP_ToolCallWithoutEventStreamIsBuffered(t *testing.T) {
+	agent := newStubAgent(t, "echo", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("pong"))
+	})
+	h := newTestHandler(agent)
+
+	resp := callToolHTTP(t, h, "echo", map[string]interface{}{"query": "ping"})
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	raw, _ := json.Marshal(resp.Result)
+	if !strings.Contains(string(raw), "pong") {
+		t.Errorf("expected buffered result, got %s", raw)
+	}
+}
+
+func TestReadChunks_HoldsBackSplitRunes(t *testing.T) {
+	const text = "héllo wörld ✓"
+	var chunks []string
+	body, err := readChunks(iotest.OneByteReader(strings.NewReader(text)), 1024, func(b []byte) {
+		if !utf8.Valid(b) {
+			t.Errorf("chunk %q splits a rune", b)
+		}
+		chunks = append(chunks, string(b))
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(body) != text || strings.Join(chunks, "") != text {
+		t.Errorf("expected %q, got body %q and chunks %q", text, body, chunks)
+	}
+}
+
+func TestHandleCallTool_SSEProgressHeartbeat(t *testing.T) {
+	agent := newStubAgent(t, "slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(120 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"result":"done"}`))
+	})
+
+	tests := []struct {
+		name         string
+		meta         *RequestMeta
+		wantProgress bool
+	}{
+		{name: "with progress token", meta: &RequestMeta{ProgressToken: "tok"}, wantProgress: true},
+		{name: "without progress token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(agent)
+			h.SetProgressInterval(20 * time.Millisecond)
+			rec := httptest.NewRecorder()
+			sess := &session{id: 1, initialized: true, writer: rec, flusher: rec, done: make(chan struct{})}
+
+			h.handleCallTool(context.Background(), sess, &Request{
+				JSONRPC: "2.0",
+				ID:      9,
+				Method:  "tools/call",
+				Params:  CallToolParams{Name: "slow", Arguments: map[string]interface{}{"query": "hi"}, Meta: tt.meta},
+			}, nil)
+			// Give a leaked heartbeat the chance to write after the result
+			time.Sleep(50 * time.Millisecond)
+
+			messages := sseMessages(t, rec.Body.String())
+			if len(messages) == 0 || messages[len(messages)-1]["id"] != float64(9) {
+				t.Fatalf("expected the result as the last message, got %v", messages)
+			}
+			progress := messages[:len(messages)-1]
+			if !tt.wantProgress {
+				if len(progress) != 0 {
+					t.Errorf("expected no progress without a token, got %v", progress)
+				}
+				return
+			}
+			if len(progress) < 2 {
+				t.Fatalf("expected several progress heartbeats, got %v", progress)
+			}
+			for i, msg := range progress {
+				params, _ := msg["params"].(map[string]interface{})
+				if msg["method"] != "notifications/progress" || params["progressToken"] != "tok" || params["progress"] != float64(i+1) {
+					t.Errorf("expected progress %d for tok, got %v", i+1, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleCallTool_SSEAgentTimeout(t *testing.T) {
+	agent := newStubAgent(t, "slow", slowAgent)
+	agent.Spec.RequestTimeout = 50 * time.Millisecond
+	h := newTestHandler(agent)
+
+	rec := httptest.NewRecorder()
+	sess := &session{id: 1, writer: rec, flusher: rec, done: make(chan struct{})}
+	req := &Request{
+		JSONRPC: "2.0",
+		ID:      7,
+		Method:  "tools/call",
+		Params:  map[string]interface{}{"name": "slow", "arguments": map[string]interface{}{"query": "hi"}},
+	}
+
+	h.handleCallTool(context.Background(), sess, req, nil)
+
+	var payload string
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if strings.HasPrefix(line, "data: ") {
+			payload += strings.TrimPrefix(line, "data: ")
+		}
+	}
+	var resp Response
+	if err := json.Unmarshal([]byte(payload), &resp); err != nil {
+		t.Fatalf("failed to decode SSE payload %q: %v", payload, err)
+	}
+	if resp.Error == nil || resp.Error.Code != ErrCodeAgentTimeout {
+		t.Fatalf("expected agent timeout error, got %+v", resp)
+	}
+}
+
+func TestHandleHTTP_PassesThroughContentArray(t *testing.T) {
+	agent := newStubAgent(t, "painter", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"here you go"},{"type":"image","data":"aGVsbG8=","mimeType":"image/png"}]}`))
+	})
+	h := newTestHandler(agent)
+
+	resp := callToolHTTP(t, h, "painter", map[string]interface{}{"query": "draw"})
+
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	raw, _ := json.Marshal(resp.Result)
+	var result CallToolResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("expected 2 content blocks, got %d: %s", len(result.Content), raw)
+	}
+	if result.Content[1].Type != "image" || result.Content[1].MimeType != "image/png" || result.Content[1].Data != "aGVsbG8=" {
+		t.Errorf("image block not passed through: %+v", result.Content[1])
+	}
+}
+
+func TestHandleHTTP_ConvertsTypedImage(t *testing.T) {
+	agent := newStubAgent(t, "camera", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"contentType":"image/jpeg","data":"/9j/4AAQ"}`))
+	})
+	h := newTestHandler(agent)
+
+	resp := callToolHTTP(t, h, "camera", map[string]interface{}{"query": "snap"})
+
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	raw, _ := json.Marshal(resp.Result)
+	var result CallToolResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if len(result.Content) != 1 || result.Content[0].Type != "image" || result.Content[0].MimeType != "image/jpeg" || result.Content[0].Data != "/9j/4AAQ" {
+		t.Errorf("expected an image block, got %s", raw)
+	}
+}
+
+func TestAgentContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		validate func(t *testing.T, content []Content)
+	}{
+		{
+			name: "top-level content array",
+			body: `{"content":[{"type":"text","text":"a"},{"type":"text","text":"b"}]}`,
+			validate: func(t *testing.T, content []Content) {
+				if len(content) != 2 || content[0].Text != "a" || content[1].Text != "b" {
+					t.Errorf("unexpected content: %+v", content)
+				}
+			},
+		},
+		{
+			name: "content array nested under result",
+			body: `{"result":{"content":[{"type":"resource","resource":{"uri":"file:///a.txt","text":"hi"}}]}}`,
+			validate: func(t *testing.T, content []Content) {
+				if len(content) != 1 || content[0].Resource == nil || content[0].Resource.URI != "file:///a.txt" {
+					t.Errorf("unexpected content: %+v", content)
+				}
+			},
+		},
+		{
+			name: "unknown block type falls back to text",
+			body: `{"content":[{"type":"video","url":"x"}]}`,
+			validate: func(t *testing.T, content []Content) {
+				if len(content) != 1 || content[0].Type != "text" || content[0].Text != `{"content":[{"type":"video","url":"x"}]}` {
+					t.Errorf("unexpected content: %+v", content)
+				}
+			},
+		},
+		{
+			name: "image missing mime type falls back to text",
+			body: `{"result":"ok","content":[{"type":"image","data":"abc"}]}`,
+			validate: func(t *testing.T, content []Content) {
+				if len(content) != 1 || content[0].Text != "ok" {
+					t.Errorf("unexpected content: %+v", content)
+				}
+			},
+		},
+		{
+			name: "typed image part",
+			body: `{"contentType":"image/png","data":"iVBORw0KGgo="}`,
+			validate: func(t *testing.T, content []Content) {
+				if len(content) != 1 || content[0].Type != "image" || content[0].Data != "iVBORw0KGgo=" || content[0].MimeType != "image/png" {
+					t.Errorf("unexpected content: %+v", content)
+				}
+			},
+		},
+		{
+			name: "typed JSON part becomes a resource",
+			body: `{"contentType":"application/json","content":{"rows":2},"uri":"report://q3"}`,
+			validate: func(t *testing.T, content []Content) {
+				if len(content) != 1 || content[0].Type != "resource" {
+					t.Fatalf("unexpected content: %+v", content)
+				}
+				r := content[0].Resource
+				if r.URI != "report://q3" || r.MimeType != "application/json" || r.Text != `{"rows":2}` {
+					t.Errorf("unexpected resource: %+v", r)
+				}
+			},
+		},
+		{
+			name: "typed binary part becomes a blob resource",
+			body: `{"contentType":"application/pdf","content":"JVBERi0="}`,
+			validate: func(t *testing.T, content []Content) {
+				if len(content) != 1 || content[0].Resource == nil {
+					t.Fatalf("unexpected content: %+v", content)
+				}
+				r := content[0].Resource
+				if r.URI != inlineResourceURI || r.Blob != "JVBERi0=" || r.Text != "" {
+					t.Errorf("unexpected resource: %+v", r)
+				}
+			},
+		},
+		{
+			name: "multi-part content mixing block shapes",
+			body: `{"content":[{"type":"text","text":"chart:"},{"contentType":"image/svg+xml","data":"PHN2Zy8+"},{"contentType":"text/markdown","content":"*done*"}]}`,
+			validate: func(t *testing.T, content []Content) {
+				if len(content) != 3 {
+					t.Fatalf("expected 3 blocks, got %+v", content)
+				}
+				if content[0].Text != "chart:" || content[1].Type != "image" || content[1].MimeType != "image/svg+xml" || content[2].Type != "text" || content[2].Text != "*done*" {
+					t.Errorf("unexpected content: %+v", content)
+				}
+			},
+		},
+		{
+			name: "typed part without payload falls back to text",
+			body: `{"contentType":"image/png","result":"no image"}`,
+			validate: func(t *testing.T, content []Content) {
+				if len(content) != 1 || content[0].Type != "text" || content[0].Text != "no image" {
+					t.Errorf("unexpected content: %+v", content)
+				}
+			},
+		},
+		{
+			name: "string result",
+			body: `{"result":"done"}`,
+			validate: func(t *testing.T, content []Content) {
+				if len(content) != 1 || content[0].Type != "text" || content[0].Text != "done" {
+					t.Errorf("unexpected content: %+v", content)
+				}
+			},
+		},
+		{
+			name: "non-JSON body",
+			body: "plain text",
+			validate: func(t *testing.T, content []Content) {
+				if len(content) != 1 || content[0].Text != "plain text" {
+					t.Errorf("unexpected content: %+v", content)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.validate(t, agentContent([]byte(tt.body)))
+		})
+	}
+}
+
+func TestHandleHTTP_ResponseTooLarge(t *testing.T) {
+	agent := newStubAgent(t, "chatty", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bytes.Repeat([]byte("x"), 4096))
+	})
+	h := newTestHandler(agent)
+	h.SetMaxResponseBytes(1024)
+
+	resp := callToolHTTP(t, h, "chatty", map[string]interface{}{"query": "talk"})
+
+	if resp.Error != nil {
+		t.Fatalf("expected tool error result, got %+v", resp.Error)
+	}
+	raw, _ := json.Marshal(resp.Result)
+	var result CallToolResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if !result.IsError || len(result.Content) != 1 || !strings.Contains(result.Content[0].Text, "response too large") {
+		t.Errorf("expected response too large error, got %s", raw)
+	}
+}
+
+func TestToolGroup_SingleToolBalancedDispatch(t *testing.T) {
+	var callsA, callsB atomic.Int32
+	agentA := newStubAgent(t, "search-v1", func(w http.ResponseWriter, r *http.Request) {
+		callsA.Add(1)
+		_, _ = w.Write([]byte(`{"result":"a"}`))
+	})
+	agentB := newStubAgent(t, "search-v2", func(w http.ResponseWriter, r *http.Request) {
+		callsB.Add(1)
+		_, _ = w.Write([]byte(`{"result":"b"}`))
+	})
+	for _, agent := range []*k8s.Agent{agentA, agentB} {
+		agent.Labels = map[string]string{ToolGroupLabel: "search"}
+		agent.Spec.Tools = []k8s.AgentTool{{Name: "lookup", Description: "Look something up"}}
+	}
+	loner := newStubAgent(t, "echo", func(w http.ResponseWriter, r *http.Request) {})
+	h := newTestHandler(agentA, agentB, loner)
+
+	var names []string
+	for _, tool := range h.buildToolsList().Tools {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(names, ",") != "echo,search_lookup" {
+		t.Fatalf("expected one logical tool for the group, got %v", names)
+	}
+
+	const calls = 200
+	for i := 0; i < calls; i++ {
+		resp := callToolHTTP(t, h, "search_lookup", map[string]interface{}{"query": "q"})
+		if resp.Error != nil {
+			t.Fatalf("unexpected error: %+v", resp.Error)
+		}
+	}
+
+	a, b := callsA.Load(), callsB.Load()
+	if a+b != calls {
+		t.Fatalf("expected %d calls across the group, got %d + %d", calls, a, b)
+	}
+	if a < calls/4 || b < calls/4 {
+		t.Errorf("expected calls spread across both agents, got %d and %d", a, b)
+	}
+}
+
+func TestToolGroup_CanaryVariant(t *testing.T) {
+	var callsStable, callsCanary atomic.Int32
+	stable := newStubAgent(t, "summarize-v1", func(w http.ResponseWriter, r *http.Request) {
+		callsStable.Add(1)
+		_, _ = w.Write([]byte(`{"result":"ok"}`))
+	})
+	canary := newStubAgent(t, "summarize-v2", func(w http.ResponseWriter, r *http.Request) {
+		callsCanary.Add(1)
+		http.Error(w, "model unavailable", http.StatusBadGateway)
+	})
+	stable.Labels = map[string]string{ToolGroupLabel: "canarytest", ToolWeightLabel: "50"}
+	canary.Labels = map[string]string{ToolGroupLabel: "canarytest", ToolWeightLabel: "50", ToolVariantLabel: "canary"}
+	h := newTestHandler(stable, canary)
+
+	for i := 0; i < 100; i++ {
+		callToolHTTP(t, h, "canarytest", map[string]interface{}{"query": "q"})
+	}
+	if callsStable.Load() == 0 || callsCanary.Load() == 0 {
+		t.Fatalf("expected calls on both variants, got stable=%d canary=%d", callsStable.Load(), callsCanary.Load())
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{
+		fmt.Sprintf(`mcpfabric_mcp_tool_variant_calls_total{group="canarytest",outcome="success",variant="stable"} %d`, callsStable.Load()),
+		fmt.Sprintf(`mcpfabric_mcp_tool_variant_calls_total{group="canarytest",outcome="error",variant="canary"} %d`, callsCanary.Load()),
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("expected metric %q", want)
+		}
+	}
+}
+
+func TestToolGroup_Weights(t *testing.T) {
+	var callsHeavy, callsIdle atomic.Int32
+	heavy := newStubAgent(t, "search-v1", func(w http.ResponseWriter, r *http.Request) {
+		callsHeavy.Add(1)
+	})
+	idle := newStubAgent(t, "search-v2", func(w http.ResponseWriter, r *http.Request) {
+		callsIdle.Add(1)
+	})
+	heavy.Labels = map[string]string{ToolGroupLabel: "search"}
+	idle.Labels = map[string]string{ToolGroupLabel: "search", ToolWeightLabel: "0"}
+	h := newTestHandler(heavy, idle)
+
+	for i := 0; i < 20; i++ {
+		callToolHTTP(t, h, "search", map[string]interface{}{"query": "q"})
+	}
+	if callsHeavy.Load() != 20 || callsIdle.Load() != 0 {
+		t.Errorf("expected all calls on the weighted agent, got %d and %d", callsHeavy.Load(), callsIdle.Load())
+	}
+}
+
+func listToolNamesHTTP(t *testing.T, h *Handler) []string {
+	t.Helper()
+	body, err := json.Marshal(Request{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.HandleHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body)))
+
+	var resp struct {
+		Result ListToolsResult `json:"result"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	var names []string
+	for _, tool := range resp.Result.Tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestToolsList_Cache(t *testing.T) {
+	first := newStubAgent(t, "alpha", func(w http.ResponseWriter, r *http.Request) {})
+	h := newTestHandler(first)
+
+	if got := listToolNamesHTTP(t, h); strings.Join(got, ",") != "alpha" {
+		t.Fatalf("expected [alpha], got %v", got)
+	}
+
+	// A new agent is not visible until the cache is invalidated
+	h.watcher.Store(newStubAgent(t, "beta", func(w http.ResponseWriter, r *http.Request) {}))
+	if got := listToolNamesHTTP(t, h); strings.Join(got, ",") != "alpha" {
+		t.Errorf("expected cached [alpha], got %v", got)
+	}
+
+	h.NotifyToolsListChanged()
+	if got := listToolNamesHTTP(t, h); strings.Join(got, ",") != "alpha,beta" {
+		t.Errorf("expected [alpha beta] after agent change, got %v", got)
+	}
+}
+
+func TestToolsList_SkipsInvalidToolNames(t *testing.T) {
+	valid := newStubAgent(t, "search", func(w http.ResponseWriter, r *http.Request) {})
+	valid.Spec.Tools = []k8s.AgentTool{
+		{Name: "query-docs"},
+		{Name: "find files"},
+		{Name: "lookup/v2"},
+		{Name: strings.Repeat("x", 64)},
+	}
+	dotted := newStubAgent(t, "legacy.v1", func(w http.ResponseWriter, r *http.Request) {})
+	h := newTestHandler(valid, dotted)
+
+	got := listToolNamesHTTP(t, h)
+
+	if strings.Join(got, ",") != "search_query-docs" {
+		t.Errorf("expected only [search_query-docs], got %v", got)
+	}
+}
+
+func TestToolCall_UnderscoreNamesResolveExactly(t *testing.T) {
+	answer := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprintf(w, `{"result":"%s"}`, name)
+		}
+	}
+	code := newStubAgent(t, "code", answer("code"))
+	code.Spec.Tools = []k8s.AgentTool{{Name: "review_pr"}, {Name: "lint_all_files"}}
+	codeReview := newStubAgent(t, "code-review", answer("code-review"))
+	codeReview.Spec.Tools = []k8s.AgentTool{{Name: "pr"}}
+	clash := newStubAgent(t, "code_review", answer("code_review"))
+	clash.Spec.Tools = []k8s.AgentTool{{Name: "pr"}}
+	h := newTestHandler(code, codeReview, clash)
+
+	got := listToolNamesHTTP(t, h)
+	want := "code_review_pr,code_lint_all_files,code-review_pr,code_review__pr"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("expected tools %s, got %v", want, got)
+	}
+
+	calls := map[string]toolRef{
+		"code_review_pr":      {prefix: "code", tool: "review_pr"},
+		"code_lint_all_files": {prefix: "code", tool: "lint_all_files"},
+		"code-review_pr":      {prefix: "code-review", tool: "pr"},
+		"code_review__pr":     {prefix: "code_review", tool: "pr"},
+	}
+	for name, want := range calls {
+		if prefix, tool := h.resolveToolName(name); prefix != want.prefix || tool != want.tool {
+			t.Errorf("%s: expected %+v, got %s/%s", name, want, prefix, tool)
+		}
+
+		resp := callToolHTTP(t, h, name, map[string]interface{}{"query": "go"})
+		if resp.Error != nil {
+			t.Errorf("%s: unexpected error: %+v", name, resp.Error)
+			continue
+		}
+		raw, _ := json.Marshal(resp.Result)
+		var result CallToolResult
+		if err := json.Unmarshal(raw, &result); err != nil || len(result.Content) != 1 {
+			t.Fatalf("%s: unexpected result %s", name, raw)
+		}
+		if result.Content[0].Text != want.prefix {
+			t.Errorf("%s: expected a call to %s, got %q", name, want.prefix, result.Content[0].Text)
+		}
+	}
+}
+
+func TestResolveToolName_FallsBackForUnlistedNames(t *testing.T) {
+	h := newTestHandler()
+	h.watcher.Store(&k8s.Agent{Name: "pending", Namespace: "default"})
+
+	if prefix, tool := h.resolveToolName("pending_do_work"); prefix != "pending" || tool != "do_work" {
+		t.Errorf("expected pending/do_work, got %s/%s", prefix, tool)
+	}
+
+	// An agent that became ready after the last tools/list is still found
+	h.watcher.Store(newStubAgent(t, "late", func(w http.ResponseWriter, r *http.Request) {}))
+	if prefix, tool := h.resolveToolName("late"); prefix != "late" || tool != "" {
+		t.Errorf("expected late with no tool, got %s/%s", prefix, tool)
+	}
+}
+
+func TestResolveToolName_UnknownNamesDoNotRebuild(t *testing.T) {
+	code := newStubAgent(t, "code", func(w http.ResponseWriter, r *http.Request) {})
+	code.Spec.Tools = []k8s.AgentTool{{Name: "review_pr"}}
+	h := newTestHandler(code)
+	h.SetToolsListCacheTTL(0)
+	listToolNamesHTTP(t, h)
+
+	// Listed as "code_review__pr" once built, as "code_review_pr" is taken
+	late := newStubAgent(t, "code_review", func(w http.ResponseWriter, r *http.Request) {})
+	late.Spec.Tools = []k8s.AgentTool{{Name: "pr"}}
+	h.watcher.Store(late)
+
+	// Without a change notification, a miss falls back instead of rebuilding
+	for i := 0; i < 3; i++ {
+		if prefix, tool := h.resolveToolName("code_review__pr"); prefix != "code" || tool != "review__pr" {
+			t.Fatalf("expected the fallback code/review__pr without a rebuild, got %s/%s", prefix, tool)
+		}
+	}
+
+	h.NotifyToolsListChanged()
+	if prefix, tool := h.resolveToolName("code_review__pr"); prefix != "code_review" || tool != "pr" {
+		t.Errorf("expected code_review/pr after the tools list changed, got %s/%s", prefix, tool)
+	}
+}
+
+func TestToolsList_Annotations(t *testing.T) {
+	readOnly, destructive := true, false
+	agent := newStubAgent(t, "files", func(w http.ResponseWriter, r *http.Request) {})
+	agent.Spec.Tools = []k8s.AgentTool{
+		{Name: "read", Annotations: &k8s.AgentToolAnnotations{ReadOnlyHint: &readOnly, DestructiveHint: &destructive}},
+		{Name: "write"},
+	}
+	h := newTestHandler(agent)
+
+	var result struct {
+		Tools []map[string]json.RawMessage `json:"tools"`
+	}
+	rpcHTTP(t, h, "tools/list", nil, &result)
+
+	if len(result.Tools) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(result.Tools))
+	}
+	if got := string(result.Tools[0]["annotations"]); got != `{"destructiveHint":false,"readOnlyHint":true}` {
+		t.Errorf("expected only the configured hints, got %s", got)
+	}
+	if _, ok := result.Tools[1]["annotations"]; ok {
+		t.Errorf("expected no annotations for an unannotated tool, got %s", result.Tools[1]["annotations"])
+	}
+}
+
+func TestValidToolName(t *testing.T) {
+	tests := map[string]bool{
+		"analyze_costs":         true,
+		"Agent-1":               true,
+		"":                      false,
+		"has space":             false,
+		"dotted.name":           false,
+		"slash/name":            false,
+		"emoji_\u2728":          false,
+		strings.Repeat("a", 64): true,
+		strings.Repeat("a", 65): false,
+	}
+	for name, want := range tests {
+		if got := validToolName(name); got != want {
+			t.Errorf("validToolName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestToolsList_CacheExpires(t *testing.T) {
+	h := newTestHandler(newStubAgent(t, "alpha", func(w http.ResponseWriter, r *http.Request) {}))
+	h.SetToolsListCacheTTL(20 * time.Millisecond)
+
+	listToolNamesHTTP(t, h)
+	h.watcher.Store(newStubAgent(t, "beta", func(w http.ResponseWriter, r *http.Request) {}))
+	time.Sleep(30 * time.Millisecond)
+
+	if got := listToolNamesHTTP(t, h); strings.Join(got, ",") != "alpha,beta" {
+		t.Errorf("expected [alpha beta] after the TTL, got %v", got)
+	}
+}
@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/k8s"
+)
+
+// promptName returns the name a prompt is listed under: prefixed like the
+// agent's tools, so prompts of different agents cannot collide.
+func promptName(agent *k8s.Agent, prompt k8s.AgentPrompt) string {
+	prefix := agent.Name
+	if group := agent.Labels[ToolGroupLabel]; group != "" {
+		prefix = group
+	}
+	return fmt.Sprintf("%s_%s", prefix, prompt.Name)
+}
+
+// buildPromptsList collects the prompts declared by ready agents. Agents in
+// a tool group share a prefix, so their prompts are listed once.
+func (h *Handler) buildPromptsList() ListPromptsResult {
+	agents := h.watcher.ListReady()
+	sortAgents(agents)
+
+	prompts := []Prompt{}
+	seen := make(map[string]bool)
+	for _, agent := range agents {
+		for _, p := range agent.Spec.Prompts {
+			name := promptName(agent, p)
+			if p.Name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			prompt := Prompt{Name: name, Description: p.Description}
+			for _, arg := range p.Arguments {
+				prompt.Arguments = append(prompt.Arguments, PromptArgument{
+					Name:        arg.Name,
+					Description: arg.Description,
+					Required:    arg.Required,
+				})
+			}
+			prompts = append(prompts, prompt)
+		}
+	}
+
+	return ListPromptsResult{Prompts: prompts}
+}
+
+// resolvePrompt returns the prompt listed under name, taken from the same
+// agent buildPromptsList listed it under.
+func (h *Handler) resolvePrompt(name string) (k8s.AgentPrompt, bool) {
+	agents := h.watcher.ListReady()
+	sortAgents(agents)
+
+	for _, agent := range agents {
+		for _, p := range agent.Spec.Prompts {
+			if p.Name != "" && promptName(agent, p) == name {
+				return p, true
+			}
+		}
+	}
+	return k8s.AgentPrompt{}, false
+}
+
+// getPrompt handles prompts/get for both transports, returning the
+// JSON-RPC error to send on failure.
+func (h *Handler) getPrompt(req *Request) (*GetPromptResult, *Error) {
+	paramsJSON, err := json.Marshal(req.Params)
+	if err != nil {
+		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Invalid params", Data: err.Error()}
+	}
+
+	var params GetPromptParams
+	if err := json.Unmarshal(paramsJSON, &params); err != nil {
+		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Invalid params", Data: err.Error()}
+	}
+	if params.Name == "" {
+		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Invalid params", Data: "name is required"}
+	}
+
+	prompt, found := h.resolvePrompt(params.Name)
+	if !found {
+		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Prompt not found", Data: params.Name}
+	}
+
+	text, err := renderPrompt(prompt, params.Arguments)
+	if err != nil {
+		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Invalid params", Data: err.Error()}
+	}
+
+	return &GetPromptResult{
+		Description: prompt.Description,
+		Messages: []PromptMessage{{
+			Role:    "user",
+			Content: Content{Type: "text", Text: text},
+		}},
+	}, nil
+}
+
+// renderPrompt substitutes args into the prompt's {{name}} placeholders.
+// Declared arguments that were not supplied render empty; missing required
+// arguments are an error.
+func renderPrompt(prompt k8s.AgentPrompt, args map[string]string) (string, error) {
+	var pairs []string
+	for _, arg := range prompt.Arguments {
+		value, ok := args[arg.Name]
+		if !ok && arg.Required {
+			return "", fmt.Errorf("missing required argument: %s", arg.Name)
+		}
+		pairs = append(pairs, "{{"+arg.Name+"}}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(prompt.Template), nil
+}
+
+func (h *Handler) handleListPrompts(sess *session, req *Request) {
+	h.sendResult(sess, req.ID, h.buildPromptsList())
+}
+
+func (h *Handler) handleGetPrompt(sess *session, req *Request) {
+	result, rpcErr := h.getPrompt(req)
+	if rpcErr != nil {
+		h.sendSSEMessage(sess, Response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr})
+		return
+	}
+	h.sendResult(sess, req.ID, result)
+}
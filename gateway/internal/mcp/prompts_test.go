@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/k8s"
+)
+
+func incidentAgent() *k8s.Agent {
+	return &k8s.Agent{
+		Name:      "ops",
+		Namespace: "default",
+		Spec: k8s.AgentSpec{Prompts: []k8s.AgentPrompt{{
+			Name:        "incident",
+			Description: "Summarize an incident",
+			Template:    "Summarize the {{severity}} incident affecting {{service}}.{{extra}}",
+			Arguments: []k8s.AgentPromptArgument{
+				{Name: "service", Description: "Affected service", Required: true},
+				{Name: "severity"},
+				{Name: "extra"},
+			},
+		}}},
+		Status: k8s.AgentStatus{Ready: true, Endpoint: "ops:8080"},
+	}
+}
+
+func TestInitialize_AdvertisesPrompts(t *testing.T) {
+	h := newTestHandler()
+
+	var result InitializeResult
+	rpcHTTP(t, h, "initialize", InitializeParams{ProtocolVersion: latestProtocolVersion}, &result)
+
+	if result.Capabilities.Prompts == nil || !result.Capabilities.Prompts.ListChanged {
+		t.Errorf("expected prompts capability with listChanged, got %+v", result.Capabilities.Prompts)
+	}
+}
+
+func TestPromptsList(t *testing.T) {
+	notReady := incidentAgent()
+	notReady.Name = "pending"
+	notReady.Status = k8s.AgentStatus{}
+	h := newTestHandler(incidentAgent(), notReady)
+
+	var result ListPromptsResult
+	resp := rpcHTTP(t, h, "prompts/list", nil, &result)
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+
+	want := []Prompt{{
+		Name:        "ops_incident",
+		Description: "Summarize an incident",
+		Arguments: []PromptArgument{
+			{Name: "service", Description: "Affected service", Required: true},
+			{Name: "severity"},
+			{Name: "extra"},
+		},
+	}}
+	if !reflect.DeepEqual(result.Prompts, want) {
+		t.Errorf("expected %+v, got %+v", want, result.Prompts)
+	}
+}
+
+func TestPromptsGet(t *testing.T) {
+	h := newTestHandler(incidentAgent())
+
+	var result GetPromptResult
+	resp := rpcHTTP(t, h, "prompts/get", GetPromptParams{
+		Name:      "ops_incident",
+		Arguments: map[string]string{"service": "checkout", "severity": "SEV2", "ignored": "x"},
+	}, &result)
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+
+	want := GetPromptResult{
+		Description: "Summarize an incident",
+		Messages: []PromptMessage{{
+			Role:    "user",
+			Content: Content{Type: "text", Text: "Summarize the SEV2 incident affecting checkout."},
+		}},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("expected %+v, got %+v", want, result)
+	}
+}
+
+func TestPromptsGet_Errors(t *testing.T) {
+	h := newTestHandler(incidentAgent())
+
+	tests := []struct {
+		name     string
+		params   GetPromptParams
+		wantData string
+	}{
+		{name: "unknown prompt", params: GetPromptParams{Name: "ops_missing"}, wantData: "ops_missing"},
+		{name: "missing required argument", params: GetPromptParams{Name: "ops_incident"}, wantData: "missing required argument: service"},
+		{name: "missing name", params: GetPromptParams{}, wantData: "name is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := rpcHTTP(t, h, "prompts/get", tt.params, nil)
+			if resp.Error == nil {
+				t.Fatalf("expected error, got result %v", resp.Result)
+			}
+			if resp.Error.Code != ErrCodeInvalidParams {
+				t.Errorf("expected code %d, got %d", ErrCodeInvalidParams, resp.Error.Code)
+			}
+			if resp.Error.Data != tt.wantData {
+				t.Errorf("expected data %q, got %v", tt.wantData, resp.Error.Data)
+			}
+		})
+	}
+}
+
+func TestNotifyPromptsListChanged(t *testing.T) {
+	h := newTestHandler()
+	rec := httptest.NewRecorder()
+	h.sessions.Store(uint64(1), &session{id: 1, initialized: true, writer: rec, flusher: rec, done: make(chan struct{})})
+
+	h.NotifyPromptsListChanged()
+
+	if !strings.Contains(rec.Body.String(), "notifications/prompts/list_changed") {
+		t.Errorf("expected prompts list_changed notification, got %q", rec.Body.String())
+	}
+}
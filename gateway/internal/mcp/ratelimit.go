@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/k8s"
+	"github.com/jarsater/mcp-fabric/gateway/internal/metrics"
+)
+
+// tokenBucket allows bursts of up to capacity calls and refills
+// continuously at rate tokens per second.
+type tokenBucket struct {
+	capacity float64
+	rate     float64
+	tokens   float64
+	last     time.Time
+}
+
+// newTokenBucket returns a full bucket allowing perMinute calls a minute.
+func newTokenBucket(perMinute int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		tokens:   float64(perMinute),
+		last:     now,
+	}
+}
+
+// take consumes a token if one is available. Otherwise it reports how long
+// until the next token is added.
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.capacity, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	return false, wait
+}
+
+// callLimiter enforces each agent's policy.maxCallsPerMinute on tools/call,
+// with one bucket per agent shared by all clients.
+type callLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+func newCallLimiter() *callLimiter {
+	return &callLimiter{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow reports whether a call to agent may be forwarded now and, if not,
+// how long the caller should wait before retrying. A changed limit starts
+// a fresh bucket.
+func (l *callLimiter) allow(agent *k8s.Agent) (bool, time.Duration) {
+	key := agent.Namespace + "/" + agent.Name
+	limit := agent.Spec.MaxCallsPerMinute
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limit <= 0 {
+		delete(l.buckets, key)
+		return true, 0
+	}
+	now := l.now()
+	bucket, ok := l.buckets[key]
+	if !ok || bucket.capacity != float64(limit) {
+		bucket = newTokenBucket(limit, now)
+		l.buckets[key] = bucket
+	}
+	return bucket.take(now)
+}
+
+// checkCallRate takes a call from the agent's rate limit bucket, returning
+// a callRateLimitedError if it is empty.
+func (h *Handler) checkCallRate(agent *k8s.Agent) error {
+	ok, retryAfter := h.callLimits.allow(agent)
+	if ok {
+		return nil
+	}
+	metrics.RecordMCPRateLimited(agent.Name)
+	h.logger.Debugf("[MCP] Rate limited call to %s, retry after %s", agent.Name, retryAfter)
+	return &callRateLimitedError{agent: agent.Name, limit: agent.Spec.MaxCallsPerMinute, retryAfter: retryAfter}
+}
+
+// callRateLimitedError is returned when a tools/call exceeds the agent's
+// policy.maxCallsPerMinute and is not forwarded.
+type callRateLimitedError struct {
+	agent      string
+	limit      int
+	retryAfter time.Duration
+}
+
+func (e *callRateLimitedError) Error() string {
+	return fmt.Sprintf("agent %s is limited to %d calls per minute, retry after %s", e.agent, e.limit, e.retryAfter)
+}
+
+// rpcError converts the rejection into a JSON-RPC error telling the client
+// when to retry.
+func (e *callRateLimitedError) rpcError() *Error {
+	return &Error{
+		Code:    ErrCodeInternal,
+		Message: "rate limit exceeded",
+		Data: map[string]interface{}{
+			"agent":             e.agent,
+			"maxCallsPerMinute": e.limit,
+			"retryAfter":        e.retryAfter.String(),
+			"retryAfterMs":      e.retryAfter.Milliseconds(),
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/k8s"
+)
+
+func TestTokenBucket_Refill(t *testing.T) {
+	start := time.Unix(0, 0)
+	b := newTokenBucket(60, start) // one token a second, bursts of 60
+
+	for i := 0; i < 60; i++ {
+		if ok, _ := b.take(start); !ok {
+			t.Fatalf("expected call %d of the burst to be allowed", i+1)
+		}
+	}
+	ok, wait := b.take(start)
+	if ok || wait != time.Second {
+		t.Fatalf("expected an empty bucket with a 1s wait, got %v %v", ok, wait)
+	}
+
+	// Half a token has refilled after 500ms
+	if ok, wait := b.take(start.Add(500 * time.Millisecond)); ok || wait != 500*time.Millisecond {
+		t.Errorf("expected a 500ms wait, got %v %v", ok, wait)
+	}
+	if ok, _ := b.take(start.Add(time.Second)); !ok {
+		t.Error("expected a token after 1s")
+	}
+
+	// Refill never exceeds the capacity
+	later := start.Add(time.Hour)
+	allowed := 0
+	for {
+		if ok, _ := b.take(later); !ok {
+			break
+		}
+		allowed++
+	}
+	if allowed != 60 {
+		t.Errorf("expected the refill to cap at 60 tokens, got %d", allowed)
+	}
+}
+
+func TestCallLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newCallLimiter()
+	l.now = func() time.Time { return now }
+	agent := &k8s.Agent{Name: "busy", Namespace: "default"}
+
+	// No limit configured
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.allow(agent); !ok {
+			t.Fatal("expected unlimited calls without maxCallsPerMinute")
+		}
+	}
+
+	agent.Spec.MaxCallsPerMinute = 2
+	l.allow(agent)
+	l.allow(agent)
+	if ok, wait := l.allow(agent); ok || wait != 30*time.Second {
+		t.Fatalf("expected the third call to wait 30s, got %v %v", ok, wait)
+	}
+
+	// Buckets are per agent
+	other := &k8s.Agent{Name: "other", Namespace: "default", Spec: k8s.AgentSpec{MaxCallsPerMinute: 2}}
+	if ok, _ := l.allow(other); !ok {
+		t.Error("expected another agent to have its own bucket")
+	}
+
+	// A changed limit starts a fresh bucket
+	agent.Spec.MaxCallsPerMinute = 10
+	if ok, _ := l.allow(agent); !ok {
+		t.Error("expected a raised limit to allow calls again")
+	}
+}
+
+func TestToolCall_RateLimited(t *testing.T) {
+	calls := 0
+	agent := newStubAgent(t, "busy", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"result":"ok"}`))
+	})
+	agent.Spec.MaxCallsPerMinute = 1
+	h := newTestHandler(agent)
+
+	if resp := callToolHTTP(t, h, "busy", map[string]interface{}{"query": "one"}); resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	resp := callToolHTTP(t, h, "busy", map[string]interface{}{"query": "two"})
+
+	if resp.Error == nil || resp.Error.Code != ErrCodeInternal || resp.Error.Message != "rate limit exceeded" {
+		t.Fatalf("expected a rate limit error, got %+v", resp.Error)
+	}
+	raw, _ := json.Marshal(resp.Error.Data)
+	var data struct {
+		Agent        string `json:"agent"`
+		RetryAfterMs int64  `json:"retryAfterMs"`
+	}
+	if err := json.Unmarshal(raw, &data); err != nil || data.Agent != "busy" || data.RetryAfterMs <= 0 {
+		t.Errorf("expected a retry hint for busy, got %s", raw)
+	}
+	if calls != 1 {
+		t.Errorf("expected the limited call not to reach the agent, got %d calls", calls)
+	}
+}
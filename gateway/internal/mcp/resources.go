@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/k8s"
+	"github.com/jarsater/mcp-fabric/gateway/internal/metrics"
+	"github.com/jarsater/mcp-fabric/gateway/internal/routes"
+)
+
+// resourceNotFoundError is returned when no ready agent serves a URI.
+type resourceNotFoundError struct {
+	uri string
+}
+
+func (e *resourceNotFoundError) Error() string {
+	return fmt.Sprintf("resource not found: %s", e.uri)
+}
+
+// rpcError converts the lookup failure into a JSON-RPC error.
+func (e *resourceNotFoundError) rpcError() *Error {
+	return &Error{
+		Code:    ErrCodeResourceNotFound,
+		Message: "Resource not found",
+		Data:    map[string]interface{}{"uri": e.uri},
+	}
+}
+
+// buildResourcesList collects the resources declared by ready agents. A URI
+// declared by several agents is listed once.
+func (h *Handler) buildResourcesList() ListResourcesResult {
+	agents := h.watcher.ListReady()
+	sortAgents(agents)
+
+	resources := []Resource{}
+	seen := make(map[string]bool)
+	for _, agent := range agents {
+		for _, r := range agent.Spec.Resources {
+			if r.URI == "" || seen[r.URI] {
+				continue
+			}
+			seen[r.URI] = true
+			resources = append(resources, Resource{
+				URI:         r.URI,
+				Name:        r.Name,
+				Description: r.Description,
+				MimeType:    r.MimeType,
+			})
+		}
+	}
+
+	return ListResourcesResult{Resources: resources}
+}
+
+// resolveResource returns the ready agent serving a URI, picking the same
+// agent buildResourcesList listed it under.
+func (h *Handler) resolveResource(uri string) (*k8s.Agent, k8s.AgentResource, bool) {
+	agents := h.watcher.ListReady()
+	sortAgents(agents)
+
+	for _, agent := range agents {
+		for _, r := range agent.Spec.Resources {
+			if r.URI == uri {
+				return agent, r, true
+			}
+		}
+	}
+	return nil, k8s.AgentResource{}, false
+}
+
+// readResource handles resources/read for both transports, returning the
+// JSON-RPC error to send on failure.
+func (h *Handler) readResource(ctx context.Context, req *Request, header http.Header) (*ReadResourceResult, *Error) {
+	paramsJSON, err := json.Marshal(req.Params)
+	if err != nil {
+		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Invalid params", Data: err.Error()}
+	}
+
+	var params ReadResourceParams
+	if err := json.Unmarshal(paramsJSON, &params); err != nil {
+		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Invalid params", Data: err.Error()}
+	}
+	if params.URI == "" {
+		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Invalid params", Data: "uri is required"}
+	}
+
+	h.logger.Debugf("[MCP] Resource read: %s", params.URI)
+
+	result, err := h.fetchResource(ctx, params.URI, header)
+	if err != nil {
+		h.logger.Warnf("[MCP] Resource read %s failed: %v", params.URI, err)
+		var notFound *resourceNotFoundError
+		var timeoutErr *agentTimeoutError
+		switch {
+		case errors.As(err, &notFound):
+			return nil, notFound.rpcError()
+		case errors.As(err, &timeoutErr):
+			return nil, timeoutErr.rpcError()
+		default:
+			return nil, &Error{Code: ErrCodeInternal, Message: err.Error()}
+		}
+	}
+	return result, nil
+}
+
+// fetchResource reads a resource from the agent declaring it by POSTing
+// {"uri": ...} to the agent's /resources/read endpoint.
+func (h *Handler) fetchResource(ctx context.Context, uri string, header http.Header) (*ReadResourceResult, error) {
+	agent, resource, found := h.resolveResource(uri)
+	if !found {
+		return nil, &resourceNotFoundError{uri: uri}
+	}
+
+	body, err := json.Marshal(map[string]string{"uri": uri})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel, timeout := withAgentTimeout(ctx, h.timeoutFor(agent))
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, agentURL(agent, "/resources/read"), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	h.forwardHeaders.Copy(httpReq.Header, header)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	defer metrics.TrackAgentInflight(agent.Name)()
+	resp, err := h.httpClient.Do(httpReq)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, &agentTimeoutError{agent: agent.Name, timeout: timeout}
+		}
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := routes.ReadLimited(resp.Body, h.maxRespLen)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, &agentTimeoutError{agent: agent.Name, timeout: timeout}
+		}
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, &resourceNotFoundError{uri: uri}
+	case resp.StatusCode >= 400:
+		return nil, fmt.Errorf("agent returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return resourceContents(resource, respBody), nil
+}
+
+// resourceContents converts an agent's resources/read response into MCP
+// resource contents. A response carrying a "contents" array is passed
+// through; anything else becomes the resource's text.
+func resourceContents(resource k8s.AgentResource, respBody []byte) *ReadResourceResult {
+	var envelope struct {
+		Contents []ResourceContents `json:"contents"`
+	}
+	if err := json.Unmarshal(respBody, &envelope); err == nil && len(envelope.Contents) > 0 {
+		for i := range envelope.Contents {
+			if envelope.Contents[i].URI == "" {
+				envelope.Contents[i].URI = resource.URI
+			}
+		}
+		return &ReadResourceResult{Contents: envelope.Contents}
+	}
+
+	return &ReadResourceResult{
+		Contents: []ResourceContents{{
+			URI:      resource.URI,
+			MimeType: resource.MimeType,
+			Text:     string(respBody),
+		}},
+	}
+}
+
+func (h *Handler) handleListResources(sess *session, req *Request) {
+	h.sendResult(sess, req.ID, h.buildResourcesList())
+}
+
+func (h *Handler) handleReadResource(ctx context.Context, sess *session, req *Request, header http.Header) {
+	result, rpcErr := h.readResource(ctx, req, header)
+	if rpcErr != nil {
+		h.sendSSEMessage(sess, Response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr})
+		return
+	}
+	h.sendResult(sess, req.ID, result)
+}
@@ -0,0 +1,164 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/k8s"
+)
+
+// rpcHTTP sends a JSON-RPC request over the HTTP transport and decodes the
+// response, re-encoding the result into out when given.
+func rpcHTTP(t *testing.T, h *Handler, method string, params interface{}, out interface{}) Response {
+	t.Helper()
+	body, err := json.Marshal(Request{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.HandleHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body)))
+
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	if out != nil && resp.Result != nil {
+		data, _ := json.Marshal(resp.Result)
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("failed to decode result: %v", err)
+		}
+	}
+	return resp
+}
+
+func TestInitialize_AdvertisesResources(t *testing.T) {
+	h := newTestHandler()
+
+	var result InitializeResult
+	rpcHTTP(t, h, "initialize", InitializeParams{ProtocolVersion: latestProtocolVersion}, &result)
+
+	if result.Capabilities.Resources == nil {
+		t.Error("expected resources capability")
+	}
+	if result.Capabilities.Tools == nil {
+		t.Error("expected tools capability")
+	}
+}
+
+func TestResourcesList(t *testing.T) {
+	docs := &k8s.Agent{
+		Name:      "docs",
+		Namespace: "default",
+		Spec: k8s.AgentSpec{Resources: []k8s.AgentResource{
+			{URI: "docs://runbook.md", Name: "Runbook", MimeType: "text/markdown"},
+			{URI: "docs://shared.csv", Name: "Shared", MimeType: "text/csv"},
+		}},
+		Status: k8s.AgentStatus{Ready: true, Endpoint: "docs:8080"},
+	}
+	data := &k8s.Agent{
+		Name:      "data",
+		Namespace: "other",
+		Spec: k8s.AgentSpec{Resources: []k8s.AgentResource{
+			{URI: "docs://shared.csv", Name: "Duplicate"},
+		}},
+		Status: k8s.AgentStatus{Ready: true, Endpoint: "data:8080"},
+	}
+	notReady := &k8s.Agent{
+		Name:      "pending",
+		Namespace: "default",
+		Spec: k8s.AgentSpec{Resources: []k8s.AgentResource{
+			{URI: "docs://pending.md", Name: "Pending"},
+		}},
+	}
+	h := newTestHandler(docs, data, notReady)
+
+	var result ListResourcesResult
+	resp := rpcHTTP(t, h, "resources/list", nil, &result)
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+
+	want := []Resource{
+		{URI: "docs://runbook.md", Name: "Runbook", MimeType: "text/markdown"},
+		{URI: "docs://shared.csv", Name: "Shared", MimeType: "text/csv"},
+	}
+	if !reflect.DeepEqual(result.Resources, want) {
+		t.Errorf("expected %+v, got %+v", want, result.Resources)
+	}
+}
+
+func TestResourcesRead(t *testing.T) {
+	var gotPath, gotBody string
+	agent := newStubAgent(t, "docs", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotBody = r.URL.Path, string(body)
+		_, _ = w.Write([]byte("# Runbook"))
+	})
+	agent.Spec.Resources = []k8s.AgentResource{{URI: "docs://runbook.md", Name: "Runbook", MimeType: "text/markdown"}}
+	h := newTestHandler(agent)
+
+	var result ReadResourceResult
+	resp := rpcHTTP(t, h, "resources/read", ReadResourceParams{URI: "docs://runbook.md"}, &result)
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+
+	if gotPath != "/resources/read" || gotBody != `{"uri":"docs://runbook.md"}` {
+		t.Errorf("unexpected agent request %s %s", gotPath, gotBody)
+	}
+	want := []ResourceContents{{URI: "docs://runbook.md", MimeType: "text/markdown", Text: "# Runbook"}}
+	if !reflect.DeepEqual(result.Contents, want) {
+		t.Errorf("expected %+v, got %+v", want, result.Contents)
+	}
+}
+
+func TestResourcesRead_PassesThroughContents(t *testing.T) {
+	agent := newStubAgent(t, "data", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"contents":[{"mimeType":"application/octet-stream","blob":"AAEC"}]}`))
+	})
+	agent.Spec.Resources = []k8s.AgentResource{{URI: "data://sample.bin", Name: "Sample"}}
+	h := newTestHandler(agent)
+
+	var result ReadResourceResult
+	rpcHTTP(t, h, "resources/read", ReadResourceParams{URI: "data://sample.bin"}, &result)
+
+	want := []ResourceContents{{URI: "data://sample.bin", MimeType: "application/octet-stream", Blob: "AAEC"}}
+	if !reflect.DeepEqual(result.Contents, want) {
+		t.Errorf("expected %+v, got %+v", want, result.Contents)
+	}
+}
+
+func TestResourcesRead_Errors(t *testing.T) {
+	agent := newStubAgent(t, "docs", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	agent.Spec.Resources = []k8s.AgentResource{{URI: "docs://gone.md", Name: "Gone"}}
+	h := newTestHandler(agent)
+
+	tests := []struct {
+		name     string
+		params   interface{}
+		wantCode int
+	}{
+		{name: "unknown uri", params: ReadResourceParams{URI: "docs://missing.md"}, wantCode: ErrCodeResourceNotFound},
+		{name: "agent reports not found", params: ReadResourceParams{URI: "docs://gone.md"}, wantCode: ErrCodeResourceNotFound},
+		{name: "missing uri", params: map[string]interface{}{}, wantCode: ErrCodeInvalidParams},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := rpcHTTP(t, h, "resources/read", tt.params, nil)
+			if resp.Error == nil {
+				t.Fatalf("expected error, got result %v", resp.Result)
+			}
+			if resp.Error.Code != tt.wantCode {
+				t.Errorf("expected code %d, got %d (%s)", tt.wantCode, resp.Error.Code, resp.Error.Message)
+			}
+		})
+	}
+}
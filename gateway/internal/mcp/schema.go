@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/k8s"
+)
+
+// invalidArgumentsError reports tool-call arguments that do not match the
+// tool's declared input schema.
+type invalidArgumentsError struct {
+	tool     string
+	problems []string
+}
+
+func (e *invalidArgumentsError) Error() string {
+	return fmt.Sprintf("invalid arguments for %s: %s", e.tool, strings.Join(e.problems, "; "))
+}
+
+func (e *invalidArgumentsError) rpcError() *Error {
+	return &Error{Code: ErrCodeInvalidParams, Message: "Invalid params", Data: e.problems}
+}
+
+// validateToolArguments checks args against the input schema the agent
+// declares for toolName, reporting a failure as an invalidArgumentsError
+// for the tool called name. Tools without a schema accept any arguments.
+func validateToolArguments(agent *k8s.Agent, name, toolName string, args map[string]interface{}) error {
+	for _, tool := range agentTools(agent) {
+		if tool.Name != toolName || tool.InputSchema == nil {
+			continue
+		}
+		if args == nil {
+			args = map[string]interface{}{}
+		}
+		if problems := validateArguments(tool.InputSchema, args); len(problems) > 0 {
+			return &invalidArgumentsError{tool: name, problems: problems}
+		}
+		return nil
+	}
+	return nil
+}
+
+// validateArguments checks args against a JSON Schema and returns the
+// problems found. It covers what tool input schemas use in practice: type,
+// required, properties and items. Other keywords are not enforced.
+func validateArguments(schema map[string]interface{}, args map[string]interface{}) []string {
+	var problems []string
+	validateValue(schema, args, "", &problems)
+	return problems
+}
+
+func validateValue(schema map[string]interface{}, value interface{}, path string, problems *[]string) {
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !matchesType(value, types) {
+		*problems = append(*problems, fmt.Sprintf("property %q: expected %s, got %s", pathOrRoot(path), strings.Join(types, " or "), jsonType(value)))
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, r := range required {
+				if name, ok := r.(string); ok {
+					if _, present := v[name]; !present {
+						*problems = append(*problems, fmt.Sprintf("missing required property %q", joinPath(path, name)))
+					}
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sub, ok := properties[name].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if propValue, present := v[name]; present {
+				validateValue(sub, propValue, joinPath(path, name), problems)
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				validateValue(items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	}
+}
+
+// schemaTypes returns the types a schema's "type" keyword allows, given as
+// a string or a list of strings.
+func schemaTypes(t interface{}) []string {
+	switch t := t.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var types []string
+		for _, name := range t {
+			if s, ok := name.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func matchesType(value interface{}, types []string) bool {
+	actual := jsonType(value)
+	for _, t := range types {
+		switch {
+		case t == actual:
+			return true
+		case t == "integer" && actual == "number":
+			if f, ok := value.(float64); ok && f == math.Trunc(f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type name of a value decoded by
+// encoding/json.
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "arguments"
+	}
+	return path
+}
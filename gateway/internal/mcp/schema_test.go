@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/k8s"
+)
+
+var lookupSchema = map[string]interface{}{
+	"type":     "object",
+	"required": []interface{}{"query", "limit"},
+	"properties": map[string]interface{}{
+		"query": map[string]interface{}{"type": "string"},
+		"limit": map[string]interface{}{"type": "integer"},
+		"tags": map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		},
+		"filter": map[string]interface{}{
+			"type":       "object",
+			"required":   []interface{}{"field"},
+			"properties": map[string]interface{}{"exact": map[string]interface{}{"type": []interface{}{"boolean", "null"}}},
+		},
+	},
+}
+
+func TestValidateArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want []string
+	}{
+		{
+			name: "valid",
+			args: map[string]interface{}{"query": "q", "limit": float64(5), "tags": []interface{}{"a"}, "filter": map[string]interface{}{"field": "x", "exact": nil}},
+		},
+		{
+			name: "missing required",
+			args: map[string]interface{}{"query": "q"},
+			want: []string{`missing required property "limit"`},
+		},
+		{
+			name: "type mismatches",
+			args: map[string]interface{}{"query": float64(1), "limit": 2.5, "tags": []interface{}{"a", true}},
+			want: []string{
+				`property "limit": expected integer, got number`,
+				`property "query": expected string, got number`,
+				`property "tags[1]": expected string, got boolean`,
+			},
+		},
+		{
+			name: "nested object",
+			args: map[string]interface{}{"query": "q", "limit": float64(1), "filter": map[string]interface{}{"exact": "yes"}},
+			want: []string{
+				`missing required property "filter.field"`,
+				`property "filter.exact": expected boolean or null, got string`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateArguments(lookupSchema, tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHandleHTTP_InvalidArgumentsNotForwarded(t *testing.T) {
+	var calls atomic.Int32
+	agent := newStubAgent(t, "search", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`{"result":"ok"}`))
+	})
+	agent.Spec.Tools = []k8s.AgentTool{
+		{Name: "lookup", InputSchema: lookupSchema},
+		{Name: "free"},
+	}
+	h := newTestHandler(agent)
+
+	resp := callToolHTTP(t, h, "search_lookup", map[string]interface{}{"limit": "ten"})
+	if resp.Error == nil || resp.Error.Code != ErrCodeInvalidParams {
+		t.Fatalf("expected invalid params error, got %+v", resp)
+	}
+	want := []interface{}{`missing required property "query"`, `property "limit": expected integer, got string`}
+	if !reflect.DeepEqual(resp.Error.Data, want) {
+		t.Errorf("expected problems %q, got %v", want, resp.Error.Data)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("expected no agent call, got %d", n)
+	}
+
+	// Tools without a schema are forwarded as before
+	if resp := callToolHTTP(t, h, "search_free", map[string]interface{}{"anything": true}); resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected one agent call, got %d", n)
+	}
+}
@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/jarsater/mcp-fabric/gateway/internal/metrics"
+)
+
+// DefaultSessionIdleTimeout is how long an SSE session may go without
+// activity before it is reaped. Both client messages and events written to
+// the stream, keep-alive pings included, count, so open streams are kept.
+const DefaultSessionIdleTimeout = 30 * time.Minute
+
+// DefaultSSEKeepAliveInterval is how often an SSE stream gets a ping event
+// unless configured otherwise.
+const DefaultSSEKeepAliveInterval = 30 * time.Second
+
+// touch records activity on the session: a client message or an event
+// written to its stream.
+func (s *session) touch() {
+	s.lastActive.Store(time.Now().UnixNano())
+}
+
+// idleSince returns when the session was last active.
+func (s *session) idleSince() time.Time {
+	return time.Unix(0, s.lastActive.Load())
+}
+
+// SetSessionIdleTimeout sets how long an SSE session may go without
+// activity before RunSessionJanitor reaps it. Zero disables reaping.
+func (h *Handler) SetSessionIdleTimeout(timeout time.Duration) {
+	h.sessionIdle = timeout
+}
+
+// SetSSEKeepAliveInterval sets how often open SSE streams get a ping event.
+// Keep it below the idle timeout of any proxy in front of the gateway.
+// A non-positive interval restores the default.
+func (h *Handler) SetSSEKeepAliveInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultSSEKeepAliveInterval
+	}
+	h.keepAlive = interval
+}
+
+// RunSessionJanitor reaps idle SSE sessions until ctx is done. A client
+// that vanished without closing the stream is usually noticed by a failed
+// keep-alive write; reaping catches sessions whose writes never fail. It
+// returns at once if reaping is disabled.
+func (h *Handler) RunSessionJanitor(ctx context.Context) {
+	if h.sessionIdle <= 0 {
+		return
+	}
+	ticker := time.NewTicker(h.sessionIdle / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			h.reapIdleSessions(now)
+		}
+	}
+}
+
+// reapIdleSessions ends every session idle for at least the idle timeout
+// and returns how many it ended.
+func (h *Handler) reapIdleSessions(now time.Time) int {
+	reaped := 0
+	h.sessions.Range(func(_, value interface{}) bool {
+		sess := value.(*session)
+		if now.Sub(sess.idleSince()) >= h.sessionIdle {
+			h.endSession(sess, "idle timeout")
+			reaped++
+		}
+		return true
+	})
+	return reaped
+}
+
+// endSession removes a session and stops its stream. Only the first call
+// for a session has any effect.
+func (h *Handler) endSession(sess *session, reason string) {
+	sess.end.Do(func() {
+		h.sessions.Delete(sess.id)
+		activeCount := h.sseConnections.Add(-1)
+		metrics.SetMCPConnectionsActive("sse", int(activeCount))
+		sess.mu.Lock()
+		close(sess.done)
+		sess.mu.Unlock()
+		h.logger.Infof("MCP SSE session ended: %d (%s)", sess.id, reason)
+	})
+}
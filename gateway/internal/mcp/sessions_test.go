@@ -0,0 +1,164 @@
+package mcp
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openSSE connects to the handler's SSE endpoint and returns a channel of
+// the event names it streams, closed when the stream ends.
+func openSSE(t *testing.T, h *Handler) <-chan string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(h.HandleSSE))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("failed to open SSE stream: %v", err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	events := make(chan string, 100)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+				events <- name
+			}
+		}
+	}()
+	return events
+}
+
+func TestHandleSSE_KeepsAliveAcrossTicks(t *testing.T) {
+	h := newTestHandler()
+	// With the 30s default no ping would arrive before the test times out
+	h.SetSSEKeepAliveInterval(5 * time.Millisecond)
+	events := openSSE(t, h)
+
+	pings := 0
+	for pings < 3 {
+		select {
+		case name, ok := <-events:
+			if !ok {
+				t.Fatalf("stream closed after %d pings", pings)
+			}
+			if name == "ping" {
+				pings++
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out after %d pings", pings)
+		}
+	}
+}
+
+func TestSetSSEKeepAliveInterval(t *testing.T) {
+	h := newTestHandler()
+	if h.keepAlive != DefaultSSEKeepAliveInterval {
+		t.Errorf("expected default interval %v, got %v", DefaultSSEKeepAliveInterval, h.keepAlive)
+	}
+
+	h.SetSSEKeepAliveInterval(10 * time.Second)
+	if h.keepAlive != 10*time.Second {
+		t.Errorf("expected 10s, got %v", h.keepAlive)
+	}
+
+	h.SetSSEKeepAliveInterval(0)
+	if h.keepAlive != DefaultSSEKeepAliveInterval {
+		t.Errorf("expected zero to restore the default, got %v", h.keepAlive)
+	}
+}
+
+func TestReapIdleSessions(t *testing.T) {
+	h := newTestHandler()
+	h.SetSessionIdleTimeout(time.Minute)
+	events := openSSE(t, h)
+	if name := <-events; name != "endpoint" {
+		t.Fatalf("expected endpoint event, got %q", name)
+	}
+
+	if n := h.reapIdleSessions(time.Now()); n != 0 {
+		t.Errorf("expected no sessions reaped while active, got %d", n)
+	}
+	if n := h.reapIdleSessions(time.Now().Add(2 * time.Minute)); n != 1 {
+		t.Fatalf("expected one idle session reaped, got %d", n)
+	}
+
+	deadline := time.After(time.Second)
+	for open := true; open; {
+		select {
+		case _, open = <-events:
+		case <-deadline:
+			t.Fatal("expected the reaped session's stream to close")
+		}
+	}
+	h.sessions.Range(func(key, _ interface{}) bool {
+		t.Errorf("expected no sessions, found %v", key)
+		return true
+	})
+	if n := h.sseConnections.Load(); n != 0 {
+		t.Errorf("expected no active SSE connections, got %d", n)
+	}
+}
+
+func TestReapIdleSessions_KeepsOpenListenOnlyStream(t *testing.T) {
+	h := newTestHandler()
+	h.SetSessionIdleTimeout(50 * time.Millisecond)
+	h.SetSSEKeepAliveInterval(5 * time.Millisecond)
+	events := openSSE(t, h)
+
+	// The client never posts a message, but keeps reading pings well past
+	// the idle timeout
+	deadline := time.After(150 * time.Millisecond)
+	for waiting := true; waiting; {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				t.Fatal("stream closed while the client was reading it")
+			}
+		case <-deadline:
+			waiting = false
+		}
+	}
+
+	if n := h.reapIdleSessions(time.Now()); n != 0 {
+		t.Errorf("expected an open stream not to be reaped, got %d reaped", n)
+	}
+}
+
+// failingWriter is an SSE stream whose client has gone away.
+type failingWriter struct {
+	httptest.ResponseRecorder
+}
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestSendSSEEvent_FailedWriteEndsSession(t *testing.T) {
+	h := newTestHandler()
+	w := &failingWriter{}
+	sess := &session{id: 3, writer: w, flusher: w, done: make(chan struct{})}
+	h.sessions.Store(sess.id, sess)
+	h.sseConnections.Add(1)
+
+	h.sendSSEEvent(sess, "ping", "")
+
+	select {
+	case <-sess.done:
+	default:
+		t.Fatal("expected a failed write to end the session")
+	}
+	if _, ok := h.sessions.Load(sess.id); ok {
+		t.Error("expected the session removed")
+	}
+	if n := h.sseConnections.Load(); n != 0 {
+		t.Errorf("expected no active SSE connections, got %d", n)
+	}
+}
@@ -1,5 +1,12 @@
 package mcp
 
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
 // JSON-RPC 2.0 types for MCP protocol
 
 // Request represents a JSON-RPC 2.0 request.
@@ -10,10 +17,43 @@ type Request struct {
 	Params  interface{} `json:"params,omitempty"`
 }
 
-// Response represents a JSON-RPC 2.0 response.
+// UnmarshalJSON decodes a request, keeping a numeric ID as a json.Number
+// and the params as raw JSON. Both are then echoed back exactly as sent;
+// decoding numbers as float64 would mangle integer IDs above 2^53.
+func (r *Request) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field == "" {
+			return fmt.Errorf("request must be a JSON object, got %s", typeErr.Value)
+		}
+		return err
+	}
+
+	*r = Request{JSONRPC: raw.JSONRPC, Method: raw.Method}
+	if len(raw.ID) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(raw.ID))
+		dec.UseNumber()
+		if err := dec.Decode(&r.ID); err != nil {
+			return err
+		}
+	}
+	if len(raw.Params) > 0 && !bytes.Equal(raw.Params, []byte("null")) {
+		r.Params = raw.Params
+	}
+	return nil
+}
+
+// Response represents a JSON-RPC 2.0 response. ID is always present, and
+// null when the request's ID could not be determined.
 type Response struct {
 	JSONRPC string      `json:"jsonrpc"`
-	ID      interface{} `json:"id,omitempty"`
+	ID      interface{} `json:"id"`
 	Result  interface{} `json:"result,omitempty"`
 	Error   *Error      `json:"error,omitempty"`
 }
@@ -34,6 +74,14 @@ const (
 	ErrCodeInternal       = -32603
 )
 
+// Server error codes (implementation-defined range -32000 to -32099)
+const (
+	ErrCodeAgentTimeout     = -32001
+	ErrCodeResourceNotFound = -32002
+	ErrCodeAgentRateLimited = -32003
+	ErrCodeRequestCancelled = -32004
+)
+
 // MCP-specific types
 
 // InitializeParams contains parameters for the initialize request.
@@ -58,7 +106,11 @@ type Implementation struct {
 
 // Capabilities describes supported features.
 type Capabilities struct {
-	Tools *ToolsCapability `json:"tools,omitempty"`
+	Tools     *ToolsCapability     `json:"tools,omitempty"`
+	Resources *ResourcesCapability `json:"resources,omitempty"`
+	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
+	// Experimental lists non-standard extensions, such as tools/callBatch.
+	Experimental map[string]interface{} `json:"experimental,omitempty"`
 }
 
 // ToolsCapability indicates tool support.
@@ -66,11 +118,32 @@ type ToolsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+// ResourcesCapability indicates resource support.
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// PromptsCapability indicates prompt support.
+type PromptsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
 // Tool represents an MCP tool definition.
 type Tool struct {
 	Name        string                 `json:"name"`
 	Description string                 `json:"description,omitempty"`
 	InputSchema map[string]interface{} `json:"inputSchema"`
+	Annotations *ToolAnnotations       `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are hints about a tool's behavior that clients may use
+// to decide whether to ask before calling it. Unset hints are omitted.
+type ToolAnnotations struct {
+	ReadOnlyHint    *bool `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool `json:"openWorldHint,omitempty"`
 }
 
 // ListToolsResult is the result of tools/list.
@@ -82,6 +155,31 @@ type ListToolsResult struct {
 type CallToolParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
+}
+
+// RequestMeta is the _meta object a client may attach to request params.
+type RequestMeta struct {
+	// ProgressToken asks for notifications/progress about the request.
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+}
+
+// CallToolBatchParams contains parameters for tools/callBatch.
+type CallToolBatchParams struct {
+	Calls []CallToolParams `json:"calls"`
+}
+
+// CallToolBatchResult is the result of tools/callBatch: one entry per call,
+// in request order.
+type CallToolBatchResult struct {
+	Results []CallToolBatchEntry `json:"results"`
+}
+
+// CallToolBatchEntry is the outcome of one call in a tools/callBatch. Like
+// a JSON-RPC response, it carries either a result or an error.
+type CallToolBatchEntry struct {
+	Result *CallToolResult `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
 }
 
 // CallToolResult is the result of tools/call.
@@ -92,8 +190,106 @@ type CallToolResult struct {
 
 // Content represents tool output content.
 type Content struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Data     string            `json:"data,omitempty"`
+	MimeType string            `json:"mimeType,omitempty"`
+	Resource *ResourceContents `json:"resource,omitempty"`
+}
+
+// ResourceContents is an embedded resource within tool output.
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// Resource represents an MCP resource definition.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ListResourcesResult is the result of resources/list.
+type ListResourcesResult struct {
+	Resources []Resource `json:"resources"`
+}
+
+// ReadResourceParams contains parameters for resources/read.
+type ReadResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// ReadResourceResult is the result of resources/read.
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// Prompt represents an MCP prompt definition.
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptArgument describes an argument a prompt accepts.
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// ListPromptsResult is the result of prompts/list.
+type ListPromptsResult struct {
+	Prompts []Prompt `json:"prompts"`
+}
+
+// GetPromptParams contains parameters for prompts/get.
+type GetPromptParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// GetPromptResult is the result of prompts/get.
+type GetPromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// PromptMessage is one message of a rendered prompt.
+type PromptMessage struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+}
+
+// valid reports whether the block has the fields required by its type.
+func (c Content) valid() bool {
+	switch c.Type {
+	case "text":
+		return c.Text != ""
+	case "image":
+		return c.Data != "" && c.MimeType != ""
+	case "resource":
+		return c.Resource != nil && c.Resource.URI != ""
+	default:
+		return false
+	}
+}
+
+// ProgressParams are the params of a notifications/progress notification.
+type ProgressParams struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      int64       `json:"progress"`
+	Message       string      `json:"message,omitempty"`
+}
+
+// CancelledParams are the params of a notifications/cancelled notification.
+type CancelledParams struct {
+	RequestID interface{} `json:"requestId"`
+	Reason    string      `json:"reason,omitempty"`
 }
 
 // Notification represents a JSON-RPC notification (no id).
@@ -2,6 +2,8 @@
 package metrics
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -91,6 +93,60 @@ var (
 		[]string{"agent", "namespace"},
 	)
 
+	// GatewayInflightRejections counts requests rejected by the global in-flight limit
+	GatewayInflightRejections = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystemGateway,
+			Name:      "inflight_rejections_total",
+			Help:      "Total number of requests rejected by the global in-flight limit",
+		},
+	)
+
+	// GatewayHedgedRequests counts hedge requests sent to a second backend
+	GatewayHedgedRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystemGateway,
+			Name:      "hedged_requests_total",
+			Help:      "Total number of hedge requests sent to a second backend, by which request answered first",
+		},
+		[]string{"route", "winner"},
+	)
+
+	// GatewayRetries counts agent calls retried after a transient failure
+	GatewayRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystemGateway,
+			Name:      "retries_total",
+			Help:      "Total number of agent calls retried after a connection error or 502, 503 or 504 response",
+		},
+		[]string{"agent"},
+	)
+
+	// GatewayBackendEjected shows backends ejected by outlier detection
+	GatewayBackendEjected = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystemGateway,
+			Name:      "backend_ejected",
+			Help:      "Whether the agent's backend is ejected after consecutive failures (0=no, 1=yes)",
+		},
+		[]string{"agent"},
+	)
+
+	// GatewayAgentInflight shows requests being forwarded to each agent
+	GatewayAgentInflight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystemGateway,
+			Name:      "agent_inflight",
+			Help:      "Number of requests currently being forwarded to the agent",
+		},
+		[]string{"agent"},
+	)
+
 	// === Circuit Breaker Metrics ===
 
 	// CircuitBreakerActive shows active requests
@@ -137,13 +193,13 @@ var (
 		[]string{"route", "from_state", "to_state"},
 	)
 
-	// CircuitBreakerState shows circuit state (0=closed, 1=open)
+	// CircuitBreakerState shows circuit state (0=closed, 1=open, 2=half-open)
 	CircuitBreakerState = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: namespace,
 			Subsystem: subsystemCircuit,
 			Name:      "state",
-			Help:      "Circuit breaker state (0=closed, 1=open)",
+			Help:      "Circuit breaker state (0=closed, 1=open, 2=half-open)",
 		},
 		[]string{"route"},
 	)
@@ -216,13 +272,53 @@ var (
 		[]string{"agent", "tool"},
 	)
 
+	// MCPToolVariantCallsTotal counts tool group calls by variant and outcome
+	MCPToolVariantCallsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystemMCP,
+			Name:      "tool_variant_calls_total",
+			Help:      "Total number of tool group calls by variant and outcome",
+		},
+		[]string{"group", "variant", "outcome"},
+	)
+
+	// MCPToolVariantDuration measures tool group call latency by variant
+	MCPToolVariantDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystemMCP,
+			Name:      "tool_variant_call_duration_seconds",
+			Help:      "Tool group call latency in seconds by variant",
+			Buckets:   DurationBuckets,
+		},
+		[]string{"group", "variant"},
+	)
+
+	// MCPRateLimitedTotal counts tools/call requests rejected by an agent's
+	// maxCallsPerMinute
+	MCPRateLimitedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystemMCP,
+			Name:      "rate_limited_total",
+			Help:      "Total number of tools/call requests rejected by an agent's call rate limit",
+		},
+		[]string{"agent"},
+	)
+
 	// registry holds all metrics
 	registry = prometheus.NewRegistry()
 )
 
 func init() {
-	// Register all metrics
-	registry.MustRegister(
+	Register()
+}
+
+// Register registers all gateway metrics with the gateway registry. It is
+// idempotent, so re-initializing (as tests may) does not panic.
+func Register() {
+	register(registry,
 		// Gateway metrics
 		GatewayRequestsTotal,
 		GatewayRequestDuration,
@@ -230,6 +326,11 @@ func init() {
 		GatewayRouteMatches,
 		GatewayRouteNoMatch,
 		GatewayBackendForwards,
+		GatewayInflightRejections,
+		GatewayHedgedRequests,
+		GatewayRetries,
+		GatewayBackendEjected,
+		GatewayAgentInflight,
 		// Circuit breaker metrics
 		CircuitBreakerActive,
 		CircuitBreakerWaiting,
@@ -243,11 +344,28 @@ func init() {
 		MCPErrorsTotal,
 		MCPToolsListTotal,
 		MCPToolsCallTotal,
+		MCPToolVariantCallsTotal,
+		MCPToolVariantDuration,
+		MCPRateLimitedTotal,
+		// Go runtime and process collectors
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 	)
+}
 
-	// Also register Go runtime and process collectors
-	registry.MustRegister(collectors.NewGoCollector())
-	registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+// register adds collectors to reg, skipping any that are already
+// registered. Other registration errors are programming errors and panic,
+// as with MustRegister.
+func register(reg prometheus.Registerer, cs ...prometheus.Collector) {
+	for _, c := range cs {
+		if err := reg.Register(c); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			if errors.As(err, &are) {
+				continue
+			}
+			panic(err)
+		}
+	}
 }
 
 // Handler returns an HTTP handler for metrics
@@ -257,10 +375,34 @@ func Handler() http.Handler {
 	})
 }
 
-// RecordRequest records a gateway request
-func RecordRequest(agent, route, statusCode string, duration float64) {
+type traceIDKey struct{}
+
+// ContextWithTraceID returns a copy of ctx carrying traceID, which
+// RecordRequest attaches to the request duration as an exemplar. An empty
+// traceID returns ctx unchanged.
+func ContextWithTraceID(ctx context.Context, traceID string) context.Context {
+	if traceID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext returns the trace ID carried by ctx, or "".
+func TraceIDFromContext(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDKey{}).(string)
+	return traceID
+}
+
+// RecordRequest records a gateway request. When ctx carries a trace ID the
+// duration is observed with a trace_id exemplar.
+func RecordRequest(ctx context.Context, agent, route, statusCode string, duration float64) {
 	GatewayRequestsTotal.WithLabelValues(agent, route, statusCode).Inc()
-	GatewayRequestDuration.WithLabelValues(agent, route).Observe(duration)
+	observer := GatewayRequestDuration.WithLabelValues(agent, route)
+	if traceID := TraceIDFromContext(ctx); traceID != "" {
+		observer.(prometheus.ExemplarObserver).ObserveWithExemplar(duration, prometheus.Labels{"trace_id": traceID})
+		return
+	}
+	observer.Observe(duration)
 }
 
 // RecordRequestError records a request error
@@ -283,6 +425,40 @@ func RecordBackendForward(agent, namespace string) {
 	GatewayBackendForwards.WithLabelValues(agent, namespace).Inc()
 }
 
+// RecordInflightRejection records a request rejected by the global in-flight limit
+func RecordInflightRejection() {
+	GatewayInflightRejections.Inc()
+}
+
+// RecordHedgedRequest records a hedged request and whether the "primary"
+// or the "hedge" request answered first
+func RecordHedgedRequest(route, winner string) {
+	GatewayHedgedRequests.WithLabelValues(route, winner).Inc()
+}
+
+// RecordRetry records an agent call retried after a transient failure
+func RecordRetry(agent string) {
+	GatewayRetries.WithLabelValues(agent).Inc()
+}
+
+// SetBackendEjected sets whether an agent's backend is ejected by outlier
+// detection
+func SetBackendEjected(agent string, ejected bool) {
+	val := 0.0
+	if ejected {
+		val = 1.0
+	}
+	GatewayBackendEjected.WithLabelValues(agent).Set(val)
+}
+
+// TrackAgentInflight counts a request forwarded to an agent as in flight
+// until the returned func is called
+func TrackAgentInflight(agent string) func() {
+	gauge := GatewayAgentInflight.WithLabelValues(agent)
+	gauge.Inc()
+	return gauge.Dec
+}
+
 // SetCircuitBreakerActive sets the active count for a circuit breaker
 func SetCircuitBreakerActive(route string, count int) {
 	CircuitBreakerActive.WithLabelValues(route).Set(float64(count))
@@ -298,13 +474,10 @@ func RecordCircuitBreakerRejection(route, reason string) {
 	CircuitBreakerRejections.WithLabelValues(route, reason).Inc()
 }
 
-// SetCircuitBreakerState sets the circuit breaker state (0=closed, 1=open)
-func SetCircuitBreakerState(route string, open bool) {
-	val := 0.0
-	if open {
-		val = 1.0
-	}
-	CircuitBreakerState.WithLabelValues(route).Set(val)
+// SetCircuitBreakerState sets the circuit breaker state (0=closed, 1=open,
+// 2=half-open)
+func SetCircuitBreakerState(route string, state int) {
+	CircuitBreakerState.WithLabelValues(route).Set(float64(state))
 }
 
 // RecordCircuitBreakerStateChange records a circuit breaker state transition
@@ -337,3 +510,16 @@ func RecordMCPToolsList() {
 func RecordMCPToolsCall(agent, tool string) {
 	MCPToolsCallTotal.WithLabelValues(agent, tool).Inc()
 }
+
+// RecordMCPRateLimited records a tools/call rejected by the agent's call
+// rate limit
+func RecordMCPRateLimited(agent string) {
+	MCPRateLimitedTotal.WithLabelValues(agent).Inc()
+}
+
+// RecordMCPToolVariantCall records the outcome of a tool group call served by
+// the given variant
+func RecordMCPToolVariantCall(group, variant, outcome string, duration float64) {
+	MCPToolVariantCallsTotal.WithLabelValues(group, variant, outcome).Inc()
+	MCPToolVariantDuration.WithLabelValues(group, variant).Observe(duration)
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegister_Idempotent(t *testing.T) {
+	// init has already registered everything once
+	Register()
+	Register()
+
+	RecordInflightRejection()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), "mcpfabric_gateway_inflight_rejections_total") {
+		t.Errorf("expected registered metrics to be served, got:\n%s", rec.Body.String())
+	}
+}
+
+func TestRecordRequest_TraceExemplar(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	RecordRequest(ContextWithTraceID(context.Background(), traceID), "exemplar-agent", "exemplar-route", "200", 0.042)
+	RecordRequest(context.Background(), "plain-agent", "plain-route", "200", 0.042)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, req)
+
+	var traced, plain bool
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if !strings.HasPrefix(line, "mcpfabric_gateway_request_duration_seconds_bucket") {
+			continue
+		}
+		if strings.Contains(line, `agent="exemplar-agent"`) && strings.Contains(line, `# {trace_id="`+traceID+`"} 0.042`) {
+			traced = true
+		}
+		if strings.Contains(line, `agent="plain-agent"`) && strings.Contains(line, "trace_id") {
+			plain = true
+		}
+	}
+	if !traced {
+		t.Errorf("expected a trace_id exemplar on the request duration, got:\n%s", rec.Body.String())
+	}
+	if plain {
+		t.Error("expected no exemplar without a trace ID")
+	}
+}
@@ -0,0 +1,60 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HeaderAllowlist names the client request headers passed through to agents.
+// Entries match case-insensitively; an entry ending in "*" matches every
+// header with that prefix, e.g. "X-Trace-*". Sensitive headers only match an
+// exact entry, so a prefix never forwards credentials by accident.
+type HeaderAllowlist []string
+
+// sensitiveHeaders carry credentials and are never matched by a prefix entry.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Cookie":              true,
+	"Proxy-Authorization": true,
+}
+
+// ParseHeaderAllowlist parses a comma-separated list of header names,
+// ignoring empty entries.
+func ParseHeaderAllowlist(value string) HeaderAllowlist {
+	var allow HeaderAllowlist
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			allow = append(allow, name)
+		}
+	}
+	return allow
+}
+
+// Allows reports whether the header name is on the allowlist.
+func (a HeaderAllowlist) Allows(name string) bool {
+	sensitive := sensitiveHeaders[http.CanonicalHeaderKey(name)]
+	for _, entry := range a {
+		if prefix, ok := strings.CutSuffix(entry, "*"); ok {
+			if !sensitive && len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+				return true
+			}
+		} else if strings.EqualFold(name, entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// Copy adds the allowlisted headers of src to dst.
+func (a HeaderAllowlist) Copy(dst, src http.Header) {
+	if len(a) == 0 {
+		return
+	}
+	for name, values := range src {
+		if a.Allows(name) {
+			for _, v := range values {
+				dst.Add(name, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package routes
+
+import "time"
+
+// DefaultOutlierEjection is how long a backend is ejected when the route
+// defaults set outlierConsecutiveErrors without outlierEjectionMs.
+const DefaultOutlierEjection = 30 * time.Second
+
+// OutlierPolicy controls passive health checking of backends: a backend
+// whose calls fail ConsecutiveErrors times in a row is skipped by selection
+// for the Ejection window. The zero value never ejects.
+type OutlierPolicy struct {
+	// ConsecutiveErrors is how many calls in a row must fail to eject.
+	ConsecutiveErrors int
+	// Ejection is how long an ejected backend is skipped.
+	Ejection time.Duration
+}
+
+// OutlierPolicy returns the outlier detection policy configured by the
+// defaults. It is safe to call on nil defaults.
+func (d *RouteDefaultConfig) OutlierPolicy() OutlierPolicy {
+	if d == nil || d.OutlierConsecutiveErrors <= 0 {
+		return OutlierPolicy{}
+	}
+	ejection := time.Duration(d.OutlierEjectionMs) * time.Millisecond
+	if ejection <= 0 {
+		ejection = DefaultOutlierEjection
+	}
+	return OutlierPolicy{ConsecutiveErrors: int(d.OutlierConsecutiveErrors), Ejection: ejection}
+}
+
+// Enabled reports whether the policy ejects backends at all.
+func (p OutlierPolicy) Enabled() bool {
+	return p.ConsecutiveErrors > 0
+}
+
+// outlierState is the recent health of one backend.
+type outlierState struct {
+	failures     int
+	ejectedUntil time.Time
+}
+
+// RecordResult records the outcome of a call to backend and reports whether
+// it ejected the backend. A success clears the failure count and re-admits
+// the backend at once if it was ejected. Nothing is recorded when the
+// policy is disabled.
+func (s *Selector) RecordResult(backend *CompiledRouteBackend, failed bool, policy OutlierPolicy) bool {
+	if !policy.Enabled() {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !failed {
+		delete(s.outliers, backend.Endpoint)
+		return false
+	}
+	state := s.outliers[backend.Endpoint]
+	if state == nil {
+		state = &outlierState{}
+		s.outliers[backend.Endpoint] = state
+	}
+	state.failures++
+	if state.failures < policy.ConsecutiveErrors {
+		return false
+	}
+	state.failures = 0
+	state.ejectedUntil = time.Now().Add(policy.Ejection)
+	return true
+}
+
+// Ejected reports whether the backend at endpoint is currently ejected.
+func (s *Selector) Ejected(endpoint string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.outliers[endpoint]
+	return state != nil && time.Now().Before(state.ejectedUntil)
+}
+
+// Healthy returns the backends that are not currently ejected. It returns
+// the input unchanged when every backend is ejected, so requests still go
+// somewhere instead of failing.
+func (s *Selector) Healthy(backends []CompiledRouteBackend) []CompiledRouteBackend {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.outliers) == 0 {
+		return backends
+	}
+	now := time.Now()
+	healthy := make([]CompiledRouteBackend, 0, len(backends))
+	for _, b := range backends {
+		if state := s.outliers[b.Endpoint]; state != nil && now.Before(state.ejectedUntil) {
+			continue
+		}
+		healthy = append(healthy, b)
+	}
+	if len(healthy) == 0 {
+		return backends
+	}
+	return healthy
+}
@@ -0,0 +1,92 @@
+package routes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOutlierPolicy(t *testing.T) {
+	var nilDefaults *RouteDefaultConfig
+	if nilDefaults.OutlierPolicy().Enabled() {
+		t.Error("expected nil defaults to disable outlier detection")
+	}
+
+	policy := (&RouteDefaultConfig{OutlierConsecutiveErrors: 3}).OutlierPolicy()
+	if policy.ConsecutiveErrors != 3 || policy.Ejection != DefaultOutlierEjection {
+		t.Errorf("expected 3 errors with the default ejection, got %+v", policy)
+	}
+
+	policy = (&RouteDefaultConfig{OutlierConsecutiveErrors: 1, OutlierEjectionMs: 500}).OutlierPolicy()
+	if policy.Ejection != 500*time.Millisecond {
+		t.Errorf("expected a 500ms ejection, got %v", policy.Ejection)
+	}
+}
+
+func names(backends []CompiledRouteBackend) []string {
+	var got []string
+	for _, b := range backends {
+		got = append(got, b.AgentName)
+	}
+	return got
+}
+
+func TestSelector_EjectsAndReadmits(t *testing.T) {
+	s := NewSelector()
+	backends := fairnessBackends()
+	policy := OutlierPolicy{ConsecutiveErrors: 3, Ejection: 50 * time.Millisecond}
+
+	// A success in between resets the count
+	s.RecordResult(&backends[0], true, policy)
+	s.RecordResult(&backends[0], true, policy)
+	s.RecordResult(&backends[0], false, policy)
+	s.RecordResult(&backends[0], true, policy)
+	if s.Ejected(backends[0].Endpoint) {
+		t.Fatal("expected non-consecutive failures not to eject")
+	}
+
+	s.RecordResult(&backends[0], true, policy)
+	if !s.RecordResult(&backends[0], true, policy) {
+		t.Fatal("expected the third consecutive failure to eject")
+	}
+	if got := names(s.Healthy(backends)); len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Errorf("expected the ejected backend skipped, got %v", got)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if s.Ejected(backends[0].Endpoint) || len(s.Healthy(backends)) != 3 {
+		t.Error("expected the backend re-admitted after the ejection window")
+	}
+}
+
+func TestSelector_AllEjectedFallsBack(t *testing.T) {
+	s := NewSelector()
+	backends := fairnessBackends()[:2]
+	policy := OutlierPolicy{ConsecutiveErrors: 1, Ejection: time.Minute}
+
+	for i := range backends {
+		s.RecordResult(&backends[i], true, policy)
+	}
+	if got := s.Healthy(backends); len(got) != 2 {
+		t.Errorf("expected every backend kept when all are ejected, got %v", names(got))
+	}
+
+	// A success re-admits early
+	s.RecordResult(&backends[1], false, policy)
+	if got := names(s.Healthy(backends)); len(got) != 1 || got[0] != "b" {
+		t.Errorf("expected the recovered backend only, got %v", got)
+	}
+}
+
+func TestSelector_OutlierDetectionDisabled(t *testing.T) {
+	s := NewSelector()
+	backends := fairnessBackends()
+
+	for i := 0; i < 10; i++ {
+		if s.RecordResult(&backends[0], true, OutlierPolicy{}) {
+			t.Fatal("expected a disabled policy never to eject")
+		}
+	}
+	if len(s.Healthy(backends)) != 3 {
+		t.Error("expected every backend healthy")
+	}
+}
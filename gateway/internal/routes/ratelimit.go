@@ -0,0 +1,91 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRateLimitMaxWait caps a single Retry-After wait before a
+// rate-limited agent call is retried.
+const DefaultRateLimitMaxWait = 10 * time.Second
+
+// RateLimitPolicy controls how forwarders handle an agent answering 429 Too
+// Many Requests. The zero value never retries: the 429 is reported to the
+// caller together with the agent's Retry-After.
+type RateLimitPolicy struct {
+	// Retries is how many times a rate-limited call is retried.
+	Retries int
+	// MaxWait caps a single Retry-After wait; agents asking for longer are
+	// not retried. Zero means no cap beyond the request deadline.
+	MaxWait time.Duration
+}
+
+// RateLimitedError reports that an agent rejected a call with 429.
+type RateLimitedError struct {
+	Agent string
+	// RetryAfter is the delay the agent asked for, zero if it sent none.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("agent %s is rate limited, retry after %s", e.Agent, e.RetryAfter)
+	}
+	return fmt.Sprintf("agent %s is rate limited", e.Agent)
+}
+
+// RetryAfterSeconds returns RetryAfter rounded up to whole seconds, as used
+// in a Retry-After header.
+func (e *RateLimitedError) RetryAfterSeconds() int64 {
+	return int64((e.RetryAfter + time.Second - 1) / time.Second)
+}
+
+// ParseRetryAfter parses a Retry-After header value, given either as
+// delay-seconds or as an HTTP date relative to now. Dates in the past yield
+// zero.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(at.Sub(now), 0), true
+}
+
+// Wait blocks for retryAfter before retry number attempt (starting at 1)
+// and reports whether the call should be retried. It returns false at once
+// when retries are exhausted, the wait exceeds MaxWait, or it would outlast
+// ctx's deadline.
+func (p RateLimitPolicy) Wait(ctx context.Context, attempt int, retryAfter time.Duration) bool {
+	if attempt > p.Retries {
+		return false
+	}
+	if p.MaxWait > 0 && retryAfter > p.MaxWait {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(retryAfter).After(deadline) {
+		return false
+	}
+
+	timer := time.NewTimer(retryAfter)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
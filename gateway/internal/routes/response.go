@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxResponseBytes caps the size of agent response bodies.
+const DefaultMaxResponseBytes int64 = 10 << 20
+
+// ErrResponseTooLarge is returned when an agent response exceeds the size cap.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// ReadLimited reads at most max bytes from r, failing with
+// ErrResponseTooLarge if there is more. On a read error it also returns
+// whatever was read before the error.
+func ReadLimited(r io.Reader, max int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return body, err
+	}
+	if int64(len(body)) > max {
+		return nil, TooLargeError(max)
+	}
+	return body, nil
+}
+
+// TooLargeError returns an ErrResponseTooLarge naming the exceeded cap.
+func TooLargeError(max int64) error {
+	return fmt.Errorf("%w: exceeds %d bytes", ErrResponseTooLarge, max)
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadLimited(t *testing.T) {
+	body, err := ReadLimited(strings.NewReader("12345"), 5)
+	if err != nil || string(body) != "12345" {
+		t.Errorf("ReadLimited() at the cap = %q, %v; want the whole body", body, err)
+	}
+
+	body, err = ReadLimited(strings.NewReader("123456"), 5)
+	if !errors.Is(err, ErrResponseTooLarge) || body != nil {
+		t.Errorf("ReadLimited() over the cap = %q, %v; want ErrResponseTooLarge", body, err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "exceeds 5 bytes") {
+		t.Errorf("expected the error to name the cap, got %v", err)
+	}
+}
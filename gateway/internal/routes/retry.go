@@ -0,0 +1,88 @@
+package routes
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// DefaultRetryBackoff is the delay before the first retry when the route
+// defaults set maxRetries without retryBackoffMs.
+const DefaultRetryBackoff = 100 * time.Millisecond
+
+// maxRetryBackoff caps the delay between two retries.
+const maxRetryBackoff = 10 * time.Second
+
+// RetryPolicy controls retries of agent calls that fail transiently, with a
+// connection error or a 502, 503 or 504 response. The zero value never
+// retries.
+type RetryPolicy struct {
+	// MaxRetries is how many times a failed call is retried.
+	MaxRetries int
+	// Backoff is the delay before the first retry, doubled for each further
+	// retry.
+	Backoff time.Duration
+}
+
+// RetryPolicy returns the retry policy configured by the defaults. It is
+// safe to call on nil defaults.
+func (d *RouteDefaultConfig) RetryPolicy() RetryPolicy {
+	if d == nil || d.MaxRetries <= 0 {
+		return RetryPolicy{}
+	}
+	backoff := time.Duration(d.RetryBackoffMs) * time.Millisecond
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	return RetryPolicy{MaxRetries: int(d.MaxRetries), Backoff: backoff}
+}
+
+// RetryableStatus reports whether an agent response status indicates a
+// transient failure worth retrying.
+func RetryableStatus(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Delay returns the backoff before retry number attempt (starting at 1):
+// Backoff doubled for each earlier retry, capped at 10s, of which the upper
+// half is random so that callers retrying together spread out.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxRetryBackoff)
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// Wait blocks for the backoff before retry number attempt (starting at 1)
+// and reports whether the call should be retried. It returns false at once
+// when retries are exhausted or the backoff would outlast ctx's deadline.
+func (p RetryPolicy) Wait(ctx context.Context, attempt int) bool {
+	if attempt > p.MaxRetries || ctx.Err() != nil {
+		return false
+	}
+	delay := p.Delay(attempt)
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
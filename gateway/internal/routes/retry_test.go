@@ -0,0 +1,63 @@
+package routes
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRouteDefaultConfig_RetryPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults *RouteDefaultConfig
+		want     RetryPolicy
+	}{
+		{name: "nil defaults", want: RetryPolicy{}},
+		{name: "retries unset", defaults: &RouteDefaultConfig{RetryBackoffMs: 50}, want: RetryPolicy{}},
+		{name: "configured", defaults: &RouteDefaultConfig{MaxRetries: 3, RetryBackoffMs: 50}, want: RetryPolicy{MaxRetries: 3, Backoff: 50 * time.Millisecond}},
+		{name: "default backoff", defaults: &RouteDefaultConfig{MaxRetries: 1}, want: RetryPolicy{MaxRetries: 1, Backoff: DefaultRetryBackoff}},
+	}
+	for _, tt := range tests {
+		if got := tt.defaults.RetryPolicy(); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 10, Backoff: 100 * time.Millisecond}
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 1, max: 100 * time.Millisecond},
+		{attempt: 2, max: 200 * time.Millisecond},
+		{attempt: 4, max: 800 * time.Millisecond},
+		{attempt: 10, max: maxRetryBackoff},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			if got := p.Delay(tt.attempt); got < tt.max/2 || got >= tt.max {
+				t.Fatalf("Delay(%d) = %s, want within [%s, %s)", tt.attempt, got, tt.max/2, tt.max)
+			}
+		}
+	}
+}
+
+func TestRetryPolicy_Wait(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 1, Backoff: time.Millisecond}
+	if !p.Wait(context.Background(), 1) {
+		t.Error("expected the first retry to be allowed")
+	}
+	if p.Wait(context.Background(), 2) {
+		t.Error("expected retries to be exhausted")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	slow := RetryPolicy{MaxRetries: 1, Backoff: time.Second}
+	start := time.Now()
+	if slow.Wait(ctx, 1) || time.Since(start) > 5*time.Millisecond {
+		t.Error("expected a backoff past the deadline to be refused at once")
+	}
+}
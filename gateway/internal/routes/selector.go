@@ -11,12 +11,22 @@ import (
 type Selector struct {
 	rng *rand.Rand
 	mu  sync.Mutex
+
+	// next holds round-robin positions keyed by rule name.
+	next map[string]uint64
+	// active counts in-flight requests keyed by backend endpoint.
+	active map[string]int64
+	// outliers tracks failing and ejected backends keyed by endpoint.
+	outliers map[string]*outlierState
 }
 
 // NewSelector creates a new backend selector.
 func NewSelector() *Selector {
 	return &Selector{
-		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		next:     make(map[string]uint64),
+		active:   make(map[string]int64),
+		outliers: make(map[string]*outlierState),
 	}
 }
 
@@ -82,6 +92,117 @@ func (s *Selector) SelectConsistentHash(backends []CompiledRouteBackend, key str
 	return &backends[idx]
 }
 
+// PreferZone narrows backends to those in the given zone. It returns the
+// input unchanged when zone is empty or no backend is in that zone, so
+// requests fall back to other zones instead of failing.
+func PreferZone(backends []CompiledRouteBackend, zone string) []CompiledRouteBackend {
+	if zone == "" {
+		return backends
+	}
+
+	var local []CompiledRouteBackend
+	for _, b := range backends {
+		if b.Zone == zone {
+			local = append(local, b)
+		}
+	}
+	if len(local) == 0 {
+		return backends
+	}
+	return local
+}
+
+// SelectRoundRobin cycles through backends in order, keeping a separate
+// position per key (usually the rule name). Weights are ignored.
+func (s *Selector) SelectRoundRobin(backends []CompiledRouteBackend, key string) *CompiledRouteBackend {
+	if len(backends) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	n := s.next[key]
+	s.next[key] = n + 1
+	s.mu.Unlock()
+
+	return &backends[n%uint64(len(backends))]
+}
+
+// SelectLeastConnections picks the backend with the fewest in-flight
+// requests, as recorded by Track. Ties go to the earliest backend.
+func (s *Selector) SelectLeastConnections(backends []CompiledRouteBackend) *CompiledRouteBackend {
+	if len(backends) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	best := 0
+	for i := 1; i < len(backends); i++ {
+		if s.active[backends[i].Endpoint] < s.active[backends[best].Endpoint] {
+			best = i
+		}
+	}
+	return &backends[best]
+}
+
+// Track records a request in flight to backend until the returned
+// function is called.
+func (s *Selector) Track(backend *CompiledRouteBackend) (done func()) {
+	key := backend.Endpoint
+
+	s.mu.Lock()
+	s.active[key]++
+	s.mu.Unlock()
+
+	return s.untrack(key)
+}
+
+// TryTrack is Track for forwarding to a backend that may have a
+// concurrency cap: the cap is checked and the request recorded in one
+// step, so concurrent requests that all saw the backend Available cannot
+// together exceed it. It reports false, recording nothing, if the backend
+// is at its cap.
+func (s *Selector) TryTrack(backend *CompiledRouteBackend) (done func(), ok bool) {
+	key := backend.Endpoint
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if backend.MaxConcurrentRequests > 0 && s.active[key] >= int64(backend.MaxConcurrentRequests) {
+		return nil, false
+	}
+	s.active[key]++
+	return s.untrack(key), true
+}
+
+// untrack returns the function ending a request tracked for key.
+func (s *Selector) untrack(key string) func() {
+	return func() {
+		s.mu.Lock()
+		if s.active[key]--; s.active[key] <= 0 {
+			delete(s.active, key)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Available returns the backends that are below their concurrency cap, as
+// recorded by Track. Backends without a cap are always available.
+func (s *Selector) Available(backends []CompiledRouteBackend) []CompiledRouteBackend {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	available := make([]CompiledRouteBackend, 0, len(backends))
+	for _, b := range backends {
+		if b.MaxConcurrentRequests > 0 && s.active[b.Endpoint] >= int64(b.MaxConcurrentRequests) {
+			continue
+		}
+		available = append(available, b)
+	}
+	return available
+}
+
 // SelectionStrategy defines how backends are selected.
 type SelectionStrategy int
 
@@ -90,14 +211,92 @@ const (
 	StrategyWeightedRandom SelectionStrategy = iota
 	// StrategyConsistentHash uses consistent hashing by key.
 	StrategyConsistentHash
+	// StrategyRoundRobin cycles through backends per rule.
+	StrategyRoundRobin
+	// StrategyLeastConnections picks the backend with the fewest in-flight requests.
+	StrategyLeastConnections
 )
 
-// Select picks a backend using the specified strategy.
-func (s *Selector) Select(backends []CompiledRouteBackend, strategy SelectionStrategy, hashKey string) *CompiledRouteBackend {
+// Strategy names as they appear in the compiled route config.
+const (
+	StrategyNameWeightedRandom   = "weighted-random"
+	StrategyNameConsistentHash   = "consistent-hash"
+	StrategyNameRoundRobin       = "round-robin"
+	StrategyNameLeastConnections = "least-connections"
+)
+
+// ParseStrategy maps a configured strategy name to a SelectionStrategy.
+// It reports false for empty or unknown names.
+func ParseStrategy(name string) (SelectionStrategy, bool) {
+	switch name {
+	case StrategyNameWeightedRandom:
+		return StrategyWeightedRandom, true
+	case StrategyNameConsistentHash:
+		return StrategyConsistentHash, true
+	case StrategyNameRoundRobin:
+		return StrategyRoundRobin, true
+	case StrategyNameLeastConnections:
+		return StrategyLeastConnections, true
+	default:
+		return StrategyWeightedRandom, false
+	}
+}
+
+// String returns the configured name of the strategy.
+func (st SelectionStrategy) String() string {
+	switch st {
+	case StrategyConsistentHash:
+		return StrategyNameConsistentHash
+	case StrategyRoundRobin:
+		return StrategyNameRoundRobin
+	case StrategyLeastConnections:
+		return StrategyNameLeastConnections
+	default:
+		return StrategyNameWeightedRandom
+	}
+}
+
+// Select picks a backend using the specified strategy. The key is the hash
+// key for consistent hashing and the rotation key for round-robin. Backends
+// named in exclude are skipped unless that would leave none, in which case
+// all backends are considered.
+func (s *Selector) Select(backends []CompiledRouteBackend, strategy SelectionStrategy, key string, exclude []string) *CompiledRouteBackend {
+	backends = excludeBackends(backends, exclude)
+
 	switch strategy {
 	case StrategyConsistentHash:
-		return s.SelectConsistentHash(backends, hashKey)
+		return s.SelectConsistentHash(backends, key)
+	case StrategyRoundRobin:
+		return s.SelectRoundRobin(backends, key)
+	case StrategyLeastConnections:
+		return s.SelectLeastConnections(backends)
 	default:
 		return s.SelectWeighted(backends)
 	}
 }
+
+// excludeBackends returns the backends not named in exclude, or all of them
+// if every backend is excluded.
+func excludeBackends(backends []CompiledRouteBackend, exclude []string) []CompiledRouteBackend {
+	if len(exclude) == 0 {
+		return backends
+	}
+
+	kept := make([]CompiledRouteBackend, 0, len(backends))
+	for i := range backends {
+		excluded := false
+		for _, name := range exclude {
+			if backends[i].HasName(name) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, backends[i])
+		}
+	}
+	if len(kept) == 0 {
+		return backends
+	}
+	return kept
+}
@@ -0,0 +1,172 @@
+package routes
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPreferZone(t *testing.T) {
+	backends := []CompiledRouteBackend{
+		{AgentName: "a", Zone: "eu-west-1a", Weight: 100, Ready: true},
+		{AgentName: "b", Zone: "eu-west-1b", Weight: 100, Ready: true},
+		{AgentName: "c", Weight: 100, Ready: true},
+	}
+
+	tests := []struct {
+		name string
+		zone string
+		want []string
+	}{
+		{name: "same-zone backend preferred", zone: "eu-west-1a", want: []string{"a"}},
+		{name: "no backend in zone falls back to all", zone: "eu-west-1c", want: []string{"a", "b", "c"}},
+		{name: "unknown gateway zone keeps all", zone: "", want: []string{"a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PreferZone(backends, tt.zone)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %+v", tt.want, got)
+			}
+			for i, name := range tt.want {
+				if got[i].AgentName != name {
+					t.Errorf("expected backend %d to be %s, got %s", i, name, got[i].AgentName)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectWeighted_PrefersSameZone(t *testing.T) {
+	s := NewSelector()
+	backends := []CompiledRouteBackend{
+		{AgentName: "local", Zone: "eu-west-1a", Weight: 100, Ready: true},
+		{AgentName: "remote-1", Zone: "eu-west-1b", Weight: 100, Ready: true},
+		{AgentName: "remote-2", Zone: "eu-west-1c", Weight: 100, Ready: true},
+	}
+
+	for i := 0; i < 100; i++ {
+		b := s.SelectWeighted(PreferZone(backends, "eu-west-1a"))
+		if b == nil || b.AgentName != "local" {
+			t.Fatalf("expected same-zone backend, got %+v", b)
+		}
+	}
+}
+
+func fairnessBackends() []CompiledRouteBackend {
+	return []CompiledRouteBackend{
+		{AgentName: "a", Endpoint: "http://a", Weight: 100, Ready: true},
+		{AgentName: "b", Endpoint: "http://b", Weight: 100, Ready: true},
+		{AgentName: "c", Endpoint: "http://c", Weight: 100, Ready: true},
+	}
+}
+
+func TestSelectRoundRobin_Fairness(t *testing.T) {
+	s := NewSelector()
+	backends := fairnessBackends()
+
+	counts := map[string]int{}
+	for i := 0; i < 300; i++ {
+		counts[s.Select(backends, StrategyRoundRobin, "rule-a", nil).AgentName]++
+		// Another rule's rotation does not disturb rule-a's
+		s.Select(backends, StrategyRoundRobin, "rule-b", nil)
+	}
+	for _, b := range backends {
+		if counts[b.AgentName] != 100 {
+			t.Errorf("expected %s picked 100 times, got %d", b.AgentName, counts[b.AgentName])
+		}
+	}
+}
+
+func TestSelectLeastConnections_Fairness(t *testing.T) {
+	s := NewSelector()
+	backends := fairnessBackends()
+
+	// Requests that never finish spread evenly over the backends
+	counts := map[string]int{}
+	for i := 0; i < 30; i++ {
+		b := s.Select(backends, StrategyLeastConnections, "", nil)
+		s.Track(b)
+		counts[b.AgentName]++
+	}
+	for _, b := range backends {
+		if counts[b.AgentName] != 10 {
+			t.Errorf("expected %s picked 10 times, got %d", b.AgentName, counts[b.AgentName])
+		}
+	}
+
+	// A backend whose requests finish is preferred until it catches up
+	done := s.Track(&backends[1])
+	done()
+	if got := s.Select(backends, StrategyLeastConnections, "", nil); got.AgentName != "a" {
+		t.Errorf("expected a tie to go to the first backend, got %s", got.AgentName)
+	}
+	finish := []func(){s.Track(&backends[0]), s.Track(&backends[2])}
+	if got := s.Select(backends, StrategyLeastConnections, "", nil); got.AgentName != "b" {
+		t.Errorf("expected the least loaded backend, got %s", got.AgentName)
+	}
+	for _, f := range finish {
+		f()
+	}
+}
+
+func TestSelectWeighted_Fairness(t *testing.T) {
+	s := NewSelector()
+	backends := fairnessBackends()
+	backends[2].Weight = 200
+
+	counts := map[string]int{}
+	const picks = 20000
+	for i := 0; i < picks; i++ {
+		counts[s.Select(backends, StrategyWeightedRandom, "", nil).AgentName]++
+	}
+	// Expect 25%/25%/50% within a generous margin
+	for name, want := range map[string]float64{"a": 0.25, "b": 0.25, "c": 0.5} {
+		if got := float64(counts[name]) / picks; got < want-0.05 || got > want+0.05 {
+			t.Errorf("expected %s picked about %.0f%% of the time, got %.1f%%", name, want*100, got*100)
+		}
+	}
+}
+
+func TestTryTrack_EnforcesCap(t *testing.T) {
+	s := NewSelector()
+	backend := &CompiledRouteBackend{AgentName: "a", Endpoint: "http://a", MaxConcurrentRequests: 2}
+
+	// Many goroutines racing for the slots never take more than the cap
+	var wg sync.WaitGroup
+	var reserved atomic.Int32
+	dones := make(chan func(), 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if done, ok := s.TryTrack(backend); ok {
+				reserved.Add(1)
+				dones <- done
+			}
+		}()
+	}
+	wg.Wait()
+	close(dones)
+	if got := reserved.Load(); got != 2 {
+		t.Fatalf("expected 2 reservations against a cap of 2, got %d", got)
+	}
+	if len(s.Available([]CompiledRouteBackend{*backend})) != 0 {
+		t.Error("expected the backend unavailable at its cap")
+	}
+
+	for done := range dones {
+		done()
+	}
+	if _, ok := s.TryTrack(backend); !ok {
+		t.Error("expected a slot once reservations are released")
+	}
+
+	uncapped := &CompiledRouteBackend{AgentName: "b", Endpoint: "http://b"}
+	for i := 0; i < 10; i++ {
+		if _, ok := s.TryTrack(uncapped); !ok {
+			t.Fatal("expected a backend without a cap always reserved")
+		}
+	}
+}
@@ -1,12 +1,18 @@
 package routes
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"os"
 	"regexp"
 	"sync"
 )
 
+// RouteModeFanOut is the CompiledRouteRule mode that sends a request to
+// every backend of the rule instead of selecting one.
+const RouteModeFanOut = "fan-out"
+
 // RouteConfig is the compiled routing configuration.
 type RouteConfig struct {
 	Rules    []CompiledRouteRule `json:"rules"`
@@ -15,10 +21,15 @@ type RouteConfig struct {
 
 // CompiledRouteRule is a pre-compiled route rule.
 type CompiledRouteRule struct {
-	Name     string                 `json:"name"`
-	Priority int32                  `json:"priority"`
-	Match    CompiledRouteMatch     `json:"match"`
-	Backends []CompiledRouteBackend `json:"backends"`
+	Name              string                 `json:"name"`
+	Priority          int32                  `json:"priority"`
+	Match             CompiledRouteMatch     `json:"match"`
+	Backends          []CompiledRouteBackend `json:"backends"`
+	SelectionStrategy string                 `json:"selectionStrategy,omitempty"`
+	QueueTimeoutMs    int64                  `json:"queueTimeoutMs,omitempty"`
+	Mode              string                 `json:"mode,omitempty"`
+	FanOutConcurrency int32                  `json:"fanOutConcurrency,omitempty"`
+	HedgeAfterMs      int64                  `json:"hedgeAfterMs,omitempty"`
 }
 
 // CompiledRouteMatch is the match criteria for a rule.
@@ -36,16 +47,53 @@ type CompiledRouteBackend struct {
 	Endpoint  string `json:"endpoint"`
 	Weight    int32  `json:"weight"`
 	Ready     bool   `json:"ready"`
+	Zone      string `json:"zone,omitempty"`
+	// MaxConcurrentRequests caps in-flight requests to the backend; zero
+	// means unlimited.
+	MaxConcurrentRequests int32 `json:"maxConcurrentRequests,omitempty"`
+}
+
+// HasName reports whether name refers to the backend, either as "agent" or
+// as "namespace/agent".
+func (b *CompiledRouteBackend) HasName(name string) bool {
+	return name == b.AgentName || name == b.Namespace+"/"+b.AgentName
 }
 
 // RouteDefaultConfig contains default routing configuration.
 type RouteDefaultConfig struct {
-	Backend          *CompiledRouteBackend `json:"backend,omitempty"`
-	MaxConcurrent    int32                 `json:"maxConcurrent"`
-	MaxQueueSize     int32                 `json:"maxQueueSize"`
-	QueueTimeoutMs   int64                 `json:"queueTimeoutMs"`
-	RequestTimeoutMs int64                 `json:"requestTimeoutMs"`
-	RejectUnmatched  bool                  `json:"rejectUnmatched"`
+	Backend           *CompiledRouteBackend `json:"backend,omitempty"`
+	MaxConcurrent     int32                 `json:"maxConcurrent"`
+	MaxQueueSize      int32                 `json:"maxQueueSize"`
+	QueueTimeoutMs    int64                 `json:"queueTimeoutMs"`
+	RequestTimeoutMs  int64                 `json:"requestTimeoutMs"`
+	RejectUnmatched   bool                  `json:"rejectUnmatched"`
+	LocalityAware     bool                  `json:"localityAware"`
+	SelectionStrategy string                `json:"selectionStrategy,omitempty"`
+	// MaxRetries is how many times an agent call failing with a connection
+	// error or a 502, 503 or 504 response is retried.
+	MaxRetries int32 `json:"maxRetries,omitempty"`
+	// RetryBackoffMs is the delay before the first retry, doubled for each
+	// further retry.
+	RetryBackoffMs int64 `json:"retryBackoffMs,omitempty"`
+	// ForwardHeaders lists client request headers copied onto agent calls
+	// in addition to the gateway's own allowlist.
+	ForwardHeaders HeaderAllowlist `json:"forwardHeaders,omitempty"`
+	// OutlierConsecutiveErrors is how many agent calls in a row must fail
+	// with a connection error or a 5xx response before the backend is
+	// ejected.
+	OutlierConsecutiveErrors int32 `json:"outlierConsecutiveErrors,omitempty"`
+	// OutlierEjectionMs is how long an ejected backend is skipped.
+	OutlierEjectionMs int64 `json:"outlierEjectionMs,omitempty"`
+	// ErrorThresholdPercent is the share of failed requests within
+	// ErrorWindowMs that opens a rule's circuit breaker for CooldownMs,
+	// after which a probe request decides whether it closes. Zero disables
+	// failure-based opening.
+	ErrorThresholdPercent int32 `json:"errorThresholdPercent,omitempty"`
+	// MinimumRequests is how many requests the window must hold before the
+	// error rate is considered.
+	MinimumRequests int32 `json:"minimumRequests,omitempty"`
+	ErrorWindowMs   int64 `json:"errorWindowMs,omitempty"`
+	CooldownMs      int64 `json:"cooldownMs,omitempty"`
 }
 
 // Table holds the in-memory route table with compiled regexes.
@@ -53,6 +101,7 @@ type Table struct {
 	mu       sync.RWMutex
 	config   *RouteConfig
 	compiled []compiledRule
+	hash     string
 }
 
 type compiledRule struct {
@@ -95,9 +144,12 @@ func (t *Table) LoadFromJSON(data []byte) error {
 		compiled = append(compiled, cr)
 	}
 
+	sum := sha256.Sum256(data)
+
 	t.mu.Lock()
 	t.config = &config
 	t.compiled = compiled
+	t.hash = hex.EncodeToString(sum[:8])
 	t.mu.Unlock()
 
 	return nil
@@ -115,6 +167,15 @@ type MatchRequest struct {
 type MatchResult struct {
 	RuleName string
 	Backends []CompiledRouteBackend
+	// SelectionStrategy is the matched rule's configured strategy, if any.
+	SelectionStrategy string
+	// Mode is the matched rule's mode; RouteModeFanOut calls every backend.
+	Mode string
+	// FanOutConcurrency caps concurrent calls of a fan-out rule (0 = all).
+	FanOutConcurrency int32
+	// HedgeAfterMs is how long to wait for the selected backend before also
+	// calling another one (0 = no hedging).
+	HedgeAfterMs int64
 }
 
 // Match finds the first matching rule and returns its ready backends.
@@ -133,8 +194,12 @@ func (t *Table) Match(req MatchRequest) *MatchResult {
 				readyBackends := filterReadyBackends(cr.rule.Backends)
 				if len(readyBackends) > 0 {
 					return &MatchResult{
-						RuleName: cr.rule.Name,
-						Backends: readyBackends,
+						RuleName:          cr.rule.Name,
+						Backends:          readyBackends,
+						SelectionStrategy: cr.rule.SelectionStrategy,
+						Mode:              cr.rule.Mode,
+						FanOutConcurrency: cr.rule.FanOutConcurrency,
+						HedgeAfterMs:      cr.rule.HedgeAfterMs,
 					}
 				}
 			}
@@ -147,8 +212,12 @@ func (t *Table) Match(req MatchRequest) *MatchResult {
 			readyBackends := filterReadyBackends(cr.rule.Backends)
 			if len(readyBackends) > 0 {
 				return &MatchResult{
-					RuleName: cr.rule.Name,
-					Backends: readyBackends,
+					RuleName:          cr.rule.Name,
+					Backends:          readyBackends,
+					SelectionStrategy: cr.rule.SelectionStrategy,
+					Mode:              cr.rule.Mode,
+					FanOutConcurrency: cr.rule.FanOutConcurrency,
+					HedgeAfterMs:      cr.rule.HedgeAfterMs,
 				}
 			}
 		}
@@ -224,3 +293,11 @@ func (t *Table) GetConfig() *RouteConfig {
 	defer t.mu.RUnlock()
 	return t.config
 }
+
+// ConfigHash returns a short hash of the raw configuration last loaded, or
+// "" if none has been loaded.
+func (t *Table) ConfigHash() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.hash
+}
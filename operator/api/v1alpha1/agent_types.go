@@ -30,6 +30,36 @@ type ModelConfig struct {
 	// Endpoint overrides the default provider endpoint.
 	// +optional
 	Endpoint string `json:"endpoint,omitempty"`
+
+	// OIDC authenticates to the provider with short-lived credentials
+	// exchanged from a projected ServiceAccount token instead of static keys.
+	// Supported for the "bedrock" and "vertex" providers.
+	// +optional
+	OIDC *ModelOIDCAuth `json:"oidc,omitempty"`
+}
+
+// ModelOIDCAuth configures OIDC token exchange for model provider credentials.
+type ModelOIDCAuth struct {
+	// Audience of the projected token. Defaults to "sts.amazonaws.com" for
+	// bedrock; required for vertex, where it names the workload identity
+	// pool provider.
+	// +optional
+	Audience string `json:"audience,omitempty"`
+
+	// RoleARN is the IAM role to assume. Required for bedrock.
+	// +optional
+	RoleARN string `json:"roleArn,omitempty"`
+
+	// ServiceAccountEmail is the Google service account to impersonate
+	// after the exchange (vertex only).
+	// +optional
+	ServiceAccountEmail string `json:"serviceAccountEmail,omitempty"`
+
+	// ExpirationSeconds is the requested lifetime of the projected token.
+	// +kubebuilder:validation:Minimum=600
+	// +kubebuilder:default=3600
+	// +optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
 }
 
 // ToolRef references a Tool and optionally selects specific tools.
@@ -62,6 +92,21 @@ type MCPServerSelector struct {
 	Namespaces []string `json:"namespaces,omitempty"`
 }
 
+// AgentInvokeMapping controls how MCP tool-call arguments are mapped onto
+// the agent's /invoke request.
+type AgentInvokeMapping struct {
+	// QueryField names the argument used as the request query. When unset,
+	// the first of query, question, request and description is used, falling
+	// back to all string arguments.
+	// +optional
+	QueryField string `json:"queryField,omitempty"`
+
+	// PassRawArguments sends the tool-call arguments as the request body
+	// as-is, instead of wrapping them with a query and metadata.
+	// +optional
+	PassRawArguments bool `json:"passRawArguments,omitempty"`
+}
+
 // AgentPolicy defines runtime constraints for the agent.
 type AgentPolicy struct {
 	// MaxToolCalls limits total tool invocations per request.
@@ -85,6 +130,58 @@ type AgentPolicy struct {
 	// +kubebuilder:default=10
 	// +optional
 	MaxConcurrentRequests *int32 `json:"maxConcurrentRequests,omitempty"`
+
+	// MaxCallsPerMinute limits MCP tools/call requests the gateway forwards
+	// to the agent, across all clients. Unset means unlimited.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxCallsPerMinute *int32 `json:"maxCallsPerMinute,omitempty"`
+}
+
+// SecurityContextConfig adjusts the hardened security context rendered for
+// an Agent's or Task's pods. Unset fields keep the hardened defaults.
+type SecurityContextConfig struct {
+	// RunAsNonRoot requires containers to run as a non-root user. Setting it
+	// either way requires runAsUser, so the uid checked is explicit.
+	// Defaults to true, with the image's USER.
+	// +optional
+	RunAsNonRoot *bool `json:"runAsNonRoot,omitempty"`
+
+	// RunAsUser is the UID the containers run as. Defaults to the image's
+	// USER. Must not be 0 unless runAsNonRoot is false.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RunAsUser *int64 `json:"runAsUser,omitempty"`
+
+	// FSGroup is the supplemental group that owns mounted volumes, for
+	// images whose user must write to them.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+
+	// ReadOnlyRootFilesystem mounts the containers' root filesystems
+	// read-only. Set it to false for images that write outside their
+	// mounted volumes. Defaults to true.
+	// +optional
+	ReadOnlyRootFilesystem *bool `json:"readOnlyRootFilesystem,omitempty"`
+}
+
+// AgentPreStop configures a preStop hook that lets an agent pod drain before
+// it exits. The pod is removed from Service endpoints as soon as it starts
+// terminating, so the hook covers the window in which the gateway may still
+// route to it.
+type AgentPreStop struct {
+	// Sleep delays shutdown so endpoint removal propagates before the agent
+	// stops accepting connections.
+	// +optional
+	Sleep *metav1.Duration `json:"sleep,omitempty"`
+
+	// DrainPath is an HTTP path on the agent port called before shutdown,
+	// which should return once in-flight requests finish. Takes precedence
+	// over Sleep.
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	DrainPath string `json:"drainPath,omitempty"`
 }
 
 // AgentTool declares an MCP tool exposed by this agent.
@@ -101,6 +198,101 @@ type AgentTool struct {
 	// InputSchema is the JSON Schema for tool parameters.
 	// +optional
 	InputSchema *apiextensionsv1.JSON `json:"inputSchema,omitempty"`
+
+	// DefaultArguments are merged into every call of the tool, so clients
+	// need not supply constants such as a tenant or locale. Arguments the
+	// client sends take precedence.
+	// +optional
+	DefaultArguments map[string]string `json:"defaultArguments,omitempty"`
+
+	// Annotations are MCP hints about the tool's behavior that clients may
+	// use to decide whether to ask before calling it.
+	// +optional
+	Annotations *AgentToolAnnotations `json:"annotations,omitempty"`
+}
+
+// AgentToolAnnotations are the MCP tool annotations advertised for a tool.
+// Unset hints are left out, so clients apply their own defaults.
+type AgentToolAnnotations struct {
+	// ReadOnlyHint indicates the tool does not modify its environment.
+	// +optional
+	ReadOnlyHint *bool `json:"readOnlyHint,omitempty"`
+
+	// DestructiveHint indicates the tool may perform destructive updates.
+	// Only meaningful when the tool is not read-only.
+	// +optional
+	DestructiveHint *bool `json:"destructiveHint,omitempty"`
+
+	// IdempotentHint indicates repeated calls with the same arguments have
+	// no additional effect. Only meaningful when the tool is not read-only.
+	// +optional
+	IdempotentHint *bool `json:"idempotentHint,omitempty"`
+
+	// OpenWorldHint indicates the tool interacts with external entities,
+	// such as the web, rather than a closed domain.
+	// +optional
+	OpenWorldHint *bool `json:"openWorldHint,omitempty"`
+}
+
+// AgentResource declares an MCP resource, such as a document or dataset,
+// exposed by this agent. The gateway reads it from the agent's
+// /resources/read endpoint.
+type AgentResource struct {
+	// URI identifies the resource (e.g., "docs://runbooks/oncall.md").
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	URI string `json:"uri"`
+
+	// Name is a human-readable name for the resource.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Description explains what the resource contains.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// MimeType is the resource's media type (e.g., "text/markdown").
+	// +optional
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// AgentPrompt declares a reusable MCP prompt template exposed by this agent.
+type AgentPrompt struct {
+	// Name is the prompt identifier (e.g., "summarize_incident").
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Description explains what the prompt is for.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Template is the prompt text. Placeholders such as {{service}} are
+	// replaced with the matching argument values.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Template string `json:"template"`
+
+	// Arguments declares the arguments the template accepts.
+	// +optional
+	Arguments []AgentPromptArgument `json:"arguments,omitempty"`
+}
+
+// AgentPromptArgument declares an argument of an AgentPrompt.
+type AgentPromptArgument struct {
+	// Name is the argument name used in template placeholders.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Description explains what the argument is for.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Required marks arguments that must be supplied.
+	// +optional
+	Required bool `json:"required,omitempty"`
 }
 
 // NetworkSpec defines network egress rules for the agent.
@@ -124,6 +316,43 @@ type NetworkSpec struct {
 	AllowObjectStore *bool `json:"allowObjectStore,omitempty"`
 }
 
+// ExternalSecretsSpec syncs credentials from an external secret manager into
+// an operator-managed Secret exposed to the agent as environment variables.
+type ExternalSecretsSpec struct {
+	// Provider names the secret manager backend (e.g., "aws-secrets-manager").
+	// No backend is wired into the operator yet, so any value leaves the
+	// Agent not Ready with reason ExternalSecretsProviderUnavailable.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Provider string `json:"provider"`
+
+	// Data maps keys in the synced Secret to remote secrets.
+	// +kubebuilder:validation:MinItems=1
+	Data []ExternalSecretData `json:"data"`
+
+	// RefreshInterval controls how often remote values are re-read.
+	// +kubebuilder:default="1h"
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// ExternalSecretData maps one remote secret to a key in the synced Secret.
+type ExternalSecretData struct {
+	// SecretKey is the key in the synced Secret and the env var name.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	SecretKey string `json:"secretKey"`
+
+	// RemoteRef identifies the secret in the external store (name, ARN, or path).
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	RemoteRef string `json:"remoteRef"`
+
+	// Property selects a field when the remote secret is a JSON object.
+	// +optional
+	Property string `json:"property,omitempty"`
+}
+
 // AgentSpec defines the desired state of Agent.
 type AgentSpec struct {
 	// Prompt is the system instruction/persona for the agent.
@@ -171,6 +400,12 @@ type AgentSpec struct {
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 
+	// InitContainerResources defines compute resource requirements for the
+	// init containers that install shared libraries and ToolPackages.
+	// Defaults to small requests with limits sized for pip installs.
+	// +optional
+	InitContainerResources *corev1.ResourceRequirements `json:"initContainerResources,omitempty"`
+
 	// Image overrides the default strands-agent-runner image.
 	// +optional
 	Image string `json:"image,omitempty"`
@@ -188,6 +423,34 @@ type AgentSpec struct {
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 
+	// SecurityContext relaxes the hardened security context of the agent
+	// pods for images that need it. Defaults to non-root with a read-only
+	// root filesystem, running as the image's USER.
+	// +optional
+	SecurityContext *SecurityContextConfig `json:"securityContext,omitempty"`
+
+	// TerminationGracePeriodSeconds is how long an agent pod may take to
+	// finish in-flight requests when stopped. Defaults to the policy request
+	// timeout plus a short shutdown margin.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// PreStop adds a preStop hook to the agent container for graceful
+	// connection draining.
+	// +optional
+	PreStop *AgentPreStop `json:"preStop,omitempty"`
+
+	// Labels are added to the agent's Deployment, pods and Service, e.g. for
+	// cost allocation. Labels managed by the operator take precedence.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to the agent's Deployment, pods and Service, e.g.
+	// for policy engines. Annotations managed by the operator take precedence.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
 	// Env sets environment variables directly in the agent container.
 	// Use for non-secret values like AWS_DEFAULT_REGION.
 	// +optional
@@ -198,10 +461,30 @@ type AgentSpec struct {
 	// +optional
 	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
 
+	// ExternalSecrets syncs credentials from an external secret manager
+	// into a managed Secret loaded into the agent container's environment.
+	// +optional
+	ExternalSecrets *ExternalSecretsSpec `json:"externalSecrets,omitempty"`
+
 	// Tools declares MCP tools this agent exposes.
 	// These are used by the gateway for MCP protocol discovery.
 	// +optional
 	Tools []AgentTool `json:"tools,omitempty"`
+
+	// MCPResources declares MCP resources this agent exposes.
+	// These are listed by the gateway for MCP resources/list.
+	// +optional
+	MCPResources []AgentResource `json:"mcpResources,omitempty"`
+
+	// Prompts declares MCP prompt templates this agent exposes.
+	// These are listed by the gateway for MCP prompts/list.
+	// +optional
+	Prompts []AgentPrompt `json:"prompts,omitempty"`
+
+	// InvokeMapping controls how the gateway turns an MCP tool call into a
+	// request to the agent's /invoke endpoint.
+	// +optional
+	InvokeMapping *AgentInvokeMapping `json:"invokeMapping,omitempty"`
 }
 
 // ResolvedMCPEndpoint represents a discovered MCP server endpoint.
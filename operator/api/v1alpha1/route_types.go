@@ -4,6 +4,31 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// SelectionStrategy determines how the gateway picks among a rule's backends.
+// +kubebuilder:validation:Enum=weighted-random;consistent-hash;round-robin;least-connections
+type SelectionStrategy string
+
+const (
+	SelectionStrategyWeightedRandom   SelectionStrategy = "weighted-random"
+	SelectionStrategyConsistentHash   SelectionStrategy = "consistent-hash"
+	SelectionStrategyRoundRobin       SelectionStrategy = "round-robin"
+	SelectionStrategyLeastConnections SelectionStrategy = "least-connections"
+)
+
+// RouteMode determines whether a rule sends a request to one backend or to
+// all of them.
+// +kubebuilder:validation:Enum=select;fan-out
+type RouteMode string
+
+const (
+	// RouteModeSelect sends each request to one backend picked by the
+	// selection strategy.
+	RouteModeSelect RouteMode = "select"
+	// RouteModeFanOut sends each request to every backend in parallel and
+	// returns their results together.
+	RouteModeFanOut RouteMode = "fan-out"
+)
+
 // RouteBackend defines a target agent for routing.
 type RouteBackend struct {
 	// AgentRef references an Agent by name.
@@ -49,6 +74,34 @@ type RouteRule struct {
 	// Backends are the target agents (supports weighted routing).
 	// +kubebuilder:validation:MinItems=1
 	Backends []RouteBackend `json:"backends"`
+
+	// SelectionStrategy overrides the route default strategy for this rule.
+	// +optional
+	SelectionStrategy SelectionStrategy `json:"selectionStrategy,omitempty"`
+
+	// QueueTimeout overrides the route default circuit breaker queue timeout
+	// for this rule, so latency-sensitive rules can fail fast while batch
+	// rules wait longer for capacity.
+	// +optional
+	QueueTimeout *metav1.Duration `json:"queueTimeout,omitempty"`
+
+	// Mode selects between routing each request to one backend (select, the
+	// default) and fanning it out to all backends (fan-out).
+	// +optional
+	Mode RouteMode `json:"mode,omitempty"`
+
+	// FanOutConcurrency caps how many backends a fan-out rule calls at once.
+	// Unset calls all backends at once.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FanOutConcurrency *int32 `json:"fanOutConcurrency,omitempty"`
+
+	// HedgeAfter enables request hedging for latency-sensitive rules: when
+	// the selected backend has not answered after this delay, the gateway
+	// also sends the request to another backend and uses whichever answers
+	// first. Unset disables hedging. Ignored for fan-out rules.
+	// +optional
+	HedgeAfter *metav1.Duration `json:"hedgeAfter,omitempty"`
 }
 
 // RouteMatch defines matching criteria for a route rule.
@@ -94,6 +147,31 @@ type CircuitBreakerConfig struct {
 	// +kubebuilder:default="5m"
 	// +optional
 	RequestTimeout *metav1.Duration `json:"requestTimeout,omitempty"`
+
+	// ErrorThresholdPercent is the share of requests failing with a 5xx
+	// within ErrorWindow that opens the circuit: requests are rejected with
+	// 503 for Cooldown, after which a single probe request closes it again
+	// on success. Unset never opens the circuit on failures.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ErrorThresholdPercent *int32 `json:"errorThresholdPercent,omitempty"`
+
+	// MinimumRequests is how many requests ErrorWindow must hold before the
+	// error rate is considered. Defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinimumRequests *int32 `json:"minimumRequests,omitempty"`
+
+	// ErrorWindow is the rolling window the error rate is measured over.
+	// Defaults to 1m.
+	// +optional
+	ErrorWindow *metav1.Duration `json:"errorWindow,omitempty"`
+
+	// Cooldown is how long the circuit stays open before a probe request
+	// is let through. Defaults to 30s.
+	// +optional
+	Cooldown *metav1.Duration `json:"cooldown,omitempty"`
 }
 
 // RouteDefaults defines default behavior when no rules match.
@@ -111,6 +189,51 @@ type RouteDefaults struct {
 	// +kubebuilder:default=false
 	// +optional
 	RejectUnmatched *bool `json:"rejectUnmatched,omitempty"`
+
+	// LocalityAware prefers backends in the gateway's own zone, falling
+	// back to other zones when none are ready.
+	// +kubebuilder:default=false
+	// +optional
+	LocalityAware *bool `json:"localityAware,omitempty"`
+
+	// SelectionStrategy is how backends are picked for rules that don't set
+	// their own. When unset, requests with a tenant or correlation ID use
+	// consistent-hash and all others use weighted-random.
+	// +optional
+	SelectionStrategy SelectionStrategy `json:"selectionStrategy,omitempty"`
+
+	// MaxRetries is how many times the gateway retries an agent call that
+	// fails with a connection error or a 502, 503 or 504 response. Unset
+	// disables retries.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRetries *int32 `json:"maxRetries,omitempty"`
+
+	// RetryBackoff is the delay before the first retry, doubled with jitter
+	// for each further retry. Defaults to 100ms.
+	// +optional
+	RetryBackoff *metav1.Duration `json:"retryBackoff,omitempty"`
+
+	// ForwardHeaders lists client request headers the gateway copies onto
+	// agent calls, in addition to its -forward-headers flag. A trailing "*"
+	// matches a prefix, but credentials such as Authorization and Cookie are
+	// only forwarded when named exactly.
+	// +optional
+	ForwardHeaders []string `json:"forwardHeaders,omitempty"`
+
+	// OutlierConsecutiveErrors is how many agent calls in a row must fail
+	// with a connection error or a 5xx response before the gateway stops
+	// sending requests to that backend for OutlierEjection. Unset disables
+	// outlier detection.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	OutlierConsecutiveErrors *int32 `json:"outlierConsecutiveErrors,omitempty"`
+
+	// OutlierEjection is how long a backend ejected by outlier detection is
+	// skipped. When every backend of a rule is ejected they are all used
+	// anyway. Defaults to 30s.
+	// +optional
+	OutlierEjection *metav1.Duration `json:"outlierEjection,omitempty"`
 }
 
 // RouteSpec defines the desired state of Route.
@@ -139,6 +262,22 @@ type BackendStatus struct {
 	// Endpoint is the resolved agent service URL.
 	// +optional
 	Endpoint string `json:"endpoint,omitempty"`
+
+	// Zone is the topology zone the agent runs in, if known.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// MaxConcurrentRequests is the agent's concurrency policy, which the
+	// gateway enforces per backend. Zero means unlimited.
+	// +optional
+	MaxConcurrentRequests int32 `json:"maxConcurrentRequests,omitempty"`
+
+	// LastReadyTime is when the agent was last observed ready. It is only
+	// updated when readiness changes: for a ready agent it is when it became
+	// ready, for a lost one when the loss was observed. It keeps the Route
+	// ready while a lost backend recovers within the operator's grace period.
+	// +optional
+	LastReadyTime *metav1.Time `json:"lastReadyTime,omitempty"`
 }
 
 // RouteStatus defines the observed state of Route.
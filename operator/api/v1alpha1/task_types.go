@@ -68,6 +68,17 @@ type TaskSource struct {
 	Inline string `json:"inline,omitempty"`
 }
 
+// NamedTaskSource is an additional document passed to the orchestrator under
+// its name (e.g. "requirements" or "design").
+type NamedTaskSource struct {
+	// Name is the key the document is passed under in the task config.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9_-]*$`
+	Name string `json:"name"`
+
+	TaskSource `json:",inline"`
+}
+
 // TaskLimits defines execution constraints.
 type TaskLimits struct {
 	// MaxIterations is the maximum number of loop iterations.
@@ -109,6 +120,23 @@ const (
 	GitProviderBitbucket GitProvider = "bitbucket"
 )
 
+// WorkspaceStorageConfig configures the PersistentVolumeClaim that backs a
+// Task's workspace.
+type WorkspaceStorageConfig struct {
+	// StorageClassName is the storage class of the workspace PVC. Defaults to
+	// the cluster's default storage class.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// AccessMode of the workspace PVC. ReadWriteMany lets several pods, such
+	// as parallel workers, mount the workspace at once, and needs a storage
+	// class whose provisioner supports it. Fixed once the PVC is created.
+	// +kubebuilder:validation:Enum=ReadWriteOnce;ReadWriteMany
+	// +kubebuilder:default=ReadWriteOnce
+	// +optional
+	AccessMode corev1.PersistentVolumeAccessMode `json:"accessMode,omitempty"`
+}
+
 // GitConfig defines Git repository settings for task artifacts.
 // Only cloning existing repositories is supported - creating new repos is not allowed.
 type GitConfig struct {
@@ -145,6 +173,12 @@ type GitConfig struct {
 	// +optional
 	Depth *int32 `json:"depth,omitempty"`
 
+	// Resources defines compute resource requirements for the git clone
+	// init container. Defaults to small requests with limits sized for
+	// cloning.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
 	// CredentialsSecret references a Secret containing git credentials.
 	// Required key: "token" (GitHub PAT or equivalent).
 	// +kubebuilder:validation:Required
@@ -183,8 +217,46 @@ type GitConfig struct {
 	// Supports placeholders: {task}, {completed}, {total}.
 	// +optional
 	PRBody string `json:"prBody,omitempty"`
+
+	// Reviewers are requested to review the created PR (provider usernames).
+	// +kubebuilder:validation:items:MinLength=1
+	// +optional
+	Reviewers []string `json:"reviewers,omitempty"`
+
+	// Assignees are assigned to the created PR (provider usernames).
+	// +kubebuilder:validation:items:MinLength=1
+	// +optional
+	Assignees []string `json:"assignees,omitempty"`
+
+	// PRLabels are applied to the created PR (e.g. "automated").
+	// +kubebuilder:validation:items:MinLength=1
+	// +optional
+	PRLabels []string `json:"prLabels,omitempty"`
+
+	// PropagateTaskLabels also applies the Task's own labels to the PR,
+	// each rendered as "key=value".
+	// +kubebuilder:default=false
+	// +optional
+	PropagateTaskLabels bool `json:"propagateTaskLabels,omitempty"`
 }
 
+// TaskResumePolicy selects how a running Task is handled when the operator
+// finds its orchestrator Job in an ambiguous state (e.g. after an operator
+// restart, the Job has not finished but has no running pods).
+// +kubebuilder:validation:Enum=Continue;Restart;Fail
+type TaskResumePolicy string
+
+const (
+	// TaskResumePolicyContinue keeps waiting on the existing Job.
+	TaskResumePolicyContinue TaskResumePolicy = "Continue"
+
+	// TaskResumePolicyRestart deletes the Job and recreates it from scratch.
+	TaskResumePolicyRestart TaskResumePolicy = "Restart"
+
+	// TaskResumePolicyFail marks the task failed for manual intervention.
+	TaskResumePolicyFail TaskResumePolicy = "Fail"
+)
+
 // QualityGate defines a command to run as a quality check.
 type QualityGate struct {
 	// Name identifies this quality gate.
@@ -217,6 +289,22 @@ type TaskSpec struct {
 	// +kubebuilder:validation:Required
 	WorkerRef AgentReference `json:"workerRef"`
 
+	// WorkerEndpoint overrides how the orchestrator reaches the worker with an
+	// explicit http(s) base URL (e.g. a shared Service or a gateway route);
+	// the orchestrator appends "/invoke". When set, the worker is not
+	// co-located as a sidecar and WorkerRef is not looked up.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	WorkerEndpoint string `json:"workerEndpoint,omitempty"`
+
+	// WorkerPreflight probes WorkerEndpoint's /healthz before launching the
+	// orchestrator Job, surfacing network-policy or DNS problems as a
+	// WorkerUnreachable condition. It has no effect without WorkerEndpoint,
+	// since a co-located worker only starts with the Job.
+	// +kubebuilder:default=false
+	// +optional
+	WorkerPreflight bool `json:"workerPreflight,omitempty"`
+
 	// OrchestratorRef references the orchestrator agent that manages task execution.
 	// If not specified, defaults to "task-orchestrator" in the same namespace.
 	// +optional
@@ -226,6 +314,13 @@ type TaskSpec struct {
 	// +kubebuilder:validation:Required
 	TaskSource TaskSource `json:"taskSource"`
 
+	// AdditionalSources are further documents (e.g. requirements, design)
+	// loaded alongside the PRD and passed to the orchestrator by name.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	AdditionalSources []NamedTaskSource `json:"additionalSources,omitempty"`
+
 	// Limits defines execution constraints.
 	// +optional
 	Limits *TaskLimits `json:"limits,omitempty"`
@@ -247,6 +342,64 @@ type TaskSpec struct {
 	// Context provides additional context to pass to the orchestrator.
 	// +optional
 	Context string `json:"context,omitempty"`
+
+	// WorkspaceDir is where the workspace volume is mounted in the
+	// orchestrator Job (and exported as WORKSPACE_DIR), for orchestrators
+	// that expect a different path. Defaults to /workspace.
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	WorkspaceDir string `json:"workspaceDir,omitempty"`
+
+	// WorkspaceStorage configures the workspace PVC's storage class and
+	// access mode. Defaults to a ReadWriteOnce PVC of the default class.
+	// +optional
+	WorkspaceStorage *WorkspaceStorageConfig `json:"workspaceStorage,omitempty"`
+
+	// RetainWorkspace keeps the workspace PVC when the task is deleted, for
+	// inspecting what the orchestrator left behind. The PVC is released from
+	// the task and labelled fabric.jarsater.ai/orphaned=true; deleting it is
+	// then up to the user.
+	// +optional
+	RetainWorkspace bool `json:"retainWorkspace,omitempty"`
+
+	// SecurityContext relaxes the hardened security context of the
+	// orchestrator Job's pod. The worker sidecar uses the worker Agent's
+	// securityContext with the fields set here taking precedence. Defaults
+	// to non-root with a read-only root filesystem, running as the images'
+	// USER.
+	// +optional
+	SecurityContext *SecurityContextConfig `json:"securityContext,omitempty"`
+
+	// IterationHistoryLimit is how many of the most recent iteration results
+	// are kept in status.recentIterations. Lower it to keep the object small,
+	// raise it for more history to analyse.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=50
+	// +kubebuilder:default=10
+	// +optional
+	IterationHistoryLimit *int32 `json:"iterationHistoryLimit,omitempty"`
+
+	// ResumeFromTaskID reruns the task starting at the PRD story with this
+	// id: the orchestrator treats every story before it as already done.
+	// Must match a story id in the PRD. Intended for debugging.
+	// +optional
+	ResumeFromTaskID string `json:"resumeFromTaskId,omitempty"`
+
+	// ResumePolicy controls what happens when the operator finds a running
+	// task whose orchestrator Job is neither finished nor running any pods.
+	// +kubebuilder:default=Continue
+	// +optional
+	ResumePolicy TaskResumePolicy `json:"resumePolicy,omitempty"`
+
+	// Labels are added to the orchestrator Job and its pod, e.g. for cost
+	// allocation. Labels managed by the operator take precedence.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to the orchestrator Job and its pod, e.g. for
+	// policy engines. Annotations managed by the operator take precedence.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // IterationResult captures the outcome of a single iteration.
@@ -281,6 +434,21 @@ type IterationResult struct {
 	Learnings string `json:"learnings,omitempty"`
 }
 
+// PRDChanges summarizes how a run changed the PRD, by story ID.
+type PRDChanges struct {
+	// Added lists stories the orchestrator added to the PRD.
+	// +optional
+	Added []string `json:"added,omitempty"`
+
+	// Removed lists stories the orchestrator removed from the PRD.
+	// +optional
+	Removed []string `json:"removed,omitempty"`
+
+	// Passed lists stories newly marked as passed.
+	// +optional
+	Passed []string `json:"passed,omitempty"`
+}
+
 // TaskStatus defines the observed state of Task.
 type TaskStatus struct {
 	// Phase is the current execution phase.
@@ -299,6 +467,14 @@ type TaskStatus struct {
 	// +optional
 	TotalTasks int32 `json:"totalTasks,omitempty"`
 
+	// CompletedCriteria is the number of acceptance criteria on passed stories.
+	// +optional
+	CompletedCriteria int32 `json:"completedCriteria,omitempty"`
+
+	// TotalCriteria is the total number of acceptance criteria in the PRD.
+	// +optional
+	TotalCriteria int32 `json:"totalCriteria,omitempty"`
+
 	// LastTaskID is the ID of the last attempted task.
 	// +optional
 	LastTaskID string `json:"lastTaskId,omitempty"`
@@ -319,9 +495,10 @@ type TaskStatus struct {
 	// +optional
 	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
 
-	// RecentIterations contains the most recent iteration results.
+	// RecentIterations contains the most recent iteration results, up to
+	// spec.iterationHistoryLimit.
 	// +optional
-	// +kubebuilder:validation:MaxItems=10
+	// +kubebuilder:validation:MaxItems=50
 	RecentIterations []IterationResult `json:"recentIterations,omitempty"`
 
 	// RepositoryURL is the URL of the Git repository being used.
@@ -336,6 +513,10 @@ type TaskStatus struct {
 	// +optional
 	PullRequestURL string `json:"pullRequestUrl,omitempty"`
 
+	// PRDChanges summarizes how the last run changed the PRD.
+	// +optional
+	PRDChanges *PRDChanges `json:"prdChanges,omitempty"`
+
 	// ObservedGeneration is the last observed generation.
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
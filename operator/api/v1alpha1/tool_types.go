@@ -75,6 +75,30 @@ type ToolSpec struct {
 	// EntryModule is the Python module path to import (e.g., "mypackage.tools").
 	// +optional
 	EntryModule string `json:"entryModule,omitempty"`
+
+	// PipIndex installs the package's Python requirements from a private index
+	// when the agent pod starts. The image must provide pip. When unset, the
+	// image must already contain all dependencies.
+	// +optional
+	PipIndex *PipIndexConfig `json:"pipIndex,omitempty"`
+}
+
+// PipIndexConfig configures a Python package index for runtime installs.
+type PipIndexConfig struct {
+	// URL is the index URL (e.g., "https://pypi.example.com/simple").
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// CredentialsSecret references a Secret with "username" and "password"
+	// keys used to authenticate against the index. Values must be URL-safe.
+	// +optional
+	CredentialsSecret *LocalObjectReference `json:"credentialsSecret,omitempty"`
+
+	// RequirementsFile is the requirements file path inside the image.
+	// +kubebuilder:default="/app/requirements.txt"
+	// +optional
+	RequirementsFile string `json:"requirementsFile,omitempty"`
 }
 
 // LocalObjectReference references a local object by name.
@@ -98,6 +122,15 @@ type ToolStatus struct {
 	// +optional
 	AvailableTools []ToolDefinition `json:"availableTools,omitempty"`
 
+	// ValidatedSpecHash is the hash of the image and entry module that were
+	// last validated successfully. Validation is skipped while it matches.
+	// +optional
+	ValidatedSpecHash string `json:"validatedSpecHash,omitempty"`
+
+	// ValidatedTools are the definitions produced by the last successful validation.
+	// +optional
+	ValidatedTools []ToolDefinition `json:"validatedTools,omitempty"`
+
 	// Conditions represent the latest available observations.
 	// +optional
 	// +patchMergeKey=type
@@ -38,6 +38,21 @@ func (in *Agent) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentInvokeMapping) DeepCopyInto(out *AgentInvokeMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentInvokeMapping.
+func (in *AgentInvokeMapping) DeepCopy() *AgentInvokeMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentInvokeMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AgentList) DeepCopyInto(out *AgentList) {
 	*out = *in
@@ -93,6 +108,11 @@ func (in *AgentPolicy) DeepCopyInto(out *AgentPolicy) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MaxCallsPerMinute != nil {
+		in, out := &in.MaxCallsPerMinute, &out.MaxCallsPerMinute
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentPolicy.
@@ -105,6 +125,61 @@ func (in *AgentPolicy) DeepCopy() *AgentPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentPreStop) DeepCopyInto(out *AgentPreStop) {
+	*out = *in
+	if in.Sleep != nil {
+		in, out := &in.Sleep, &out.Sleep
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentPreStop.
+func (in *AgentPreStop) DeepCopy() *AgentPreStop {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentPreStop)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentPrompt) DeepCopyInto(out *AgentPrompt) {
+	*out = *in
+	if in.Arguments != nil {
+		in, out := &in.Arguments, &out.Arguments
+		*out = make([]AgentPromptArgument, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentPrompt.
+func (in *AgentPrompt) DeepCopy() *AgentPrompt {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentPrompt)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentPromptArgument) DeepCopyInto(out *AgentPromptArgument) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentPromptArgument.
+func (in *AgentPromptArgument) DeepCopy() *AgentPromptArgument {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentPromptArgument)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AgentRef) DeepCopyInto(out *AgentRef) {
 	*out = *in
@@ -135,6 +210,21 @@ func (in *AgentReference) DeepCopy() *AgentReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentResource) DeepCopyInto(out *AgentResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentResource.
+func (in *AgentResource) DeepCopy() *AgentResource {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AgentSpec) DeepCopyInto(out *AgentSpec) {
 	*out = *in
@@ -176,6 +266,11 @@ func (in *AgentSpec) DeepCopyInto(out *AgentSpec) {
 		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.InitContainerResources != nil {
+		in, out := &in.InitContainerResources, &out.InitContainerResources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
 		*out = make(map[string]string, len(*in))
@@ -190,6 +285,35 @@ func (in *AgentSpec) DeepCopyInto(out *AgentSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(SecurityContextConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PreStop != nil {
+		in, out := &in.PreStop, &out.PreStop
+		*out = new(AgentPreStop)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]corev1.EnvVar, len(*in))
@@ -204,6 +328,11 @@ func (in *AgentSpec) DeepCopyInto(out *AgentSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ExternalSecrets != nil {
+		in, out := &in.ExternalSecrets, &out.ExternalSecrets
+		*out = new(ExternalSecretsSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Tools != nil {
 		in, out := &in.Tools, &out.Tools
 		*out = make([]AgentTool, len(*in))
@@ -211,6 +340,23 @@ func (in *AgentSpec) DeepCopyInto(out *AgentSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MCPResources != nil {
+		in, out := &in.MCPResources, &out.MCPResources
+		*out = make([]AgentResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.Prompts != nil {
+		in, out := &in.Prompts, &out.Prompts
+		*out = make([]AgentPrompt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InvokeMapping != nil {
+		in, out := &in.InvokeMapping, &out.InvokeMapping
+		*out = new(AgentInvokeMapping)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentSpec.
@@ -265,6 +411,18 @@ func (in *AgentTool) DeepCopyInto(out *AgentTool) {
 		*out = new(apiextensionsv1.JSON)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DefaultArguments != nil {
+		in, out := &in.DefaultArguments, &out.DefaultArguments
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = new(AgentToolAnnotations)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentTool.
@@ -277,10 +435,49 @@ func (in *AgentTool) DeepCopy() *AgentTool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentToolAnnotations) DeepCopyInto(out *AgentToolAnnotations) {
+	*out = *in
+	if in.ReadOnlyHint != nil {
+		in, out := &in.ReadOnlyHint, &out.ReadOnlyHint
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DestructiveHint != nil {
+		in, out := &in.DestructiveHint, &out.DestructiveHint
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IdempotentHint != nil {
+		in, out := &in.IdempotentHint, &out.IdempotentHint
+		*out = new(bool)
+		**out = **in
+	}
+	if in.OpenWorldHint != nil {
+		in, out := &in.OpenWorldHint, &out.OpenWorldHint
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentToolAnnotations.
+func (in *AgentToolAnnotations) DeepCopy() *AgentToolAnnotations {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentToolAnnotations)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackendStatus) DeepCopyInto(out *BackendStatus) {
 	*out = *in
 	out.AgentRef = in.AgentRef
+	if in.LastReadyTime != nil {
+		in, out := &in.LastReadyTime, &out.LastReadyTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendStatus.
@@ -316,6 +513,26 @@ func (in *CircuitBreakerConfig) DeepCopyInto(out *CircuitBreakerConfig) {
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.ErrorThresholdPercent != nil {
+		in, out := &in.ErrorThresholdPercent, &out.ErrorThresholdPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinimumRequests != nil {
+		in, out := &in.MinimumRequests, &out.MinimumRequests
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ErrorWindow != nil {
+		in, out := &in.ErrorWindow, &out.ErrorWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Cooldown != nil {
+		in, out := &in.Cooldown, &out.Cooldown
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreakerConfig.
@@ -328,6 +545,66 @@ func (in *CircuitBreakerConfig) DeepCopy() *CircuitBreakerConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalSecretData) DeepCopyInto(out *ExternalSecretData) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretData.
+func (in *ExternalSecretData) DeepCopy() *ExternalSecretData {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSecretData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalSecretsSpec) DeepCopyInto(out *ExternalSecretsSpec) {
+	*out = *in
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make([]ExternalSecretData, len(*in))
+		copy(*out, *in)
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretsSpec.
+func (in *ExternalSecretsSpec) DeepCopy() *ExternalSecretsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSecretsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceStorageConfig) DeepCopyInto(out *WorkspaceStorageConfig) {
+	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceStorageConfig.
+func (in *WorkspaceStorageConfig) DeepCopy() *WorkspaceStorageConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceStorageConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitConfig) DeepCopyInto(out *GitConfig) {
 	*out = *in
@@ -336,6 +613,11 @@ func (in *GitConfig) DeepCopyInto(out *GitConfig) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 	out.CredentialsSecret = in.CredentialsSecret
 	if in.AutoPush != nil {
 		in, out := &in.AutoPush, &out.AutoPush
@@ -352,6 +634,21 @@ func (in *GitConfig) DeepCopyInto(out *GitConfig) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Reviewers != nil {
+		in, out := &in.Reviewers, &out.Reviewers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Assignees != nil {
+		in, out := &in.Assignees, &out.Assignees
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PRLabels != nil {
+		in, out := &in.PRLabels, &out.PRLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitConfig.
@@ -467,6 +764,11 @@ func (in *ModelConfig) DeepCopyInto(out *ModelConfig) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(ModelOIDCAuth)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelConfig.
@@ -479,6 +781,42 @@ func (in *ModelConfig) DeepCopy() *ModelConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelOIDCAuth) DeepCopyInto(out *ModelOIDCAuth) {
+	*out = *in
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelOIDCAuth.
+func (in *ModelOIDCAuth) DeepCopy() *ModelOIDCAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelOIDCAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamedTaskSource) DeepCopyInto(out *NamedTaskSource) {
+	*out = *in
+	in.TaskSource.DeepCopyInto(&out.TaskSource)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamedTaskSource.
+func (in *NamedTaskSource) DeepCopy() *NamedTaskSource {
+	if in == nil {
+		return nil
+	}
+	out := new(NamedTaskSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
 	*out = *in
@@ -514,6 +852,56 @@ func (in *NetworkSpec) DeepCopy() *NetworkSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PRDChanges) DeepCopyInto(out *PRDChanges) {
+	*out = *in
+	if in.Added != nil {
+		in, out := &in.Added, &out.Added
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Removed != nil {
+		in, out := &in.Removed, &out.Removed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Passed != nil {
+		in, out := &in.Passed, &out.Passed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PRDChanges.
+func (in *PRDChanges) DeepCopy() *PRDChanges {
+	if in == nil {
+		return nil
+	}
+	out := new(PRDChanges)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipIndexConfig) DeepCopyInto(out *PipIndexConfig) {
+	*out = *in
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipIndexConfig.
+func (in *PipIndexConfig) DeepCopy() *PipIndexConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PipIndexConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *QualityGate) DeepCopyInto(out *QualityGate) {
 	*out = *in
@@ -620,6 +1008,36 @@ func (in *RouteDefaults) DeepCopyInto(out *RouteDefaults) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.LocalityAware != nil {
+		in, out := &in.LocalityAware, &out.LocalityAware
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RetryBackoff != nil {
+		in, out := &in.RetryBackoff, &out.RetryBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ForwardHeaders != nil {
+		in, out := &in.ForwardHeaders, &out.ForwardHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OutlierConsecutiveErrors != nil {
+		in, out := &in.OutlierConsecutiveErrors, &out.OutlierConsecutiveErrors
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OutlierEjection != nil {
+		in, out := &in.OutlierEjection, &out.OutlierEjection
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteDefaults.
@@ -702,6 +1120,21 @@ func (in *RouteRule) DeepCopyInto(out *RouteRule) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.QueueTimeout != nil {
+		in, out := &in.QueueTimeout, &out.QueueTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.FanOutConcurrency != nil {
+		in, out := &in.FanOutConcurrency, &out.FanOutConcurrency
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HedgeAfter != nil {
+		in, out := &in.HedgeAfter, &out.HedgeAfter
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteRule.
@@ -754,7 +1187,9 @@ func (in *RouteStatus) DeepCopyInto(out *RouteStatus) {
 	if in.Backends != nil {
 		in, out := &in.Backends, &out.Backends
 		*out = make([]BackendStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -775,6 +1210,41 @@ func (in *RouteStatus) DeepCopy() *RouteStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityContextConfig) DeepCopyInto(out *SecurityContextConfig) {
+	*out = *in
+	if in.RunAsNonRoot != nil {
+		in, out := &in.RunAsNonRoot, &out.RunAsNonRoot
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RunAsUser != nil {
+		in, out := &in.RunAsUser, &out.RunAsUser
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FSGroup != nil {
+		in, out := &in.FSGroup, &out.FSGroup
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ReadOnlyRootFilesystem != nil {
+		in, out := &in.ReadOnlyRootFilesystem, &out.ReadOnlyRootFilesystem
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityContextConfig.
+func (in *SecurityContextConfig) DeepCopy() *SecurityContextConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityContextConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Task) DeepCopyInto(out *Task) {
 	*out = *in
@@ -909,6 +1379,13 @@ func (in *TaskSpec) DeepCopyInto(out *TaskSpec) {
 		**out = **in
 	}
 	in.TaskSource.DeepCopyInto(&out.TaskSource)
+	if in.AdditionalSources != nil {
+		in, out := &in.AdditionalSources, &out.AdditionalSources
+		*out = make([]NamedTaskSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Limits != nil {
 		in, out := &in.Limits, &out.Limits
 		*out = new(TaskLimits)
@@ -926,6 +1403,35 @@ func (in *TaskSpec) DeepCopyInto(out *TaskSpec) {
 		*out = new(GitConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.WorkspaceStorage != nil {
+		in, out := &in.WorkspaceStorage, &out.WorkspaceStorage
+		*out = new(WorkspaceStorageConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(SecurityContextConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IterationHistoryLimit != nil {
+		in, out := &in.IterationHistoryLimit, &out.IterationHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskSpec.
@@ -960,6 +1466,11 @@ func (in *TaskStatus) DeepCopyInto(out *TaskStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PRDChanges != nil {
+		in, out := &in.PRDChanges, &out.PRDChanges
+		*out = new(PRDChanges)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -1103,6 +1614,11 @@ func (in *ToolSpec) DeepCopyInto(out *ToolSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PipIndex != nil {
+		in, out := &in.PipIndex, &out.PipIndex
+		*out = new(PipIndexConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ToolSpec.
@@ -1125,6 +1641,13 @@ func (in *ToolStatus) DeepCopyInto(out *ToolStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ValidatedTools != nil {
+		in, out := &in.ValidatedTools, &out.ValidatedTools
+		*out = make([]ToolDefinition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
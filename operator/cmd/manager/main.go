@@ -4,6 +4,7 @@ import (
 	"flag"
 	"os"
 	"strings"
+	"time"
 
 	"go.uber.org/zap/zapcore"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -17,6 +18,8 @@ import (
 
 	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
 	"github.com/jarsater/mcp-fabric/operator/internal/controllers"
+	"github.com/jarsater/mcp-fabric/operator/internal/render"
+	"github.com/jarsater/mcp-fabric/operator/internal/secrets"
 )
 
 var (
@@ -50,11 +53,25 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var gatewayNamespace string
+	var registryMirror string
+	var defaultOrchestrator string
+	var defaultGitImage string
+	var maxPRDTasks int
+	var routeNotReadyGrace time.Duration
+	var watchNamespaces string
+	var ignoreNamespaces string
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager.")
-	flag.StringVar(&gatewayNamespace, "gateway-namespace", "mcp-fabric-gateway", "Namespace where gateway routes ConfigMap is created.")
+	flag.StringVar(&gatewayNamespace, "gateway-namespace", "mcp-fabric-gateway", "Namespace where the gateway routes and tool catalog ConfigMaps are created.")
+	flag.StringVar(&registryMirror, "image-registry-mirror", "", "Registry to pull default images from (e.g. for air-gapped clusters). Explicit images are not rewritten.")
+	flag.StringVar(&defaultOrchestrator, "default-orchestrator", "task-orchestrator", "Orchestrator Agent used by Tasks that do not set orchestratorRef.")
+	flag.StringVar(&defaultGitImage, "default-git-image", "", "Git image for Tasks that do not set git.image (empty = "+render.DefaultGitImage+", following --image-registry-mirror).")
+	flag.DurationVar(&routeNotReadyGrace, "route-not-ready-grace-period", 0, "How long a Route stays Ready after losing a backend, so brief losses during rollouts do not flip it (0 = flip immediately).")
+	flag.IntVar(&maxPRDTasks, "max-prd-tasks", 1000, "Maximum number of tasks a Task's PRD may declare; larger PRDs fail with reason TooManyTasks.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "", "Comma-separated namespaces whose Agents and Tasks are managed (empty = all).")
+	flag.StringVar(&ignoreNamespaces, "ignore-namespaces", "", "Comma-separated namespaces whose Agents and Tasks are ignored; takes precedence over --watch-namespaces.")
 
 	// Configure log level from LOG_LEVEL environment variable
 	logLevel := parseLogLevel(os.Getenv("LOG_LEVEL"))
@@ -67,6 +84,22 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if strings.TrimSpace(defaultOrchestrator) == "" {
+		setupLog.Error(nil, "--default-orchestrator must not be empty")
+		os.Exit(1)
+	}
+	if maxPRDTasks <= 0 {
+		setupLog.Error(nil, "--max-prd-tasks must be positive")
+		os.Exit(1)
+	}
+
+	render.SetRegistryMirror(registryMirror)
+
+	namespaces := controllers.NamespaceFilter{
+		Allow: controllers.ParseNamespaceList(watchNamespaces),
+		Deny:  controllers.ParseNamespaceList(ignoreNamespaces),
+	}
+
 	restConfig := ctrl.GetConfigOrDie()
 
 	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
@@ -99,10 +132,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	// External secret providers available to Agents. None is wired yet:
+	// Agents setting externalSecrets report ExternalSecretsProviderUnavailable.
+	secretProviders := secrets.NewRegistry()
+
 	// Setup Agent controller
 	if err = (&controllers.AgentReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		SecretProviders:  secretProviders,
+		CatalogNamespace: gatewayNamespace,
+		Namespaces:       namespaces,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Agent")
 		os.Exit(1)
@@ -110,9 +150,10 @@ func main() {
 
 	// Setup Route controller
 	if err = (&controllers.RouteReconciler{
-		Client:           mgr.GetClient(),
-		Scheme:           mgr.GetScheme(),
-		GatewayNamespace: gatewayNamespace,
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		GatewayNamespace:    gatewayNamespace,
+		NotReadyGracePeriod: routeNotReadyGrace,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Route")
 		os.Exit(1)
@@ -120,9 +161,14 @@ func main() {
 
 	// Setup Task controller
 	if err = (&controllers.TaskReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		Clientset: clientset,
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Clientset:               clientset,
+		DefaultOrchestratorName: defaultOrchestrator,
+		MaxPRDTasks:             maxPRDTasks,
+		DefaultGitImage:         defaultGitImage,
+		Namespaces:              namespaces,
+		Recorder:                mgr.GetEventRecorder("task-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Task")
 		os.Exit(1)
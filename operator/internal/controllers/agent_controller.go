@@ -2,17 +2,22 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -20,12 +25,30 @@ import (
 	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
 	"github.com/jarsater/mcp-fabric/operator/internal/metrics"
 	"github.com/jarsater/mcp-fabric/operator/internal/render"
+	"github.com/jarsater/mcp-fabric/operator/internal/secrets"
+)
+
+const (
+	// defaultExternalSecretsRefresh is how often external secrets are re-read
+	// when the Agent does not set a refresh interval.
+	defaultExternalSecretsRefresh = time.Hour
 )
 
 // AgentReconciler reconciles an Agent object.
 type AgentReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// SecretProviders resolves Agent external secrets.
+	SecretProviders *secrets.Registry
+
+	// CatalogNamespace is where the tool catalog ConfigMap is maintained.
+	// Defaults to the gateway namespace.
+	CatalogNamespace string
+
+	// Namespaces limits the Agents this controller manages; Agents outside
+	// it are ignored. The zero value manages every namespace.
+	Namespaces NamespaceFilter
 }
 
 // +kubebuilder:rbac:groups=fabric.jarsater.ai,resources=agents,verbs=get;list;watch;create;update;patch;delete
@@ -36,6 +59,7 @@ type AgentReconciler struct {
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile handles Agent reconciliation.
 func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -46,13 +70,35 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	var agent aiv1alpha1.Agent
 	if err := r.Get(ctx, req.NamespacedName, &agent); err != nil {
 		if client.IgnoreNotFound(err) == nil {
-			// Agent was deleted, clean up metrics
+			// Agent was deleted, clean up metrics and drop it from the catalog
 			metrics.DeleteAgentMetrics(req.Name, req.Namespace)
+			return ctrl.Result{}, r.reconcileToolCatalog(ctx, nil)
 		}
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+		return ctrl.Result{}, err
 	}
 
 	logger.Info("Reconciling Agent", "name", agent.Name)
+	observedStatus := agent.Status.DeepCopy()
+
+	// Reject specs that cannot be rendered; only a spec change fixes them
+	if reason, errorType, err := validateAgentSpec(&agent); err != nil {
+		r.setCondition(&agent, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: agent.Generation,
+			Reason:             reason,
+			Message:            err.Error(),
+		})
+		agent.Status.Ready = false
+		if statusErr := r.Status().Update(ctx, &agent); statusErr != nil {
+			metrics.RecordReconcile(metrics.ControllerAgent, metrics.ResultError, time.Since(startTime).Seconds())
+			metrics.RecordReconcileError(metrics.ControllerAgent, "status_update")
+			return ctrl.Result{}, statusErr
+		}
+		metrics.RecordReconcile(metrics.ControllerAgent, metrics.ResultError, time.Since(startTime).Seconds())
+		metrics.RecordReconcileError(metrics.ControllerAgent, errorType)
+		return ctrl.Result{}, nil
+	}
 
 	// Resolve Tools
 	toolPackages, err := r.resolveToolPackages(ctx, &agent)
@@ -94,7 +140,39 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	}
 	agent.Status.ConfigHash = configHash
 
+	// Sync credentials from the external secret manager
+	externalSecretHash, err := r.reconcileExternalSecrets(ctx, &agent, agentLabels)
+	if err != nil {
+		// A provider the operator has no client for will not appear on a
+		// retry, so wait for the spec to change instead
+		unavailable := secrets.IsUnknownProvider(err)
+		reason := "ExternalSecretsSyncFailed"
+		if unavailable {
+			reason = "ExternalSecretsProviderUnavailable"
+		}
+		r.setCondition(&agent, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: agent.Generation,
+			Reason:             reason,
+			Message:            err.Error(),
+		})
+		agent.Status.Ready = false
+		if statusErr := r.Status().Update(ctx, &agent); statusErr != nil {
+			metrics.RecordReconcile(metrics.ControllerAgent, metrics.ResultError, time.Since(startTime).Seconds())
+			metrics.RecordReconcileError(metrics.ControllerAgent, "status_update")
+			return ctrl.Result{}, statusErr
+		}
+		metrics.RecordReconcile(metrics.ControllerAgent, metrics.ResultError, time.Since(startTime).Seconds())
+		metrics.RecordReconcileError(metrics.ControllerAgent, "external_secrets")
+		if unavailable {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
 	agent.Status.ObservedGeneration = agent.Generation
+	r.setToolNamesCondition(&agent)
 
 	// A non-standalone agent is only used as a Task worker (co-located as a
 	// sidecar by the Task controller). Skip the standalone Deployment/Service,
@@ -105,7 +183,7 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	var ready bool
 	if standalone {
 		// Create/Update Deployment
-		if err := r.reconcileDeployment(ctx, &agent, configHash, agentLabels, toolPackages); err != nil {
+		if err := r.reconcileDeployment(ctx, &agent, configHash, externalSecretHash, agentLabels, toolPackages); err != nil {
 			return ctrl.Result{}, err
 		}
 
@@ -166,9 +244,21 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		})
 	}
 
-	if err := r.Status().Update(ctx, &agent); err != nil {
+	// An unchanged status means the agent was already up to date
+	result := metrics.ResultNoOp
+	if !equality.Semantic.DeepEqual(*observedStatus, agent.Status) {
+		if err := r.Status().Update(ctx, &agent); err != nil {
+			metrics.RecordReconcile(metrics.ControllerAgent, metrics.ResultError, time.Since(startTime).Seconds())
+			metrics.RecordReconcileError(metrics.ControllerAgent, "status_update")
+			return ctrl.Result{}, err
+		}
+		result = metrics.ResultSuccess
+	}
+
+	// Publish the agent's readiness and tools to the discovery catalog
+	if err := r.reconcileToolCatalog(ctx, &agent); err != nil {
 		metrics.RecordReconcile(metrics.ControllerAgent, metrics.ResultError, time.Since(startTime).Seconds())
-		metrics.RecordReconcileError(metrics.ControllerAgent, "status_update")
+		metrics.RecordReconcileError(metrics.ControllerAgent, "tool_catalog")
 		return ctrl.Result{}, err
 	}
 
@@ -177,7 +267,7 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	if agent.Spec.Model.ModelID != "" {
 		modelID = agent.Spec.Model.ModelID
 	}
-	image := render.DefaultAgentRunnerImage
+	image := render.DefaultImage(render.DefaultAgentRunnerImage)
 	if agent.Spec.Image != "" {
 		image = agent.Spec.Image
 	}
@@ -189,9 +279,18 @@ func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	metrics.SetAgentMetrics(agent.Name, agent.Namespace, modelID, image, ready, int(desiredReplicas), int(agent.Status.AvailableReplicas), toolsCount)
 
 	// Record reconciliation success
-	metrics.RecordReconcile(metrics.ControllerAgent, metrics.ResultSuccess, time.Since(startTime).Seconds())
+	metrics.RecordReconcile(metrics.ControllerAgent, result, time.Since(startTime).Seconds())
 
 	logger.Info("Agent reconciled", "name", agent.Name, "ready", ready)
+
+	// Periodically re-read external secrets to pick up rotations
+	if es := agent.Spec.ExternalSecrets; es != nil {
+		refresh := defaultExternalSecretsRefresh
+		if es.RefreshInterval != nil && es.RefreshInterval.Duration > 0 {
+			refresh = es.RefreshInterval.Duration
+		}
+		return ctrl.Result{RequeueAfter: refresh}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -221,6 +320,7 @@ func (r *AgentReconciler) resolveToolPackages(ctx context.Context, agent *aiv1al
 			EntryModule:   tool.Spec.EntryModule,
 			EnabledTools:  ref.EnabledTools,
 			DisabledTools: ref.DisabledTools,
+			PipIndex:      tool.Spec.PipIndex,
 		})
 	}
 
@@ -298,13 +398,14 @@ func (r *AgentReconciler) reconcileConfigMap(ctx context.Context, agent *aiv1alp
 	return configHash, r.Update(ctx, existing)
 }
 
-func (r *AgentReconciler) reconcileDeployment(ctx context.Context, agent *aiv1alpha1.Agent, configHash string, agentLabels map[string]string, toolPackages []render.ToolPackageInfo) error {
+func (r *AgentReconciler) reconcileDeployment(ctx context.Context, agent *aiv1alpha1.Agent, configHash, externalSecretHash string, agentLabels map[string]string, toolPackages []render.ToolPackageInfo) error {
 	deployment := render.AgentDeployment(render.AgentDeploymentParams{
-		Agent:         agent,
-		ConfigMapName: agent.Name + "-config",
-		ConfigHash:    configHash,
-		Labels:        agentLabels,
-		ToolPackages:  toolPackages,
+		Agent:              agent,
+		ConfigMapName:      agent.Name + "-config",
+		ConfigHash:         configHash,
+		Labels:             agentLabels,
+		ToolPackages:       toolPackages,
+		ExternalSecretHash: externalSecretHash,
 	})
 
 	if err := controllerutil.SetControllerReference(agent, deployment, r.Scheme); err != nil {
@@ -322,9 +423,54 @@ func (r *AgentReconciler) reconcileDeployment(ctx context.Context, agent *aiv1al
 	// Update deployment spec
 	existing.Spec = deployment.Spec
 	existing.Labels = deployment.Labels
+	mergeAnnotations(&existing.ObjectMeta, deployment.Annotations)
 	return r.Update(ctx, existing)
 }
 
+// reconcileExternalSecrets syncs the agent's external secrets into a managed
+// Secret and returns a hash of its data. The Secret is removed when the agent
+// no longer references external secrets.
+func (r *AgentReconciler) reconcileExternalSecrets(ctx context.Context, agent *aiv1alpha1.Agent, agentLabels map[string]string) (string, error) {
+	if agent.Spec.ExternalSecrets == nil {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: render.ExternalSecretName(agent), Namespace: agent.Namespace},
+		}
+		if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+			return "", err
+		}
+		return "", nil
+	}
+
+	data, err := r.SecretProviders.Resolve(ctx, agent.Spec.ExternalSecrets)
+	if err != nil {
+		return "", err
+	}
+
+	secret := render.AgentExternalSecret(agent, data, agentLabels)
+	if err := controllerutil.SetControllerReference(agent, secret, r.Scheme); err != nil {
+		return "", err
+	}
+
+	// Map keys marshal in sorted order, so the hash is stable
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	hash := render.HashConfig(dataJSON)
+
+	existing := &corev1.Secret{}
+	err = r.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, existing)
+	if errors.IsNotFound(err) {
+		return hash, r.Create(ctx, secret)
+	} else if err != nil {
+		return "", err
+	}
+
+	existing.Data = secret.Data
+	existing.Labels = secret.Labels
+	return hash, r.Update(ctx, existing)
+}
+
 func (r *AgentReconciler) reconcileService(ctx context.Context, agent *aiv1alpha1.Agent, agentLabels map[string]string) error {
 	svc := render.AgentService(agent, agentLabels)
 
@@ -344,9 +490,25 @@ func (r *AgentReconciler) reconcileService(ctx context.Context, agent *aiv1alpha
 	svc.Spec.ClusterIP = existing.Spec.ClusterIP
 	existing.Spec = svc.Spec
 	existing.Labels = svc.Labels
+	mergeAnnotations(&existing.ObjectMeta, svc.Annotations)
 	return r.Update(ctx, existing)
 }
 
+// mergeAnnotations sets the rendered annotations on an existing object while
+// keeping annotations written by other controllers (e.g. the Deployment
+// revision).
+func mergeAnnotations(existing *metav1.ObjectMeta, annotations map[string]string) {
+	if len(annotations) == 0 {
+		return
+	}
+	if existing.Annotations == nil {
+		existing.Annotations = make(map[string]string, len(annotations))
+	}
+	for key, value := range annotations {
+		existing.Annotations[key] = value
+	}
+}
+
 // deleteStandaloneWorkload removes the Deployment and Service for an agent that
 // is no longer run standalone (e.g. a Task worker). Both are named after the
 // agent. Missing objects are ignored.
@@ -383,6 +545,91 @@ func (r *AgentReconciler) checkDeploymentReady(ctx context.Context, agent *aiv1a
 	return ready, deployment.Status.ReadyReplicas
 }
 
+// reconcileToolCatalog rebuilds the tool catalog ConfigMap from all ready
+// Agents. It is a read-only discovery artifact for systems outside the
+// gateway, so it is left unowned and survives individual Agents.
+//
+// current, if set, is the Agent being reconciled and replaces its listed
+// copy: the cache may not have caught up with the status just written, and
+// that write does not trigger another reconcile to correct the catalog.
+func (r *AgentReconciler) reconcileToolCatalog(ctx context.Context, current *aiv1alpha1.Agent) error {
+	var agents aiv1alpha1.AgentList
+	if err := r.List(ctx, &agents); err != nil {
+		return fmt.Errorf("failed to list agents for tool catalog: %w", err)
+	}
+	if current != nil {
+		i := slices.IndexFunc(agents.Items, func(a aiv1alpha1.Agent) bool {
+			return a.Namespace == current.Namespace && a.Name == current.Name
+		})
+		if i >= 0 {
+			agents.Items[i] = *current
+		} else {
+			agents.Items = append(agents.Items, *current)
+		}
+	}
+
+	namespace := r.CatalogNamespace
+	if namespace == "" {
+		namespace = render.GatewayNamespace
+	}
+	cm, err := render.ToolCatalogConfigMap(namespace, render.BuildToolCatalog(agents.Items))
+	if err != nil {
+		return err
+	}
+
+	existing := &corev1.ConfigMap{}
+	err = r.Get(ctx, types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}, existing)
+	if errors.IsNotFound(err) {
+		return r.Create(ctx, cm)
+	} else if err != nil {
+		return err
+	}
+
+	if existing.Data[render.ToolCatalogFileName] == cm.Data[render.ToolCatalogFileName] {
+		return nil
+	}
+	existing.Data = cm.Data
+	existing.Labels = cm.Labels
+	return r.Update(ctx, existing)
+}
+
+// setToolNamesCondition records whether every MCP tool name the gateway
+// generates for the agent is valid as a ToolNamesValid condition. Invalid
+// tools are skipped by the gateway but do not affect readiness.
+func (r *AgentReconciler) setToolNamesCondition(agent *aiv1alpha1.Agent) {
+	invalid := render.InvalidMCPToolNames(agent)
+	if len(invalid) == 0 {
+		r.setCondition(agent, metav1.Condition{
+			Type:               "ToolNamesValid",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: agent.Generation,
+			Reason:             "Valid",
+			Message:            "All MCP tool names are valid",
+		})
+		return
+	}
+	r.setCondition(agent, metav1.Condition{
+		Type:               "ToolNamesValid",
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: agent.Generation,
+		Reason:             "InvalidToolNames",
+		Message: fmt.Sprintf("MCP tool names must match %s; not listed by the gateway: %s",
+			render.MCPToolNamePattern, strings.Join(invalid, ", ")),
+	})
+}
+
+// validateAgentSpec rejects spec settings that cannot work, returning the
+// Ready condition reason and reconcile error type to report.
+func validateAgentSpec(agent *aiv1alpha1.Agent) (reason, errorType string, err error) {
+	if err := render.ValidateModelOIDC(agent.Spec.Model); err != nil {
+		return "InvalidModelAuth", "invalid_model_auth", err
+	}
+	if err := render.ValidateSecurityContext(agent.Spec.SecurityContext); err != nil {
+		return "InvalidSecurityContext", "invalid_security_context", err
+	}
+	return "", "", nil
+}
+
 func (r *AgentReconciler) setCondition(agent *aiv1alpha1.Agent, condition metav1.Condition) {
 	condition.LastTransitionTime = metav1.Now()
 	meta.SetStatusCondition(&agent.Status.Conditions, condition)
@@ -391,11 +638,13 @@ func (r *AgentReconciler) setCondition(agent *aiv1alpha1.Agent, condition metav1
 // SetupWithManager sets up the controller with the Manager.
 func (r *AgentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&aiv1alpha1.Agent{}).
+		For(&aiv1alpha1.Agent{}, builder.WithPredicates(specChanged())).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.ServiceAccount{}).
+		Owns(&corev1.Secret{}).
+		WithEventFilter(r.Namespaces.Predicate()).
 		Named("agent").
 		Complete(r)
 }
@@ -1,12 +1,19 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -14,8 +21,12 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+	"github.com/jarsater/mcp-fabric/operator/internal/metrics"
+	"github.com/jarsater/mcp-fabric/operator/internal/render"
+	"github.com/jarsater/mcp-fabric/operator/internal/secrets"
 )
 
 func newAgentTestReconciler(objs ...client.Object) *AgentReconciler {
@@ -88,6 +99,38 @@ func TestAgentReconcile_NonStandalone_SkipsAndCleansWorkload(t *testing.T) {
 	}
 }
 
+func TestAgentReconcile_UnchangedAgentIsNoOp(t *testing.T) {
+	r := newAgentTestReconciler(newWorkerAgent(ptr.To(false)))
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "code-worker", Namespace: "default"}}
+	noOps := metrics.ReconcileTotal.WithLabelValues(metrics.ControllerAgent, metrics.ResultNoOp)
+	successes := metrics.ReconcileTotal.WithLabelValues(metrics.ControllerAgent, metrics.ResultSuccess)
+
+	// The first reconcile fills in the status
+	beforeNoOps, beforeSuccesses := testutil.ToFloat64(noOps), testutil.ToFloat64(successes)
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if testutil.ToFloat64(successes)-beforeSuccesses != 1 || testutil.ToFloat64(noOps) != beforeNoOps {
+		t.Fatal("expected the first reconcile to be recorded as a success")
+	}
+
+	// The second finds nothing to change
+	var agent aiv1alpha1.Agent
+	_ = r.Get(ctx, req.NamespacedName, &agent)
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := testutil.ToFloat64(noOps) - beforeNoOps; got != 1 {
+		t.Errorf("expected the second reconcile to be recorded as a no-op, got %v", got)
+	}
+	var after aiv1alpha1.Agent
+	_ = r.Get(ctx, req.NamespacedName, &after)
+	if after.ResourceVersion != agent.ResourceVersion {
+		t.Errorf("expected no status update, resource version went from %s to %s", agent.ResourceVersion, after.ResourceVersion)
+	}
+}
+
 func TestAgentReconcile_Standalone_CreatesWorkload(t *testing.T) {
 	agent := newWorkerAgent(nil) // nil => standalone defaults to true
 
@@ -115,3 +158,369 @@ func TestAgentReconcile_Standalone_CreatesWorkload(t *testing.T) {
 		t.Error("expected standalone agent to publish an endpoint")
 	}
 }
+
+func TestAgentReconcile_ToolNamesCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		agentName  string
+		labels     map[string]string
+		tools      []aiv1alpha1.AgentTool
+		wantStatus metav1.ConditionStatus
+		wantNames  []string
+	}{
+		{
+			name:       "valid tools",
+			agentName:  "code-worker",
+			tools:      []aiv1alpha1.AgentTool{{Name: "analyze_costs"}, {Name: "list-files"}},
+			wantStatus: metav1.ConditionTrue,
+		},
+		{
+			name:       "invalid tool names",
+			agentName:  "code-worker",
+			tools:      []aiv1alpha1.AgentTool{{Name: "analyze costs"}, {Name: "ok"}, {Name: "read/file"}},
+			wantStatus: metav1.ConditionFalse,
+			wantNames:  []string{"code-worker_analyze costs", "code-worker_read/file"},
+		},
+		{
+			name:       "dotted agent name without tools",
+			agentName:  "code.worker",
+			wantStatus: metav1.ConditionFalse,
+			wantNames:  []string{"code.worker"},
+		},
+		{
+			name:       "tool group replaces a dotted agent name",
+			agentName:  "code.worker",
+			labels:     map[string]string{render.MCPToolGroupLabel: "code"},
+			tools:      []aiv1alpha1.AgentTool{{Name: "review"}},
+			wantStatus: metav1.ConditionTrue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := newWorkerAgent(ptr.To(false))
+			agent.Name = tt.agentName
+			agent.Labels = tt.labels
+			agent.Spec.Tools = tt.tools
+			r := newAgentTestReconciler(agent)
+			ctx := context.Background()
+			key := types.NamespacedName{Name: tt.agentName, Namespace: "default"}
+
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got aiv1alpha1.Agent
+			if err := r.Get(ctx, key, &got); err != nil {
+				t.Fatalf("failed to get agent: %v", err)
+			}
+			cond := meta.FindStatusCondition(got.Status.Conditions, "ToolNamesValid")
+			if cond == nil || cond.Status != tt.wantStatus {
+				t.Fatalf("expected ToolNamesValid=%s, got %+v", tt.wantStatus, cond)
+			}
+			if !reflect.DeepEqual(render.InvalidMCPToolNames(&got), tt.wantNames) {
+				t.Errorf("expected invalid names %v, got %v", tt.wantNames, render.InvalidMCPToolNames(&got))
+			}
+			for _, name := range tt.wantNames {
+				if !strings.Contains(cond.Message, name) {
+					t.Errorf("expected condition message to name %q, got %q", name, cond.Message)
+				}
+			}
+			if !got.Status.Ready {
+				t.Error("expected invalid tool names not to affect readiness")
+			}
+		})
+	}
+}
+
+func TestAgentReconcile_ExternalSecrets_SyncsSecret(t *testing.T) {
+	agent := newWorkerAgent(nil)
+	agent.Spec.ExternalSecrets = &aiv1alpha1.ExternalSecretsSpec{
+		Provider: secrets.ProviderFake,
+		Data: []aiv1alpha1.ExternalSecretData{
+			{SecretKey: "API_TOKEN", RemoteRef: "prod/agent/token"},
+			{SecretKey: "DB_PASSWORD", RemoteRef: "prod/agent/db", Property: "password"},
+		},
+	}
+
+	provider := secrets.NewFakeProvider(map[string]string{
+		"prod/agent/token": "t0ken",
+		"prod/agent/db":    `{"username":"app","password":"s3cret"}`,
+	})
+	r := newAgentTestReconciler(agent)
+	r.SecretProviders = secrets.NewRegistry()
+	r.SecretProviders.Register(secrets.ProviderFake, provider)
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "code-worker", Namespace: "default"}
+
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != defaultExternalSecretsRefresh {
+		t.Errorf("expected requeue after %v, got %v", defaultExternalSecretsRefresh, result.RequeueAfter)
+	}
+
+	secretKey := types.NamespacedName{Name: "code-worker-external-secrets", Namespace: "default"}
+	var secret corev1.Secret
+	if err := r.Get(ctx, secretKey, &secret); err != nil {
+		t.Fatalf("expected synced Secret, got err=%v", err)
+	}
+	if string(secret.Data["API_TOKEN"]) != "t0ken" {
+		t.Errorf("expected API_TOKEN t0ken, got %q", secret.Data["API_TOKEN"])
+	}
+	if string(secret.Data["DB_PASSWORD"]) != "s3cret" {
+		t.Errorf("expected DB_PASSWORD s3cret, got %q", secret.Data["DB_PASSWORD"])
+	}
+	if len(secret.OwnerReferences) != 1 || secret.OwnerReferences[0].Name != "code-worker" {
+		t.Errorf("expected Secret to be owned by the agent, got %v", secret.OwnerReferences)
+	}
+
+	var dep appsv1.Deployment
+	if err := r.Get(ctx, key, &dep); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	envFrom := dep.Spec.Template.Spec.Containers[0].EnvFrom
+	if len(envFrom) != 1 || envFrom[0].SecretRef == nil || envFrom[0].SecretRef.Name != "code-worker-external-secrets" {
+		t.Errorf("expected envFrom synced Secret, got %v", envFrom)
+	}
+	firstHash := dep.Spec.Template.Annotations["fabric.jarsater.ai/external-secrets-hash"]
+	if firstHash == "" {
+		t.Error("expected external secrets hash annotation")
+	}
+
+	// Rotating the remote value updates the Secret and rolls the pods
+	provider.Set("prod/agent/token", "r0tated")
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Get(ctx, secretKey, &secret); err != nil {
+		t.Fatalf("failed to get secret: %v", err)
+	}
+	if string(secret.Data["API_TOKEN"]) != "r0tated" {
+		t.Errorf("expected rotated API_TOKEN, got %q", secret.Data["API_TOKEN"])
+	}
+	if err := r.Get(ctx, key, &dep); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	if dep.Spec.Template.Annotations["fabric.jarsater.ai/external-secrets-hash"] == firstHash {
+		t.Error("expected external secrets hash to change after rotation")
+	}
+
+	// Removing the reference deletes the synced Secret
+	var got aiv1alpha1.Agent
+	if err := r.Get(ctx, key, &got); err != nil {
+		t.Fatalf("failed to get agent: %v", err)
+	}
+	got.Spec.ExternalSecrets = nil
+	if err := r.Update(ctx, &got); err != nil {
+		t.Fatalf("failed to update agent: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Get(ctx, secretKey, &secret); !apierrors.IsNotFound(err) {
+		t.Errorf("expected synced Secret to be deleted, got err=%v", err)
+	}
+}
+
+func TestAgentReconcile_ExternalSecrets_MissingRemoteSecret(t *testing.T) {
+	agent := newWorkerAgent(nil)
+	agent.Spec.ExternalSecrets = &aiv1alpha1.ExternalSecretsSpec{
+		Provider: secrets.ProviderFake,
+		Data:     []aiv1alpha1.ExternalSecretData{{SecretKey: "API_TOKEN", RemoteRef: "missing"}},
+	}
+
+	r := newAgentTestReconciler(agent)
+	r.SecretProviders = secrets.NewRegistry()
+	r.SecretProviders.Register(secrets.ProviderFake, secrets.NewFakeProvider(nil))
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "code-worker", Namespace: "default"}
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err == nil {
+		t.Fatal("expected error for missing remote secret")
+	}
+
+	var got aiv1alpha1.Agent
+	if err := r.Get(ctx, key, &got); err != nil {
+		t.Fatalf("failed to get agent: %v", err)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, "Ready")
+	if cond == nil || cond.Reason != "ExternalSecretsSyncFailed" {
+		t.Errorf("expected ExternalSecretsSyncFailed condition, got %+v", cond)
+	}
+	var dep appsv1.Deployment
+	if err := r.Get(ctx, key, &dep); !apierrors.IsNotFound(err) {
+		t.Errorf("expected no Deployment before secrets sync, got err=%v", err)
+	}
+}
+
+func TestAgentReconcile_ExternalSecrets_UnavailableProvider(t *testing.T) {
+	agent := newWorkerAgent(nil)
+	agent.Spec.ExternalSecrets = &aiv1alpha1.ExternalSecretsSpec{
+		Provider: secrets.ProviderAWSSecretsManager,
+		Data:     []aiv1alpha1.ExternalSecretData{{SecretKey: "API_TOKEN", RemoteRef: "prod/api-token"}},
+	}
+
+	// As in the manager, no provider is registered
+	r := newAgentTestReconciler(agent)
+	r.SecretProviders = secrets.NewRegistry()
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "code-worker", Namespace: "default"}
+
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("expected no retry for a provider the operator lacks, got %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Errorf("expected no requeue, got %v", result.RequeueAfter)
+	}
+
+	var got aiv1alpha1.Agent
+	if err := r.Get(ctx, key, &got); err != nil {
+		t.Fatalf("failed to get agent: %v", err)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, "Ready")
+	if cond == nil || cond.Reason != "ExternalSecretsProviderUnavailable" || !strings.Contains(cond.Message, secrets.ProviderAWSSecretsManager) {
+		t.Errorf("expected ExternalSecretsProviderUnavailable condition naming the provider, got %+v", cond)
+	}
+}
+
+func TestAgentReconcile_ToolCatalog(t *testing.T) {
+	worker := newWorkerAgent(ptr.To(false))
+	worker.Spec.Tools = []aiv1alpha1.AgentTool{{Name: "write_code", Description: "Writes code"}}
+
+	searcher := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "searcher", Namespace: "apps"},
+		Spec: aiv1alpha1.AgentSpec{
+			Model: aiv1alpha1.ModelConfig{Provider: "anthropic", ModelID: "claude-sonnet-4"},
+		},
+		Status: aiv1alpha1.AgentStatus{
+			Ready:    true,
+			Endpoint: "searcher.apps.svc.cluster.local:8080",
+			AvailableTools: []aiv1alpha1.AgentTool{{
+				Name:        "search",
+				Description: "Searches the web",
+				InputSchema: &apiextensionsv1.JSON{Raw: []byte(`{"type":"object"}`)},
+			}},
+		},
+	}
+	notReady := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "starting", Namespace: "apps"},
+		Spec: aiv1alpha1.AgentSpec{
+			Model: aiv1alpha1.ModelConfig{Provider: "anthropic", ModelID: "claude-sonnet-4"},
+			Tools: []aiv1alpha1.AgentTool{{Name: "pending", Description: "Not ready yet"}},
+		},
+	}
+
+	r := newAgentTestReconciler(worker, searcher, notReady)
+	r.CatalogNamespace = "discovery"
+	ctx := context.Background()
+
+	readCatalog := func() render.ToolCatalog {
+		t.Helper()
+		var cm corev1.ConfigMap
+		if err := r.Get(ctx, types.NamespacedName{Name: render.ToolCatalogConfigMapName, Namespace: "discovery"}, &cm); err != nil {
+			t.Fatalf("failed to get tool catalog: %v", err)
+		}
+		var catalog render.ToolCatalog
+		if err := json.Unmarshal([]byte(cm.Data[render.ToolCatalogFileName]), &catalog); err != nil {
+			t.Fatalf("invalid tool catalog: %v", err)
+		}
+		return catalog
+	}
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "code-worker", Namespace: "default"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []render.ToolCatalogAgent{
+		{
+			Name:      "searcher",
+			Namespace: "apps",
+			Endpoint:  "searcher.apps.svc.cluster.local:8080",
+			Provider:  "anthropic",
+			ModelID:   "claude-sonnet-4",
+			Tools:     []render.ToolCatalogTool{{Name: "search", Description: "Searches the web"}},
+		},
+		{
+			Name:      "code-worker",
+			Namespace: "default",
+			Provider:  "bedrock",
+			ModelID:   "amazon.nova-lite-v1:0",
+			Tools:     []render.ToolCatalogTool{{Name: "write_code", Description: "Writes code"}},
+		},
+	}
+	got := readCatalog().Agents
+	if len(got) > 0 && len(got[0].Tools) > 0 {
+		// Schemas are re-indented in the catalog, so compare them compacted
+		var schema bytes.Buffer
+		if schemaJSON := got[0].Tools[0].InputSchema; schemaJSON == nil || json.Compact(&schema, schemaJSON.Raw) != nil || schema.String() != `{"type":"object"}` {
+			t.Errorf("expected search input schema, got %v", schemaJSON)
+		}
+		got[0].Tools[0].InputSchema = nil
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected catalog:\n got %+v\nwant %+v", got, want)
+	}
+
+	// Deleting an agent drops it from the catalog
+	if err := r.Delete(ctx, searcher); err != nil {
+		t.Fatalf("failed to delete agent: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "searcher", Namespace: "apps"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := readCatalog().Agents; len(got) != 1 || got[0].Name != "code-worker" {
+		t.Errorf("expected only code-worker after deletion, got %+v", got)
+	}
+}
+
+func TestAgentReconcile_ToolCatalogUsesFreshStatus(t *testing.T) {
+	worker := newWorkerAgent(ptr.To(false))
+	worker.Spec.Tools = []aiv1alpha1.AgentTool{{Name: "write_code", Description: "Writes code"}}
+
+	scheme := runtime.NewScheme()
+	_ = aiv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	// Listing serves Agents as a cache that has not seen the status written
+	// in this reconcile would
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(worker).
+		WithStatusSubresource(&aiv1alpha1.Agent{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if err := c.List(ctx, list, opts...); err != nil {
+					return err
+				}
+				if agents, ok := list.(*aiv1alpha1.AgentList); ok {
+					for i := range agents.Items {
+						agents.Items[i].Status = aiv1alpha1.AgentStatus{}
+					}
+				}
+				return nil
+			},
+		}).
+		Build()
+	r := &AgentReconciler{Client: fakeClient, Scheme: scheme, CatalogNamespace: "discovery"}
+	ctx := context.Background()
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "code-worker", Namespace: "default"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var cm corev1.ConfigMap
+	if err := r.Get(ctx, types.NamespacedName{Name: render.ToolCatalogConfigMapName, Namespace: "discovery"}, &cm); err != nil {
+		t.Fatalf("failed to get tool catalog: %v", err)
+	}
+	var catalog render.ToolCatalog
+	if err := json.Unmarshal([]byte(cm.Data[render.ToolCatalogFileName]), &catalog); err != nil {
+		t.Fatalf("invalid tool catalog: %v", err)
+	}
+	if len(catalog.Agents) != 1 || catalog.Agents[0].Name != "code-worker" {
+		t.Errorf("expected the agent that just became ready in the catalog, got %+v", catalog.Agents)
+	}
+}
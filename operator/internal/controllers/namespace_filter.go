@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// NamespaceFilter scopes a controller to a set of namespaces. Objects in a
+// Deny namespace are ignored; when Allow is non-empty, so are objects outside
+// it. The zero value manages every namespace.
+type NamespaceFilter struct {
+	Allow []string
+	Deny  []string
+}
+
+// ParseNamespaceList splits a comma-separated flag value into namespaces,
+// dropping blanks.
+func ParseNamespaceList(value string) []string {
+	var namespaces []string
+	for _, ns := range strings.Split(value, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// Allows reports whether objects in namespace are in scope.
+func (f NamespaceFilter) Allows(namespace string) bool {
+	if slices.Contains(f.Deny, namespace) {
+		return false
+	}
+	return len(f.Allow) == 0 || slices.Contains(f.Allow, namespace)
+}
+
+// Predicate filters watch events to in-scope objects. Cluster-scoped
+// Namespace objects are matched by their own name, so a watched Namespace
+// only triggers reconciles when it is itself in scope.
+func (f NamespaceFilter) Predicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		namespace := obj.GetNamespace()
+		if namespace == "" {
+			namespace = obj.GetName()
+		}
+		return f.Allows(namespace)
+	})
+}
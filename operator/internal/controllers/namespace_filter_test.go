@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+func TestParseNamespaceList(t *testing.T) {
+	got := ParseNamespaceList(" team-a, ,team-b,")
+	want := []string{"team-a", "team-b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseNamespaceList() = %v, want %v", got, want)
+	}
+	if got := ParseNamespaceList(""); got != nil {
+		t.Errorf("ParseNamespaceList(\"\") = %v, want nil", got)
+	}
+}
+
+func TestNamespaceFilter_Allows(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    NamespaceFilter
+		namespace string
+		want      bool
+	}{
+		{name: "zero value allows all", namespace: "team-a", want: true},
+		{name: "allowed", filter: NamespaceFilter{Allow: []string{"team-a"}}, namespace: "team-a", want: true},
+		{name: "not in allow list", filter: NamespaceFilter{Allow: []string{"team-a"}}, namespace: "team-b", want: false},
+		{name: "denied", filter: NamespaceFilter{Deny: []string{"team-b"}}, namespace: "team-b", want: false},
+		{name: "not denied", filter: NamespaceFilter{Deny: []string{"team-b"}}, namespace: "team-a", want: true},
+		{name: "deny wins over allow", filter: NamespaceFilter{Allow: []string{"team-a"}, Deny: []string{"team-a"}}, namespace: "team-a", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Allows(tt.namespace); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.namespace, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamespaceFilter_PredicateIgnoresOutOfScopeObjects(t *testing.T) {
+	p := NamespaceFilter{Allow: []string{"team-a", "team-b"}, Deny: []string{"team-b"}}.Predicate()
+
+	inScope := &aiv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: "team-a"}}
+	for _, obj := range []*aiv1alpha1.Task{
+		{ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: "team-b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: "team-c"}},
+	} {
+		if p.Create(event.CreateEvent{Object: obj}) {
+			t.Errorf("Create in %s passed the filter", obj.Namespace)
+		}
+		if p.Update(event.UpdateEvent{ObjectOld: obj, ObjectNew: obj}) {
+			t.Errorf("Update in %s passed the filter", obj.Namespace)
+		}
+		if p.Delete(event.DeleteEvent{Object: obj}) {
+			t.Errorf("Delete in %s passed the filter", obj.Namespace)
+		}
+		if p.Generic(event.GenericEvent{Object: obj}) {
+			t.Errorf("Generic in %s passed the filter", obj.Namespace)
+		}
+	}
+
+	if !p.Create(event.CreateEvent{Object: inScope}) || !p.Update(event.UpdateEvent{ObjectOld: inScope, ObjectNew: inScope}) {
+		t.Error("in-scope Task was filtered out")
+	}
+
+	agent := &aiv1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "team-c"}}
+	if p.Create(event.CreateEvent{Object: agent}) {
+		t.Error("out-of-scope Agent passed the filter")
+	}
+}
+
+func TestNamespaceFilter_PredicateMatchesNamespaceByName(t *testing.T) {
+	p := NamespaceFilter{Allow: []string{"team-a"}}.Predicate()
+
+	in := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}
+	out := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}}
+	if !p.Update(event.UpdateEvent{ObjectOld: in, ObjectNew: in}) {
+		t.Error("in-scope Namespace was filtered out")
+	}
+	if p.Update(event.UpdateEvent{ObjectOld: out, ObjectNew: out}) {
+		t.Error("out-of-scope Namespace passed the filter")
+	}
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// specChanged filters a controller's primary resource to events that can
+// change what it reconciles: creates, deletes, spec changes (a new
+// generation) and the start of deletion. Updates touching only status or
+// metadata -- including the controller's own status and annotation writes --
+// are dropped so they do not trigger another reconcile. Reconcilers must
+// not rely on their own writes to run again: Task phases that continue
+// after a status write requeue explicitly, and the Agent controller builds
+// the tool catalog from the Agent it just updated rather than the cache.
+func specChanged() predicate.Predicate {
+	return predicate.Or(predicate.GenerationChangedPredicate{}, predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return false
+			}
+			return e.ObjectOld.GetDeletionTimestamp() == nil && e.ObjectNew.GetDeletionTimestamp() != nil
+		},
+	})
+}
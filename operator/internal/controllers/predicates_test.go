@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+func TestSpecChanged_Update(t *testing.T) {
+	old := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: "default", Generation: 1},
+		Status:     aiv1alpha1.TaskStatus{Phase: aiv1alpha1.TaskPhaseRunning},
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*aiv1alpha1.Task)
+		want   bool
+	}{
+		{
+			name: "status only",
+			mutate: func(task *aiv1alpha1.Task) {
+				task.Status.Phase = aiv1alpha1.TaskPhaseCompleted
+				task.Status.CompletedTasks = 3
+			},
+			want: false,
+		},
+		{
+			name: "annotation only",
+			mutate: func(task *aiv1alpha1.Task) {
+				task.Annotations = map[string]string{jobRecreationAnnotation: "1"}
+			},
+			want: false,
+		},
+		{
+			name: "finalizer added",
+			mutate: func(task *aiv1alpha1.Task) {
+				task.Finalizers = []string{taskFinalizer}
+			},
+			want: false,
+		},
+		{
+			name: "spec change",
+			mutate: func(task *aiv1alpha1.Task) {
+				task.Spec.Paused = true
+				task.Generation = 2
+			},
+			want: true,
+		},
+		{
+			name: "deletion started",
+			mutate: func(task *aiv1alpha1.Task) {
+				now := metav1.Now()
+				task.DeletionTimestamp = &now
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := old.DeepCopy()
+			tt.mutate(updated)
+			if got := specChanged().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated}); got != tt.want {
+				t.Errorf("Update() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpecChanged_AgentStatusSelfUpdate(t *testing.T) {
+	old := &aiv1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default", Generation: 4}}
+	updated := old.DeepCopy()
+	updated.Status.Ready = true
+	updated.Status.Endpoint = "http://agent.default.svc:8080"
+
+	if specChanged().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated}) {
+		t.Error("status-only Agent update triggered a reconcile")
+	}
+}
+
+func TestSpecChanged_CreateAndDelete(t *testing.T) {
+	tool := &aiv1alpha1.Tool{ObjectMeta: metav1.ObjectMeta{Name: "tool", Namespace: "default"}}
+
+	if !specChanged().Create(event.CreateEvent{Object: tool}) {
+		t.Error("Create was filtered out")
+	}
+	if !specChanged().Delete(event.DeleteEvent{Object: tool}) {
+		t.Error("Delete was filtered out")
+	}
+}
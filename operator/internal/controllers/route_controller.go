@@ -15,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -26,6 +27,10 @@ type RouteReconciler struct {
 	client.Client
 	Scheme           *runtime.Scheme
 	GatewayNamespace string // Namespace where gateway routes ConfigMap is created
+
+	// NotReadyGracePeriod is how long a lost backend may take to recover
+	// before the Route is marked not ready. Zero flips it immediately.
+	NotReadyGracePeriod time.Duration
 }
 
 // +kubebuilder:rbac:groups=fabric.jarsater.ai,resources=routes,verbs=get;list;watch;create;update;patch;delete
@@ -52,7 +57,9 @@ func (r *RouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	logger.Info("Reconciling Route", "name", route.Name)
 
 	// Resolve all backend agents
+	now := metav1.Now()
 	backends, allReady := r.resolveBackends(ctx, &route)
+	trackLastReady(backends, route.Status.Backends, now)
 	route.Status.Backends = backends
 
 	// Compile routing config
@@ -95,6 +102,13 @@ func (r *RouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	route.Status.ObservedGeneration = route.Generation
 	route.Status.Ready = allReady
 
+	// Lost backends within the grace period keep the Route ready; recheck
+	// once the earliest of them runs out of it
+	var graceRemaining time.Duration
+	if !allReady {
+		graceRemaining = r.notReadyGraceRemaining(backends, now.Time)
+	}
+
 	if allReady {
 		r.setCondition(&route, metav1.Condition{
 			Type:               "Ready",
@@ -103,6 +117,15 @@ func (r *RouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			Reason:             "AllBackendsReady",
 			Message:            "All backend agents are ready",
 		})
+	} else if graceRemaining > 0 {
+		route.Status.Ready = true
+		r.setCondition(&route, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: route.Generation,
+			Reason:             "BackendsRecovering",
+			Message:            "Some backend agents are not ready but still within the grace period",
+		})
 	} else {
 		r.setCondition(&route, metav1.Condition{
 			Type:               "Ready",
@@ -138,7 +161,58 @@ func (r *RouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	metrics.RecordReconcile(metrics.ControllerRoute, metrics.ResultSuccess, time.Since(startTime).Seconds())
 
 	logger.Info("Route reconciled", "name", route.Name, "rules", route.Status.ActiveRules, "ready", route.Status.Ready)
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: graceRemaining}, nil
+}
+
+// trackLastReady sets each backend's LastReadyTime from its previous status:
+// backends that became ready or were just lost are stamped with now, the
+// rest keep their previous time.
+func trackLastReady(backends, previous []aiv1alpha1.BackendStatus, now metav1.Time) {
+	prev := make(map[aiv1alpha1.AgentRef]aiv1alpha1.BackendStatus, len(previous))
+	for _, b := range previous {
+		prev[b.AgentRef] = b
+	}
+
+	for i := range backends {
+		b := &backends[i]
+		old, found := prev[b.AgentRef]
+		switch {
+		case b.Ready && found && old.Ready && old.LastReadyTime != nil:
+			b.LastReadyTime = old.LastReadyTime
+		case b.Ready || (found && old.Ready):
+			stamp := now
+			b.LastReadyTime = &stamp
+		case found:
+			b.LastReadyTime = old.LastReadyTime
+		}
+	}
+}
+
+// notReadyGraceRemaining returns how long until the first not-ready backend
+// runs out of the grace period, or zero if any already has, or never was
+// ready.
+func (r *RouteReconciler) notReadyGraceRemaining(backends []aiv1alpha1.BackendStatus, now time.Time) time.Duration {
+	if r.NotReadyGracePeriod <= 0 {
+		return 0
+	}
+
+	var remaining time.Duration
+	for _, b := range backends {
+		if b.Ready {
+			continue
+		}
+		if b.LastReadyTime == nil {
+			return 0
+		}
+		left := b.LastReadyTime.Add(r.NotReadyGracePeriod).Sub(now)
+		if left <= 0 {
+			return 0
+		}
+		if remaining == 0 || left < remaining {
+			remaining = left
+		}
+	}
+	return remaining
 }
 
 // resolveBackends fetches all referenced agents and returns their status.
@@ -178,6 +252,8 @@ func (r *RouteReconciler) resolveBackends(ctx context.Context, route *aiv1alpha1
 			} else {
 				status.Ready = agent.Status.Ready
 				status.Endpoint = agent.Status.Endpoint
+				status.Zone = agentZone(&agent)
+				status.MaxConcurrentRequests = agentMaxConcurrentRequests(&agent)
 				if !agent.Status.Ready {
 					allReady = false
 				}
@@ -213,6 +289,8 @@ func (r *RouteReconciler) resolveBackends(ctx context.Context, route *aiv1alpha1
 			} else {
 				status.Ready = agent.Status.Ready
 				status.Endpoint = agent.Status.Endpoint
+				status.Zone = agentZone(&agent)
+				status.MaxConcurrentRequests = agentMaxConcurrentRequests(&agent)
 				if !agent.Status.Ready {
 					allReady = false
 				}
@@ -225,6 +303,24 @@ func (r *RouteReconciler) resolveBackends(ctx context.Context, route *aiv1alpha1
 	return backends, allReady
 }
 
+// agentZone returns the topology zone an agent is pinned to. An explicit
+// zone label on the Agent wins over its pod node selector.
+func agentZone(agent *aiv1alpha1.Agent) string {
+	if zone := agent.Labels[corev1.LabelTopologyZone]; zone != "" {
+		return zone
+	}
+	return agent.Spec.NodeSelector[corev1.LabelTopologyZone]
+}
+
+// agentMaxConcurrentRequests returns the agent's concurrency cap, or zero
+// when its policy does not set one.
+func agentMaxConcurrentRequests(agent *aiv1alpha1.Agent) int32 {
+	if agent.Spec.Policy == nil || agent.Spec.Policy.MaxConcurrentRequests == nil {
+		return 0
+	}
+	return *agent.Spec.Policy.MaxConcurrentRequests
+}
+
 // compileRouteConfig transforms Route into the gateway-consumable format.
 func (r *RouteReconciler) compileRouteConfig(route *aiv1alpha1.Route, backends []aiv1alpha1.BackendStatus) *render.RouteConfig {
 	// Create a lookup map for backend status
@@ -249,12 +345,23 @@ func (r *RouteReconciler) compileRouteConfig(route *aiv1alpha1.Route, backends [
 				TenantID:    rule.Match.TenantID,
 				Headers:     rule.Match.Headers,
 			},
-			Backends: make([]render.CompiledRouteBackend, 0, len(rule.Backends)),
+			Backends:          make([]render.CompiledRouteBackend, 0, len(rule.Backends)),
+			SelectionStrategy: string(rule.SelectionStrategy),
+			Mode:              string(rule.Mode),
 		}
 
 		if rule.Priority != nil {
 			compiled.Priority = *rule.Priority
 		}
+		if rule.QueueTimeout != nil {
+			compiled.QueueTimeoutMs = rule.QueueTimeout.Milliseconds()
+		}
+		if rule.FanOutConcurrency != nil {
+			compiled.FanOutConcurrency = *rule.FanOutConcurrency
+		}
+		if rule.HedgeAfter != nil {
+			compiled.HedgeAfterMs = rule.HedgeAfter.Milliseconds()
+		}
 
 		for _, backend := range rule.Backends {
 			ns := backend.AgentRef.Namespace
@@ -270,11 +377,13 @@ func (r *RouteReconciler) compileRouteConfig(route *aiv1alpha1.Route, backends [
 			}
 
 			compiled.Backends = append(compiled.Backends, render.CompiledRouteBackend{
-				AgentName: backend.AgentRef.Name,
-				Namespace: ns,
-				Endpoint:  status.Endpoint,
-				Weight:    weight,
-				Ready:     status.Ready,
+				AgentName:             backend.AgentRef.Name,
+				Namespace:             ns,
+				Endpoint:              status.Endpoint,
+				Weight:                weight,
+				Ready:                 status.Ready,
+				Zone:                  status.Zone,
+				MaxConcurrentRequests: status.MaxConcurrentRequests,
 			})
 		}
 
@@ -310,12 +419,44 @@ func (r *RouteReconciler) compileRouteConfig(route *aiv1alpha1.Route, backends [
 			if cb.RequestTimeout != nil {
 				defaults.RequestTimeoutMs = cb.RequestTimeout.Milliseconds()
 			}
+			if cb.ErrorThresholdPercent != nil {
+				defaults.ErrorThresholdPercent = *cb.ErrorThresholdPercent
+			}
+			if cb.MinimumRequests != nil {
+				defaults.MinimumRequests = *cb.MinimumRequests
+			}
+			if cb.ErrorWindow != nil {
+				defaults.ErrorWindowMs = cb.ErrorWindow.Milliseconds()
+			}
+			if cb.Cooldown != nil {
+				defaults.CooldownMs = cb.Cooldown.Milliseconds()
+			}
 		}
 
 		if route.Spec.Defaults.RejectUnmatched != nil {
 			defaults.RejectUnmatched = *route.Spec.Defaults.RejectUnmatched
 		}
 
+		if route.Spec.Defaults.LocalityAware != nil {
+			defaults.LocalityAware = *route.Spec.Defaults.LocalityAware
+		}
+
+		defaults.SelectionStrategy = string(route.Spec.Defaults.SelectionStrategy)
+
+		if route.Spec.Defaults.MaxRetries != nil {
+			defaults.MaxRetries = *route.Spec.Defaults.MaxRetries
+		}
+		if route.Spec.Defaults.RetryBackoff != nil {
+			defaults.RetryBackoffMs = route.Spec.Defaults.RetryBackoff.Milliseconds()
+		}
+		defaults.ForwardHeaders = route.Spec.Defaults.ForwardHeaders
+		if route.Spec.Defaults.OutlierConsecutiveErrors != nil {
+			defaults.OutlierConsecutiveErrors = *route.Spec.Defaults.OutlierConsecutiveErrors
+		}
+		if route.Spec.Defaults.OutlierEjection != nil {
+			defaults.OutlierEjectionMs = route.Spec.Defaults.OutlierEjection.Milliseconds()
+		}
+
 		if route.Spec.Defaults.Backend != nil {
 			ref := route.Spec.Defaults.Backend.AgentRef
 			ns := ref.Namespace
@@ -331,11 +472,13 @@ func (r *RouteReconciler) compileRouteConfig(route *aiv1alpha1.Route, backends [
 			}
 
 			defaults.Backend = &render.CompiledRouteBackend{
-				AgentName: ref.Name,
-				Namespace: ns,
-				Endpoint:  status.Endpoint,
-				Weight:    weight,
-				Ready:     status.Ready,
+				AgentName:             ref.Name,
+				Namespace:             ns,
+				Endpoint:              status.Endpoint,
+				Weight:                weight,
+				Ready:                 status.Ready,
+				Zone:                  status.Zone,
+				MaxConcurrentRequests: status.MaxConcurrentRequests,
 			}
 		}
 
@@ -373,8 +516,10 @@ func (r *RouteReconciler) setCondition(route *aiv1alpha1.Route, condition metav1
 // SetupWithManager sets up the controller with the Manager.
 func (r *RouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&aiv1alpha1.Route{}).
-		// Watch Agent resources and reconcile Routes that reference them
+		For(&aiv1alpha1.Route{}, builder.WithPredicates(specChanged())).
+		// Watch Agent resources and reconcile Routes that reference them.
+		// Unfiltered: Route readiness and endpoints follow Agent status, and
+		// zone-aware routing follows Agent labels.
 		Watches(
 			&aiv1alpha1.Agent{},
 			handler.EnqueueRequestsFromMapFunc(r.findRoutesForAgent),
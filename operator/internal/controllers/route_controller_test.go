@@ -0,0 +1,390 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+func newRouteTestReconciler(objs ...client.Object) *RouteReconciler {
+	scheme := newTestScheme()
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&aiv1alpha1.Route{}).
+		Build()
+
+	return &RouteReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+	}
+}
+
+func newZonedAgent(name string, labels, nodeSelector map[string]string) *aiv1alpha1.Agent {
+	return &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    labels,
+		},
+		Spec: aiv1alpha1.AgentSpec{
+			NodeSelector: nodeSelector,
+		},
+		Status: aiv1alpha1.AgentStatus{
+			Ready:    true,
+			Endpoint: "http://" + name + ".default.svc:8080",
+		},
+	}
+}
+
+func TestCompileRouteConfig_SurfacesZones(t *testing.T) {
+	labelled := newZonedAgent("labelled", map[string]string{corev1.LabelTopologyZone: "eu-west-1a"},
+		map[string]string{corev1.LabelTopologyZone: "eu-west-1c"})
+	pinned := newZonedAgent("pinned", nil, map[string]string{corev1.LabelTopologyZone: "eu-west-1b"})
+	floating := newZonedAgent("floating", nil, nil)
+
+	localityAware := true
+	route := &aiv1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: "default"},
+		Spec: aiv1alpha1.RouteSpec{
+			Rules: []aiv1alpha1.RouteRule{{
+				Name:  "all",
+				Match: aiv1alpha1.RouteMatch{Agent: "labelled"},
+				Backends: []aiv1alpha1.RouteBackend{
+					{AgentRef: aiv1alpha1.AgentRef{Name: "labelled"}},
+					{AgentRef: aiv1alpha1.AgentRef{Name: "pinned"}},
+					{AgentRef: aiv1alpha1.AgentRef{Name: "floating"}},
+				},
+			}},
+			Defaults: &aiv1alpha1.RouteDefaults{LocalityAware: &localityAware},
+		},
+	}
+
+	r := newRouteTestReconciler(labelled, pinned, floating)
+	backends, _ := r.resolveBackends(context.Background(), route)
+	config := r.compileRouteConfig(route, backends)
+
+	if config.Defaults == nil || !config.Defaults.LocalityAware {
+		t.Errorf("expected localityAware defaults, got %+v", config.Defaults)
+	}
+
+	want := map[string]string{"labelled": "eu-west-1a", "pinned": "eu-west-1b", "floating": ""}
+	for _, b := range config.Rules[0].Backends {
+		if b.Zone != want[b.AgentName] {
+			t.Errorf("backend %s: expected zone %q, got %q", b.AgentName, want[b.AgentName], b.Zone)
+		}
+	}
+}
+
+func TestCompileRouteConfig_SelectionStrategy(t *testing.T) {
+	agent := newZonedAgent("echo", nil, nil)
+	route := &aiv1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: "default"},
+		Spec: aiv1alpha1.RouteSpec{
+			Rules: []aiv1alpha1.RouteRule{
+				{
+					Name:              "pinned",
+					Match:             aiv1alpha1.RouteMatch{Agent: "echo"},
+					Backends:          []aiv1alpha1.RouteBackend{{AgentRef: aiv1alpha1.AgentRef{Name: "echo"}}},
+					SelectionStrategy: aiv1alpha1.SelectionStrategyLeastConnections,
+				},
+				{
+					Name:     "inherited",
+					Match:    aiv1alpha1.RouteMatch{IntentRegex: ".*"},
+					Backends: []aiv1alpha1.RouteBackend{{AgentRef: aiv1alpha1.AgentRef{Name: "echo"}}},
+				},
+			},
+			Defaults: &aiv1alpha1.RouteDefaults{SelectionStrategy: aiv1alpha1.SelectionStrategyRoundRobin},
+		},
+	}
+
+	r := newRouteTestReconciler(agent)
+	backends, _ := r.resolveBackends(context.Background(), route)
+	config := r.compileRouteConfig(route, backends)
+
+	if config.Defaults.SelectionStrategy != "round-robin" {
+		t.Errorf("expected default strategy round-robin, got %q", config.Defaults.SelectionStrategy)
+	}
+	strategies := map[string]string{}
+	for _, rule := range config.Rules {
+		strategies[rule.Name] = rule.SelectionStrategy
+	}
+	if strategies["pinned"] != "least-connections" {
+		t.Errorf("expected rule strategy least-connections, got %q", strategies["pinned"])
+	}
+	if strategies["inherited"] != "" {
+		t.Errorf("expected rule without strategy to inherit defaults, got %q", strategies["inherited"])
+	}
+}
+
+func TestCompileRouteConfig_MaxConcurrentRequests(t *testing.T) {
+	capped := newZonedAgent("capped", nil, nil)
+	limit := int32(4)
+	capped.Spec.Policy = &aiv1alpha1.AgentPolicy{MaxConcurrentRequests: &limit}
+	uncapped := newZonedAgent("uncapped", nil, nil)
+
+	route := &aiv1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: "default"},
+		Spec: aiv1alpha1.RouteSpec{
+			Rules: []aiv1alpha1.RouteRule{{
+				Name:  "all",
+				Match: aiv1alpha1.RouteMatch{Agent: "capped"},
+				Backends: []aiv1alpha1.RouteBackend{
+					{AgentRef: aiv1alpha1.AgentRef{Name: "capped"}},
+					{AgentRef: aiv1alpha1.AgentRef{Name: "uncapped"}},
+				},
+			}},
+			Defaults: &aiv1alpha1.RouteDefaults{
+				Backend: &aiv1alpha1.RouteBackend{AgentRef: aiv1alpha1.AgentRef{Name: "capped"}},
+			},
+		},
+	}
+
+	r := newRouteTestReconciler(capped, uncapped)
+	backends, _ := r.resolveBackends(context.Background(), route)
+	config := r.compileRouteConfig(route, backends)
+
+	want := map[string]int32{"capped": 4, "uncapped": 0}
+	for _, b := range config.Rules[0].Backends {
+		if b.MaxConcurrentRequests != want[b.AgentName] {
+			t.Errorf("backend %s: expected cap %d, got %d", b.AgentName, want[b.AgentName], b.MaxConcurrentRequests)
+		}
+	}
+	if got := config.Defaults.Backend.MaxConcurrentRequests; got != 4 {
+		t.Errorf("expected default backend cap 4, got %d", got)
+	}
+}
+
+func TestCompileRouteConfig_RuleQueueTimeout(t *testing.T) {
+	agent := newZonedAgent("echo", nil, nil)
+	route := &aiv1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: "default"},
+		Spec: aiv1alpha1.RouteSpec{
+			Rules: []aiv1alpha1.RouteRule{
+				{
+					Name:         "interactive",
+					Match:        aiv1alpha1.RouteMatch{Agent: "echo"},
+					Backends:     []aiv1alpha1.RouteBackend{{AgentRef: aiv1alpha1.AgentRef{Name: "echo"}}},
+					QueueTimeout: &metav1.Duration{Duration: 500 * time.Millisecond},
+				},
+				{
+					Name:     "inherited",
+					Match:    aiv1alpha1.RouteMatch{IntentRegex: ".*"},
+					Backends: []aiv1alpha1.RouteBackend{{AgentRef: aiv1alpha1.AgentRef{Name: "echo"}}},
+				},
+			},
+		},
+	}
+
+	r := newRouteTestReconciler(agent)
+	backends, _ := r.resolveBackends(context.Background(), route)
+	config := r.compileRouteConfig(route, backends)
+
+	timeouts := map[string]int64{}
+	for _, rule := range config.Rules {
+		timeouts[rule.Name] = rule.QueueTimeoutMs
+	}
+	if timeouts["interactive"] != 500 {
+		t.Errorf("expected rule queue timeout 500ms, got %d", timeouts["interactive"])
+	}
+	if timeouts["inherited"] != 0 {
+		t.Errorf("expected rule without queue timeout to inherit defaults, got %d", timeouts["inherited"])
+	}
+}
+
+func TestCompileRouteConfig_FanOut(t *testing.T) {
+	agent := newZonedAgent("echo", nil, nil)
+	concurrency := int32(2)
+	route := &aiv1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: "default"},
+		Spec: aiv1alpha1.RouteSpec{
+			Rules: []aiv1alpha1.RouteRule{{
+				Name:              "survey",
+				Match:             aiv1alpha1.RouteMatch{IntentRegex: "survey"},
+				Backends:          []aiv1alpha1.RouteBackend{{AgentRef: aiv1alpha1.AgentRef{Name: "echo"}}},
+				Mode:              aiv1alpha1.RouteModeFanOut,
+				FanOutConcurrency: &concurrency,
+			}},
+		},
+	}
+
+	r := newRouteTestReconciler(agent)
+	backends, _ := r.resolveBackends(context.Background(), route)
+	config := r.compileRouteConfig(route, backends)
+
+	rule := config.Rules[0]
+	if rule.Mode != "fan-out" || rule.FanOutConcurrency != 2 {
+		t.Errorf("expected fan-out with concurrency 2, got mode %q concurrency %d", rule.Mode, rule.FanOutConcurrency)
+	}
+}
+
+func TestCompileRouteConfig_HedgeAfter(t *testing.T) {
+	agent := newZonedAgent("echo", nil, nil)
+	route := &aiv1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: "default"},
+		Spec: aiv1alpha1.RouteSpec{
+			Rules: []aiv1alpha1.RouteRule{
+				{
+					Name:       "interactive",
+					Match:      aiv1alpha1.RouteMatch{IntentRegex: "chat"},
+					Backends:   []aiv1alpha1.RouteBackend{{AgentRef: aiv1alpha1.AgentRef{Name: "echo"}}},
+					HedgeAfter: &metav1.Duration{Duration: 250 * time.Millisecond},
+				},
+				{
+					Name:     "batch",
+					Match:    aiv1alpha1.RouteMatch{IntentRegex: "report"},
+					Backends: []aiv1alpha1.RouteBackend{{AgentRef: aiv1alpha1.AgentRef{Name: "echo"}}},
+				},
+			},
+		},
+	}
+
+	r := newRouteTestReconciler(agent)
+	backends, _ := r.resolveBackends(context.Background(), route)
+	config := r.compileRouteConfig(route, backends)
+
+	hedges := map[string]int64{}
+	for _, rule := range config.Rules {
+		hedges[rule.Name] = rule.HedgeAfterMs
+	}
+	if hedges["interactive"] != 250 || hedges["batch"] != 0 {
+		t.Errorf("expected hedging after 250ms on interactive only, got %v", hedges)
+	}
+}
+
+func TestReconcile_RouteGracePeriodOnBackendLoss(t *testing.T) {
+	ctx := context.Background()
+	agent := newZonedAgent("echo", nil, nil)
+	route := &aiv1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: "default"},
+		Spec: aiv1alpha1.RouteSpec{
+			Rules: []aiv1alpha1.RouteRule{{
+				Name:     "echo",
+				Match:    aiv1alpha1.RouteMatch{Agent: "echo"},
+				Backends: []aiv1alpha1.RouteBackend{{AgentRef: aiv1alpha1.AgentRef{Name: "echo"}}},
+			}},
+		},
+	}
+	r := newRouteTestReconciler(agent, route)
+	r.NotReadyGracePeriod = time.Minute
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "routes", Namespace: "default"}}
+
+	setAgentReady := func(ready bool) {
+		t.Helper()
+		var current aiv1alpha1.Agent
+		if err := r.Get(ctx, client.ObjectKeyFromObject(agent), &current); err != nil {
+			t.Fatalf("failed to get agent: %v", err)
+		}
+		current.Status.Ready = ready
+		if err := r.Update(ctx, &current); err != nil {
+			t.Fatalf("failed to update agent: %v", err)
+		}
+	}
+	reconcileRoute := func(wantReady bool, wantReason string) (ctrl.Result, *aiv1alpha1.Route) {
+		t.Helper()
+		result, err := r.Reconcile(ctx, req)
+		if err != nil {
+			t.Fatalf("reconcile failed: %v", err)
+		}
+		var got aiv1alpha1.Route
+		if err := r.Get(ctx, req.NamespacedName, &got); err != nil {
+			t.Fatalf("failed to get route: %v", err)
+		}
+		cond := meta.FindStatusCondition(got.Status.Conditions, "Ready")
+		if got.Status.Ready != wantReady || cond == nil || cond.Reason != wantReason {
+			t.Fatalf("expected ready=%v with reason %s, got ready=%v condition %+v", wantReady, wantReason, got.Status.Ready, cond)
+		}
+		return result, &got
+	}
+
+	_, got := reconcileRoute(true, "AllBackendsReady")
+	if got.Status.Backends[0].LastReadyTime == nil {
+		t.Fatal("expected LastReadyTime on a ready backend")
+	}
+
+	// A transient loss keeps the Route ready and rechecks within the window
+	setAgentReady(false)
+	result, _ := reconcileRoute(true, "BackendsRecovering")
+	if result.RequeueAfter <= 0 || result.RequeueAfter > time.Minute {
+		t.Errorf("expected a requeue within the grace period, got %s", result.RequeueAfter)
+	}
+
+	setAgentReady(true)
+	if result, _ := reconcileRoute(true, "AllBackendsReady"); result.RequeueAfter != 0 {
+		t.Errorf("expected no requeue once recovered, got %s", result.RequeueAfter)
+	}
+
+	// A loss outlasting the window flips the Route
+	setAgentReady(false)
+	_, got = reconcileRoute(true, "BackendsRecovering")
+	lostAt := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	got.Status.Backends[0].LastReadyTime = &lostAt
+	if err := r.Status().Update(ctx, got); err != nil {
+		t.Fatalf("failed to age the backend loss: %v", err)
+	}
+	reconcileRoute(false, "BackendsNotReady")
+}
+
+func TestReconcile_RouteWithoutGracePeriodFlipsImmediately(t *testing.T) {
+	agent := newZonedAgent("echo", nil, nil)
+	agent.Status.Ready = false
+	route := &aiv1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: "default"},
+		Spec: aiv1alpha1.RouteSpec{
+			Rules: []aiv1alpha1.RouteRule{{
+				Name:     "echo",
+				Backends: []aiv1alpha1.RouteBackend{{AgentRef: aiv1alpha1.AgentRef{Name: "echo"}}},
+			}},
+		},
+		Status: aiv1alpha1.RouteStatus{Backends: []aiv1alpha1.BackendStatus{{
+			AgentRef: aiv1alpha1.AgentRef{Name: "echo", Namespace: "default"},
+			Ready:    true,
+		}}},
+	}
+	r := newRouteTestReconciler(agent, route)
+
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "routes", Namespace: "default"}})
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Errorf("expected no requeue, got %s", result.RequeueAfter)
+	}
+	var got aiv1alpha1.Route
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(route), &got); err != nil {
+		t.Fatalf("failed to get route: %v", err)
+	}
+	if got.Status.Ready {
+		t.Error("expected the Route to flip to not ready without a grace period")
+	}
+}
+
+func TestCompileRouteConfig_Retries(t *testing.T) {
+	maxRetries := int32(3)
+	route := &aiv1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: "default"},
+		Spec: aiv1alpha1.RouteSpec{
+			Defaults: &aiv1alpha1.RouteDefaults{
+				MaxRetries:   &maxRetries,
+				RetryBackoff: &metav1.Duration{Duration: 250 * time.Millisecond},
+			},
+		},
+	}
+
+	r := newRouteTestReconciler()
+	config := r.compileRouteConfig(route, nil)
+
+	if config.Defaults.MaxRetries != 3 || config.Defaults.RetryBackoffMs != 250 {
+		t.Errorf("expected 3 retries after 250ms, got %d after %dms", config.Defaults.MaxRetries, config.Defaults.RetryBackoffMs)
+	}
+}
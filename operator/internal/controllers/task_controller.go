@@ -5,27 +5,39 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
 	"github.com/jarsater/mcp-fabric/operator/internal/metrics"
 	"github.com/jarsater/mcp-fabric/operator/internal/render"
 )
 
+// NamespacePauseAnnotation, set to "true" on a Namespace, pauses every
+// unfinished Task in it without editing their specs. Removing it resumes them.
+const NamespacePauseAnnotation = "fabric.jarsater.ai/pause-tasks"
+
 const (
 	// Default values for Task limits
 	defaultMaxIterations          = int32(100)
@@ -33,9 +45,16 @@ const (
 	defaultTotalTimeout           = 24 * time.Hour
 	defaultMaxConsecutiveFailures = int32(3)
 
+	// defaultIterationHistoryLimit is how many iteration results are kept
+	// in status when the task does not say.
+	defaultIterationHistoryLimit = 10
+
 	// Default orchestrator agent name
 	defaultOrchestratorName = "task-orchestrator"
 
+	// defaultMaxPRDTasks caps how many tasks a PRD may declare.
+	defaultMaxPRDTasks = 1000
+
 	// Requeue intervals
 	// requeueDelay re-runs reconcile promptly after we mutate the object
 	// ourselves (finalizer added, status initialized, conflict retry). It
@@ -53,6 +72,30 @@ const (
 	// Maximum Job recreations before failing
 	maxJobRecreations       = 3
 	jobRecreationAnnotation = "fabric.jarsater.ai/job-recreations"
+
+	// workerProbeTimeout bounds the worker reachability pre-check.
+	workerProbeTimeout = 5 * time.Second
+
+	// agentRoleConflictCondition is set while a Task uses one Agent as both
+	// its orchestrator and its worker.
+	agentRoleConflictCondition = "AgentRoleConflict"
+
+	// qualityGateCommandMissingCondition is set when the orchestrator reports
+	// quality gate commands that are not installed in its image.
+	qualityGateCommandMissingCondition = "QualityGateCommandMissing"
+
+	// taskSourceDeletedCondition is set when a ConfigMap or Secret the Task
+	// reads its PRD or additional sources from is deleted mid-run.
+	taskSourceDeletedCondition = "TaskSourceDeleted"
+
+	// ambiguousJobGracePeriod is how long an unfinished Job may go without
+	// running pods before the Task's ResumePolicy is applied to it.
+	ambiguousJobGracePeriod = 2 * time.Minute
+
+	// imagePullCheckWindow is how long after the orchestrator Job is created
+	// a pod that cannot pull its image fails the Task. Later pull failures,
+	// such as of a pod retried under backoffLimit, are left to the kubelet.
+	imagePullCheckWindow = 6 * jobPollInterval
 )
 
 // TaskReconciler reconciles a Task object.
@@ -60,6 +103,33 @@ type TaskReconciler struct {
 	client.Client
 	Scheme    *runtime.Scheme
 	Clientset *kubernetes.Clientset
+
+	// DefaultOrchestratorName is the orchestrator Agent used when a Task
+	// omits OrchestratorRef (defaults to "task-orchestrator").
+	DefaultOrchestratorName string
+
+	// MaxPRDTasks is the most tasks a PRD may declare before the Task is
+	// failed with reason TooManyTasks (defaults to 1000).
+	MaxPRDTasks int
+
+	// DefaultGitImage is the git-clone image for Tasks whose git config
+	// sets none (defaults to render.DefaultGitImage).
+	DefaultGitImage string
+
+	// Namespaces limits the Tasks this controller manages; Tasks outside it
+	// are ignored. The zero value manages every namespace.
+	Namespaces NamespaceFilter
+
+	// HTTPClient is used for worker reachability pre-checks (defaults to
+	// http.DefaultClient).
+	HTTPClient *http.Client
+
+	// Recorder records orchestrator milestones as Events on the Task. No
+	// Events are recorded when it is nil.
+	Recorder events.EventRecorder
+
+	// milestones tracks the milestones already recorded for running Tasks.
+	milestones milestoneTracker
 }
 
 // +kubebuilder:rbac:groups=fabric.jarsater.ai,resources=tasks,verbs=get;list;watch;create;update;patch;delete
@@ -70,7 +140,11 @@ type TaskReconciler struct {
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=pods/log,verbs=get
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 // Reconcile handles Task reconciliation.
 func (r *TaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -114,27 +188,42 @@ func (r *TaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return ctrl.Result{RequeueAfter: requeueDelay}, nil
 	}
 
-	// Check if task is paused
-	if task.Spec.Paused {
+	// Check if task is paused, either directly or through its namespace.
+	// Finished tasks are left alone so resuming the namespace never reruns them.
+	paused, pauseReason, pauseMessage := task.Spec.Paused, "Paused", "Task is paused"
+	if !paused && task.Status.Phase != aiv1alpha1.TaskPhaseCompleted && task.Status.Phase != aiv1alpha1.TaskPhaseFailed {
+		nsPaused, err := r.namespacePaused(ctx, task.Namespace)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if nsPaused {
+			paused, pauseReason = true, "NamespacePaused"
+			pauseMessage = fmt.Sprintf("All tasks in namespace %s are paused by the %s annotation", task.Namespace, NamespacePauseAnnotation)
+		}
+	}
+	if paused {
 		if task.Status.Phase != aiv1alpha1.TaskPhasePaused {
 			task.Status.Phase = aiv1alpha1.TaskPhasePaused
 			r.setCondition(&task, metav1.Condition{
 				Type:               "Ready",
 				Status:             metav1.ConditionFalse,
 				ObservedGeneration: task.Generation,
-				Reason:             "Paused",
-				Message:            "Task is paused",
+				Reason:             pauseReason,
+				Message:            pauseMessage,
 			})
 			if err := r.Status().Update(ctx, &task); err != nil {
 				return ctrl.Result{}, err
 			}
+			return ctrl.Result{}, nil
 		}
+		metrics.RecordReconcile(metrics.ControllerTask, metrics.ResultNoOp, time.Since(startTime).Seconds())
 		return ctrl.Result{}, nil
 	}
 
 	// Check if task is already completed or failed
 	if task.Status.Phase == aiv1alpha1.TaskPhaseCompleted ||
 		task.Status.Phase == aiv1alpha1.TaskPhaseFailed {
+		metrics.RecordReconcile(metrics.ControllerTask, metrics.ResultNoOp, time.Since(startTime).Seconds())
 		return ctrl.Result{}, nil
 	}
 
@@ -148,8 +237,8 @@ func (r *TaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	case aiv1alpha1.TaskPhaseRunning:
 		result, err = r.handleRunningPhase(ctx, &task)
 	default:
-		// Re-evaluate paused tasks
-		if task.Status.Phase == aiv1alpha1.TaskPhasePaused && !task.Spec.Paused {
+		// Re-evaluate paused tasks; reaching here means no pause applies
+		if task.Status.Phase == aiv1alpha1.TaskPhasePaused {
 			task.Status.Phase = aiv1alpha1.TaskPhaseRunning
 			task.Status.ConsecutiveFailures = 0
 			r.setCondition(&task, metav1.Condition{
@@ -209,15 +298,108 @@ func (r *TaskReconciler) handlePendingPhase(ctx context.Context, task *aiv1alpha
 		return ctrl.Result{RequeueAfter: failureRequeueDelay}, nil
 	}
 
-	// Get worker agent (needed for endpoint)
-	workerAgent, err := r.getAgent(ctx, task.Spec.WorkerRef, task.Namespace)
+	// Resolve how the orchestrator reaches the worker
+	workerEndpoint, err := render.WorkerEndpoint(task)
 	if err != nil {
-		logger.Error(err, "Failed to get worker agent")
+		logger.Error(err, "Invalid worker endpoint")
 		r.setCondition(task, metav1.Condition{
 			Type:               "Ready",
 			Status:             metav1.ConditionFalse,
 			ObservedGeneration: task.Generation,
-			Reason:             "WorkerNotFound",
+			Reason:             "InvalidWorkerEndpoint",
+			Message:            err.Error(),
+		})
+		if err := r.Status().Update(ctx, task); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: failureRequeueDelay}, nil
+	}
+
+	// Get worker agent to co-locate as a sidecar, unless the task targets an
+	// external worker endpoint
+	var workerAgent *aiv1alpha1.Agent
+	if task.Spec.WorkerEndpoint == "" {
+		workerAgent, err = r.getAgent(ctx, task.Spec.WorkerRef, task.Namespace)
+		if err != nil {
+			logger.Error(err, "Failed to get worker agent")
+			r.setCondition(task, metav1.Condition{
+				Type:               "Ready",
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: task.Generation,
+				Reason:             "WorkerNotFound",
+				Message:            err.Error(),
+			})
+			if err := r.Status().Update(ctx, task); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: failureRequeueDelay}, nil
+		}
+	}
+
+	// Flag the same Agent being used for both roles, usually a copy-paste
+	// mistake. This is informational and does not block the Task.
+	if workerAgent != nil && workerAgent.Namespace == orchestratorAgent.Namespace && workerAgent.Name == orchestratorAgent.Name {
+		logger.Info("Orchestrator and worker are the same agent", "agent", orchestratorAgent.Name)
+		r.setCondition(task, metav1.Condition{
+			Type:               agentRoleConflictCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: task.Generation,
+			Reason:             "SameAgent",
+			Message:            fmt.Sprintf("Agent %s/%s is both the orchestrator and the worker", orchestratorAgent.Namespace, orchestratorAgent.Name),
+		})
+	} else {
+		meta.RemoveStatusCondition(&task.Status.Conditions, agentRoleConflictCondition)
+	}
+
+	// Verify an external worker is reachable before starting the Job
+	if task.Spec.WorkerPreflight && task.Spec.WorkerEndpoint != "" {
+		if err := r.probeWorker(ctx, workerEndpoint); err != nil {
+			logger.Error(err, "Worker unreachable", "endpoint", workerEndpoint)
+			r.setCondition(task, metav1.Condition{
+				Type:               "Ready",
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: task.Generation,
+				Reason:             "WorkerUnreachable",
+				Message:            err.Error(),
+			})
+			if err := r.Status().Update(ctx, task); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: failureRequeueDelay}, nil
+		}
+	}
+
+	// Reject security settings that cannot work together, including the
+	// worker sidecar's once the Task's settings are layered over its Agent's
+	err = render.ValidateSecurityContext(task.Spec.SecurityContext)
+	if err == nil && workerAgent != nil {
+		if err = render.ValidateSecurityContext(render.LayerSecurityContext(workerAgent.Spec.SecurityContext, task.Spec.SecurityContext)); err != nil {
+			err = fmt.Errorf("worker sidecar: %w", err)
+		}
+	}
+	if err != nil {
+		logger.Error(err, "Invalid security context")
+		r.setCondition(task, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: task.Generation,
+			Reason:             "SecurityContextInvalid",
+			Message:            err.Error(),
+		})
+		if err := r.Status().Update(ctx, task); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: failureRequeueDelay}, nil
+	}
+
+	// Reject workspace storage that cannot provide the requested access mode
+	if err := r.validateWorkspaceStorage(ctx, task); err != nil {
+		logger.Error(err, "Invalid workspace storage")
+		r.setCondition(task, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: task.Generation,
+			Reason:             "WorkspaceStorageInvalid",
 			Message:            err.Error(),
 		})
 		if err := r.Status().Update(ctx, task); err != nil {
@@ -249,18 +431,89 @@ func (r *TaskReconciler) handlePendingPhase(ctx context.Context, task *aiv1alpha
 		return ctrl.Result{RequeueAfter: failureRequeueDelay}, nil
 	}
 
-	// Count total tasks in PRD
+	// Load additional named documents (requirements, design, ...)
+	additionalSources, err := r.loadAdditionalSources(ctx, task)
+	if err != nil {
+		logger.Error(err, "Failed to load additional sources")
+		r.setCondition(task, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: task.Generation,
+			Reason:             "TaskSourceError",
+			Message:            err.Error(),
+		})
+		if err := r.Status().Update(ctx, task); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: failureRequeueDelay}, nil
+	}
+
+	// Count total tasks and acceptance criteria in PRD
 	totalTasks := r.countTasksInPRD(prdContent)
+	var totalCriteria int
+	var storyIDs []string
+	if progress, err := parsePRD(prdContent); err == nil {
+		totalCriteria = progress.Criteria
+		storyIDs = progress.StoryIDs
+	}
+
+	// A resume point must name a story the orchestrator can find
+	if id := task.Spec.ResumeFromTaskID; id != "" && !slices.Contains(storyIDs, id) {
+		err := fmt.Errorf("resumeFromTaskId %q does not match any task in the PRD", id)
+		logger.Error(err, "Invalid resume task id")
+		r.setCondition(task, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: task.Generation,
+			Reason:             "InvalidResumeTaskID",
+			Message:            err.Error(),
+		})
+		if err := r.Status().Update(ctx, task); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: failureRequeueDelay}, nil
+	}
 
-	// Create orchestrator Job. The worker runs as a sidecar in the same Pod
-	// (sharing the workspace), so the orchestrator reaches it over loopback.
+	// Refuse PRDs large enough to keep the orchestrator looping far longer
+	// than intended
+	maxTasks := r.MaxPRDTasks
+	if maxTasks <= 0 {
+		maxTasks = defaultMaxPRDTasks
+	}
+	if totalTasks > maxTasks {
+		logger.Info("PRD declares too many tasks, failing task", "tasks", totalTasks, "max", maxTasks)
+		task.Status.Phase = aiv1alpha1.TaskPhaseFailed
+		task.Status.Message = fmt.Sprintf("PRD declares %d tasks, more than the maximum of %d", totalTasks, maxTasks)
+		task.Status.TotalTasks = int32(totalTasks)
+		now := metav1.Now()
+		task.Status.CompletedAt = &now
+		r.setCondition(task, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: task.Generation,
+			Reason:             "TooManyTasks",
+			Message:            task.Status.Message,
+		})
+		if err := r.Status().Update(ctx, task); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Create orchestrator Job. By default the worker runs as a sidecar in the
+	// same Pod (sharing the workspace), so the orchestrator reaches it over
+	// loopback. A PR left by a previous run is reused so reruns don't open
+	// duplicates.
 	jobParams := render.OrchestratorJobParams{
-		Task:              task,
-		OrchestratorAgent: orchestratorAgent,
-		WorkerAgent:       workerAgent,
-		WorkerEndpoint:    render.LocalWorkerEndpoint(),
-		WorkspacePVC:      render.WorkspacePVCName(task),
-		PRD:               prdContent,
+		Task:                   task,
+		OrchestratorAgent:      orchestratorAgent,
+		WorkerAgent:            workerAgent,
+		WorkerEndpoint:         workerEndpoint,
+		WorkspacePVC:           render.WorkspacePVCName(task),
+		PRD:                    prdContent,
+		Sources:                additionalSources,
+		ExistingPullRequestURL: task.Status.PullRequestURL,
+		GitImage:               r.DefaultGitImage,
 	}
 
 	job, err := render.OrchestratorJob(jobParams)
@@ -301,6 +554,7 @@ func (r *TaskReconciler) handlePendingPhase(ctx context.Context, task *aiv1alpha
 	task.Status.Phase = aiv1alpha1.TaskPhaseRunning
 	task.Status.StartedAt = &now
 	task.Status.TotalTasks = int32(totalTasks)
+	task.Status.TotalCriteria = int32(totalCriteria)
 	if task.Spec.Git != nil {
 		task.Status.RepositoryURL = task.Spec.Git.URL
 	}
@@ -319,6 +573,31 @@ func (r *TaskReconciler) handlePendingPhase(ctx context.Context, task *aiv1alpha
 	return ctrl.Result{RequeueAfter: jobPollInterval}, nil
 }
 
+// probeWorker checks that the worker's /healthz answers with a 2xx status.
+func (r *TaskReconciler) probeWorker(ctx context.Context, endpoint string) error {
+	ctx, cancel := context.WithTimeout(ctx, workerProbeTimeout)
+	defer cancel()
+
+	healthURL := strings.TrimSuffix(endpoint, "/") + "/healthz"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
+	if err != nil {
+		return err
+	}
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("worker %s unreachable: %w", healthURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("worker %s returned %d", healthURL, resp.StatusCode)
+	}
+	return nil
+}
+
 // handleRunningPhase monitors the orchestrator Job and extracts results.
 func (r *TaskReconciler) handleRunningPhase(ctx context.Context, task *aiv1alpha1.Task) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -397,17 +676,48 @@ func (r *TaskReconciler) handleRunningPhase(ctx context.Context, task *aiv1alpha
 		return ctrl.Result{}, err
 	}
 
-	// Check Job status
+	// Check Job status, recording the milestones of its last moments first
 	if job.Status.Succeeded > 0 {
 		logger.Info("Orchestrator Job succeeded", "job", jobName)
+		r.recordMilestones(ctx, task, &job)
+		r.milestones.forget(task.UID)
 		return r.handleJobSuccess(ctx, task, &job)
 	}
 
 	if job.Status.Failed > 0 {
 		logger.Info("Orchestrator Job failed", "job", jobName)
+		r.recordMilestones(ctx, task, &job)
+		r.milestones.forget(task.UID)
 		return r.handleJobFailure(ctx, task, &job)
 	}
 
+	// A run whose source was deleted could neither persist its PRD nor be
+	// rerun, so stop it now
+	deleted, err := r.deletedTaskSource(ctx, task)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if deleted != "" {
+		logger.Info("Task source deleted, failing task", "source", deleted)
+		task.Status.Phase = aiv1alpha1.TaskPhaseFailed
+		task.Status.Message = fmt.Sprintf("Task source %s was deleted", deleted)
+		now := metav1.Now()
+		task.Status.CompletedAt = &now
+		r.setSourceDeletedCondition(task, task.Status.Message)
+		r.setCondition(task, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: task.Generation,
+			Reason:             "TaskSourceDeleted",
+			Message:            task.Status.Message,
+		})
+		if err := r.Status().Update(ctx, task); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.cleanupOrchestratorJob(ctx, task)
+		return ctrl.Result{}, nil
+	}
+
 	// Check for deadline exceeded
 	for _, cond := range job.Status.Conditions {
 		if cond.Type == batchv1.JobFailed && cond.Reason == "DeadlineExceeded" {
@@ -430,25 +740,136 @@ func (r *TaskReconciler) handleRunningPhase(ctx context.Context, task *aiv1alpha
 		}
 	}
 
-	// Job still running, requeue to check again
+	// An unfinished Job with no pods may have been orphaned by an operator
+	// restart; let the Task decide whether to trust it
+	if jobStateAmbiguous(&job) {
+		switch task.Spec.ResumePolicy {
+		case aiv1alpha1.TaskResumePolicyRestart:
+			logger.Info("Orchestrator Job state ambiguous, restarting", "job", jobName)
+			if err := r.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+				return ctrl.Result{}, err
+			}
+			// The next reconcile recreates the Job, bounded by MaxJobRecreations
+			return ctrl.Result{RequeueAfter: requeueDelay}, nil
+		case aiv1alpha1.TaskResumePolicyFail:
+			logger.Info("Orchestrator Job state ambiguous, failing task", "job", jobName)
+			task.Status.Phase = aiv1alpha1.TaskPhaseFailed
+			task.Status.Message = "Orchestrator Job has no running pods; manual intervention required"
+			now := metav1.Now()
+			task.Status.CompletedAt = &now
+			r.setCondition(task, metav1.Condition{
+				Type:               "Ready",
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: task.Generation,
+				Reason:             "JobStateAmbiguous",
+				Message:            task.Status.Message,
+			})
+			if err := r.Status().Update(ctx, task); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+
+	// A pod that cannot pull its image never starts; fail now instead of
+	// waiting out the total timeout
+	var pullErr string
+	if time.Since(job.CreationTimestamp.Time) < imagePullCheckWindow {
+		if pullErr, err = r.imagePullFailure(ctx, &job); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if pullErr != "" {
+		logger.Info("Orchestrator Job cannot pull its image, failing task", "job", jobName, "error", pullErr)
+		task.Status.Phase = aiv1alpha1.TaskPhaseFailed
+		task.Status.Message = pullErr
+		now := metav1.Now()
+		task.Status.CompletedAt = &now
+		r.setCondition(task, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: task.Generation,
+			Reason:             "ImagePullError",
+			Message:            task.Status.Message,
+		})
+		if err := r.Status().Update(ctx, task); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.cleanupOrchestratorJob(ctx, task)
+		return ctrl.Result{}, nil
+	}
+
+	// Job still running: surface its progress, then requeue to check again
+	r.recordMilestones(ctx, task, &job)
 	logger.V(1).Info("Orchestrator Job still running", "job", jobName)
 	return ctrl.Result{RequeueAfter: jobPollInterval}, nil
 }
 
+// jobStateAmbiguous reports whether an unfinished Job has had no active pods
+// for longer than ambiguousJobGracePeriod.
+func jobStateAmbiguous(job *batchv1.Job) bool {
+	if job.Status.Active > 0 || job.Status.Succeeded > 0 || job.Status.Failed > 0 {
+		return false
+	}
+	for _, cond := range job.Status.Conditions {
+		if (cond.Type == batchv1.JobComplete || cond.Type == batchv1.JobFailed) && cond.Status == corev1.ConditionTrue {
+			return false
+		}
+	}
+	return time.Since(job.CreationTimestamp.Time) > ambiguousJobGracePeriod
+}
+
+// imagePullReasons are the container waiting reasons of an image that
+// cannot be pulled. ErrImagePull is left out: a single failed pull is often
+// a registry hiccup, and one that persists turns into ImagePullBackOff.
+var imagePullReasons = map[string]bool{
+	"ImagePullBackOff": true,
+	"InvalidImageName": true,
+}
+
+// imagePullFailure returns a message describing the first container of the
+// Job's pods, init containers included, that is waiting on an image it
+// cannot pull, or "" if there is none.
+func (r *TaskReconciler) imagePullFailure(ctx context.Context, job *batchv1.Job) (string, error) {
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, client.InNamespace(job.Namespace), client.MatchingLabels{
+		"job-name": job.Name,
+	}); err != nil {
+		return "", fmt.Errorf("failed to list Job pods: %w", err)
+	}
+
+	for _, pod := range podList.Items {
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			waiting := status.State.Waiting
+			if waiting == nil || !imagePullReasons[waiting.Reason] {
+				continue
+			}
+			message := fmt.Sprintf("Container %s cannot pull image %s: %s", status.Name, status.Image, waiting.Reason)
+			if waiting.Message != "" {
+				message += ": " + waiting.Message
+			}
+			return message, nil
+		}
+	}
+	return "", nil
+}
+
 // OrchestratorResult represents the result from the orchestrator Job.
 type OrchestratorResult struct {
-	Passed         bool            `json:"passed"`
-	CompletedTasks int             `json:"completedTasks"`
-	TotalTasks     int             `json:"totalTasks"`
-	Iterations     int             `json:"iterations"`
-	Learnings      string          `json:"learnings"`
-	CommitSHA      string          `json:"commitSha"`
-	PullRequestURL string          `json:"pullRequestUrl"`
-	PRD            json.RawMessage `json:"prd"`
-	Error          string          `json:"error"`
-	NoChanges      bool            `json:"noChanges"`
-	Pushed         bool            `json:"pushed"`
-	GitError       string          `json:"gitError"`
+	Passed          bool            `json:"passed"`
+	CompletedTasks  int             `json:"completedTasks"`
+	TotalTasks      int             `json:"totalTasks"`
+	Iterations      int             `json:"iterations"`
+	Learnings       string          `json:"learnings"`
+	CommitSHA       string          `json:"commitSha"`
+	PullRequestURL  string          `json:"pullRequestUrl"`
+	PRD             json.RawMessage `json:"prd"`
+	Error           string          `json:"error"`
+	NoChanges       bool            `json:"noChanges"`
+	Pushed          bool            `json:"pushed"`
+	GitError        string          `json:"gitError"`
+	MissingCommands []string        `json:"missingCommands"`
 }
 
 // handleJobSuccess processes a successful orchestrator Job.
@@ -471,6 +892,7 @@ func (r *TaskReconciler) handleJobSuccess(ctx context.Context, task *aiv1alpha1.
 	if result.TotalTasks > 0 {
 		task.Status.TotalTasks = int32(result.TotalTasks)
 	}
+	setCriteriaProgress(task, result)
 
 	if result.Passed {
 		task.Status.Phase = aiv1alpha1.TaskPhaseCompleted
@@ -504,6 +926,8 @@ func (r *TaskReconciler) handleJobSuccess(ctx context.Context, task *aiv1alpha1.
 	if result.PullRequestURL != "" {
 		task.Status.PullRequestURL = result.PullRequestURL
 	}
+	r.setGitConditions(task, result)
+	r.setQualityGateCondition(task, result)
 
 	// Add final iteration result
 	iterResult := aiv1alpha1.IterationResult{
@@ -518,16 +942,25 @@ func (r *TaskReconciler) handleJobSuccess(ctx context.Context, task *aiv1alpha1.
 	} else {
 		iterResult.StartedAt = now
 	}
-	task.Status.RecentIterations = append(task.Status.RecentIterations, iterResult)
-	if len(task.Status.RecentIterations) > 10 {
-		task.Status.RecentIterations = task.Status.RecentIterations[len(task.Status.RecentIterations)-10:]
-	}
+	appendIterationResult(task, iterResult)
 
 	task.Status.ObservedGeneration = task.Generation
 
-	// Update the PRD in source ConfigMap if provided
+	// Update the PRD in source ConfigMap if provided, first diffing it
+	// against the source as loaded for this run
 	if len(result.PRD) > 0 {
-		if err := r.persistUpdatedPRD(ctx, task, string(result.PRD)); err != nil {
+		if original, err := r.loadTaskSource(ctx, task); err != nil {
+			logger.Error(err, "Failed to load original PRD for diff")
+		} else if changes, err := diffPRD(original, string(result.PRD)); err != nil {
+			logger.Error(err, "Failed to diff updated PRD")
+		} else {
+			task.Status.PRDChanges = changes
+		}
+		if err := r.persistUpdatedPRD(ctx, task, string(result.PRD)); errors.IsNotFound(err) {
+			logger.Info("Task source deleted, updated PRD not persisted")
+			r.setSourceDeletedCondition(task, fmt.Sprintf("Task source ConfigMap %q was deleted; the updated PRD was not persisted",
+				task.Spec.TaskSource.ConfigMapRef.Name))
+		} else if err != nil {
 			logger.Error(err, "Failed to persist updated PRD")
 		}
 	}
@@ -546,6 +979,82 @@ func (r *TaskReconciler) handleJobSuccess(ctx context.Context, task *aiv1alpha1.
 	return ctrl.Result{}, nil
 }
 
+// setGitConditions records the orchestrator's git push and pull request
+// outcome as GitPushed and PullRequestCreated conditions, so permission or
+// remote failures are visible separately from task completion. Results
+// that never attempted a push leave the conditions untouched.
+func (r *TaskReconciler) setGitConditions(task *aiv1alpha1.Task, result *OrchestratorResult) {
+	switch {
+	case result.Pushed:
+		metrics.RecordTaskGitPush(task.Namespace, true)
+		r.setCondition(task, metav1.Condition{
+			Type:               "GitPushed",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: task.Generation,
+			Reason:             "Pushed",
+			Message:            fmt.Sprintf("Pushed commit %s", result.CommitSHA),
+		})
+	case result.GitError != "":
+		metrics.RecordTaskGitPush(task.Namespace, false)
+		r.setCondition(task, metav1.Condition{
+			Type:               "GitPushed",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: task.Generation,
+			Reason:             "PushFailed",
+			Message:            result.GitError,
+		})
+		return
+	case result.NoChanges:
+		r.setCondition(task, metav1.Condition{
+			Type:               "GitPushed",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: task.Generation,
+			Reason:             "NoChanges",
+			Message:            "No changes to push",
+		})
+		return
+	default:
+		return
+	}
+
+	// The push succeeded; a git error now means the pull request step failed
+	switch {
+	case result.PullRequestURL != "":
+		r.setCondition(task, metav1.Condition{
+			Type:               "PullRequestCreated",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: task.Generation,
+			Reason:             "Created",
+			Message:            result.PullRequestURL,
+		})
+	case result.GitError != "":
+		r.setCondition(task, metav1.Condition{
+			Type:               "PullRequestCreated",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: task.Generation,
+			Reason:             "CreateFailed",
+			Message:            result.GitError,
+		})
+	}
+}
+
+// setQualityGateCondition records quality gate commands the orchestrator
+// found missing as a QualityGateCommandMissing condition, clearing it once
+// a run reports none.
+func (r *TaskReconciler) setQualityGateCondition(task *aiv1alpha1.Task, result *OrchestratorResult) {
+	if len(result.MissingCommands) == 0 {
+		meta.RemoveStatusCondition(&task.Status.Conditions, qualityGateCommandMissingCondition)
+		return
+	}
+	r.setCondition(task, metav1.Condition{
+		Type:               qualityGateCommandMissingCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: task.Generation,
+		Reason:             "CommandNotFound",
+		Message:            "Quality gate commands not found: " + strings.Join(result.MissingCommands, ", "),
+	})
+}
+
 // handleJobFailure processes a failed orchestrator Job.
 func (r *TaskReconciler) handleJobFailure(ctx context.Context, task *aiv1alpha1.Task, job *batchv1.Job) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -561,12 +1070,15 @@ func (r *TaskReconciler) handleJobFailure(ctx context.Context, task *aiv1alpha1.
 	if result != nil {
 		task.Status.CurrentIteration = int32(result.Iterations)
 		task.Status.CompletedTasks = int32(result.CompletedTasks)
+		setCriteriaProgress(task, result)
 		if result.Error != "" {
 			task.Status.Message = result.Error
 		}
 		if result.CommitSHA != "" {
 			task.Status.LastCommitSHA = result.CommitSHA
 		}
+		r.setGitConditions(task, result)
+		r.setQualityGateCondition(task, result)
 	}
 
 	r.setCondition(task, metav1.Condition{
@@ -588,33 +1100,9 @@ func (r *TaskReconciler) handleJobFailure(ctx context.Context, task *aiv1alpha1.
 
 // getOrchestratorResult extracts the result from orchestrator Job logs.
 func (r *TaskReconciler) getOrchestratorResult(ctx context.Context, job *batchv1.Job) (*OrchestratorResult, error) {
-	if r.Clientset == nil {
-		return nil, fmt.Errorf("kubernetes clientset not available")
-	}
-
-	// Find the pod for this Job
-	var podList corev1.PodList
-	if err := r.List(ctx, &podList, client.InNamespace(job.Namespace), client.MatchingLabels{
-		"job-name": job.Name,
-	}); err != nil {
-		return nil, fmt.Errorf("failed to list Job pods: %w", err)
-	}
-
-	if len(podList.Items) == 0 {
-		return nil, fmt.Errorf("no pods found for Job %s", job.Name)
-	}
-
-	// Get logs from the orchestrator container
-	pod := podList.Items[0]
-	tailLines := int64(1000)
-	req := r.Clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
-		Container: "orchestrator",
-		TailLines: &tailLines,
-	})
-
-	logs, err := req.Stream(ctx)
+	logs, err := r.openOrchestratorLogs(ctx, job, 1000)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get pod logs: %w", err)
+		return nil, err
 	}
 	defer func() { _ = logs.Close() }()
 
@@ -643,6 +1131,39 @@ func (r *TaskReconciler) getOrchestratorResult(ctx context.Context, job *batchv1
 	return &result, nil
 }
 
+// openOrchestratorLogs streams the last tailLines lines of the orchestrator
+// container's logs in the Job's pod. The caller must close the stream.
+func (r *TaskReconciler) openOrchestratorLogs(ctx context.Context, job *batchv1.Job, tailLines int64) (io.ReadCloser, error) {
+	if r.Clientset == nil {
+		return nil, fmt.Errorf("kubernetes clientset not available")
+	}
+
+	// Find the pod for this Job
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, client.InNamespace(job.Namespace), client.MatchingLabels{
+		"job-name": job.Name,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list Job pods: %w", err)
+	}
+
+	if len(podList.Items) == 0 {
+		return nil, fmt.Errorf("no pods found for Job %s", job.Name)
+	}
+
+	// Get logs from the orchestrator container
+	pod := podList.Items[0]
+	req := r.Clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: render.OrchestratorContainerName,
+		TailLines: &tailLines,
+	})
+
+	logs, err := req.Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod logs: %w", err)
+	}
+	return logs, nil
+}
+
 // cleanupOrchestratorJob deletes the orchestrator Job.
 func (r *TaskReconciler) cleanupOrchestratorJob(ctx context.Context, task *aiv1alpha1.Task) {
 	jobName := fmt.Sprintf("%s-orchestrator", task.Name)
@@ -668,18 +1189,28 @@ func (r *TaskReconciler) handleDeletion(ctx context.Context, task *aiv1alpha1.Ta
 
 	// Clean up orchestrator Job
 	r.cleanupOrchestratorJob(ctx, task)
+	r.milestones.forget(task.UID)
 
 	// Clean up workspace PVC
 	pvcName := render.WorkspacePVCName(task)
-	pvc := &corev1.PersistentVolumeClaim{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      pvcName,
-			Namespace: task.Namespace,
-		},
-	}
-	if err := r.Delete(ctx, pvc); err != nil && !errors.IsNotFound(err) {
-		logger.Error(err, "Failed to delete workspace PVC", "pvc", pvcName)
-		// Continue with cleanup even if PVC deletion fails
+	if task.Spec.RetainWorkspace {
+		// Without the owner reference garbage collection would delete the
+		// PVC anyway, so keep the finalizer until it is released
+		if err := r.retainWorkspacePVC(ctx, task); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to retain workspace PVC %s: %w", pvcName, err)
+		}
+		logger.Info("Retained workspace PVC", "pvc", pvcName)
+	} else {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pvcName,
+				Namespace: task.Namespace,
+			},
+		}
+		if err := r.Delete(ctx, pvc); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete workspace PVC", "pvc", pvcName)
+			// Continue with cleanup even if PVC deletion fails
+		}
 	}
 
 	// Remove finalizer
@@ -704,8 +1235,12 @@ func (r *TaskReconciler) getOrchestratorAgent(ctx context.Context, task *aiv1alp
 	ref := task.Spec.OrchestratorRef
 	if ref == nil {
 		// Use default orchestrator
+		name := r.DefaultOrchestratorName
+		if name == "" {
+			name = defaultOrchestratorName
+		}
 		ref = &aiv1alpha1.AgentReference{
-			Name:      defaultOrchestratorName,
+			Name:      name,
 			Namespace: task.Namespace,
 		}
 	}
@@ -734,31 +1269,174 @@ func (r *TaskReconciler) getAgent(ctx context.Context, ref aiv1alpha1.AgentRefer
 // precedence (stories first, then tasks) so the operator's task count and the
 // work the orchestrator actually executes stay in agreement.
 type PRDDocument struct {
-	Stories []struct {
-		ID    string `json:"id"`
-		Title string `json:"title"`
-	} `json:"stories"`
-	Tasks []struct {
-		ID    string `json:"id"`
-		Title string `json:"title"`
-	} `json:"tasks"`
+	Stories []PRDStory `json:"stories"`
+	Tasks   []PRDStory `json:"tasks"`
 }
 
-// countTasksInPRD counts the total number of tasks in the PRD using proper JSON parsing.
-func (r *TaskReconciler) countTasksInPRD(prdContent string) int {
+// PRDStory is a single story (or task) in a PRD document.
+type PRDStory struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+
+	// AcceptanceCriteria entries are usually strings, but any JSON value
+	// counts as one criterion.
+	AcceptanceCriteria []json.RawMessage `json:"acceptanceCriteria,omitempty"`
+
+	// Passes is flipped by the orchestrator once the story is done.
+	Passes bool `json:"passes,omitempty"`
+
+	// TaskIDs lists the story's sub-tasks, given either as ids or as
+	// objects with an "id" field.
+	TaskIDs prdTaskIDs `json:"tasks,omitempty"`
+}
+
+// prdTaskIDs decodes a list of task ids from strings or {"id": ...} objects.
+type prdTaskIDs []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (ids *prdTaskIDs) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	*ids = nil
+	for _, item := range items {
+		var id string
+		if err := json.Unmarshal(item, &id); err != nil {
+			var obj struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(item, &obj); err != nil {
+				return err
+			}
+			id = obj.ID
+		}
+		if id != "" {
+			*ids = append(*ids, id)
+		}
+	}
+	return nil
+}
+
+// stories returns the canonical story list, falling back to the "tasks" alias.
+func (d *PRDDocument) stories() []PRDStory {
+	if len(d.Stories) > 0 {
+		return d.Stories
+	}
+	return d.Tasks
+}
+
+// PRDProgress summarizes how far a PRD has progressed.
+type PRDProgress struct {
+	Stories           int
+	CompletedStories  int
+	Criteria          int
+	CompletedCriteria int
+	// StoryIDs lists the ids of the stories, in PRD order.
+	StoryIDs []string
+	// TaskIDs maps story id to the ids of its sub-tasks.
+	TaskIDs map[string][]string
+}
+
+// Progress counts stories and acceptance criteria, treating the criteria of
+// passed stories as completed.
+func (d *PRDDocument) Progress() PRDProgress {
+	progress := PRDProgress{TaskIDs: map[string][]string{}}
+	for _, story := range d.stories() {
+		progress.Stories++
+		progress.Criteria += len(story.AcceptanceCriteria)
+		if story.Passes {
+			progress.CompletedStories++
+			progress.CompletedCriteria += len(story.AcceptanceCriteria)
+		}
+		if story.ID != "" {
+			progress.StoryIDs = append(progress.StoryIDs, story.ID)
+		}
+		if story.ID != "" && len(story.TaskIDs) > 0 {
+			progress.TaskIDs[story.ID] = story.TaskIDs
+		}
+	}
+	return progress
+}
+
+// parsePRD parses PRD content into its progress summary.
+func parsePRD(prdContent string) (PRDProgress, error) {
 	var prd PRDDocument
 	if err := json.Unmarshal([]byte(prdContent), &prd); err != nil {
-		// If JSON parsing fails, return 0 (unknown task count)
-		return 0
+		return PRDProgress{}, err
 	}
+	return prd.Progress(), nil
+}
 
-	// Prefer the canonical "stories" field, falling back to the "tasks" alias.
-	taskCount := len(prd.Stories)
-	if taskCount == 0 {
-		taskCount = len(prd.Tasks)
+// setCriteriaProgress updates the criteria counters from the PRD returned by
+// the orchestrator, if any.
+func setCriteriaProgress(task *aiv1alpha1.Task, result *OrchestratorResult) {
+	if len(result.PRD) == 0 {
+		return
 	}
+	progress, err := parsePRD(string(result.PRD))
+	if err != nil {
+		return
+	}
+	task.Status.TotalCriteria = int32(progress.Criteria)
+	task.Status.CompletedCriteria = int32(progress.CompletedCriteria)
+}
 
-	return taskCount
+// diffPRD summarizes the stories added, removed and newly passed between two
+// PRD documents. Stories without an ID are ignored. It returns nil when
+// nothing changed.
+func diffPRD(before, after string) (*aiv1alpha1.PRDChanges, error) {
+	var oldDoc, newDoc PRDDocument
+	if err := json.Unmarshal([]byte(before), &oldDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse original PRD: %w", err)
+	}
+	if err := json.Unmarshal([]byte(after), &newDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse updated PRD: %w", err)
+	}
+
+	oldPasses := map[string]bool{}
+	for _, story := range oldDoc.stories() {
+		if story.ID != "" {
+			oldPasses[story.ID] = story.Passes
+		}
+	}
+
+	changes := &aiv1alpha1.PRDChanges{}
+	seen := map[string]bool{}
+	for _, story := range newDoc.stories() {
+		if story.ID == "" || seen[story.ID] {
+			continue
+		}
+		seen[story.ID] = true
+		passed, existed := oldPasses[story.ID]
+		if !existed {
+			changes.Added = append(changes.Added, story.ID)
+		}
+		if story.Passes && !passed {
+			changes.Passed = append(changes.Passed, story.ID)
+		}
+	}
+	for _, story := range oldDoc.stories() {
+		if story.ID != "" && !seen[story.ID] {
+			seen[story.ID] = true
+			changes.Removed = append(changes.Removed, story.ID)
+		}
+	}
+
+	if len(changes.Added) == 0 && len(changes.Removed) == 0 && len(changes.Passed) == 0 {
+		return nil, nil
+	}
+	return changes, nil
+}
+
+// countTasksInPRD counts the total number of tasks in the PRD using proper JSON parsing.
+func (r *TaskReconciler) countTasksInPRD(prdContent string) int {
+	progress, err := parsePRD(prdContent)
+	if err != nil {
+		// If JSON parsing fails, return 0 (unknown task count)
+		return 0
+	}
+	return progress.Stories
 }
 
 // getEffectiveLimits returns the limits with defaults applied.
@@ -790,6 +1468,48 @@ func (r *TaskReconciler) getEffectiveLimits(task *aiv1alpha1.Task) *aiv1alpha1.T
 	return limits
 }
 
+// appendIterationResult records an iteration result in the task's status,
+// dropping the oldest ones beyond spec.iterationHistoryLimit.
+func appendIterationResult(task *aiv1alpha1.Task, result aiv1alpha1.IterationResult) {
+	limit := defaultIterationHistoryLimit
+	if task.Spec.IterationHistoryLimit != nil {
+		limit = max(int(*task.Spec.IterationHistoryLimit), 0)
+	}
+
+	recent := append(task.Status.RecentIterations, result)
+	if len(recent) > limit {
+		recent = recent[len(recent)-limit:]
+	}
+	if len(recent) == 0 {
+		recent = nil
+	}
+	task.Status.RecentIterations = recent
+}
+
+// retainWorkspacePVC releases the task's workspace PVC so it outlives the
+// task: it drops the task's owner reference and labels the PVC orphaned.
+// A missing PVC is not an error.
+func (r *TaskReconciler) retainWorkspacePVC(ctx context.Context, task *aiv1alpha1.Task) error {
+	var pvc corev1.PersistentVolumeClaim
+	key := types.NamespacedName{Name: render.WorkspacePVCName(task), Namespace: task.Namespace}
+	if err := r.Get(ctx, key, &pvc); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	owners := pvc.OwnerReferences[:0]
+	for _, ref := range pvc.OwnerReferences {
+		if ref.UID != task.UID {
+			owners = append(owners, ref)
+		}
+	}
+	pvc.OwnerReferences = owners
+	if pvc.Labels == nil {
+		pvc.Labels = map[string]string{}
+	}
+	pvc.Labels[render.WorkspaceOrphanedLabel] = "true"
+	return r.Update(ctx, &pvc)
+}
+
 // reconcileWorkspacePVC ensures the workspace PVC exists.
 func (r *TaskReconciler) reconcileWorkspacePVC(ctx context.Context, task *aiv1alpha1.Task) error {
 	pvc := render.TaskWorkspacePVC(task)
@@ -814,10 +1534,94 @@ func (r *TaskReconciler) reconcileWorkspacePVC(ctx context.Context, task *aiv1al
 	return err
 }
 
+// rwxIncapableProvisioners are block storage provisioners known not to
+// support ReadWriteMany volumes.
+var rwxIncapableProvisioners = map[string]bool{
+	"ebs.csi.aws.com":          true,
+	"kubernetes.io/aws-ebs":    true,
+	"pd.csi.storage.gke.io":    true,
+	"kubernetes.io/gce-pd":     true,
+	"disk.csi.azure.com":       true,
+	"kubernetes.io/azure-disk": true,
+	"cinder.csi.openstack.org": true,
+	"kubernetes.io/cinder":     true,
+	"rancher.io/local-path":    true,
+}
+
+// validateWorkspaceStorage checks a ReadWriteMany workspace against the
+// provisioner of its storage class, where the class can be found. Classes
+// it cannot read are left for the provisioner to accept or reject.
+func (r *TaskReconciler) validateWorkspaceStorage(ctx context.Context, task *aiv1alpha1.Task) error {
+	if render.WorkspaceAccessMode(task) != corev1.ReadWriteMany {
+		return nil
+	}
+	class, err := r.workspaceStorageClass(ctx, task)
+	if err != nil || class == nil {
+		return err
+	}
+	if rwxIncapableProvisioners[class.Provisioner] {
+		return fmt.Errorf("storage class %s uses provisioner %s, which does not support ReadWriteMany", class.Name, class.Provisioner)
+	}
+	return nil
+}
+
+// workspaceStorageClass returns the storage class the workspace PVC will
+// use: the one named in the task, or the cluster default. It returns nil
+// when the class does not exist or cannot be read.
+func (r *TaskReconciler) workspaceStorageClass(ctx context.Context, task *aiv1alpha1.Task) (*storagev1.StorageClass, error) {
+	if storage := task.Spec.WorkspaceStorage; storage != nil && storage.StorageClassName != nil {
+		var class storagev1.StorageClass
+		err := r.Get(ctx, types.NamespacedName{Name: *storage.StorageClassName}, &class)
+		if errors.IsNotFound(err) || errors.IsForbidden(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &class, nil
+	}
+
+	var classes storagev1.StorageClassList
+	if err := r.List(ctx, &classes); err != nil {
+		if errors.IsForbidden(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for i := range classes.Items {
+		if classes.Items[i].Annotations["storageclass.kubernetes.io/is-default-class"] == "true" {
+			return &classes.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
 // loadTaskSource loads the PRD content from the configured source.
 func (r *TaskReconciler) loadTaskSource(ctx context.Context, task *aiv1alpha1.Task) (string, error) {
-	source := task.Spec.TaskSource
+	return r.loadSource(ctx, task.Spec.TaskSource, task.Namespace)
+}
+
+// loadAdditionalSources loads the task's additional documents keyed by name.
+func (r *TaskReconciler) loadAdditionalSources(ctx context.Context, task *aiv1alpha1.Task) (map[string]string, error) {
+	if len(task.Spec.AdditionalSources) == 0 {
+		return nil, nil
+	}
+	sources := make(map[string]string, len(task.Spec.AdditionalSources))
+	for _, source := range task.Spec.AdditionalSources {
+		if _, dup := sources[source.Name]; dup {
+			return nil, fmt.Errorf("duplicate additional source %q", source.Name)
+		}
+		content, err := r.loadSource(ctx, source.TaskSource, task.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("additional source %q: %w", source.Name, err)
+		}
+		sources[source.Name] = content
+	}
+	return sources, nil
+}
 
+// loadSource reads a single task source from the task's namespace.
+func (r *TaskReconciler) loadSource(ctx context.Context, source aiv1alpha1.TaskSource, namespace string) (string, error) {
 	switch source.Type {
 	case aiv1alpha1.TaskSourceTypeInline:
 		return source.Inline, nil
@@ -829,7 +1633,7 @@ func (r *TaskReconciler) loadTaskSource(ctx context.Context, task *aiv1alpha1.Ta
 		var cm corev1.ConfigMap
 		if err := r.Get(ctx, types.NamespacedName{
 			Name:      source.ConfigMapRef.Name,
-			Namespace: task.Namespace,
+			Namespace: namespace,
 		}, &cm); err != nil {
 			return "", fmt.Errorf("failed to get ConfigMap %s: %w", source.ConfigMapRef.Name, err)
 		}
@@ -850,7 +1654,7 @@ func (r *TaskReconciler) loadTaskSource(ctx context.Context, task *aiv1alpha1.Ta
 		var secret corev1.Secret
 		if err := r.Get(ctx, types.NamespacedName{
 			Name:      source.SecretRef.Name,
-			Namespace: task.Namespace,
+			Namespace: namespace,
 		}, &secret); err != nil {
 			return "", fmt.Errorf("failed to get Secret %s: %w", source.SecretRef.Name, err)
 		}
@@ -869,6 +1673,46 @@ func (r *TaskReconciler) loadTaskSource(ctx context.Context, task *aiv1alpha1.Ta
 	}
 }
 
+// deletedTaskSource returns the ConfigMap or Secret, of the PRD or an
+// additional source, that no longer exists, or "" if all are present.
+func (r *TaskReconciler) deletedTaskSource(ctx context.Context, task *aiv1alpha1.Task) (string, error) {
+	sources := []aiv1alpha1.TaskSource{task.Spec.TaskSource}
+	for _, source := range task.Spec.AdditionalSources {
+		sources = append(sources, source.TaskSource)
+	}
+	for _, source := range sources {
+		var obj client.Object
+		var kind, name string
+		switch {
+		case source.Type == aiv1alpha1.TaskSourceTypeConfigMap && source.ConfigMapRef != nil:
+			obj, kind, name = &corev1.ConfigMap{}, "ConfigMap", source.ConfigMapRef.Name
+		case source.Type == aiv1alpha1.TaskSourceTypeSecret && source.SecretRef != nil:
+			obj, kind, name = &corev1.Secret{}, "Secret", source.SecretRef.Name
+		default:
+			continue
+		}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: task.Namespace}, obj)
+		if errors.IsNotFound(err) {
+			return fmt.Sprintf("%s %q", kind, name), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// setSourceDeletedCondition records that a task source was deleted.
+func (r *TaskReconciler) setSourceDeletedCondition(task *aiv1alpha1.Task, message string) {
+	r.setCondition(task, metav1.Condition{
+		Type:               taskSourceDeletedCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: task.Generation,
+		Reason:             "NotFound",
+		Message:            message,
+	})
+}
+
 // persistUpdatedPRD writes the updated PRD back to the source ConfigMap.
 func (r *TaskReconciler) persistUpdatedPRD(ctx context.Context, task *aiv1alpha1.Task, updatedPRD string) error {
 	source := task.Spec.TaskSource
@@ -925,9 +1769,44 @@ func (r *TaskReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// controller creates itself -- the workspace PVC and orchestrator Job --
 	// are owned.
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&aiv1alpha1.Task{}).
+		For(&aiv1alpha1.Task{}, builder.WithPredicates(specChanged())).
 		Owns(&corev1.PersistentVolumeClaim{}).
 		Owns(&batchv1.Job{}).
+		// Watch Namespaces so toggling the pause annotation takes effect at once
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.findTasksForNamespace),
+		).
+		WithEventFilter(r.Namespaces.Predicate()).
 		Named("task").
 		Complete(r)
 }
+
+// namespacePaused reports whether the namespace carries the pause annotation.
+func (r *TaskReconciler) namespacePaused(ctx context.Context, namespace string) (bool, error) {
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+	return ns.Annotations[NamespacePauseAnnotation] == "true", nil
+}
+
+// findTasksForNamespace maps a Namespace to all Tasks in it.
+func (r *TaskReconciler) findTasksForNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	var taskList aiv1alpha1.TaskList
+	if err := r.List(ctx, &taskList, client.InNamespace(obj.GetName())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list Tasks for Namespace watch")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(taskList.Items))
+	for _, task := range taskList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: task.Name, Namespace: task.Namespace},
+		})
+	}
+	return requests
+}
@@ -4,13 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+	"github.com/jarsater/mcp-fabric/operator/internal/metrics"
+	"github.com/jarsater/mcp-fabric/operator/internal/render"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -48,6 +58,7 @@ func newTestScheme() *runtime.Scheme {
 	_ = aiv1alpha1.AddToScheme(scheme)
 	_ = corev1.AddToScheme(scheme)
 	_ = batchv1.AddToScheme(scheme)
+	_ = storagev1.AddToScheme(scheme)
 	return scheme
 }
 
@@ -175,6 +186,118 @@ func TestReconcile_PausedTask(t *testing.T) {
 	}
 }
 
+func TestReconcile_NamespacePause(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{NamespacePauseAnnotation: "true"},
+		},
+	}
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-task",
+			Namespace:  "default",
+			Finalizers: []string{taskFinalizer},
+		},
+		Spec: aiv1alpha1.TaskSpec{
+			WorkerRef: aiv1alpha1.AgentReference{Name: "worker"},
+			TaskSource: aiv1alpha1.TaskSource{
+				Type:   aiv1alpha1.TaskSourceTypeInline,
+				Inline: `{"tasks":[]}`,
+			},
+		},
+		Status: aiv1alpha1.TaskStatus{
+			Phase: aiv1alpha1.TaskPhaseRunning,
+		},
+	}
+
+	r := newTestReconciler(ns, task)
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-task", Namespace: "default"}
+
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Error("expected no requeue for paused task")
+	}
+
+	var updatedTask aiv1alpha1.Task
+	if err := r.Get(ctx, key, &updatedTask); err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if updatedTask.Status.Phase != aiv1alpha1.TaskPhasePaused {
+		t.Errorf("expected phase Paused, got %s", updatedTask.Status.Phase)
+	}
+	cond := meta.FindStatusCondition(updatedTask.Status.Conditions, "Ready")
+	if cond == nil || cond.Reason != "NamespacePaused" {
+		t.Errorf("expected Ready reason NamespacePaused, got %+v", cond)
+	}
+
+	// The Namespace watch must enqueue the Task
+	if reqs := r.findTasksForNamespace(ctx, ns); len(reqs) != 1 || reqs[0].NamespacedName != key {
+		t.Errorf("expected namespace to map to %v, got %v", key, reqs)
+	}
+
+	// Removing the annotation resumes the Task
+	delete(ns.Annotations, NamespacePauseAnnotation)
+	if err := r.Update(ctx, ns); err != nil {
+		t.Fatalf("failed to update namespace: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Get(ctx, key, &updatedTask); err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if updatedTask.Status.Phase != aiv1alpha1.TaskPhaseRunning {
+		t.Errorf("expected phase Running after resume, got %s", updatedTask.Status.Phase)
+	}
+}
+
+func TestReconcile_NamespacePauseSkipsFinishedTasks(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{NamespacePauseAnnotation: "true"},
+		},
+	}
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-task",
+			Namespace:  "default",
+			Finalizers: []string{taskFinalizer},
+		},
+		Spec: aiv1alpha1.TaskSpec{
+			WorkerRef: aiv1alpha1.AgentReference{Name: "worker"},
+			TaskSource: aiv1alpha1.TaskSource{
+				Type:   aiv1alpha1.TaskSourceTypeInline,
+				Inline: `{"tasks":[]}`,
+			},
+		},
+		Status: aiv1alpha1.TaskStatus{
+			Phase: aiv1alpha1.TaskPhaseCompleted,
+		},
+	}
+
+	r := newTestReconciler(ns, task)
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-task", Namespace: "default"}
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var updatedTask aiv1alpha1.Task
+	if err := r.Get(ctx, key, &updatedTask); err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if updatedTask.Status.Phase != aiv1alpha1.TaskPhaseCompleted {
+		t.Errorf("expected phase Completed, got %s", updatedTask.Status.Phase)
+	}
+}
+
 func TestReconcile_CompletedTaskNoOp(t *testing.T) {
 	task := &aiv1alpha1.Task{
 		ObjectMeta: metav1.ObjectMeta{
@@ -196,6 +319,8 @@ func TestReconcile_CompletedTaskNoOp(t *testing.T) {
 
 	r := newTestReconciler(task)
 	ctx := context.Background()
+	noOps := metrics.ReconcileTotal.WithLabelValues(metrics.ControllerTask, metrics.ResultNoOp)
+	before := testutil.ToFloat64(noOps)
 
 	result, err := r.Reconcile(ctx, ctrl.Request{
 		NamespacedName: types.NamespacedName{
@@ -210,6 +335,47 @@ func TestReconcile_CompletedTaskNoOp(t *testing.T) {
 	if result.RequeueAfter != 0 {
 		t.Error("expected no requeue for completed task")
 	}
+	if got := testutil.ToFloat64(noOps) - before; got != 1 {
+		t.Errorf("expected one no-op reconcile recorded, got %v", got)
+	}
+}
+
+func TestAppendIterationResult(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         *int32
+		existing      int
+		wantLen       int
+		wantOldestRun int32
+	}{
+		{name: "default keeps 10", existing: 10, wantLen: 10, wantOldestRun: 2},
+		{name: "below the limit", limit: ptr.To(int32(5)), existing: 2, wantLen: 3, wantOldestRun: 1},
+		{name: "lowered limit trims older results", limit: ptr.To(int32(3)), existing: 10, wantLen: 3, wantOldestRun: 9},
+		{name: "raised limit keeps more history", limit: ptr.To(int32(20)), existing: 14, wantLen: 15, wantOldestRun: 1},
+		{name: "zero keeps none", limit: ptr.To(int32(0)), existing: 4, wantLen: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &aiv1alpha1.Task{Spec: aiv1alpha1.TaskSpec{IterationHistoryLimit: tt.limit}}
+			for i := 1; i <= tt.existing; i++ {
+				task.Status.RecentIterations = append(task.Status.RecentIterations, aiv1alpha1.IterationResult{Iteration: int32(i)})
+			}
+
+			appendIterationResult(task, aiv1alpha1.IterationResult{Iteration: int32(tt.existing + 1)})
+
+			recent := task.Status.RecentIterations
+			if len(recent) != tt.wantLen {
+				t.Fatalf("expected %d results, got %d", tt.wantLen, len(recent))
+			}
+			if tt.wantLen == 0 {
+				return
+			}
+			if recent[0].Iteration != tt.wantOldestRun || recent[len(recent)-1].Iteration != int32(tt.existing+1) {
+				t.Errorf("expected iterations %d..%d, got %d..%d", tt.wantOldestRun, tt.existing+1, recent[0].Iteration, recent[len(recent)-1].Iteration)
+			}
+		})
+	}
 }
 
 func TestGetEffectiveLimits_Defaults(t *testing.T) {
@@ -417,6 +583,110 @@ func TestLoadTaskSource_ConfigMapNotFound(t *testing.T) {
 	}
 }
 
+func TestLoadAdditionalSources(t *testing.T) {
+	docs := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "task-docs", Namespace: "default"},
+		Data:       map[string]string{"requirements.md": "# Requirements"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "task-design", Namespace: "default"},
+		Data:       map[string][]byte{"design.md": []byte("# Design")},
+	}
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"},
+		Spec: aiv1alpha1.TaskSpec{
+			TaskSource: aiv1alpha1.TaskSource{
+				Type:   aiv1alpha1.TaskSourceTypeInline,
+				Inline: `{"tasks":[]}`,
+			},
+			AdditionalSources: []aiv1alpha1.NamedTaskSource{
+				{
+					Name: "requirements",
+					TaskSource: aiv1alpha1.TaskSource{
+						Type: aiv1alpha1.TaskSourceTypeConfigMap,
+						ConfigMapRef: &corev1.ConfigMapKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "task-docs"},
+							Key:                  "requirements.md",
+						},
+					},
+				},
+				{
+					Name: "design",
+					TaskSource: aiv1alpha1.TaskSource{
+						Type: aiv1alpha1.TaskSourceTypeSecret,
+						SecretRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "task-design"},
+							Key:                  "design.md",
+						},
+					},
+				},
+				{
+					Name:       "notes",
+					TaskSource: aiv1alpha1.TaskSource{Type: aiv1alpha1.TaskSourceTypeInline, Inline: "keep it small"},
+				},
+			},
+		},
+	}
+
+	r := newTestReconciler(task, docs, secret)
+
+	sources, err := r.loadAdditionalSources(context.Background(), task)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"requirements": "# Requirements",
+		"design":       "# Design",
+		"notes":        "keep it small",
+	}
+	if !reflect.DeepEqual(sources, expected) {
+		t.Errorf("expected %v, got %v", expected, sources)
+	}
+}
+
+func TestLoadAdditionalSources_Errors(t *testing.T) {
+	inline := aiv1alpha1.TaskSource{Type: aiv1alpha1.TaskSourceTypeInline, Inline: "x"}
+	missing := aiv1alpha1.TaskSource{
+		Type: aiv1alpha1.TaskSourceTypeConfigMap,
+		ConfigMapRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "non-existent"},
+			Key:                  "design.md",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		sources []aiv1alpha1.NamedTaskSource
+		wantErr string
+	}{
+		{
+			name:    "missing configmap names the source",
+			sources: []aiv1alpha1.NamedTaskSource{{Name: "design", TaskSource: missing}},
+			wantErr: `additional source "design"`,
+		},
+		{
+			name:    "duplicate names",
+			sources: []aiv1alpha1.NamedTaskSource{{Name: "notes", TaskSource: inline}, {Name: "notes", TaskSource: inline}},
+			wantErr: `duplicate additional source "notes"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &aiv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"},
+				Spec:       aiv1alpha1.TaskSpec{AdditionalSources: tt.sources},
+			}
+			r := newTestReconciler(task)
+
+			_, err := r.loadAdditionalSources(context.Background(), task)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestCountTasksInPRD(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -487,6 +757,199 @@ func TestCountTasksInPRD(t *testing.T) {
 	}
 }
 
+func TestParsePRD_AcceptanceCriteria(t *testing.T) {
+	tests := []struct {
+		name     string
+		prd      string
+		expected PRDProgress
+	}{
+		{
+			name: "stories with acceptance criteria",
+			prd: `{"stories":[
+				{"id":"s1","title":"A","acceptanceCriteria":["a","b","c"],"passes":true},
+				{"id":"s2","title":"B","acceptanceCriteria":["d","e"]}
+			]}`,
+			expected: PRDProgress{Stories: 2, CompletedStories: 1, Criteria: 5, CompletedCriteria: 3, StoryIDs: []string{"s1", "s2"}, TaskIDs: map[string][]string{}},
+		},
+		{
+			name:     "stories without criteria",
+			prd:      `{"stories":[{"id":"s1","title":"A","passes":true},{"id":"s2","title":"B"}]}`,
+			expected: PRDProgress{Stories: 2, CompletedStories: 1, StoryIDs: []string{"s1", "s2"}, TaskIDs: map[string][]string{}},
+		},
+		{
+			name: "per-story task ids as strings and objects",
+			prd: `{"stories":[
+				{"id":"s1","title":"A","tasks":["t1","t2"]},
+				{"id":"s2","title":"B","tasks":[{"id":"t3","title":"x"},"t4"]}
+			]}`,
+			expected: PRDProgress{Stories: 2, StoryIDs: []string{"s1", "s2"}, TaskIDs: map[string][]string{"s1": {"t1", "t2"}, "s2": {"t3", "t4"}}},
+		},
+		{
+			name: "mixed criteria shapes",
+			prd: `{"tasks":[
+				{"id":"1","title":"A","acceptanceCriteria":["plain",{"description":"structured"}],"passes":true},
+				{"id":"2","title":"B","priority":2}
+			]}`,
+			expected: PRDProgress{Stories: 2, CompletedStories: 1, Criteria: 2, CompletedCriteria: 2, StoryIDs: []string{"1", "2"}, TaskIDs: map[string][]string{}},
+		},
+		{
+			name: "stories take precedence over tasks",
+			prd: `{"stories":[{"id":"s1","acceptanceCriteria":["a"]}],
+				"tasks":[{"id":"t1","acceptanceCriteria":["a","b"]},{"id":"t2"}]}`,
+			expected: PRDProgress{Stories: 1, Criteria: 1, StoryIDs: []string{"s1"}, TaskIDs: map[string][]string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress, err := parsePRD(tt.prd)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(progress, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, progress)
+			}
+		})
+	}
+}
+
+func TestParsePRD_Invalid(t *testing.T) {
+	for _, prd := range []string{`not json`, `{"stories":[{"id":"s1","tasks":[1]}]}`} {
+		if _, err := parsePRD(prd); err == nil {
+			t.Errorf("expected error for %s", prd)
+		}
+	}
+}
+
+func TestDiffPRD(t *testing.T) {
+	before := `{"stories":[
+		{"id":"s1","title":"One","passes":true},
+		{"id":"s2","title":"Two"},
+		{"id":"s3","title":"Three"}
+	]}`
+
+	tests := []struct {
+		name  string
+		after string
+		want  *aiv1alpha1.PRDChanges
+	}{
+		{
+			name:  "unchanged",
+			after: before,
+			want:  nil,
+		},
+		{
+			name: "passed added and removed",
+			after: `{"stories":[
+				{"id":"s1","title":"One","passes":true},
+				{"id":"s2","title":"Two","passes":true},
+				{"id":"s4","title":"Four","passes":true},
+				{"title":"No id"}
+			]}`,
+			want: &aiv1alpha1.PRDChanges{
+				Added:   []string{"s4"},
+				Removed: []string{"s3"},
+				Passed:  []string{"s2", "s4"},
+			},
+		},
+		{
+			name:  "tasks alias",
+			after: `{"tasks":[{"id":"s1","passes":true},{"id":"s2"},{"id":"s3","passes":true}]}`,
+			want:  &aiv1alpha1.PRDChanges{Passed: []string{"s3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diffPRD(before, tt.after)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := diffPRD(before, `not json`); err == nil {
+		t.Error("expected error for invalid updated PRD")
+	}
+}
+
+func TestHandlePendingPhase_ResumeFromTaskID(t *testing.T) {
+	tests := []struct {
+		name        string
+		resumeFrom  string
+		expectPhase aiv1alpha1.TaskPhase
+		expectJob   bool
+	}{
+		{name: "known story id", resumeFrom: "s2", expectPhase: aiv1alpha1.TaskPhaseRunning, expectJob: true},
+		{name: "unknown story id", resumeFrom: "s9", expectPhase: aiv1alpha1.TaskPhasePending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := &aiv1alpha1.Agent{
+				ObjectMeta: metav1.ObjectMeta{Name: "task-orchestrator", Namespace: "default"},
+				Spec:       aiv1alpha1.AgentSpec{Image: "orchestrator:v1"},
+			}
+			worker := &aiv1alpha1.Agent{
+				ObjectMeta: metav1.ObjectMeta{Name: "code-worker", Namespace: "default"},
+				Spec:       aiv1alpha1.AgentSpec{Image: "worker:v1"},
+			}
+			task := &aiv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"},
+				Spec: aiv1alpha1.TaskSpec{
+					WorkerRef: aiv1alpha1.AgentReference{Name: "code-worker"},
+					TaskSource: aiv1alpha1.TaskSource{
+						Type:   aiv1alpha1.TaskSourceTypeInline,
+						Inline: `{"stories":[{"id":"s1"},{"id":"s2"},{"id":"s3"}]}`,
+					},
+					ResumeFromTaskID: tt.resumeFrom,
+				},
+				Status: aiv1alpha1.TaskStatus{Phase: aiv1alpha1.TaskPhasePending},
+			}
+
+			r := newTestReconciler(task, orchestrator, worker)
+			ctx := context.Background()
+			if _, err := r.handlePendingPhase(ctx, task); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if task.Status.Phase != tt.expectPhase {
+				t.Errorf("expected phase %s, got %s", tt.expectPhase, task.Status.Phase)
+			}
+
+			var job batchv1.Job
+			err := r.Get(ctx, types.NamespacedName{Name: "test-task-orchestrator", Namespace: "default"}, &job)
+			if !tt.expectJob {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("expected no Job for an unknown resume id, got err=%v", err)
+				}
+				cond := meta.FindStatusCondition(task.Status.Conditions, "Ready")
+				if cond == nil || cond.Reason != "InvalidResumeTaskID" {
+					t.Errorf("expected reason InvalidResumeTaskID, got %+v", cond)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get orchestrator job: %v", err)
+			}
+			var config map[string]interface{}
+			for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+				if env.Name == "TASK_CONFIG" {
+					if err := json.Unmarshal([]byte(env.Value), &config); err != nil {
+						t.Fatalf("failed to parse TASK_CONFIG: %v", err)
+					}
+				}
+			}
+			if config["resumeFromTaskId"] != tt.resumeFrom {
+				t.Errorf("expected resumeFromTaskId %q in TASK_CONFIG, got %v", tt.resumeFrom, config["resumeFromTaskId"])
+			}
+		})
+	}
+}
+
 func TestGetOrchestratorAgent_Default(t *testing.T) {
 	// Create the default orchestrator agent
 	orchestrator := &aiv1alpha1.Agent{
@@ -521,42 +984,154 @@ func TestGetOrchestratorAgent_Default(t *testing.T) {
 	}
 }
 
-func TestGetOrchestratorAgent_CustomRef(t *testing.T) {
-	// Create a custom orchestrator agent
-	customOrchestrator := &aiv1alpha1.Agent{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "custom-orchestrator",
-			Namespace: "custom-ns",
-		},
-		Spec: aiv1alpha1.AgentSpec{
-			Image: "custom-orch:v1",
-		},
+func TestReconcile_CustomDefaultOrchestrator(t *testing.T) {
+	orchestrator := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-orchestrator", Namespace: "default"},
+		Spec:       aiv1alpha1.AgentSpec{Image: "tenant-orchestrator:v1"},
+	}
+	worker := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "code-worker", Namespace: "default"},
+		Spec:       aiv1alpha1.AgentSpec{Image: "worker:v1"},
 	}
-
 	task := &aiv1alpha1.Task{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "test-task",
-			Namespace: "default",
+			Name:       "test-task",
+			Namespace:  "default",
+			Finalizers: []string{taskFinalizer},
 		},
 		Spec: aiv1alpha1.TaskSpec{
-			OrchestratorRef: &aiv1alpha1.AgentReference{
-				Name:      "custom-orchestrator",
-				Namespace: "custom-ns",
+			WorkerRef: aiv1alpha1.AgentReference{Name: "code-worker"},
+			TaskSource: aiv1alpha1.TaskSource{
+				Type:   aiv1alpha1.TaskSourceTypeInline,
+				Inline: `{"tasks":[{"id":"1","title":"Test"}]}`,
 			},
 		},
+		Status: aiv1alpha1.TaskStatus{
+			Phase: aiv1alpha1.TaskPhasePending,
+		},
 	}
 
-	r := newTestReconciler(task, customOrchestrator)
+	r := newTestReconciler(task, orchestrator, worker)
+	r.DefaultOrchestratorName = "tenant-orchestrator"
 	ctx := context.Background()
 
-	agent, err := r.getOrchestratorAgent(ctx, task)
-	if err != nil {
-		t.Errorf("unexpected error: %v", err)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-task", Namespace: "default"}}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if agent.Name != "custom-orchestrator" {
-		t.Errorf("expected agent name 'custom-orchestrator', got %s", agent.Name)
+
+	var updatedTask aiv1alpha1.Task
+	if err := r.Get(ctx, req.NamespacedName, &updatedTask); err != nil {
+		t.Fatalf("failed to get task: %v", err)
 	}
-	if agent.Namespace != "custom-ns" {
+	if updatedTask.Status.Phase != aiv1alpha1.TaskPhaseRunning {
+		t.Fatalf("expected phase Running, got %s", updatedTask.Status.Phase)
+	}
+
+	var job batchv1.Job
+	if err := r.Get(ctx, types.NamespacedName{Name: "test-task-orchestrator", Namespace: "default"}, &job); err != nil {
+		t.Fatalf("failed to get orchestrator job: %v", err)
+	}
+	if image := job.Spec.Template.Spec.Containers[0].Image; image != "tenant-orchestrator:v1" {
+		t.Errorf("expected orchestrator image tenant-orchestrator:v1, got %s", image)
+	}
+}
+
+func TestReconcile_TooManyTasks(t *testing.T) {
+	orchestrator := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "task-orchestrator", Namespace: "default"},
+		Spec:       aiv1alpha1.AgentSpec{Image: "orchestrator:v1"},
+	}
+	worker := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "code-worker", Namespace: "default"},
+		Spec:       aiv1alpha1.AgentSpec{Image: "worker:v1"},
+	}
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-task",
+			Namespace:  "default",
+			Finalizers: []string{taskFinalizer},
+		},
+		Spec: aiv1alpha1.TaskSpec{
+			WorkerRef: aiv1alpha1.AgentReference{Name: "code-worker"},
+			TaskSource: aiv1alpha1.TaskSource{
+				Type:   aiv1alpha1.TaskSourceTypeInline,
+				Inline: `{"tasks":[{"id":"1"},{"id":"2"},{"id":"3"}]}`,
+			},
+		},
+		Status: aiv1alpha1.TaskStatus{
+			Phase: aiv1alpha1.TaskPhasePending,
+		},
+	}
+
+	r := newTestReconciler(task, orchestrator, worker)
+	r.MaxPRDTasks = 2
+	ctx := context.Background()
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-task", Namespace: "default"}}
+	result, err := r.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Errorf("expected no requeue for an over-limit PRD, got %v", result.RequeueAfter)
+	}
+
+	var updatedTask aiv1alpha1.Task
+	if err := r.Get(ctx, req.NamespacedName, &updatedTask); err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if updatedTask.Status.Phase != aiv1alpha1.TaskPhaseFailed {
+		t.Errorf("expected phase Failed, got %s", updatedTask.Status.Phase)
+	}
+	cond := meta.FindStatusCondition(updatedTask.Status.Conditions, "Ready")
+	if cond == nil || cond.Reason != "TooManyTasks" {
+		t.Fatalf("expected Ready condition with reason TooManyTasks, got %+v", cond)
+	}
+
+	var job batchv1.Job
+	err = r.Get(ctx, types.NamespacedName{Name: "test-task-orchestrator", Namespace: "default"}, &job)
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected no orchestrator Job for an over-limit PRD, got err=%v", err)
+	}
+}
+
+func TestGetOrchestratorAgent_CustomRef(t *testing.T) {
+	// Create a custom orchestrator agent
+	customOrchestrator := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "custom-orchestrator",
+			Namespace: "custom-ns",
+		},
+		Spec: aiv1alpha1.AgentSpec{
+			Image: "custom-orch:v1",
+		},
+	}
+
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-task",
+			Namespace: "default",
+		},
+		Spec: aiv1alpha1.TaskSpec{
+			OrchestratorRef: &aiv1alpha1.AgentReference{
+				Name:      "custom-orchestrator",
+				Namespace: "custom-ns",
+			},
+		},
+	}
+
+	r := newTestReconciler(task, customOrchestrator)
+	ctx := context.Background()
+
+	agent, err := r.getOrchestratorAgent(ctx, task)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if agent.Name != "custom-orchestrator" {
+		t.Errorf("expected agent name 'custom-orchestrator', got %s", agent.Name)
+	}
+	if agent.Namespace != "custom-ns" {
 		t.Errorf("expected namespace 'custom-ns', got %s", agent.Namespace)
 	}
 }
@@ -595,6 +1170,53 @@ func TestReconcileWorkspacePVC(t *testing.T) {
 	}
 }
 
+func TestValidateWorkspaceStorage(t *testing.T) {
+	ebs := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "gp3"},
+		Provisioner: "ebs.csi.aws.com",
+	}
+	efs := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "efs"},
+		Provisioner: "efs.csi.aws.com",
+	}
+	defaultEBS := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "standard",
+			Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"},
+		},
+		Provisioner: "ebs.csi.aws.com",
+	}
+
+	tests := []struct {
+		name    string
+		storage *aiv1alpha1.WorkspaceStorageConfig
+		classes []client.Object
+		wantErr bool
+	}{
+		{name: "default access mode", classes: []client.Object{defaultEBS}},
+		{name: "read-write-once on block storage", storage: &aiv1alpha1.WorkspaceStorageConfig{StorageClassName: ptr.To("gp3"), AccessMode: corev1.ReadWriteOnce}, classes: []client.Object{ebs}},
+		{name: "read-write-many on shared storage", storage: &aiv1alpha1.WorkspaceStorageConfig{StorageClassName: ptr.To("efs"), AccessMode: corev1.ReadWriteMany}, classes: []client.Object{efs}},
+		{name: "read-write-many on block storage", storage: &aiv1alpha1.WorkspaceStorageConfig{StorageClassName: ptr.To("gp3"), AccessMode: corev1.ReadWriteMany}, classes: []client.Object{ebs}, wantErr: true},
+		{name: "read-write-many on block default class", storage: &aiv1alpha1.WorkspaceStorageConfig{AccessMode: corev1.ReadWriteMany}, classes: []client.Object{efs, defaultEBS}, wantErr: true},
+		{name: "unknown class is left to the provisioner", storage: &aiv1alpha1.WorkspaceStorageConfig{StorageClassName: ptr.To("nfs"), AccessMode: corev1.ReadWriteMany}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &aiv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"},
+				Spec:       aiv1alpha1.TaskSpec{WorkspaceStorage: tt.storage},
+			}
+			r := newTestReconciler(tt.classes...)
+
+			err := r.validateWorkspaceStorage(context.Background(), task)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateWorkspaceStorage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestSetCondition(t *testing.T) {
 	task := &aiv1alpha1.Task{
 		ObjectMeta: metav1.ObjectMeta{
@@ -640,7 +1262,492 @@ func TestSetCondition(t *testing.T) {
 	}
 }
 
-func TestHandlePendingPhase_MissingOrchestrator(t *testing.T) {
+func TestSetGitConditions_GitError(t *testing.T) {
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-task",
+			Namespace: "git-error-ns",
+		},
+	}
+	r := newTestReconciler()
+
+	r.setGitConditions(task, &OrchestratorResult{
+		Passed:   true,
+		GitError: "remote: Permission to org/repo.git denied",
+	})
+
+	cond := meta.FindStatusCondition(task.Status.Conditions, "GitPushed")
+	if cond == nil {
+		t.Fatal("expected GitPushed condition")
+	}
+	if cond.Status != metav1.ConditionFalse || cond.Reason != "PushFailed" {
+		t.Errorf("expected GitPushed=False/PushFailed, got %s/%s", cond.Status, cond.Reason)
+	}
+	if cond.Message != "remote: Permission to org/repo.git denied" {
+		t.Errorf("expected git error message, got %q", cond.Message)
+	}
+	if meta.FindStatusCondition(task.Status.Conditions, "PullRequestCreated") != nil {
+		t.Error("expected no PullRequestCreated condition when push failed")
+	}
+	if got := testutil.ToFloat64(metrics.TaskGitPushTotal.WithLabelValues("git-error-ns", metrics.ResultError)); got != 1 {
+		t.Errorf("expected 1 failed push recorded, got %v", got)
+	}
+}
+
+func TestSetGitConditions(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     OrchestratorResult
+		wantPushed string
+		wantPR     string
+	}{
+		{
+			name:       "pushed with pull request",
+			result:     OrchestratorResult{Pushed: true, CommitSHA: "abc123", PullRequestURL: "https://github.com/org/repo/pull/1"},
+			wantPushed: "Pushed",
+			wantPR:     "Created",
+		},
+		{
+			name:       "pushed but pull request failed",
+			result:     OrchestratorResult{Pushed: true, CommitSHA: "abc123", GitError: "gh: not authorized"},
+			wantPushed: "Pushed",
+			wantPR:     "CreateFailed",
+		},
+		{
+			name:       "no changes",
+			result:     OrchestratorResult{NoChanges: true},
+			wantPushed: "NoChanges",
+		},
+		{
+			name:   "git disabled",
+			result: OrchestratorResult{Passed: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &aiv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"}}
+			r := newTestReconciler()
+
+			r.setGitConditions(task, &tt.result)
+
+			for condType, want := range map[string]string{"GitPushed": tt.wantPushed, "PullRequestCreated": tt.wantPR} {
+				cond := meta.FindStatusCondition(task.Status.Conditions, condType)
+				switch {
+				case want == "" && cond != nil:
+					t.Errorf("expected no %s condition, got %s", condType, cond.Reason)
+				case want != "" && cond == nil:
+					t.Errorf("expected %s condition with reason %s", condType, want)
+				case want != "" && cond.Reason != want:
+					t.Errorf("expected %s reason %s, got %s", condType, want, cond.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestSetQualityGateCondition(t *testing.T) {
+	task := &aiv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"}}
+	r := newTestReconciler()
+
+	r.setQualityGateCondition(task, &OrchestratorResult{MissingCommands: []string{"npm", "make"}})
+
+	cond := meta.FindStatusCondition(task.Status.Conditions, qualityGateCommandMissingCondition)
+	if cond == nil {
+		t.Fatal("expected QualityGateCommandMissing condition")
+	}
+	if cond.Status != metav1.ConditionTrue || cond.Reason != "CommandNotFound" {
+		t.Errorf("expected QualityGateCommandMissing=True/CommandNotFound, got %s/%s", cond.Status, cond.Reason)
+	}
+	if cond.Message != "Quality gate commands not found: npm, make" {
+		t.Errorf("unexpected message %q", cond.Message)
+	}
+
+	// A later run with every command present clears the condition
+	r.setQualityGateCondition(task, &OrchestratorResult{Passed: true})
+	if meta.FindStatusCondition(task.Status.Conditions, qualityGateCommandMissingCondition) != nil {
+		t.Error("expected QualityGateCommandMissing condition to be cleared")
+	}
+}
+
+func TestHandlePendingPhase_MissingOrchestrator(t *testing.T) {
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-task",
+			Namespace: "default",
+		},
+		Spec: aiv1alpha1.TaskSpec{
+			WorkerRef: aiv1alpha1.AgentReference{Name: "worker"},
+			TaskSource: aiv1alpha1.TaskSource{
+				Type:   aiv1alpha1.TaskSourceTypeInline,
+				Inline: `{"tasks":[{"id":"1","title":"Test"}]}`,
+			},
+		},
+		Status: aiv1alpha1.TaskStatus{
+			Phase: aiv1alpha1.TaskPhasePending,
+		},
+	}
+
+	// Note: No orchestrator agent created - should requeue with delay
+	r := newTestReconciler(task)
+	ctx := context.Background()
+
+	result, err := r.handlePendingPhase(ctx, task)
+
+	// The function handles missing orchestrator gracefully - no error returned
+	// It sets a condition and requeues after failure delay
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// Should requeue after failure delay
+	if result.RequeueAfter != failureRequeueDelay {
+		t.Errorf("expected RequeueAfter %v, got %v", failureRequeueDelay, result.RequeueAfter)
+	}
+
+	// Verify condition was set
+	var updatedTask aiv1alpha1.Task
+	if err := r.Get(ctx, types.NamespacedName{Name: "test-task", Namespace: "default"}, &updatedTask); err != nil {
+		t.Errorf("failed to get task: %v", err)
+	}
+
+	// Check that the OrchestratorNotFound condition was set
+	found := false
+	for _, cond := range updatedTask.Status.Conditions {
+		if cond.Type == "Ready" && cond.Reason == "OrchestratorNotFound" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("expected OrchestratorNotFound condition to be set")
+	}
+}
+
+func TestHandlePendingPhase_Success(t *testing.T) {
+	orchestrator := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      defaultOrchestratorName,
+			Namespace: "default",
+		},
+		Spec: aiv1alpha1.AgentSpec{
+			Image: "orchestrator:v1",
+		},
+	}
+
+	worker := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "code-worker",
+			Namespace: "default",
+		},
+		Spec: aiv1alpha1.AgentSpec{
+			Image: "worker:v1",
+		},
+	}
+
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-task",
+			Namespace: "default",
+			UID:       "test-uid-123",
+		},
+		Spec: aiv1alpha1.TaskSpec{
+			WorkerRef: aiv1alpha1.AgentReference{Name: "code-worker"},
+			TaskSource: aiv1alpha1.TaskSource{
+				Type:   aiv1alpha1.TaskSourceTypeInline,
+				Inline: `{"tasks":[{"id":"1","title":"Test","acceptanceCriteria":["a","b"]}]}`,
+			},
+		},
+		Status: aiv1alpha1.TaskStatus{
+			Phase: aiv1alpha1.TaskPhasePending,
+		},
+	}
+
+	r := newTestReconciler(task, orchestrator, worker)
+	ctx := context.Background()
+
+	result, err := r.handlePendingPhase(ctx, task)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Should requeue to poll job status
+	if result.RequeueAfter != jobPollInterval {
+		t.Errorf("expected RequeueAfter %v, got %v", jobPollInterval, result.RequeueAfter)
+	}
+
+	// Verify task status was updated
+	var updatedTask aiv1alpha1.Task
+	if err := r.Get(ctx, types.NamespacedName{Name: "test-task", Namespace: "default"}, &updatedTask); err != nil {
+		t.Errorf("failed to get task: %v", err)
+	}
+	if updatedTask.Status.Phase != aiv1alpha1.TaskPhaseRunning {
+		t.Errorf("expected phase Running, got %s", updatedTask.Status.Phase)
+	}
+	if updatedTask.Status.TotalTasks != 1 || updatedTask.Status.TotalCriteria != 2 {
+		t.Errorf("expected 1 task with 2 criteria, got %d tasks with %d criteria",
+			updatedTask.Status.TotalTasks, updatedTask.Status.TotalCriteria)
+	}
+
+	// Verify orchestrator job was created
+	var job batchv1.Job
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      "test-task-orchestrator",
+		Namespace: "default",
+	}, &job); err != nil {
+		t.Errorf("failed to get orchestrator job: %v", err)
+	}
+
+	// Verify workspace PVC was created
+	var pvc corev1.PersistentVolumeClaim
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      "test-task-workspace",
+		Namespace: "default",
+	}, &pvc); err != nil {
+		t.Errorf("failed to get workspace PVC: %v", err)
+	}
+}
+
+func TestHandlePendingPhase_RerunReusesPullRequest(t *testing.T) {
+	orchestrator := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultOrchestratorName, Namespace: "default"},
+		Spec:       aiv1alpha1.AgentSpec{Image: "orchestrator:v1"},
+	}
+	worker := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "code-worker", Namespace: "default"},
+		Spec:       aiv1alpha1.AgentSpec{Image: "worker:v1"},
+	}
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default", UID: "test-uid-123"},
+		Spec: aiv1alpha1.TaskSpec{
+			WorkerRef: aiv1alpha1.AgentReference{Name: "code-worker"},
+			TaskSource: aiv1alpha1.TaskSource{
+				Type:   aiv1alpha1.TaskSourceTypeInline,
+				Inline: `{"tasks":[{"id":"1","title":"Test"}]}`,
+			},
+			Git: &aiv1alpha1.GitConfig{
+				URL:               "https://github.com/org/repo.git",
+				CredentialsSecret: corev1.LocalObjectReference{Name: "git-creds"},
+			},
+		},
+		Status: aiv1alpha1.TaskStatus{
+			Phase:          aiv1alpha1.TaskPhasePending,
+			PullRequestURL: "https://github.com/org/repo/pull/42",
+		},
+	}
+
+	r := newTestReconciler(task, orchestrator, worker)
+	ctx := context.Background()
+
+	if _, err := r.handlePendingPhase(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var job batchv1.Job
+	if err := r.Get(ctx, types.NamespacedName{Name: "test-task-orchestrator", Namespace: "default"}, &job); err != nil {
+		t.Fatalf("failed to get orchestrator job: %v", err)
+	}
+	var config struct {
+		Git map[string]interface{} `json:"git"`
+	}
+	for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == "TASK_CONFIG" {
+			if err := json.Unmarshal([]byte(env.Value), &config); err != nil {
+				t.Fatalf("TASK_CONFIG is not valid JSON: %v", err)
+			}
+		}
+	}
+	if config.Git["reusePR"] != true {
+		t.Errorf("expected reusePR hint on rerun, got %v", config.Git)
+	}
+	if config.Git["pullRequestUrl"] != "https://github.com/org/repo/pull/42" {
+		t.Errorf("expected existing PR URL, got %v", config.Git["pullRequestUrl"])
+	}
+}
+
+func TestHandlePendingPhase_WorkerEndpointOverride(t *testing.T) {
+	orchestrator := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultOrchestratorName, Namespace: "default"},
+		Spec:       aiv1alpha1.AgentSpec{Image: "orchestrator:v1"},
+	}
+	// No worker Agent exists: an explicit endpoint must not require one
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"},
+		Spec: aiv1alpha1.TaskSpec{
+			WorkerRef:      aiv1alpha1.AgentReference{Name: "code-worker"},
+			WorkerEndpoint: "http://code-worker.shared.svc:8080",
+			TaskSource: aiv1alpha1.TaskSource{
+				Type:   aiv1alpha1.TaskSourceTypeInline,
+				Inline: `{"tasks":[{"id":"1","title":"Test"}]}`,
+			},
+		},
+		Status: aiv1alpha1.TaskStatus{Phase: aiv1alpha1.TaskPhasePending},
+	}
+
+	r := newTestReconciler(task, orchestrator)
+	ctx := context.Background()
+
+	if _, err := r.handlePendingPhase(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var job batchv1.Job
+	if err := r.Get(ctx, types.NamespacedName{Name: "test-task-orchestrator", Namespace: "default"}, &job); err != nil {
+		t.Fatalf("failed to get orchestrator job: %v", err)
+	}
+	if n := len(job.Spec.Template.Spec.InitContainers); n != 0 {
+		t.Errorf("expected no worker sidecar, got %d init containers", n)
+	}
+	var config map[string]interface{}
+	for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == "TASK_CONFIG" {
+			if err := json.Unmarshal([]byte(env.Value), &config); err != nil {
+				t.Fatalf("TASK_CONFIG is not valid JSON: %v", err)
+			}
+		}
+	}
+	if config["workerEndpoint"] != "http://code-worker.shared.svc:8080" {
+		t.Errorf("expected workerEndpoint override, got %v", config["workerEndpoint"])
+	}
+}
+
+func TestHandlePendingPhase_SameOrchestratorAndWorker(t *testing.T) {
+	orchestrator := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultOrchestratorName, Namespace: "default"},
+		Spec:       aiv1alpha1.AgentSpec{Image: "orchestrator:v1"},
+	}
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"},
+		Spec: aiv1alpha1.TaskSpec{
+			WorkerRef: aiv1alpha1.AgentReference{Name: defaultOrchestratorName},
+			TaskSource: aiv1alpha1.TaskSource{
+				Type:   aiv1alpha1.TaskSourceTypeInline,
+				Inline: `{"tasks":[{"id":"1","title":"Test"}]}`,
+			},
+		},
+		Status: aiv1alpha1.TaskStatus{Phase: aiv1alpha1.TaskPhasePending},
+	}
+
+	r := newTestReconciler(task, orchestrator)
+	ctx := context.Background()
+
+	if _, err := r.handlePendingPhase(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var updated aiv1alpha1.Task
+	if err := r.Get(ctx, types.NamespacedName{Name: "test-task", Namespace: "default"}, &updated); err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	cond := meta.FindStatusCondition(updated.Status.Conditions, agentRoleConflictCondition)
+	if cond == nil {
+		t.Fatal("expected AgentRoleConflict condition")
+	}
+	if cond.Status != metav1.ConditionTrue || cond.Reason != "SameAgent" {
+		t.Errorf("expected True/SameAgent, got %s/%s", cond.Status, cond.Reason)
+	}
+
+	// The warning is informational: the Task still starts
+	if updated.Status.Phase != aiv1alpha1.TaskPhaseRunning {
+		t.Errorf("expected phase Running, got %s", updated.Status.Phase)
+	}
+}
+
+func TestHandlePendingPhase_InvalidWorkerEndpoint(t *testing.T) {
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"},
+		Spec: aiv1alpha1.TaskSpec{
+			WorkerRef:      aiv1alpha1.AgentReference{Name: "code-worker"},
+			WorkerEndpoint: "ftp://code-worker",
+		},
+		Status: aiv1alpha1.TaskStatus{Phase: aiv1alpha1.TaskPhasePending},
+	}
+	orchestrator := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultOrchestratorName, Namespace: "default"},
+		Spec:       aiv1alpha1.AgentSpec{Image: "orchestrator:v1"},
+	}
+
+	r := newTestReconciler(task, orchestrator)
+
+	if _, err := r.handlePendingPhase(context.Background(), task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cond := meta.FindStatusCondition(task.Status.Conditions, "Ready")
+	if cond == nil || cond.Reason != "InvalidWorkerEndpoint" {
+		t.Errorf("expected Ready condition with reason InvalidWorkerEndpoint, got %+v", cond)
+	}
+}
+
+func TestReconcile_WorkerPreflight(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		wantPhase  aiv1alpha1.TaskPhase
+		wantReason string
+	}{
+		{name: "reachable worker starts the job", status: http.StatusOK, wantPhase: aiv1alpha1.TaskPhaseRunning, wantReason: "Running"},
+		{name: "unhealthy worker blocks the job", status: http.StatusServiceUnavailable, wantPhase: aiv1alpha1.TaskPhasePending, wantReason: "WorkerUnreachable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var probed atomic.Bool
+			worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/healthz" {
+					probed.Store(true)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer worker.Close()
+
+			orchestrator := &aiv1alpha1.Agent{
+				ObjectMeta: metav1.ObjectMeta{Name: defaultOrchestratorName, Namespace: "default"},
+				Spec:       aiv1alpha1.AgentSpec{Image: "orchestrator:v1"},
+			}
+			task := &aiv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-task",
+					Namespace:  "default",
+					Finalizers: []string{taskFinalizer},
+				},
+				Spec: aiv1alpha1.TaskSpec{
+					WorkerRef:       aiv1alpha1.AgentReference{Name: "code-worker"},
+					WorkerEndpoint:  worker.URL,
+					WorkerPreflight: true,
+					TaskSource: aiv1alpha1.TaskSource{
+						Type:   aiv1alpha1.TaskSourceTypeInline,
+						Inline: `{"tasks":[{"id":"1","title":"Test"}]}`,
+					},
+				},
+				Status: aiv1alpha1.TaskStatus{Phase: aiv1alpha1.TaskPhasePending},
+			}
+
+			r := newTestReconciler(task, orchestrator)
+			ctx := context.Background()
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-task", Namespace: "default"}}
+
+			if _, err := r.Reconcile(ctx, req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !probed.Load() {
+				t.Error("expected the worker /healthz to be probed")
+			}
+
+			var updatedTask aiv1alpha1.Task
+			if err := r.Get(ctx, req.NamespacedName, &updatedTask); err != nil {
+				t.Fatalf("failed to get task: %v", err)
+			}
+			if updatedTask.Status.Phase != tt.wantPhase {
+				t.Errorf("expected phase %s, got %s", tt.wantPhase, updatedTask.Status.Phase)
+			}
+			cond := meta.FindStatusCondition(updatedTask.Status.Conditions, "Ready")
+			if cond == nil || cond.Reason != tt.wantReason {
+				t.Errorf("expected Ready reason %s, got %+v", tt.wantReason, cond)
+			}
+		})
+	}
+}
+
+func TestHandleRunningPhase_JobRunning(t *testing.T) {
 	task := &aiv1alpha1.Task{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test-task",
@@ -648,167 +1755,321 @@ func TestHandlePendingPhase_MissingOrchestrator(t *testing.T) {
 		},
 		Spec: aiv1alpha1.TaskSpec{
 			WorkerRef: aiv1alpha1.AgentReference{Name: "worker"},
-			TaskSource: aiv1alpha1.TaskSource{
-				Type:   aiv1alpha1.TaskSourceTypeInline,
-				Inline: `{"tasks":[{"id":"1","title":"Test"}]}`,
-			},
 		},
 		Status: aiv1alpha1.TaskStatus{
-			Phase: aiv1alpha1.TaskPhasePending,
+			Phase: aiv1alpha1.TaskPhaseRunning,
 		},
 	}
 
-	// Note: No orchestrator agent created - should requeue with delay
-	r := newTestReconciler(task)
-	ctx := context.Background()
+	// Job still running (no completion condition)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-task-orchestrator",
+			Namespace: "default",
+		},
+		Status: batchv1.JobStatus{
+			Active: 1,
+		},
+	}
 
-	result, err := r.handlePendingPhase(ctx, task)
+	r := newTestReconciler(task, job)
+	ctx := context.Background()
 
-	// The function handles missing orchestrator gracefully - no error returned
-	// It sets a condition and requeues after failure delay
+	result, err := r.handleRunningPhase(ctx, task)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
-	// Should requeue after failure delay
-	if result.RequeueAfter != failureRequeueDelay {
-		t.Errorf("expected RequeueAfter %v, got %v", failureRequeueDelay, result.RequeueAfter)
-	}
 
-	// Verify condition was set
-	var updatedTask aiv1alpha1.Task
-	if err := r.Get(ctx, types.NamespacedName{Name: "test-task", Namespace: "default"}, &updatedTask); err != nil {
-		t.Errorf("failed to get task: %v", err)
+	// Should requeue to poll again
+	if result.RequeueAfter != jobPollInterval {
+		t.Errorf("expected RequeueAfter %v, got %v", jobPollInterval, result.RequeueAfter)
 	}
+}
 
-	// Check that the OrchestratorNotFound condition was set
-	found := false
-	for _, cond := range updatedTask.Status.Conditions {
-		if cond.Type == "Ready" && cond.Reason == "OrchestratorNotFound" {
-			found = true
-			break
+func TestHandleRunningPhase_ImagePullError(t *testing.T) {
+	waiting := func(name, image, reason string) corev1.ContainerStatus {
+		return corev1.ContainerStatus{
+			Name:  name,
+			Image: image,
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: "pull access denied"}},
 		}
 	}
-	if !found {
-		t.Error("expected OrchestratorNotFound condition to be set")
-	}
-}
-
-func TestHandlePendingPhase_Success(t *testing.T) {
-	orchestrator := &aiv1alpha1.Agent{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      defaultOrchestratorName,
-			Namespace: "default",
+	tests := []struct {
+		name       string
+		status     corev1.PodStatus
+		jobAge     time.Duration
+		wantFailed bool
+	}{
+		{
+			name:       "orchestrator image pull backoff",
+			status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{waiting(render.OrchestratorContainerName, "ghcr.io/example/missing:v1", "ImagePullBackOff")}},
+			wantFailed: true,
 		},
-		Spec: aiv1alpha1.AgentSpec{
-			Image: "orchestrator:v1",
+		{
+			name:       "init container invalid image name",
+			status:     corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{waiting("git-clone", "alpine/git:Missing", "InvalidImageName")}},
+			wantFailed: true,
 		},
-	}
-
-	worker := &aiv1alpha1.Agent{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "code-worker",
-			Namespace: "default",
+		{
+			name:   "transient image pull error",
+			status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{waiting("git-clone", "alpine/git:v2", "ErrImagePull")}},
 		},
-		Spec: aiv1alpha1.AgentSpec{
-			Image: "worker:v1",
+		{
+			name:   "image pull backoff after the check window",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{waiting(render.OrchestratorContainerName, "orchestrator:v1", "ImagePullBackOff")}},
+			jobAge: 2 * imagePullCheckWindow,
+		},
+		{
+			name:   "container creating",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{waiting(render.OrchestratorContainerName, "orchestrator:v1", "ContainerCreating")}},
 		},
 	}
 
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &aiv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"},
+				Spec:       aiv1alpha1.TaskSpec{WorkerRef: aiv1alpha1.AgentReference{Name: "worker"}},
+				Status:     aiv1alpha1.TaskStatus{Phase: aiv1alpha1.TaskPhaseRunning},
+			}
+			job := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-task-orchestrator",
+					Namespace:         "default",
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-tt.jobAge)),
+				},
+				Status: batchv1.JobStatus{Active: 1},
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-task-orchestrator-abcde",
+					Namespace: "default",
+					Labels:    map[string]string{"job-name": job.Name},
+				},
+				Status: tt.status,
+			}
+			r := newTestReconciler(task, job, pod)
+			ctx := context.Background()
+
+			if _, err := r.handleRunningPhase(ctx, task); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !tt.wantFailed {
+				if task.Status.Phase != aiv1alpha1.TaskPhaseRunning {
+					t.Errorf("expected phase Running, got %s", task.Status.Phase)
+				}
+				return
+			}
+			if task.Status.Phase != aiv1alpha1.TaskPhaseFailed {
+				t.Fatalf("expected phase Failed, got %s", task.Status.Phase)
+			}
+			cond := meta.FindStatusCondition(task.Status.Conditions, "Ready")
+			if cond == nil || cond.Reason != "ImagePullError" || !strings.Contains(cond.Message, "pull access denied") {
+				t.Errorf("expected Ready condition with reason ImagePullError, got %+v", cond)
+			}
+			var existing batchv1.Job
+			if err := r.Get(ctx, types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, &existing); !apierrors.IsNotFound(err) {
+				t.Errorf("expected the Job to be cleaned up, got %v", err)
+			}
+		})
+	}
+}
+
+// newAmbiguousJobTask returns a running task with the given resume policy and
+// an orchestrator Job that has been without pods past the grace period.
+func newAmbiguousJobTask(policy aiv1alpha1.TaskResumePolicy) (*aiv1alpha1.Task, *batchv1.Job) {
 	task := &aiv1alpha1.Task{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test-task",
 			Namespace: "default",
-			UID:       "test-uid-123",
 		},
 		Spec: aiv1alpha1.TaskSpec{
-			WorkerRef: aiv1alpha1.AgentReference{Name: "code-worker"},
-			TaskSource: aiv1alpha1.TaskSource{
-				Type:   aiv1alpha1.TaskSourceTypeInline,
-				Inline: `{"tasks":[{"id":"1","title":"Test"}]}`,
-			},
+			WorkerRef:    aiv1alpha1.AgentReference{Name: "worker"},
+			ResumePolicy: policy,
 		},
 		Status: aiv1alpha1.TaskStatus{
-			Phase: aiv1alpha1.TaskPhasePending,
+			Phase: aiv1alpha1.TaskPhaseRunning,
+		},
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-task-orchestrator",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * ambiguousJobGracePeriod)),
 		},
 	}
+	return task, job
+}
 
-	r := newTestReconciler(task, orchestrator, worker)
+func TestHandleRunningPhase_ResumePolicyContinue(t *testing.T) {
+	task, job := newAmbiguousJobTask(aiv1alpha1.TaskResumePolicyContinue)
+	r := newTestReconciler(task, job)
 	ctx := context.Background()
 
-	result, err := r.handlePendingPhase(ctx, task)
+	result, err := r.handleRunningPhase(ctx, task)
 	if err != nil {
-		t.Errorf("unexpected error: %v", err)
+		t.Fatalf("unexpected error: %v", err)
 	}
-
-	// Should requeue to poll job status
 	if result.RequeueAfter != jobPollInterval {
 		t.Errorf("expected RequeueAfter %v, got %v", jobPollInterval, result.RequeueAfter)
 	}
 
-	// Verify task status was updated
-	var updatedTask aiv1alpha1.Task
-	if err := r.Get(ctx, types.NamespacedName{Name: "test-task", Namespace: "default"}, &updatedTask); err != nil {
-		t.Errorf("failed to get task: %v", err)
+	var existing batchv1.Job
+	if err := r.Get(ctx, types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, &existing); err != nil {
+		t.Errorf("expected Job to be kept: %v", err)
 	}
-	if updatedTask.Status.Phase != aiv1alpha1.TaskPhaseRunning {
-		t.Errorf("expected phase Running, got %s", updatedTask.Status.Phase)
+	if task.Status.Phase != aiv1alpha1.TaskPhaseRunning {
+		t.Errorf("expected phase Running, got %s", task.Status.Phase)
 	}
+}
 
-	// Verify orchestrator job was created
-	var job batchv1.Job
-	if err := r.Get(ctx, types.NamespacedName{
-		Name:      "test-task-orchestrator",
-		Namespace: "default",
-	}, &job); err != nil {
-		t.Errorf("failed to get orchestrator job: %v", err)
+func TestHandleRunningPhase_ResumePolicyRestart(t *testing.T) {
+	task, job := newAmbiguousJobTask(aiv1alpha1.TaskResumePolicyRestart)
+	r := newTestReconciler(task, job)
+	ctx := context.Background()
+
+	if _, err := r.handleRunningPhase(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
 
-	// Verify workspace PVC was created
-	var pvc corev1.PersistentVolumeClaim
-	if err := r.Get(ctx, types.NamespacedName{
-		Name:      "test-task-workspace",
-		Namespace: "default",
-	}, &pvc); err != nil {
-		t.Errorf("failed to get workspace PVC: %v", err)
+	var existing batchv1.Job
+	err := r.Get(ctx, types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, &existing)
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected Job to be deleted, got %v", err)
+	}
+
+	// The next pass takes the lost-Job path and sends the task back to Pending
+	if _, err := r.handleRunningPhase(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.Status.Phase != aiv1alpha1.TaskPhasePending {
+		t.Errorf("expected phase Pending, got %s", task.Status.Phase)
+	}
+	if task.Annotations[jobRecreationAnnotation] != "1" {
+		t.Errorf("expected recreation count 1, got %q", task.Annotations[jobRecreationAnnotation])
 	}
 }
 
-func TestHandleRunningPhase_JobRunning(t *testing.T) {
+func TestHandleRunningPhase_ResumePolicyFail(t *testing.T) {
+	task, job := newAmbiguousJobTask(aiv1alpha1.TaskResumePolicyFail)
+	r := newTestReconciler(task, job)
+	ctx := context.Background()
+
+	result, err := r.handleRunningPhase(ctx, task)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Errorf("expected no requeue, got %v", result.RequeueAfter)
+	}
+	if task.Status.Phase != aiv1alpha1.TaskPhaseFailed {
+		t.Errorf("expected phase Failed, got %s", task.Status.Phase)
+	}
+	cond := meta.FindStatusCondition(task.Status.Conditions, "Ready")
+	if cond == nil || cond.Reason != "JobStateAmbiguous" {
+		t.Errorf("expected Ready condition with reason JobStateAmbiguous, got %+v", cond)
+	}
+
+	// The Job is left in place for inspection
+	var existing batchv1.Job
+	if err := r.Get(ctx, types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, &existing); err != nil {
+		t.Errorf("expected Job to be kept: %v", err)
+	}
+}
+
+func TestHandleRunningPhase_RecentJobWithoutPodsIsNotAmbiguous(t *testing.T) {
+	task, job := newAmbiguousJobTask(aiv1alpha1.TaskResumePolicyFail)
+	job.CreationTimestamp = metav1.Now()
+	r := newTestReconciler(task, job)
+
+	if _, err := r.handleRunningPhase(context.Background(), task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.Status.Phase != aiv1alpha1.TaskPhaseRunning {
+		t.Errorf("expected phase Running within the grace period, got %s", task.Status.Phase)
+	}
+}
+
+func TestReconcile_TaskSourceDeletedMidRun(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "prd", Namespace: "default"},
+		Data:       map[string]string{"prd.json": `{"tasks":[]}`},
+	}
 	task := &aiv1alpha1.Task{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "test-task",
-			Namespace: "default",
+			Name:       "test-task",
+			Namespace:  "default",
+			Finalizers: []string{taskFinalizer},
 		},
 		Spec: aiv1alpha1.TaskSpec{
 			WorkerRef: aiv1alpha1.AgentReference{Name: "worker"},
+			TaskSource: aiv1alpha1.TaskSource{
+				Type:         aiv1alpha1.TaskSourceTypeConfigMap,
+				ConfigMapRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "prd"}, Key: "prd.json"},
+			},
 		},
 		Status: aiv1alpha1.TaskStatus{
 			Phase: aiv1alpha1.TaskPhaseRunning,
 		},
 	}
-
-	// Job still running (no completion condition)
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "test-task-orchestrator",
-			Namespace: "default",
-		},
-		Status: batchv1.JobStatus{
-			Active: 1,
+			Name:              "test-task-orchestrator",
+			Namespace:         "default",
+			CreationTimestamp: metav1.Now(),
 		},
+		Status: batchv1.JobStatus{Active: 1},
 	}
 
-	r := newTestReconciler(task, job)
+	r := newTestReconciler(cm, task, job)
 	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-task", Namespace: "default"}
 
-	result, err := r.handleRunningPhase(ctx, task)
-	if err != nil {
-		t.Errorf("unexpected error: %v", err)
+	// With the source present the run carries on
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var updatedTask aiv1alpha1.Task
+	if err := r.Get(ctx, key, &updatedTask); err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if updatedTask.Status.Phase != aiv1alpha1.TaskPhaseRunning {
+		t.Fatalf("expected phase Running, got %s", updatedTask.Status.Phase)
 	}
 
-	// Should requeue to poll again
-	if result.RequeueAfter != jobPollInterval {
-		t.Errorf("expected RequeueAfter %v, got %v", jobPollInterval, result.RequeueAfter)
+	if err := r.Delete(ctx, cm); err != nil {
+		t.Fatalf("failed to delete source: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Get(ctx, key, &updatedTask); err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if updatedTask.Status.Phase != aiv1alpha1.TaskPhaseFailed {
+		t.Errorf("expected phase Failed, got %s", updatedTask.Status.Phase)
+	}
+	cond := meta.FindStatusCondition(updatedTask.Status.Conditions, taskSourceDeletedCondition)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Fatalf("expected TaskSourceDeleted condition, got %+v", cond)
+	}
+	if !strings.Contains(cond.Message, `ConfigMap "prd"`) {
+		t.Errorf("expected message to name the ConfigMap, got %q", cond.Message)
+	}
+	ready := meta.FindStatusCondition(updatedTask.Status.Conditions, "Ready")
+	if ready == nil || ready.Reason != "TaskSourceDeleted" {
+		t.Errorf("expected Ready reason TaskSourceDeleted, got %+v", ready)
+	}
+
+	var existing batchv1.Job
+	if err := r.Get(ctx, types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, &existing); !apierrors.IsNotFound(err) {
+		t.Errorf("expected orchestrator Job to be deleted, got %v", err)
+	}
+
+	// Persisting the PRD reports the missing source as NotFound
+	if err := r.persistUpdatedPRD(ctx, &updatedTask, `{"tasks":[]}`); !apierrors.IsNotFound(err) {
+		t.Errorf("expected NotFound from persistUpdatedPRD, got %v", err)
 	}
 }
 
@@ -935,6 +2196,71 @@ func TestHandleDeletion_CleansUpResources(t *testing.T) {
 	// the task since it had a deletionTimestamp. In real Kubernetes, this is the expected behavior.
 }
 
+func TestHandleDeletion_RetainsWorkspace(t *testing.T) {
+	now := metav1.Now()
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-task",
+			Namespace:         "default",
+			UID:               "task-uid",
+			Finalizers:        []string{taskFinalizer},
+			DeletionTimestamp: &now,
+		},
+		Spec: aiv1alpha1.TaskSpec{
+			WorkerRef: aiv1alpha1.AgentReference{Name: "worker"},
+			TaskSource: aiv1alpha1.TaskSource{
+				Type:   aiv1alpha1.TaskSourceTypeInline,
+				Inline: `{"tasks":[]}`,
+			},
+			RetainWorkspace: true,
+		},
+		Status: aiv1alpha1.TaskStatus{
+			Phase: aiv1alpha1.TaskPhaseCompleted,
+		},
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-task-workspace",
+			Namespace: "default",
+			Labels:    map[string]string{"fabric.jarsater.ai/task": "test-task"},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "fabric.jarsater.ai/v1alpha1", Kind: "Task", Name: "test-task", UID: "task-uid", Controller: ptr.To(true)},
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "keep-me", UID: "other-uid"},
+			},
+		},
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-task-orchestrator",
+			Namespace: "default",
+		},
+	}
+
+	r := newTestReconciler(task, pvc, job)
+	ctx := context.Background()
+
+	if _, err := r.handleDeletion(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var retained corev1.PersistentVolumeClaim
+	if err := r.Get(ctx, types.NamespacedName{Name: "test-task-workspace", Namespace: "default"}, &retained); err != nil {
+		t.Fatalf("expected PVC to survive deletion, got err=%v", err)
+	}
+	if retained.Labels[render.WorkspaceOrphanedLabel] != "true" {
+		t.Errorf("expected PVC to be labelled orphaned, got labels %v", retained.Labels)
+	}
+	if len(retained.OwnerReferences) != 1 || retained.OwnerReferences[0].UID != "other-uid" {
+		t.Errorf("expected only the task owner reference to be removed, got %+v", retained.OwnerReferences)
+	}
+
+	var deletedJob batchv1.Job
+	if err := r.Get(ctx, types.NamespacedName{Name: "test-task-orchestrator", Namespace: "default"}, &deletedJob); err == nil {
+		t.Error("expected Job to be deleted")
+	}
+}
+
 func TestHandleDeletion_NoFinalizerNoOp(t *testing.T) {
 	now := metav1.Now()
 	// Task with a DIFFERENT finalizer (not ours) - this simulates the case where
@@ -1128,6 +2454,17 @@ ORCHESTRATOR_RESULT:{"passed":false,"completedTasks":2,"totalTasks":5,"iteration
 			},
 			wantErr: false,
 		},
+		{
+			name:       "result with missing gate commands",
+			logContent: `ORCHESTRATOR_RESULT:{"passed":false,"completedTasks":0,"totalTasks":3,"iterations":0,"error":"Quality gate commands not found: npm","missingCommands":["npm","make"]}`,
+			wantResult: &OrchestratorResult{
+				Passed:          false,
+				TotalTasks:      3,
+				Error:           "Quality gate commands not found: npm",
+				MissingCommands: []string{"npm", "make"},
+			},
+			wantErr: false,
+		},
 		{
 			name:        "missing result marker",
 			logContent:  "Some logs without the result marker",
@@ -1227,6 +2564,9 @@ ORCHESTRATOR_RESULT:{"passed":true,"completedTasks":5,"totalTasks":5,"iterations
 			if result.GitError != tt.wantResult.GitError {
 				t.Errorf("GitError: got %q, want %q", result.GitError, tt.wantResult.GitError)
 			}
+			if !reflect.DeepEqual(result.MissingCommands, tt.wantResult.MissingCommands) {
+				t.Errorf("MissingCommands: got %v, want %v", result.MissingCommands, tt.wantResult.MissingCommands)
+			}
 			// Compare PRD as strings since json.RawMessage comparison can be tricky
 			if string(result.PRD) != string(tt.wantResult.PRD) {
 				t.Errorf("PRD: got %s, want %s", string(result.PRD), string(tt.wantResult.PRD))
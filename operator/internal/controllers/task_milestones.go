@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+const (
+	// Marker for orchestrator milestones in logs
+	orchestratorEventMarker = "ORCHESTRATOR_EVENT:"
+
+	// milestoneLogTailLines is how much of the orchestrator log is scanned
+	// for milestones on each poll.
+	milestoneLogTailLines = 500
+
+	// maxMilestoneEventsPerPoll bounds the Events recorded for a Task per
+	// job poll; older milestones beyond it are dropped.
+	maxMilestoneEventsPerPoll = 5
+
+	// maxMilestoneNoteLength keeps Event notes within the API's 1kB limit.
+	maxMilestoneNoteLength = 1000
+)
+
+// milestoneEventTypes maps the milestones the orchestrator reports to the
+// type of Event recorded for them. Other milestone types are ignored.
+var milestoneEventTypes = map[string]string{
+	"IterationStarted":  corev1.EventTypeNormal,
+	"TaskPassed":        corev1.EventTypeNormal,
+	"TaskFailed":        corev1.EventTypeWarning,
+	"QualityGateFailed": corev1.EventTypeWarning,
+}
+
+// orchestratorMilestone is a progress marker printed by the orchestrator
+// while it runs, numbered by Seq from 1 in each run.
+type orchestratorMilestone struct {
+	Seq       int    `json:"seq"`
+	Type      string `json:"type"`
+	Message   string `json:"message"`
+	Iteration int32  `json:"iteration,omitempty"`
+	TaskID    string `json:"taskId,omitempty"`
+}
+
+// parseMilestones returns the milestones of known types found in
+// orchestrator logs, in log order. Malformed markers are skipped.
+func parseMilestones(logs io.Reader) ([]orchestratorMilestone, error) {
+	var milestones []orchestratorMilestone
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		line := scanner.Text()
+		idx := strings.Index(line, orchestratorEventMarker)
+		if idx == -1 {
+			continue
+		}
+		var m orchestratorMilestone
+		if err := json.Unmarshal([]byte(strings.TrimSpace(line[idx+len(orchestratorEventMarker):])), &m); err != nil {
+			continue
+		}
+		if _, ok := milestoneEventTypes[m.Type]; ok && m.Seq > 0 {
+			milestones = append(milestones, m)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pod logs: %w", err)
+	}
+	return milestones, nil
+}
+
+// milestoneCursor is the last milestone recorded for a Task's Job.
+type milestoneCursor struct {
+	job types.UID
+	seq int
+}
+
+// milestoneTracker remembers, per Task, which orchestrator milestones have
+// been recorded, so each is recorded once. It is kept in memory: after an
+// operator restart the latest milestones of a running Task are recorded
+// again, which the Events API aggregates.
+type milestoneTracker struct {
+	mu      sync.Mutex
+	cursors map[types.UID]milestoneCursor
+}
+
+// advance returns the milestones of job not recorded yet for task and marks
+// them recorded. A new Job, or a sequence that went backwards because the
+// orchestrator pod restarted, starts over.
+func (t *milestoneTracker) advance(task, job types.UID, milestones []orchestratorMilestone) []orchestratorMilestone {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(milestones) == 0 {
+		return nil
+	}
+	cursor := t.cursors[task]
+	last := milestones[len(milestones)-1].Seq
+	if cursor.job != job || last < cursor.seq {
+		cursor = milestoneCursor{job: job}
+	}
+
+	var pending []orchestratorMilestone
+	for _, m := range milestones {
+		if m.Seq > cursor.seq {
+			pending = append(pending, m)
+		}
+	}
+
+	if t.cursors == nil {
+		t.cursors = make(map[types.UID]milestoneCursor)
+	}
+	t.cursors[task] = milestoneCursor{job: job, seq: max(cursor.seq, last)}
+	return pending
+}
+
+// forget drops what was recorded for task once it no longer runs.
+func (t *milestoneTracker) forget(task types.UID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.cursors, task)
+}
+
+// recordMilestones records the milestones the orchestrator has logged since
+// the last poll as Events on the Task. Milestones are best effort: failing
+// to read the logs is only logged.
+func (r *TaskReconciler) recordMilestones(ctx context.Context, task *aiv1alpha1.Task, job *batchv1.Job) {
+	if r.Recorder == nil || r.Clientset == nil {
+		return
+	}
+	logger := log.FromContext(ctx)
+
+	logs, err := r.openOrchestratorLogs(ctx, job, milestoneLogTailLines)
+	if err != nil {
+		logger.V(1).Info("Unable to read orchestrator milestones", "job", job.Name, "error", err.Error())
+		return
+	}
+	defer func() { _ = logs.Close() }()
+
+	milestones, err := parseMilestones(logs)
+	if err != nil {
+		logger.V(1).Info("Unable to read orchestrator milestones", "job", job.Name, "error", err.Error())
+		return
+	}
+	r.emitMilestones(ctx, task, job, milestones)
+}
+
+// emitMilestones records the milestones not recorded yet as Events on the
+// Task, at most maxMilestoneEventsPerPoll of them, keeping the newest.
+func (r *TaskReconciler) emitMilestones(ctx context.Context, task *aiv1alpha1.Task, job *batchv1.Job, milestones []orchestratorMilestone) {
+	pending := r.milestones.advance(task.UID, job.UID, milestones)
+	if dropped := len(pending) - maxMilestoneEventsPerPoll; dropped > 0 {
+		log.FromContext(ctx).V(1).Info("Dropping orchestrator milestones over the per-poll limit", "dropped", dropped)
+		pending = pending[dropped:]
+	}
+	for _, m := range pending {
+		r.Recorder.Eventf(task, job, milestoneEventTypes[m.Type], m.Type, "Orchestrate", "%s", milestoneNote(m))
+	}
+}
+
+// milestoneNote returns the Event note for a milestone.
+func milestoneNote(m orchestratorMilestone) string {
+	note := m.Message
+	if note == "" {
+		note = fmt.Sprintf("%s: task %s, iteration %d", m.Type, m.TaskID, m.Iteration)
+	}
+	if len(note) > maxMilestoneNoteLength {
+		note = strings.ToValidUTF8(note[:maxMilestoneNoteLength], "")
+	}
+	return note
+}
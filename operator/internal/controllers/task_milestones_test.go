@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+const sampleOrchestratorLogs = `2025-01-15 10:30:00 INFO ITERATION 1/100
+ORCHESTRATOR_EVENT:{"seq":1,"type":"IterationStarted","message":"Iteration 1: processing US-001 - Add login","iteration":1,"taskId":"US-001"}
+2025-01-15 10:31:00 INFO Running quality gates...
+ORCHESTRATOR_EVENT:{"seq":2,"type":"QualityGateFailed","message":"Task US-001: quality gates failed: lint","iteration":1,"taskId":"US-001"}
+ORCHESTRATOR_EVENT:{"seq":3,"type":"TaskFailed","message":"Task US-001 failed","iteration":1,"taskId":"US-001"}
+ORCHESTRATOR_EVENT:{not json
+ORCHESTRATOR_EVENT:{"seq":4,"type":"SomethingElse","message":"ignored"}
+2025-01-15 10:32:00 INFO ITERATION 2/100
+ORCHESTRATOR_EVENT:{"seq":5,"type":"IterationStarted","message":"Iteration 2: processing US-001 - Add login","iteration":2,"taskId":"US-001"}
+ORCHESTRATOR_EVENT:{"seq":6,"type":"TaskPassed","message":"Task US-001 passed","iteration":2,"taskId":"US-001"}
+`
+
+func TestParseMilestones(t *testing.T) {
+	milestones, err := parseMilestones(strings.NewReader(sampleOrchestratorLogs))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, m := range milestones {
+		got = append(got, fmt.Sprintf("%d:%s", m.Seq, m.Type))
+	}
+	want := "1:IterationStarted 2:QualityGateFailed 3:TaskFailed 5:IterationStarted 6:TaskPassed"
+	if strings.Join(got, " ") != want {
+		t.Errorf("expected milestones %q, got %q", want, strings.Join(got, " "))
+	}
+	if milestones[0].TaskID != "US-001" || milestones[0].Iteration != 1 {
+		t.Errorf("expected the task and iteration parsed, got %+v", milestones[0])
+	}
+}
+
+// drainEvents returns the events recorded so far.
+func drainEvents(recorder *events.FakeRecorder) []string {
+	var got []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			got = append(got, event)
+		default:
+			return got
+		}
+	}
+}
+
+func TestEmitMilestones(t *testing.T) {
+	recorder := events.NewFakeRecorder(20)
+	r := &TaskReconciler{Recorder: recorder}
+	task := &aiv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "t", UID: types.UID("task-1")}}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "t-orchestrator", UID: types.UID("job-1")}}
+	ctx := context.Background()
+
+	milestones, _ := parseMilestones(strings.NewReader(sampleOrchestratorLogs))
+	r.emitMilestones(ctx, task, job, milestones[:3])
+	got := drainEvents(recorder)
+	want := []string{
+		"Normal IterationStarted Iteration 1: processing US-001 - Add login",
+		"Warning QualityGateFailed Task US-001: quality gates failed: lint",
+		"Warning TaskFailed Task US-001 failed",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected events %q, got %q", want, got)
+	}
+
+	// The next poll sees the same log tail plus new milestones
+	r.emitMilestones(ctx, task, job, milestones)
+	if got := drainEvents(recorder); len(got) != 2 || got[1] != "Normal TaskPassed Task US-001 passed" {
+		t.Errorf("expected only the new milestones recorded, got %q", got)
+	}
+
+	// A recreated Job starts its sequence over
+	newJob := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "t-orchestrator", UID: types.UID("job-2")}}
+	r.emitMilestones(ctx, task, newJob, milestones[:1])
+	if got := drainEvents(recorder); len(got) != 1 {
+		t.Errorf("expected the new Job's milestone recorded, got %q", got)
+	}
+}
+
+func TestEmitMilestones_BoundedPerPoll(t *testing.T) {
+	recorder := events.NewFakeRecorder(50)
+	r := &TaskReconciler{Recorder: recorder}
+	task := &aiv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "t", UID: types.UID("task-1")}}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "t-orchestrator", UID: types.UID("job-1")}}
+
+	var milestones []orchestratorMilestone
+	for i := 1; i <= 20; i++ {
+		milestones = append(milestones, orchestratorMilestone{Seq: i, Type: "TaskPassed", Message: fmt.Sprintf("Task %d passed", i)})
+	}
+	r.emitMilestones(context.Background(), task, job, milestones)
+
+	got := drainEvents(recorder)
+	if len(got) != maxMilestoneEventsPerPoll {
+		t.Fatalf("expected %d events, got %d", maxMilestoneEventsPerPoll, len(got))
+	}
+	if got[len(got)-1] != "Normal TaskPassed Task 20 passed" {
+		t.Errorf("expected the newest milestones kept, got %q", got)
+	}
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -9,17 +10,34 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
 	"github.com/jarsater/mcp-fabric/operator/internal/metrics"
+	"github.com/jarsater/mcp-fabric/operator/internal/render"
 )
 
 // ToolReconciler reconciles a Tool object.
 type ToolReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Validator validates tool images. Defaults to accepting the declared tools.
+	Validator ToolValidator
+}
+
+// ToolValidator validates a Tool's package and returns its tool definitions.
+type ToolValidator interface {
+	Validate(ctx context.Context, tool *aiv1alpha1.Tool) ([]aiv1alpha1.ToolDefinition, error)
+}
+
+// declaredToolsValidator trusts the tools declared in the spec.
+type declaredToolsValidator struct{}
+
+func (declaredToolsValidator) Validate(_ context.Context, tool *aiv1alpha1.Tool) ([]aiv1alpha1.ToolDefinition, error) {
+	return tool.Spec.Tools, nil
 }
 
 // +kubebuilder:rbac:groups=fabric.jarsater.ai,resources=tools,verbs=get;list;watch;create;update;patch;delete
@@ -44,7 +62,7 @@ func (r *ToolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	logger.Info("Reconciling Tool", "name", tool.Name)
 
 	// Validate the spec
-	if err := r.validateSpec(&tool); err != nil {
+	if err := r.validate(ctx, &tool); err != nil {
 		r.setCondition(&tool, metav1.Condition{
 			Type:               "Ready",
 			Status:             metav1.ConditionFalse,
@@ -64,8 +82,11 @@ func (r *ToolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return ctrl.Result{}, nil
 	}
 
-	// Copy declared tools to available tools (in the future, introspection Job would populate this)
+	// Declared tools take precedence over validated definitions
 	tool.Status.AvailableTools = tool.Spec.Tools
+	if len(tool.Status.AvailableTools) == 0 {
+		tool.Status.AvailableTools = tool.Status.ValidatedTools
+	}
 
 	// Set ready condition
 	r.setCondition(&tool, metav1.Condition{
@@ -93,6 +114,48 @@ func (r *ToolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	return ctrl.Result{}, nil
 }
 
+// validate checks the spec and runs the validator when the image or entry
+// module changed since the last successful validation.
+func (r *ToolReconciler) validate(ctx context.Context, t *aiv1alpha1.Tool) error {
+	if err := r.validateSpec(t); err != nil {
+		return err
+	}
+
+	hash, err := toolSpecHash(t)
+	if err != nil {
+		return err
+	}
+	if t.Status.ValidatedSpecHash == hash {
+		log.FromContext(ctx).V(1).Info("Tool spec unchanged, skipping validation", "hash", hash)
+		return nil
+	}
+
+	validator := r.Validator
+	if validator == nil {
+		validator = declaredToolsValidator{}
+	}
+	definitions, err := validator.Validate(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	t.Status.ValidatedTools = definitions
+	t.Status.ValidatedSpecHash = hash
+	return nil
+}
+
+// toolSpecHash hashes the spec fields that determine validation results.
+func toolSpecHash(t *aiv1alpha1.Tool) (string, error) {
+	data, err := json.Marshal(struct {
+		Image       string `json:"image"`
+		EntryModule string `json:"entryModule"`
+	}{t.Spec.Image, t.Spec.EntryModule})
+	if err != nil {
+		return "", err
+	}
+	return render.HashConfig(data), nil
+}
+
 // validateSpec performs validation on the Tool spec.
 func (r *ToolReconciler) validateSpec(t *aiv1alpha1.Tool) error {
 	if t.Spec.Image == "" {
@@ -110,7 +173,7 @@ func (r *ToolReconciler) setCondition(t *aiv1alpha1.Tool, condition metav1.Condi
 // SetupWithManager sets up the controller with the Manager.
 func (r *ToolReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&aiv1alpha1.Tool{}).
+		For(&aiv1alpha1.Tool{}, builder.WithPredicates(specChanged())).
 		Named("tool").
 		Complete(r)
 }
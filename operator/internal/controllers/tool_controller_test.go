@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+// countingValidator records how often validation runs.
+type countingValidator struct {
+	calls int
+	tools []aiv1alpha1.ToolDefinition
+	err   error
+}
+
+func (v *countingValidator) Validate(_ context.Context, _ *aiv1alpha1.Tool) ([]aiv1alpha1.ToolDefinition, error) {
+	v.calls++
+	return v.tools, v.err
+}
+
+func newToolTestReconciler(validator ToolValidator, objs ...client.Object) *ToolReconciler {
+	scheme := newTestScheme()
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&aiv1alpha1.Tool{}).
+		Build()
+
+	return &ToolReconciler{
+		Client:    fakeClient,
+		Scheme:    scheme,
+		Validator: validator,
+	}
+}
+
+func newTestTool() *aiv1alpha1.Tool {
+	return &aiv1alpha1.Tool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "string-tools",
+			Namespace: "default",
+		},
+		Spec: aiv1alpha1.ToolSpec{
+			Image:       "ghcr.io/example/string-tools:v1",
+			EntryModule: "string_tools.tools",
+		},
+	}
+}
+
+func reconcileTool(t *testing.T, r *ToolReconciler) *aiv1alpha1.Tool {
+	t.Helper()
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "string-tools", Namespace: "default"}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var tool aiv1alpha1.Tool
+	if err := r.Get(ctx, key, &tool); err != nil {
+		t.Fatalf("failed to get tool: %v", err)
+	}
+	return &tool
+}
+
+func TestToolReconcile_SkipsValidationWhenSpecUnchanged(t *testing.T) {
+	validator := &countingValidator{
+		tools: []aiv1alpha1.ToolDefinition{{Name: "reverse_string"}},
+	}
+	r := newToolTestReconciler(validator, newTestTool())
+
+	tool := reconcileTool(t, r)
+	if validator.calls != 1 {
+		t.Fatalf("expected 1 validation, got %d", validator.calls)
+	}
+	if !tool.Status.Ready {
+		t.Error("expected tool to be ready")
+	}
+	if tool.Status.ValidatedSpecHash == "" {
+		t.Error("expected validated spec hash to be recorded")
+	}
+	if len(tool.Status.AvailableTools) != 1 || tool.Status.AvailableTools[0].Name != "reverse_string" {
+		t.Errorf("expected validated tools to be available, got %v", tool.Status.AvailableTools)
+	}
+
+	// Reconcile again without spec changes
+	tool = reconcileTool(t, r)
+	if validator.calls != 1 {
+		t.Errorf("expected no re-validation, got %d validations", validator.calls)
+	}
+	if len(tool.Status.AvailableTools) != 1 {
+		t.Errorf("expected cached tools to remain available, got %v", tool.Status.AvailableTools)
+	}
+}
+
+func TestToolReconcile_RevalidatesOnImageChange(t *testing.T) {
+	validator := &countingValidator{}
+	r := newToolTestReconciler(validator, newTestTool())
+
+	tool := reconcileTool(t, r)
+	firstHash := tool.Status.ValidatedSpecHash
+
+	tool.Spec.Image = "ghcr.io/example/string-tools:v2"
+	if err := r.Update(context.Background(), tool); err != nil {
+		t.Fatalf("failed to update tool: %v", err)
+	}
+
+	tool = reconcileTool(t, r)
+	if validator.calls != 2 {
+		t.Errorf("expected 2 validations, got %d", validator.calls)
+	}
+	if tool.Status.ValidatedSpecHash == firstHash {
+		t.Error("expected validated spec hash to change")
+	}
+}
+
+func TestToolReconcile_ValidationFailureIsRetried(t *testing.T) {
+	validator := &countingValidator{err: errors.New("module not importable")}
+	r := newToolTestReconciler(validator, newTestTool())
+
+	tool := reconcileTool(t, r)
+	if tool.Status.Ready {
+		t.Error("expected tool not to be ready")
+	}
+	if tool.Status.ValidatedSpecHash != "" {
+		t.Errorf("expected no hash after failed validation, got %q", tool.Status.ValidatedSpecHash)
+	}
+
+	validator.err = nil
+	tool = reconcileTool(t, r)
+	if validator.calls != 2 {
+		t.Errorf("expected validation to be retried, got %d validations", validator.calls)
+	}
+	if !tool.Status.Ready {
+		t.Error("expected tool to be ready after successful validation")
+	}
+}
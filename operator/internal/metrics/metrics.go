@@ -2,6 +2,8 @@
 package metrics
 
 import (
+	"errors"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
@@ -20,6 +22,9 @@ const (
 	ResultSuccess = "success"
 	ResultError   = "error"
 	ResultRequeue = "requeue"
+	// ResultNoOp marks a reconcile that exited early without changing
+	// anything, such as for a finished or already paused Task.
+	ResultNoOp = "noop"
 )
 
 var (
@@ -186,17 +191,31 @@ var (
 		},
 		[]string{"name", "namespace"},
 	)
+
+	// TaskGitPushTotal counts git push outcomes reported by orchestrators
+	TaskGitPushTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "task_git_push_total",
+			Help:      "Total git pushes reported by task orchestrators by result",
+		},
+		[]string{"namespace", "result"},
+	)
 )
 
 func init() {
+	Register()
+}
+
+// Register registers all operator metrics with controller-runtime's global
+// registry. It is idempotent, so re-initializing (as tests may) does not
+// panic.
+func Register() {
 	// Note: the Go runtime and process collectors are intentionally NOT
 	// registered here. controller-runtime already registers them on
 	// metrics.Registry in its own init (sigs.k8s.io/controller-runtime/pkg/
-	// internal/controller/metrics), and registering them again panics with
-	// "duplicate metrics collector registration attempted".
-
-	// Register all metrics with controller-runtime's global registry
-	metrics.Registry.MustRegister(
+	// internal/controller/metrics).
+	register(metrics.Registry,
 		ReconcileTotal,
 		ReconcileDuration,
 		ReconcileErrors,
@@ -213,9 +232,25 @@ func init() {
 		TaskIteration,
 		TaskCompletedTasks,
 		TaskTotalTasks,
+		TaskGitPushTotal,
 	)
 }
 
+// register adds collectors to reg, skipping any that are already
+// registered. Other registration errors are programming errors and panic,
+// as with MustRegister.
+func register(reg prometheus.Registerer, cs ...prometheus.Collector) {
+	for _, c := range cs {
+		if err := reg.Register(c); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			if errors.As(err, &are) {
+				continue
+			}
+			panic(err)
+		}
+	}
+}
+
 // RecordReconcile records a reconciliation attempt
 func RecordReconcile(controller, result string, duration float64) {
 	ReconcileTotal.WithLabelValues(controller, result).Inc()
@@ -294,6 +329,15 @@ func SetTaskMetrics(name, namespace, phase string, iteration, completedTasks, to
 	TaskTotalTasks.WithLabelValues(name, namespace).Set(float64(totalTasks))
 }
 
+// RecordTaskGitPush records the outcome of an orchestrator git push
+func RecordTaskGitPush(namespace string, success bool) {
+	result := ResultSuccess
+	if !success {
+		result = ResultError
+	}
+	TaskGitPushTotal.WithLabelValues(namespace, result).Inc()
+}
+
 // DeleteTaskMetrics removes metrics for a deleted Task
 func DeleteTaskMetrics(name, namespace string) {
 	TaskIteration.DeleteLabelValues(name, namespace)
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegister_Idempotent(t *testing.T) {
+	// init has already registered everything once
+	Register()
+	Register()
+
+	RecordReconcileError(ControllerAgent, "idempotent_test")
+	if got := testutil.ToFloat64(ReconcileErrors.WithLabelValues(ControllerAgent, "idempotent_test")); got != 1 {
+		t.Errorf("expected registered counter to record, got %v", got)
+	}
+}
+
+func TestRegister_SkipsDuplicateCollectors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	register(reg, collectors.NewGoCollector(), ReconcileTotal)
+	// A second Go collector instance collides with the first one
+	register(reg, collectors.NewGoCollector(), ReconcileTotal)
+}
@@ -0,0 +1,104 @@
+package render
+
+import (
+	"encoding/json"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+const (
+	// ToolCatalogConfigMapName is the name of the tool discovery catalog.
+	ToolCatalogConfigMapName = "mcp-fabric-tool-catalog"
+
+	// ToolCatalogFileName is the catalog's key in the ConfigMap.
+	ToolCatalogFileName = "catalog.json"
+)
+
+// ToolCatalog lists the ready agents and the tools they expose.
+type ToolCatalog struct {
+	Agents []ToolCatalogAgent `json:"agents"`
+}
+
+// ToolCatalogAgent is a ready agent in the tool catalog.
+type ToolCatalogAgent struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Endpoint  string            `json:"endpoint,omitempty"`
+	Provider  string            `json:"provider"`
+	ModelID   string            `json:"modelId"`
+	Tools     []ToolCatalogTool `json:"tools"`
+}
+
+// ToolCatalogTool is a tool in the tool catalog.
+type ToolCatalogTool struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	InputSchema *apiextensionsv1.JSON `json:"inputSchema,omitempty"`
+}
+
+// BuildToolCatalog collects the ready agents, ordered by namespace and name.
+// An agent's available tools are listed, falling back to its declared tools.
+func BuildToolCatalog(agents []aiv1alpha1.Agent) *ToolCatalog {
+	catalog := &ToolCatalog{Agents: []ToolCatalogAgent{}}
+	for _, agent := range agents {
+		if !agent.Status.Ready || !agent.DeletionTimestamp.IsZero() {
+			continue
+		}
+		agentTools := agent.Status.AvailableTools
+		if len(agentTools) == 0 {
+			agentTools = agent.Spec.Tools
+		}
+		tools := make([]ToolCatalogTool, 0, len(agentTools))
+		for _, t := range agentTools {
+			tools = append(tools, ToolCatalogTool{
+				Name:        t.Name,
+				Description: t.Description,
+				InputSchema: t.InputSchema,
+			})
+		}
+		catalog.Agents = append(catalog.Agents, ToolCatalogAgent{
+			Name:      agent.Name,
+			Namespace: agent.Namespace,
+			Endpoint:  agent.Status.Endpoint,
+			Provider:  agent.Spec.Model.Provider,
+			ModelID:   agent.Spec.Model.ModelID,
+			Tools:     tools,
+		})
+	}
+	sort.Slice(catalog.Agents, func(i, j int) bool {
+		a, b := catalog.Agents[i], catalog.Agents[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return catalog
+}
+
+// ToolCatalogConfigMap renders the read-only tool discovery ConfigMap.
+func ToolCatalogConfigMap(namespace string, catalog *ToolCatalog) (*corev1.ConfigMap, error) {
+	catalogJSON, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ToolCatalogConfigMapName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/name":       "mcp-fabric-tool-catalog",
+				"app.kubernetes.io/component":  "catalog",
+				"app.kubernetes.io/managed-by": "mcp-fabric-operator",
+			},
+		},
+		Data: map[string]string{
+			ToolCatalogFileName: string(catalogJSON),
+		},
+	}, nil
+}
@@ -33,6 +33,18 @@ type AgentModelConfig struct {
 	Temperature *float64 `json:"temperature,omitempty"`
 	MaxTokens   *int32   `json:"maxTokens,omitempty"`
 	Endpoint    string   `json:"endpoint,omitempty"`
+
+	// OIDC is set when the provider credentials come from token exchange.
+	OIDC *AgentModelOIDCConfig `json:"oidc,omitempty"`
+}
+
+// AgentModelOIDCConfig tells the runner where to find the projected token and
+// how to exchange it for provider credentials.
+type AgentModelOIDCConfig struct {
+	TokenFile           string `json:"tokenFile"`
+	Audience            string `json:"audience"`
+	RoleARN             string `json:"roleArn,omitempty"`
+	ServiceAccountEmail string `json:"serviceAccountEmail,omitempty"`
 }
 
 // AgentToolPackageConfig references a tool package in the agent config.
@@ -76,6 +88,7 @@ type ToolPackageInfo struct {
 	EntryModule   string
 	EnabledTools  []string
 	DisabledTools []string
+	PipIndex      *aiv1alpha1.PipIndexConfig
 }
 
 // AgentConfigMap renders a ConfigMap containing the agent runtime configuration.
@@ -95,6 +108,7 @@ func AgentConfigMap(params AgentConfigMapParams) (*corev1.ConfigMap, []byte, err
 			Temperature: agent.Spec.Model.Temperature,
 			MaxTokens:   agent.Spec.Model.MaxTokens,
 			Endpoint:    agent.Spec.Model.Endpoint,
+			OIDC:        modelOIDCConfig(agent.Spec.Model),
 		},
 		MCPEndpoints: params.MCPEndpoints,
 		Policy:       buildPolicyConfig(agent.Spec.Policy),
@@ -102,7 +116,14 @@ func AgentConfigMap(params AgentConfigMapParams) (*corev1.ConfigMap, []byte, err
 
 	// Add tool packages
 	for _, tp := range params.ToolPackages {
-		config.ToolPackages = append(config.ToolPackages, AgentToolPackageConfig(tp))
+		config.ToolPackages = append(config.ToolPackages, AgentToolPackageConfig{
+			Name:          tp.Name,
+			Namespace:     tp.Namespace,
+			Image:         tp.Image,
+			EntryModule:   tp.EntryModule,
+			EnabledTools:  tp.EnabledTools,
+			DisabledTools: tp.DisabledTools,
+		})
 	}
 
 	// Marshal to JSON
@@ -161,10 +182,15 @@ type RouteConfig struct {
 
 // CompiledRouteRule is a pre-compiled route rule for the gateway.
 type CompiledRouteRule struct {
-	Name     string                 `json:"name"`
-	Priority int32                  `json:"priority"`
-	Match    CompiledRouteMatch     `json:"match"`
-	Backends []CompiledRouteBackend `json:"backends"`
+	Name              string                 `json:"name"`
+	Priority          int32                  `json:"priority"`
+	Match             CompiledRouteMatch     `json:"match"`
+	Backends          []CompiledRouteBackend `json:"backends"`
+	SelectionStrategy string                 `json:"selectionStrategy,omitempty"`
+	QueueTimeoutMs    int64                  `json:"queueTimeoutMs,omitempty"`
+	Mode              string                 `json:"mode,omitempty"`
+	FanOutConcurrency int32                  `json:"fanOutConcurrency,omitempty"`
+	HedgeAfterMs      int64                  `json:"hedgeAfterMs,omitempty"`
 }
 
 // CompiledRouteMatch is the match criteria for a compiled rule.
@@ -182,16 +208,31 @@ type CompiledRouteBackend struct {
 	Endpoint  string `json:"endpoint"`
 	Weight    int32  `json:"weight"`
 	Ready     bool   `json:"ready"`
+	Zone      string `json:"zone,omitempty"`
+	// MaxConcurrentRequests caps in-flight requests to the backend; zero
+	// means unlimited.
+	MaxConcurrentRequests int32 `json:"maxConcurrentRequests,omitempty"`
 }
 
 // RouteDefaultConfig contains default routing configuration.
 type RouteDefaultConfig struct {
-	Backend          *CompiledRouteBackend `json:"backend,omitempty"`
-	MaxConcurrent    int32                 `json:"maxConcurrent"`
-	MaxQueueSize     int32                 `json:"maxQueueSize"`
-	QueueTimeoutMs   int64                 `json:"queueTimeoutMs"`
-	RequestTimeoutMs int64                 `json:"requestTimeoutMs"`
-	RejectUnmatched  bool                  `json:"rejectUnmatched"`
+	Backend                  *CompiledRouteBackend `json:"backend,omitempty"`
+	MaxConcurrent            int32                 `json:"maxConcurrent"`
+	MaxQueueSize             int32                 `json:"maxQueueSize"`
+	QueueTimeoutMs           int64                 `json:"queueTimeoutMs"`
+	RequestTimeoutMs         int64                 `json:"requestTimeoutMs"`
+	RejectUnmatched          bool                  `json:"rejectUnmatched"`
+	LocalityAware            bool                  `json:"localityAware"`
+	SelectionStrategy        string                `json:"selectionStrategy,omitempty"`
+	MaxRetries               int32                 `json:"maxRetries,omitempty"`
+	RetryBackoffMs           int64                 `json:"retryBackoffMs,omitempty"`
+	ForwardHeaders           []string              `json:"forwardHeaders,omitempty"`
+	OutlierConsecutiveErrors int32                 `json:"outlierConsecutiveErrors,omitempty"`
+	OutlierEjectionMs        int64                 `json:"outlierEjectionMs,omitempty"`
+	ErrorThresholdPercent    int32                 `json:"errorThresholdPercent,omitempty"`
+	MinimumRequests          int32                 `json:"minimumRequests,omitempty"`
+	ErrorWindowMs            int64                 `json:"errorWindowMs,omitempty"`
+	CooldownMs               int64                 `json:"cooldownMs,omitempty"`
 }
 
 // GatewayRoutesConfigMap renders the ConfigMap consumed by the agent gateway.
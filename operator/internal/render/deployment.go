@@ -4,6 +4,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strings"
+	"time"
 
 	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -34,6 +36,10 @@ const (
 
 	// GatewayNamespace is the default namespace for the agent gateway.
 	GatewayNamespace = "mcp-fabric-gateway"
+
+	// terminationGraceMarginSeconds is added to the request timeout to give
+	// the agent time to shut down after its last request completes.
+	terminationGraceMarginSeconds = 15
 )
 
 // AgentDeploymentParams holds parameters for rendering an Agent Deployment.
@@ -43,13 +49,17 @@ type AgentDeploymentParams struct {
 	ConfigHash    string
 	Labels        map[string]string
 	ToolPackages  []ToolPackageInfo
+
+	// ExternalSecretHash changes when synced external secret values change,
+	// rolling the pods so they pick up new credentials.
+	ExternalSecretHash string
 }
 
 // AgentDeployment renders a Deployment for an Agent.
 func AgentDeployment(params AgentDeploymentParams) *appsv1.Deployment {
 	agent := params.Agent
 
-	image := DefaultAgentRunnerImage
+	image := DefaultImage(DefaultAgentRunnerImage)
 	if agent.Spec.Image != "" {
 		image = agent.Spec.Image
 	}
@@ -66,20 +76,26 @@ func AgentDeployment(params AgentDeploymentParams) *appsv1.Deployment {
 	}
 
 	// Pod labels include model metadata for Prometheus relabeling
-	podLabels := AgentPodLabels(agent)
+	podLabels := withCustom(AgentPodLabels(agent), agent.Spec.Labels)
 
 	annotations := map[string]string{
 		"fabric.jarsater.ai/config-hash": params.ConfigHash,
 	}
+	if agent.Spec.ExternalSecrets != nil {
+		annotations["fabric.jarsater.ai/external-secrets-hash"] = params.ExternalSecretHash
+	}
+
+	annotations = withCustom(annotations, agent.Spec.Annotations)
 
 	// Build init containers for ToolPackages
-	initContainers := buildToolPackageInitContainers(params.ToolPackages)
+	initContainers := buildToolPackageInitContainers(params.ToolPackages, agent.Spec.SecurityContext, agent.Spec.InitContainerResources)
 
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      agent.Name,
-			Namespace: agent.Namespace,
-			Labels:    selectorLabels,
+			Name:        agent.Name,
+			Namespace:   agent.Namespace,
+			Labels:      withCustom(selectorLabels, agent.Spec.Labels),
+			Annotations: withCustom(nil, agent.Spec.Annotations),
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: &replicas,
@@ -92,11 +108,12 @@ func AgentDeployment(params AgentDeploymentParams) *appsv1.Deployment {
 					Annotations: annotations,
 				},
 				Spec: corev1.PodSpec{
-					ServiceAccountName:           serviceAccountName(agent),
-					AutomountServiceAccountToken: ptr.To(false),
-					DNSPolicy:                    corev1.DNSClusterFirst,
-					SecurityContext:              podSecurityContext(),
-					InitContainers:               initContainers,
+					ServiceAccountName:            serviceAccountName(agent),
+					AutomountServiceAccountToken:  ptr.To(false),
+					TerminationGracePeriodSeconds: ptr.To(terminationGracePeriod(agent)),
+					DNSPolicy:                     corev1.DNSClusterFirst,
+					SecurityContext:               podSecurityContext(agent.Spec.SecurityContext),
+					InitContainers:                initContainers,
 					Containers: []corev1.Container{
 						{
 							Name:            "agent",
@@ -144,7 +161,7 @@ func AgentDeployment(params AgentDeploymentParams) *appsv1.Deployment {
 									MountPath: "/workspace",
 								},
 							},
-							SecurityContext: containerSecurityContext(),
+							SecurityContext: containerSecurityContext(agent.Spec.SecurityContext),
 							ReadinessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
 									HTTPGet: &corev1.HTTPGetAction{
@@ -222,34 +239,72 @@ func AgentDeployment(params AgentDeploymentParams) *appsv1.Deployment {
 	}
 
 	// Add envFrom sources (for loading credentials from secrets/configmaps)
-	if len(agent.Spec.EnvFrom) > 0 {
-		deployment.Spec.Template.Spec.Containers[0].EnvFrom = agent.Spec.EnvFrom
-	}
+	deployment.Spec.Template.Spec.Containers[0].EnvFrom = agentEnvFrom(agent)
+
+	// Let the pod drain before the agent exits
+	deployment.Spec.Template.Spec.Containers[0].Lifecycle = preStopLifecycle(agent.Spec.PreStop)
+
+	// Mount the projected token for provider OIDC token exchange
+	podSpec := &deployment.Spec.Template.Spec
+	applyModelOIDC(agent, podSpec, &podSpec.Containers[0])
 
 	return deployment
 }
 
-// podSecurityContext returns hardened pod security context.
-// RunAsUser/RunAsGroup are not set, allowing each image's USER directive to take effect.
-func podSecurityContext() *corev1.PodSecurityContext {
-	return &corev1.PodSecurityContext{
-		RunAsNonRoot: ptr.To(true),
-		SeccompProfile: &corev1.SeccompProfile{
-			Type: corev1.SeccompProfileTypeRuntimeDefault,
+// agentEnvFrom returns the agent's envFrom sources, including the Secret
+// synced from an external secret manager when configured.
+func agentEnvFrom(agent *aiv1alpha1.Agent) []corev1.EnvFromSource {
+	if agent.Spec.ExternalSecrets == nil {
+		return agent.Spec.EnvFrom
+	}
+	envFrom := make([]corev1.EnvFromSource, 0, len(agent.Spec.EnvFrom)+1)
+	envFrom = append(envFrom, agent.Spec.EnvFrom...)
+	return append(envFrom, corev1.EnvFromSource{
+		SecretRef: &corev1.SecretEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: ExternalSecretName(agent)},
 		},
+	})
+}
+
+// terminationGracePeriod returns the agent's pod termination grace period,
+// defaulting to long enough for a preStop sleep and then a request at the
+// policy timeout to finish.
+func terminationGracePeriod(agent *aiv1alpha1.Agent) int64 {
+	if agent.Spec.TerminationGracePeriodSeconds != nil {
+		return *agent.Spec.TerminationGracePeriodSeconds
 	}
+	grace := int64(buildPolicyConfig(agent.Spec.Policy).RequestTimeoutSeconds) + terminationGraceMarginSeconds
+	if ps := agent.Spec.PreStop; ps != nil && ps.DrainPath == "" && ps.Sleep != nil {
+		grace += sleepSeconds(ps.Sleep.Duration)
+	}
+	return grace
 }
 
-// containerSecurityContext returns hardened container security context.
-func containerSecurityContext() *corev1.SecurityContext {
-	return &corev1.SecurityContext{
-		AllowPrivilegeEscalation: ptr.To(false),
-		ReadOnlyRootFilesystem:   ptr.To(true),
-		RunAsNonRoot:             ptr.To(true),
-		Capabilities: &corev1.Capabilities{
-			Drop: []corev1.Capability{"ALL"},
-		},
+// preStopLifecycle renders the agent container's preStop hook, or nil when
+// none is configured.
+func preStopLifecycle(preStop *aiv1alpha1.AgentPreStop) *corev1.Lifecycle {
+	if preStop == nil {
+		return nil
 	}
+	var handler corev1.LifecycleHandler
+	switch {
+	case preStop.DrainPath != "":
+		handler.HTTPGet = &corev1.HTTPGetAction{
+			Path: preStop.DrainPath,
+			Port: intstr.FromInt32(AgentPort),
+		}
+	case preStop.Sleep != nil && preStop.Sleep.Duration > 0:
+		// Native sleep action: the hardened image may not ship a sleep binary
+		handler.Sleep = &corev1.SleepAction{Seconds: sleepSeconds(preStop.Sleep.Duration)}
+	default:
+		return nil
+	}
+	return &corev1.Lifecycle{PreStop: &handler}
+}
+
+// sleepSeconds rounds a preStop sleep up to whole seconds.
+func sleepSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
 }
 
 // serviceAccountName returns the SA name for an agent.
@@ -282,6 +337,23 @@ func AgentPodLabels(agent *aiv1alpha1.Agent) map[string]string {
 	return labels
 }
 
+// withCustom merges user-supplied labels or annotations under the managed
+// ones, so a custom entry never overrides a key the operator relies on. It
+// returns a new map and nil when both are empty.
+func withCustom(managed, custom map[string]string) map[string]string {
+	if len(managed) == 0 && len(custom) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(managed)+len(custom))
+	for key, value := range custom {
+		merged[key] = value
+	}
+	for key, value := range managed {
+		merged[key] = value
+	}
+	return merged
+}
+
 // sanitizeLabelValue converts a string to a valid Kubernetes label value.
 // Label values must be 63 chars or less, start/end with alphanumeric,
 // and contain only alphanumeric, '-', '_', or '.'.
@@ -326,12 +398,13 @@ func HashConfig(content []byte) string {
 // buildToolPackageInitContainers creates init containers for shared libs and each ToolPackage.
 // The agent-libs init container always runs first to provide shared libraries (logging, etc).
 // Each ToolPackage init container copies Python modules from its image to /tools/.
-func buildToolPackageInitContainers(toolPackages []ToolPackageInfo) []corev1.Container {
+// All of them get the given resources, or the defaults when nil.
+func buildToolPackageInitContainers(toolPackages []ToolPackageInfo, security *aiv1alpha1.SecurityContextConfig, resources *corev1.ResourceRequirements) []corev1.Container {
 	initContainers := []corev1.Container{
 		// Always include agent-libs first for shared libraries
 		{
 			Name:            "agent-libs",
-			Image:           AgentLibsImage,
+			Image:           DefaultImage(AgentLibsImage),
 			ImagePullPolicy: corev1.PullIfNotPresent,
 			Command: []string{
 				"sh", "-c", "cp -r /app/* /tools/",
@@ -342,12 +415,13 @@ func buildToolPackageInitContainers(toolPackages []ToolPackageInfo) []corev1.Con
 					MountPath: "/tools",
 				},
 			},
-			SecurityContext: containerSecurityContext(),
+			SecurityContext: containerSecurityContext(security),
+			Resources:       resourcesOrDefault(resources, toolPackageInitResources),
 		},
 	}
 
 	for i, tp := range toolPackages {
-		initContainers = append(initContainers, corev1.Container{
+		container := corev1.Container{
 			Name:            fmt.Sprintf("toolpkg-%d", i),
 			Image:           tp.Image,
 			ImagePullPolicy: corev1.PullIfNotPresent,
@@ -360,9 +434,71 @@ func buildToolPackageInitContainers(toolPackages []ToolPackageInfo) []corev1.Con
 					MountPath: "/tools",
 				},
 			},
-			SecurityContext: containerSecurityContext(),
-		})
+			SecurityContext: containerSecurityContext(security),
+			Resources:       resourcesOrDefault(resources, toolPackageInitResources),
+		}
+		if tp.PipIndex != nil {
+			container.Command = []string{"sh", "-c", pipInstallScript}
+			container.Env = pipIndexEnv(tp.PipIndex)
+		}
+		initContainers = append(initContainers, container)
 	}
 
 	return initContainers
 }
+
+// DefaultPipRequirementsFile is the requirements file installed when a
+// ToolPackage's pip index does not specify one.
+const DefaultPipRequirementsFile = "/app/requirements.txt"
+
+// pipInstallScript copies the package and installs its requirements into
+// /tools. Scratch space lives on the tools volume because the root
+// filesystem is read-only.
+const pipInstallScript = `cp -r /app/* /tools/ && ` +
+	`mkdir -p /tools/.pip-tmp && ` +
+	`TMPDIR=/tools/.pip-tmp HOME=/tools/.pip-tmp pip install --no-cache-dir --disable-pip-version-check ` +
+	`--target /tools -r "$PIP_REQUIREMENTS_FILE" && ` +
+	`rm -rf /tools/.pip-tmp`
+
+// pipIndexEnv builds the init container env for installing from a pip index.
+// Credentials are injected from the Secret and spliced into PIP_INDEX_URL via
+// Kubernetes $(VAR) expansion so they never appear in the pod spec.
+func pipIndexEnv(index *aiv1alpha1.PipIndexConfig) []corev1.EnvVar {
+	requirements := index.RequirementsFile
+	if requirements == "" {
+		requirements = DefaultPipRequirementsFile
+	}
+
+	var env []corev1.EnvVar
+	indexURL := index.URL
+	if index.CredentialsSecret != nil {
+		env = append(env,
+			corev1.EnvVar{
+				Name: "PIP_INDEX_USERNAME",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: index.CredentialsSecret.Name},
+						Key:                  "username",
+					},
+				},
+			},
+			corev1.EnvVar{
+				Name: "PIP_INDEX_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: index.CredentialsSecret.Name},
+						Key:                  "password",
+					},
+				},
+			},
+		)
+		if scheme, rest, ok := strings.Cut(indexURL, "://"); ok {
+			indexURL = scheme + "://$(PIP_INDEX_USERNAME):$(PIP_INDEX_PASSWORD)@" + rest
+		}
+	}
+
+	return append(env,
+		corev1.EnvVar{Name: "PIP_INDEX_URL", Value: indexURL},
+		corev1.EnvVar{Name: "PIP_REQUIREMENTS_FILE", Value: requirements},
+	)
+}
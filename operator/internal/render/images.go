@@ -0,0 +1,38 @@
+package render
+
+import "strings"
+
+// registryMirror is the registry that default images are pulled from instead
+// of their upstream registry. Empty leaves default images untouched.
+var registryMirror string
+
+// SetRegistryMirror rewrites default image references (agent runner, agent
+// libs, git) onto the given registry, e.g. "registry.internal:5000/mirror".
+// Images set explicitly on a resource are never rewritten. It is meant to be
+// called once at manager startup.
+func SetRegistryMirror(mirror string) {
+	registryMirror = strings.TrimSuffix(mirror, "/")
+}
+
+// DefaultImage returns ref relocated onto the registry mirror, if one is
+// configured. The upstream registry host is replaced; the repository path
+// and tag are kept, so "ghcr.io/jalet/agent-libs:latest" becomes
+// "<mirror>/jalet/agent-libs:latest" and "alpine/git:2.43" becomes
+// "<mirror>/alpine/git:2.43".
+func DefaultImage(ref string) string {
+	if registryMirror == "" {
+		return ref
+	}
+	return registryMirror + "/" + stripRegistry(ref)
+}
+
+// stripRegistry removes the registry host from an image reference. Like the
+// container runtime, it treats the first path component as a host only when
+// it contains a "." or ":" or is "localhost".
+func stripRegistry(ref string) string {
+	host, rest, ok := strings.Cut(ref, "/")
+	if ok && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		return rest
+	}
+	return ref
+}
@@ -0,0 +1,119 @@
+package render
+
+import (
+	"testing"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// withRegistryMirror sets the registry mirror for the duration of a test.
+func withRegistryMirror(t *testing.T, mirror string) {
+	t.Helper()
+	SetRegistryMirror(mirror)
+	t.Cleanup(func() { SetRegistryMirror("") })
+}
+
+func TestDefaultImage(t *testing.T) {
+	tests := []struct {
+		name   string
+		mirror string
+		ref    string
+		want   string
+	}{
+		{name: "no mirror", mirror: "", ref: DefaultAgentRunnerImage, want: DefaultAgentRunnerImage},
+		{name: "registry host replaced", mirror: "mirror.internal:5000", ref: "ghcr.io/jalet/agent-libs:latest", want: "mirror.internal:5000/jalet/agent-libs:latest"},
+		{name: "docker hub image", mirror: "mirror.internal:5000", ref: "alpine/git:2.43", want: "mirror.internal:5000/alpine/git:2.43"},
+		{name: "mirror with path and trailing slash", mirror: "mirror.internal/upstream/", ref: "ghcr.io/jalet/strands-agent-runner:latest", want: "mirror.internal/upstream/jalet/strands-agent-runner:latest"},
+		{name: "localhost registry", mirror: "mirror.internal", ref: "localhost/tools:dev", want: "mirror.internal/tools:dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withRegistryMirror(t, tt.mirror)
+			if got := DefaultImage(tt.ref); got != tt.want {
+				t.Errorf("DefaultImage(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryMirror_RewritesOnlyDefaults(t *testing.T) {
+	withRegistryMirror(t, "mirror.internal:5000")
+
+	agent := &aiv1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Name: "assistant", Namespace: "default"}}
+	dep := AgentDeployment(AgentDeploymentParams{Agent: agent})
+	if got := dep.Spec.Template.Spec.Containers[0].Image; got != "mirror.internal:5000/jalet/strands-agent-runner:latest" {
+		t.Errorf("expected mirrored runner image, got %s", got)
+	}
+	if got := dep.Spec.Template.Spec.InitContainers[0].Image; got != "mirror.internal:5000/jalet/agent-libs:latest" {
+		t.Errorf("expected mirrored agent-libs image, got %s", got)
+	}
+
+	agent.Spec.Image = "ghcr.io/example/custom-agent:v1"
+	dep = AgentDeployment(AgentDeploymentParams{Agent: agent})
+	if got := dep.Spec.Template.Spec.Containers[0].Image; got != "ghcr.io/example/custom-agent:v1" {
+		t.Errorf("expected explicit image untouched, got %s", got)
+	}
+
+	gitConfig := &aiv1alpha1.GitConfig{
+		URL:               "https://github.com/org/repo.git",
+		Image:             DefaultGitImage,
+		CredentialsSecret: corev1.LocalObjectReference{Name: "git-creds"},
+	}
+	if got := gitCloneInitContainer(gitConfig, DefaultWorkspaceDir, "").Image; got != "mirror.internal:5000/alpine/git:2.43" {
+		t.Errorf("expected mirrored git image, got %s", got)
+	}
+	gitConfig.Image = "bitnami/git:2.45"
+	if got := gitCloneInitContainer(gitConfig, DefaultWorkspaceDir, "").Image; got != "bitnami/git:2.45" {
+		t.Errorf("expected explicit git image untouched, got %s", got)
+	}
+}
+
+func TestOrchestratorJob_DefaultGitImage(t *testing.T) {
+	withRegistryMirror(t, "mirror.internal:5000")
+
+	gitConfig := &aiv1alpha1.GitConfig{
+		URL:               "https://github.com/org/repo.git",
+		CredentialsSecret: corev1.LocalObjectReference{Name: "git-creds"},
+	}
+	gitImage := func(image string) string {
+		t.Helper()
+		task := &aiv1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"},
+			Spec:       aiv1alpha1.TaskSpec{Git: gitConfig},
+		}
+		job, err := OrchestratorJob(OrchestratorJobParams{
+			Task:              task,
+			OrchestratorAgent: &aiv1alpha1.Agent{Spec: aiv1alpha1.AgentSpec{Image: "orchestrator:v1"}},
+			WorkerEndpoint:    "worker:8080",
+			WorkspacePVC:      "workspace",
+			PRD:               `{}`,
+			GitImage:          image,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return job.Spec.Template.Spec.InitContainers[0].Image
+	}
+
+	if got := gitImage("registry.corp/tools/git:2.47"); got != "registry.corp/tools/git:2.47" {
+		t.Errorf("expected the configured default git image, got %s", got)
+	}
+
+	// The CRD fills in DefaultGitImage when the Task sets no image
+	gitConfig.Image = DefaultGitImage
+	if got := gitImage("registry.corp/tools/git:2.47"); got != "registry.corp/tools/git:2.47" {
+		t.Errorf("expected the configured default git image over the CRD default, got %s", got)
+	}
+
+	if got := gitImage(""); got != "mirror.internal:5000/alpine/git:2.43" {
+		t.Errorf("expected the mirrored built-in git image without a configured default, got %s", got)
+	}
+
+	gitConfig.Image = "bitnami/git:2.45"
+	if got := gitImage("registry.corp/tools/git:2.47"); got != "bitnami/git:2.45" {
+		t.Errorf("expected the Task's git image to take precedence, got %s", got)
+	}
+}
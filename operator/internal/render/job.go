@@ -4,6 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
 	"time"
 
 	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
@@ -14,6 +18,27 @@ import (
 	"k8s.io/utils/ptr"
 )
 
+const (
+	// OrchestratorContainerName is the orchestrator Job's main container; the
+	// task controller reads the result from its logs.
+	OrchestratorContainerName = "orchestrator"
+
+	// DefaultWorkspaceDir is where the workspace volume is mounted, and what
+	// WORKSPACE_DIR points at, unless the Task overrides it.
+	DefaultWorkspaceDir = "/workspace"
+
+	// workspaceVolumeName is the Pod volume backed by the workspace PVC.
+	workspaceVolumeName = "workspace"
+)
+
+// WorkspaceDir returns the workspace mount path for a task's Job.
+func WorkspaceDir(task *aiv1alpha1.Task) string {
+	if task.Spec.WorkspaceDir != "" {
+		return task.Spec.WorkspaceDir
+	}
+	return DefaultWorkspaceDir
+}
+
 // WorkspacePVCName returns the PVC name for a task's workspace.
 func WorkspacePVCName(task *aiv1alpha1.Task) string {
 	return fmt.Sprintf("%s-workspace", task.Name)
@@ -27,14 +52,46 @@ func LocalWorkerEndpoint() string {
 	return fmt.Sprintf("127.0.0.1:%d", AgentPort)
 }
 
+// WorkerEndpoint returns the worker address passed to the orchestrator: the
+// task's explicit WorkerEndpoint URL if set, otherwise the co-located
+// sidecar's LocalWorkerEndpoint.
+func WorkerEndpoint(task *aiv1alpha1.Task) (string, error) {
+	endpoint := task.Spec.WorkerEndpoint
+	if endpoint == "" {
+		return LocalWorkerEndpoint(), nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid workerEndpoint %q: %w", endpoint, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid workerEndpoint %q: must be an http(s) URL with a host", endpoint)
+	}
+	return endpoint, nil
+}
+
 // OrchestratorJobParams holds parameters for rendering an orchestrator Job.
 type OrchestratorJobParams struct {
 	Task              *aiv1alpha1.Task
 	OrchestratorAgent *aiv1alpha1.Agent
 	WorkerAgent       *aiv1alpha1.Agent // co-located as a sidecar sharing the workspace
-	WorkerEndpoint    string            // e.g., "127.0.0.1:8080"
+	WorkerEndpoint    string            // e.g., "127.0.0.1:8080" or "http://worker.team:8080"
 	WorkspacePVC      string
 	PRD               string // JSON string of the PRD
+
+	// Sources are additional named documents (e.g. "requirements",
+	// "design") passed to the orchestrator alongside the PRD.
+	Sources map[string]string
+
+	// ExistingPullRequestURL is the PR opened by a previous run of the task.
+	// When set, the orchestrator pushes to that PR's branch instead of
+	// opening a new one.
+	ExistingPullRequestURL string
+
+	// GitImage is the git-clone image used when the Task's git config does
+	// not set one. Empty means DefaultGitImage, following the registry
+	// mirror.
+	GitImage string
 }
 
 // OrchestratorJob renders a Kubernetes Job for the task orchestrator.
@@ -57,9 +114,21 @@ func OrchestratorJob(params OrchestratorJobParams) (*batchv1.Job, error) {
 		"context":        task.Spec.Context,
 	}
 
-	// Add quality gates if configured
+	// Start from a specific PRD story if requested
+	if task.Spec.ResumeFromTaskID != "" {
+		taskConfig["resumeFromTaskId"] = task.Spec.ResumeFromTaskID
+	}
+
+	// Add additional named sources if configured
+	if len(params.Sources) > 0 {
+		taskConfig["sources"] = params.Sources
+	}
+
+	// Add quality gates if configured, asking the orchestrator to report
+	// gate commands missing from its image before it starts
 	if len(task.Spec.QualityGates) > 0 {
 		taskConfig["qualityGates"] = task.Spec.QualityGates
+		taskConfig["checkGateCommands"] = true
 	}
 
 	// Add limits if configured
@@ -82,6 +151,15 @@ func OrchestratorJob(params OrchestratorJobParams) (*batchv1.Job, error) {
 
 	// Add git config if present (for finalization)
 	if task.Spec.Git != nil {
+		if err := validateNonEmpty("reviewers", task.Spec.Git.Reviewers); err != nil {
+			return nil, err
+		}
+		if err := validateNonEmpty("assignees", task.Spec.Git.Assignees); err != nil {
+			return nil, err
+		}
+		if err := validateNonEmpty("prLabels", task.Spec.Git.PRLabels); err != nil {
+			return nil, err
+		}
 		gitConfigMap := map[string]interface{}{
 			"url":          task.Spec.Git.URL,
 			"branch":       getStringOrDefault(task.Spec.Git.Branch, "main"),
@@ -95,6 +173,19 @@ func OrchestratorJob(params OrchestratorJobParams) (*batchv1.Job, error) {
 			"prBody":       task.Spec.Git.PRBody,
 			"provider":     string(task.Spec.Git.Provider),
 		}
+		if len(task.Spec.Git.Reviewers) > 0 {
+			gitConfigMap["reviewers"] = task.Spec.Git.Reviewers
+		}
+		if len(task.Spec.Git.Assignees) > 0 {
+			gitConfigMap["assignees"] = task.Spec.Git.Assignees
+		}
+		if labels := pullRequestLabels(task); len(labels) > 0 {
+			gitConfigMap["prLabels"] = labels
+		}
+		if params.ExistingPullRequestURL != "" {
+			gitConfigMap["reusePR"] = true
+			gitConfigMap["pullRequestUrl"] = params.ExistingPullRequestURL
+		}
 		taskConfig["git"] = gitConfigMap
 	}
 
@@ -117,12 +208,15 @@ func OrchestratorJob(params OrchestratorJobParams) (*batchv1.Job, error) {
 		jobName = jobName[:54] + "-" + hash
 	}
 
-	labels := OrchestratorJobLabels(task)
+	labels := withCustom(OrchestratorJobLabels(task), task.Spec.Labels)
+	annotations := withCustom(nil, task.Spec.Annotations)
+	workspaceDir := WorkspaceDir(task)
+	sharedGitConfig := path.Join(workspaceDir, ".gitconfig")
 
 	// Build volumes
 	volumes := []corev1.Volume{
 		{
-			Name: "workspace",
+			Name: workspaceVolumeName,
 			VolumeSource: corev1.VolumeSource{
 				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
 					ClaimName: params.WorkspacePVC,
@@ -166,21 +260,21 @@ func OrchestratorJob(params OrchestratorJobParams) (*batchv1.Job, error) {
 	// Build init containers, in order:
 	//  1. git-clone (regular init) -- clones the repo into the shared workspace.
 	//  2. worker (native sidecar) -- starts after the clone and stays running
-	//     alongside the orchestrator, sharing /workspace so the worker's file
+	//     alongside the orchestrator, sharing the workspace so the worker's file
 	//     edits land in the cloned repo. The orchestrator reaches it on
 	//     loopback. As a native sidecar it is terminated when the orchestrator
 	//     (the Job's only regular container) exits, so the Job still completes.
 	var initContainers []corev1.Container
 	if task.Spec.Git != nil {
-		initContainers = append(initContainers, gitCloneInitContainer(task.Spec.Git))
+		initContainers = append(initContainers, gitCloneInitContainer(task.Spec.Git, workspaceDir, params.GitImage))
 	}
 	if params.WorkerAgent != nil {
-		initContainers = append(initContainers, workerSidecarContainer(params.WorkerAgent, task.Spec.Git != nil))
+		initContainers = append(initContainers, workerSidecarContainer(params.WorkerAgent, task.Spec.Git != nil, workspaceDir, LayerSecurityContext(params.WorkerAgent.Spec.SecurityContext, task.Spec.SecurityContext)))
 	}
 
 	// Build orchestrator container
 	orchestratorContainer := corev1.Container{
-		Name:            "orchestrator",
+		Name:            OrchestratorContainerName,
 		Image:           image,
 		ImagePullPolicy: corev1.PullIfNotPresent,
 		Env: []corev1.EnvVar{
@@ -190,7 +284,7 @@ func OrchestratorJob(params OrchestratorJobParams) (*batchv1.Job, error) {
 			},
 			{
 				Name:  "WORKSPACE_DIR",
-				Value: "/workspace",
+				Value: workspaceDir,
 			},
 			{
 				Name:  "PYTHONUNBUFFERED",
@@ -199,15 +293,15 @@ func OrchestratorJob(params OrchestratorJobParams) (*batchv1.Job, error) {
 		},
 		VolumeMounts: []corev1.VolumeMount{
 			{
-				Name:      "workspace",
-				MountPath: "/workspace",
+				Name:      workspaceVolumeName,
+				MountPath: workspaceDir,
 			},
 			{
 				Name:      "tmp",
 				MountPath: "/tmp",
 			},
 		},
-		SecurityContext: containerSecurityContext(),
+		SecurityContext: containerSecurityContext(task.Spec.SecurityContext),
 	}
 
 	// Add git-related volume mounts if git is configured
@@ -227,7 +321,7 @@ func OrchestratorJob(params OrchestratorJobParams) (*batchv1.Job, error) {
 			},
 			corev1.EnvVar{
 				Name:  "GIT_CONFIG_GLOBAL",
-				Value: "/workspace/.gitconfig",
+				Value: sharedGitConfig,
 			},
 		)
 	}
@@ -238,9 +332,7 @@ func OrchestratorJob(params OrchestratorJobParams) (*batchv1.Job, error) {
 	}
 
 	// Add envFrom sources
-	if len(agent.Spec.EnvFrom) > 0 {
-		orchestratorContainer.EnvFrom = agent.Spec.EnvFrom
-	}
+	orchestratorContainer.EnvFrom = agentEnvFrom(agent)
 
 	// Apply resource requirements
 	if agent.Spec.Resources != nil {
@@ -260,9 +352,10 @@ func OrchestratorJob(params OrchestratorJobParams) (*batchv1.Job, error) {
 
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      jobName,
-			Namespace: task.Namespace,
-			Labels:    labels,
+			Name:        jobName,
+			Namespace:   task.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		Spec: batchv1.JobSpec{
 			BackoffLimit:            ptr.To(int32(0)), // No retries - we handle failure in orchestrator
@@ -270,13 +363,14 @@ func OrchestratorJob(params OrchestratorJobParams) (*batchv1.Job, error) {
 			TTLSecondsAfterFinished: ptr.To(int32(3600)), // Cleanup after 1 hour (longer for debugging)
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
+					Labels:      labels,
+					Annotations: annotations,
 				},
 				Spec: corev1.PodSpec{
 					RestartPolicy:                corev1.RestartPolicyNever,
 					ServiceAccountName:           podServiceAccount,
 					AutomountServiceAccountToken: ptr.To(false),
-					SecurityContext:              podSecurityContext(),
+					SecurityContext:              podSecurityContext(task.Spec.SecurityContext),
 					InitContainers:               initContainers,
 					Containers:                   []corev1.Container{orchestratorContainer},
 					Volumes:                      volumes,
@@ -303,10 +397,11 @@ func OrchestratorJobLabels(task *aiv1alpha1.Task) map[string]string {
 // workerSidecarContainer builds the worker as a native sidecar (init container
 // with restartPolicy=Always) co-located with the orchestrator. It shares the
 // workspace volume so the worker's edits land in the cloned repo, and serves
-// HTTP on AgentPort which the orchestrator reaches over loopback.
-func workerSidecarContainer(workerAgent *aiv1alpha1.Agent, gitConfigured bool) corev1.Container {
+// HTTP on AgentPort which the orchestrator reaches over loopback. security is
+// the worker Agent's settings with the Task's layered on top.
+func workerSidecarContainer(workerAgent *aiv1alpha1.Agent, gitConfigured bool, workspaceDir string, security *aiv1alpha1.SecurityContextConfig) corev1.Container {
 	env := []corev1.EnvVar{
-		{Name: "WORKSPACE_DIR", Value: "/workspace"},
+		{Name: "WORKSPACE_DIR", Value: workspaceDir},
 		{Name: "PYTHONUNBUFFERED", Value: "1"},
 	}
 	if workerAgent.Spec.Model.ModelID != "" {
@@ -318,7 +413,7 @@ func workerSidecarContainer(workerAgent *aiv1alpha1.Agent, gitConfigured bool) c
 	if gitConfigured {
 		// Reuse the shared gitconfig written by the git-clone init container so
 		// any in-task commits carry the configured identity.
-		env = append(env, corev1.EnvVar{Name: "GIT_CONFIG_GLOBAL", Value: "/workspace/.gitconfig"})
+		env = append(env, corev1.EnvVar{Name: "GIT_CONFIG_GLOBAL", Value: path.Join(workspaceDir, ".gitconfig")})
 	}
 	env = append(env, workerAgent.Spec.Env...)
 
@@ -328,12 +423,12 @@ func workerSidecarContainer(workerAgent *aiv1alpha1.Agent, gitConfigured bool) c
 		ImagePullPolicy: corev1.PullIfNotPresent,
 		RestartPolicy:   ptr.To(corev1.ContainerRestartPolicyAlways), // native sidecar
 		Env:             env,
-		EnvFrom:         workerAgent.Spec.EnvFrom,
+		EnvFrom:         agentEnvFrom(workerAgent),
 		VolumeMounts: []corev1.VolumeMount{
-			{Name: "workspace", MountPath: "/workspace"},
+			{Name: workspaceVolumeName, MountPath: workspaceDir},
 			{Name: "tmp", MountPath: "/tmp"},
 		},
-		SecurityContext: containerSecurityContext(),
+		SecurityContext: containerSecurityContext(security),
 		// Gate orchestrator startup on the worker serving HTTP: native-sidecar
 		// startup probes must pass before the main container starts.
 		StartupProbe: &corev1.Probe{
@@ -353,12 +448,53 @@ func workerSidecarContainer(workerAgent *aiv1alpha1.Agent, gitConfigured bool) c
 	return container
 }
 
+// validateNonEmpty rejects blank entries in a git string list such as
+// reviewers or PR labels.
+func validateNonEmpty(field string, values []string) error {
+	for i, value := range values {
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("git.%s[%d] must not be empty", field, i)
+		}
+	}
+	return nil
+}
+
+// pullRequestLabels returns the labels to apply to the task's PR: the
+// configured PRLabels followed, if enabled, by the Task's own labels as
+// sorted "key=value" pairs. Duplicates are dropped.
+func pullRequestLabels(task *aiv1alpha1.Task) []string {
+	var labels []string
+	seen := map[string]bool{}
+	add := func(label string) {
+		if !seen[label] {
+			seen[label] = true
+			labels = append(labels, label)
+		}
+	}
+	for _, label := range task.Spec.Git.PRLabels {
+		add(label)
+	}
+	if task.Spec.Git.PropagateTaskLabels {
+		keys := make([]string, 0, len(task.Labels))
+		for key := range task.Labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			add(key + "=" + task.Labels[key])
+		}
+	}
+	return labels
+}
+
 // DefaultGitImage is the default container image for git operations.
 const DefaultGitImage = "alpine/git:2.43"
 
 // gitCloneInitContainer creates an init container that clones a git repository.
 // The git token is read from a mounted secret file for security (not from env vars).
-func gitCloneInitContainer(gitConfig *aiv1alpha1.GitConfig) corev1.Container {
+// The repository is cloned into workspaceDir, exposed to the script as
+// WORKSPACE_DIR. defaultImage is used when the git config sets no image.
+func gitCloneInitContainer(gitConfig *aiv1alpha1.GitConfig, workspaceDir, defaultImage string) corev1.Container {
 	// Build the clone script with feature branch support
 	// Token is read from mounted secret file to avoid exposure in env vars or logs
 	script := `
@@ -375,16 +511,16 @@ echo "https://x-access-token:${GIT_TOKEN}@${GIT_HOST}" > /home/appuser/.git-cred
 chmod 600 /home/appuser/.git-credentials
 git config --global user.name "${GIT_AUTHOR}"
 git config --global user.email "${GIT_EMAIL}"
-git config --global --add safe.directory /workspace
+git config --global --add safe.directory "${WORKSPACE_DIR}"
 
 echo "Cloning repository..."
 if [ "${GIT_DEPTH}" = "0" ]; then
-    git clone "${GIT_URL}" /workspace
+    git clone "${GIT_URL}" "${WORKSPACE_DIR}"
 else
-    git clone --depth "${GIT_DEPTH}" "${GIT_URL}" /workspace
+    git clone --depth "${GIT_DEPTH}" "${GIT_URL}" "${WORKSPACE_DIR}"
 fi
 
-cd /workspace
+cd "${WORKSPACE_DIR}"
 
 # If BaseBranch is set, create feature branch from it
 if [ -n "${GIT_BASE_BRANCH}" ]; then
@@ -397,12 +533,12 @@ else
 fi
 
 # Write shared gitconfig to workspace volume (accessible by orchestrator)
-cat > /workspace/.gitconfig <<GITCFG
+cat > "${WORKSPACE_DIR}/.gitconfig" <<GITCFG
 [user]
 	name = ${GIT_AUTHOR}
 	email = ${GIT_EMAIL}
 [safe]
-	directory = /workspace
+	directory = ${WORKSPACE_DIR}
 [credential]
 	helper = !f() { echo username=x-access-token; echo password=$(cat /secrets/git/token); }; f
 GITCFG
@@ -415,9 +551,14 @@ echo "Git setup complete. HEAD: $(git rev-parse HEAD)"
 		depth = *gitConfig.Depth
 	}
 
-	// Use configured image or default
-	gitImage := DefaultGitImage
-	if gitConfig.Image != "" {
+	// Use configured image or default. The CRD defaults the image field, so
+	// DefaultGitImage is treated as unset. A configured default is used as
+	// is; the built-in one follows the registry mirror.
+	gitImage := defaultImage
+	if gitImage == "" {
+		gitImage = DefaultImage(DefaultGitImage)
+	}
+	if gitConfig.Image != "" && gitConfig.Image != DefaultGitImage {
 		gitImage = gitConfig.Image
 	}
 
@@ -434,13 +575,15 @@ echo "Git setup complete. HEAD: $(git rev-parse HEAD)"
 			{Name: "GIT_DEPTH", Value: fmt.Sprintf("%d", depth)},
 			{Name: "GIT_AUTHOR", Value: getStringOrDefault(gitConfig.CommitAuthor, "MCP Fabric Task")},
 			{Name: "GIT_EMAIL", Value: getStringOrDefault(gitConfig.CommitEmail, "task@mcp-fabric.local")},
+			{Name: "WORKSPACE_DIR", Value: workspaceDir},
 			// Note: GIT_TOKEN is read from mounted secret file, not env var
 		},
 		VolumeMounts: []corev1.VolumeMount{
-			{Name: "workspace", MountPath: "/workspace"},
+			{Name: workspaceVolumeName, MountPath: workspaceDir},
 			{Name: "git-home", MountPath: "/home/appuser"},
 			{Name: "git-credentials", MountPath: "/secrets/git", ReadOnly: true},
 		},
+		Resources: resourcesOrDefault(gitConfig.Resources, gitCloneInitResources),
 		// Security context rationale (deliberate, not an oversight):
 		//   RunAsNonRoot=false  -- the default alpine/git image ships only a root
 		//     user; running as non-root would fail to start. Privilege escalation
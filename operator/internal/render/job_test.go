@@ -210,6 +210,9 @@ func TestOrchestratorJob(t *testing.T) {
 						if len(gatesList) != 2 {
 							t.Errorf("expected 2 quality gates, got %d", len(gatesList))
 						}
+						if config["checkGateCommands"] != true {
+							t.Errorf("expected checkGateCommands to be set, got %v", config["checkGateCommands"])
+						}
 					}
 				}
 			},
@@ -301,6 +304,298 @@ func TestOrchestratorJob(t *testing.T) {
 	}
 }
 
+func TestOrchestratorJob_ReusePR(t *testing.T) {
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"},
+		Spec: aiv1alpha1.TaskSpec{
+			Git: &aiv1alpha1.GitConfig{
+				URL:               "https://github.com/org/repo.git",
+				CredentialsSecret: corev1.LocalObjectReference{Name: "git-creds"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		existingPR  string
+		wantReusePR bool
+	}{
+		{name: "first run opens a new PR", existingPR: "", wantReusePR: false},
+		{name: "rerun reuses existing PR", existingPR: "https://github.com/org/repo/pull/42", wantReusePR: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := OrchestratorJob(OrchestratorJobParams{
+				Task:                   task,
+				OrchestratorAgent:      &aiv1alpha1.Agent{Spec: aiv1alpha1.AgentSpec{Image: "orchestrator:v1"}},
+				WorkerEndpoint:         "worker:8080",
+				WorkspacePVC:           "workspace",
+				PRD:                    `{}`,
+				ExistingPullRequestURL: tt.existingPR,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			git := taskConfigGit(t, job)
+			reusePR, _ := git["reusePR"].(bool)
+			if reusePR != tt.wantReusePR {
+				t.Errorf("expected reusePR %v, got %v", tt.wantReusePR, git["reusePR"])
+			}
+			if tt.wantReusePR && git["pullRequestUrl"] != tt.existingPR {
+				t.Errorf("expected pullRequestUrl %q, got %v", tt.existingPR, git["pullRequestUrl"])
+			}
+		})
+	}
+}
+
+func TestOrchestratorJob_ReviewersAndAssignees(t *testing.T) {
+	task := &aiv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"},
+		Spec: aiv1alpha1.TaskSpec{
+			Git: &aiv1alpha1.GitConfig{
+				URL:               "https://github.com/org/repo.git",
+				CredentialsSecret: corev1.LocalObjectReference{Name: "git-creds"},
+				Reviewers:         []string{"alice", "org/platform-team"},
+				Assignees:         []string{"bob"},
+			},
+		},
+	}
+
+	job, err := OrchestratorJob(OrchestratorJobParams{
+		Task:              task,
+		OrchestratorAgent: &aiv1alpha1.Agent{Spec: aiv1alpha1.AgentSpec{Image: "orchestrator:v1"}},
+		WorkerEndpoint:    "worker:8080",
+		WorkspacePVC:      "workspace",
+		PRD:               `{}`,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	git := taskConfigGit(t, job)
+	reviewers, _ := git["reviewers"].([]interface{})
+	if len(reviewers) != 2 || reviewers[0] != "alice" || reviewers[1] != "org/platform-team" {
+		t.Errorf("expected reviewers [alice org/platform-team], got %v", git["reviewers"])
+	}
+	assignees, _ := git["assignees"].([]interface{})
+	if len(assignees) != 1 || assignees[0] != "bob" {
+		t.Errorf("expected assignees [bob], got %v", git["assignees"])
+	}
+
+	// Unset lists are omitted rather than sent empty
+	task.Spec.Git.Reviewers = nil
+	task.Spec.Git.Assignees = nil
+	job, err = OrchestratorJob(OrchestratorJobParams{
+		Task:              task,
+		OrchestratorAgent: &aiv1alpha1.Agent{Spec: aiv1alpha1.AgentSpec{Image: "orchestrator:v1"}},
+		WorkerEndpoint:    "worker:8080",
+		WorkspacePVC:      "workspace",
+		PRD:               `{}`,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	git = taskConfigGit(t, job)
+	if _, ok := git["reviewers"]; ok {
+		t.Errorf("expected no reviewers key, got %v", git["reviewers"])
+	}
+	if _, ok := git["assignees"]; ok {
+		t.Errorf("expected no assignees key, got %v", git["assignees"])
+	}
+
+	// Blank usernames are rejected
+	task.Spec.Git.Reviewers = []string{"alice", " "}
+	_, err = OrchestratorJob(OrchestratorJobParams{
+		Task:              task,
+		OrchestratorAgent: &aiv1alpha1.Agent{Spec: aiv1alpha1.AgentSpec{Image: "orchestrator:v1"}},
+		WorkerEndpoint:    "worker:8080",
+		WorkspacePVC:      "workspace",
+		PRD:               `{}`,
+	})
+	if err == nil || !strings.Contains(err.Error(), "reviewers") {
+		t.Errorf("expected reviewers validation error, got %v", err)
+	}
+}
+
+func TestOrchestratorJob_PRLabels(t *testing.T) {
+	tests := []struct {
+		name      string
+		labels    map[string]string
+		prLabels  []string
+		propagate bool
+		want      []string
+	}{
+		{
+			name:     "configured labels only",
+			labels:   map[string]string{"team": "platform"},
+			prLabels: []string{"automated", "needs-review"},
+			want:     []string{"automated", "needs-review"},
+		},
+		{
+			name:      "task labels propagated in key order",
+			labels:    map[string]string{"team": "platform", "app": "api"},
+			prLabels:  []string{"automated"},
+			propagate: true,
+			want:      []string{"automated", "app=api", "team=platform"},
+		},
+		{
+			name:      "duplicates dropped",
+			labels:    map[string]string{"team": "platform"},
+			prLabels:  []string{"team=platform", "automated", "automated"},
+			propagate: true,
+			want:      []string{"team=platform", "automated"},
+		},
+		{
+			name: "no labels",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &aiv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default", Labels: tt.labels},
+				Spec: aiv1alpha1.TaskSpec{
+					Git: &aiv1alpha1.GitConfig{
+						URL:                 "https://github.com/org/repo.git",
+						CredentialsSecret:   corev1.LocalObjectReference{Name: "git-creds"},
+						PRLabels:            tt.prLabels,
+						PropagateTaskLabels: tt.propagate,
+					},
+				},
+			}
+			job, err := OrchestratorJob(OrchestratorJobParams{
+				Task:              task,
+				OrchestratorAgent: &aiv1alpha1.Agent{Spec: aiv1alpha1.AgentSpec{Image: "orchestrator:v1"}},
+				WorkerEndpoint:    "worker:8080",
+				WorkspacePVC:      "workspace",
+				PRD:               `{}`,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			raw, _ := taskConfigGit(t, job)["prLabels"].([]interface{})
+			var got []string
+			for _, label := range raw {
+				got = append(got, label.(string))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected prLabels %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWorkerEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{name: "derived sidecar fallback", endpoint: "", want: LocalWorkerEndpoint()},
+		{name: "shared service override", endpoint: "http://code-worker.agents.svc:8080", want: "http://code-worker.agents.svc:8080"},
+		{name: "gateway route override", endpoint: "https://gateway.example.com/v1", want: "https://gateway.example.com/v1"},
+		{name: "missing scheme", endpoint: "code-worker:8080", wantErr: true},
+		{name: "unsupported scheme", endpoint: "grpc://code-worker:8080", wantErr: true},
+		{name: "missing host", endpoint: "http://", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &aiv1alpha1.Task{Spec: aiv1alpha1.TaskSpec{WorkerEndpoint: tt.endpoint}}
+			got, err := WorkerEndpoint(task)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOrchestratorJob_Sources(t *testing.T) {
+	task := &aiv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"}}
+
+	tests := []struct {
+		name    string
+		sources map[string]string
+	}{
+		{name: "single source omits sources", sources: nil},
+		{name: "named sources are passed through", sources: map[string]string{"requirements": "# Req", "design": "# Design"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := OrchestratorJob(OrchestratorJobParams{
+				Task:              task,
+				OrchestratorAgent: &aiv1alpha1.Agent{Spec: aiv1alpha1.AgentSpec{Image: "orchestrator:v1"}},
+				WorkerEndpoint:    "worker:8080",
+				WorkspacePVC:      "workspace",
+				PRD:               `{"tasks":[]}`,
+				Sources:           tt.sources,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var config struct {
+				PRD     map[string]interface{} `json:"prd"`
+				Sources map[string]string      `json:"sources"`
+			}
+			for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+				if env.Name == "TASK_CONFIG" {
+					if err := json.Unmarshal([]byte(env.Value), &config); err != nil {
+						t.Fatalf("TASK_CONFIG is not valid JSON: %v", err)
+					}
+				}
+			}
+			if config.PRD == nil {
+				t.Error("expected prd to still be passed")
+			}
+			if len(config.Sources) != len(tt.sources) {
+				t.Fatalf("expected %d sources, got %v", len(tt.sources), config.Sources)
+			}
+			for name, content := range tt.sources {
+				if config.Sources[name] != content {
+					t.Errorf("expected source %s = %q, got %q", name, content, config.Sources[name])
+				}
+			}
+		})
+	}
+}
+
+// taskConfigGit returns the git section of the orchestrator's TASK_CONFIG.
+func taskConfigGit(t *testing.T, job *batchv1.Job) map[string]interface{} {
+	t.Helper()
+	for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+		if env.Name != "TASK_CONFIG" {
+			continue
+		}
+		var config map[string]interface{}
+		if err := json.Unmarshal([]byte(env.Value), &config); err != nil {
+			t.Fatalf("TASK_CONFIG is not valid JSON: %v", err)
+		}
+		git, ok := config["git"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("git not found in TASK_CONFIG: %s", env.Value)
+		}
+		return git
+	}
+	t.Fatal("TASK_CONFIG env var not found")
+	return nil
+}
+
 func TestGitCloneInitContainer(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -464,7 +759,7 @@ func TestGitCloneInitContainer(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			container := gitCloneInitContainer(tt.config)
+			container := gitCloneInitContainer(tt.config, DefaultWorkspaceDir, "")
 			tt.validate(t, container)
 		})
 	}
@@ -487,6 +782,49 @@ func TestOrchestratorJobLabels(t *testing.T) {
 	}
 }
 
+func TestOrchestratorJob_CustomLabelsAndAnnotations(t *testing.T) {
+	params := OrchestratorJobParams{
+		Task: &aiv1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-task", Namespace: "default"},
+			Spec: aiv1alpha1.TaskSpec{
+				Labels: map[string]string{
+					"cost-center":             "ml-platform",
+					"fabric.jarsater.ai/task": "spoofed",
+				},
+				Annotations: map[string]string{"policy.example.com/tier": "gold"},
+			},
+		},
+		OrchestratorAgent: &aiv1alpha1.Agent{Spec: aiv1alpha1.AgentSpec{Image: "orchestrator:v1"}},
+		WorkspacePVC:      "test-workspace",
+		PRD:               `{}`,
+	}
+
+	job, err := OrchestratorJob(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, meta := range map[string]metav1.ObjectMeta{
+		"job": job.ObjectMeta,
+		"pod": job.Spec.Template.ObjectMeta,
+	} {
+		if meta.Labels["cost-center"] != "ml-platform" {
+			t.Errorf("%s: expected custom label, got %v", name, meta.Labels)
+		}
+		if meta.Labels["fabric.jarsater.ai/task"] != "my-task" {
+			t.Errorf("%s: managed label overwritten, got %q", name, meta.Labels["fabric.jarsater.ai/task"])
+		}
+		if meta.Annotations["policy.example.com/tier"] != "gold" {
+			t.Errorf("%s: expected custom annotation, got %v", name, meta.Annotations)
+		}
+	}
+
+	// The Task's own spec is left untouched.
+	if params.Task.Spec.Labels["fabric.jarsater.ai/task"] != "spoofed" {
+		t.Error("rendering must not mutate the Task's labels")
+	}
+}
+
 func TestWorkspacePVCName(t *testing.T) {
 	task := &aiv1alpha1.Task{
 		ObjectMeta: metav1.ObjectMeta{
@@ -633,3 +971,132 @@ func TestOrchestratorJob_NoWorkerAgentNoSidecar(t *testing.T) {
 		t.Errorf("expected no init containers without git or worker agent, got %d", len(job.Spec.Template.Spec.InitContainers))
 	}
 }
+
+func TestOrchestratorJob_WorkspaceDir(t *testing.T) {
+	tests := []struct {
+		name         string
+		workspaceDir string
+		expected     string
+	}{
+		{name: "default", expected: DefaultWorkspaceDir},
+		{name: "override", workspaceDir: "/src", expected: "/src"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := OrchestratorJobParams{
+				Task: &aiv1alpha1.Task{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"},
+					Spec: aiv1alpha1.TaskSpec{
+						WorkspaceDir: tt.workspaceDir,
+						Git: &aiv1alpha1.GitConfig{
+							URL:               "https://github.com/example/repo.git",
+							CredentialsSecret: corev1.LocalObjectReference{Name: "git-creds"},
+						},
+					},
+				},
+				OrchestratorAgent: &aiv1alpha1.Agent{Spec: aiv1alpha1.AgentSpec{Image: "orchestrator:v1"}},
+				WorkerAgent: &aiv1alpha1.Agent{
+					ObjectMeta: metav1.ObjectMeta{Name: "code-worker", Namespace: "default"},
+					Spec:       aiv1alpha1.AgentSpec{Image: "worker:v1"},
+				},
+				WorkerEndpoint: LocalWorkerEndpoint(),
+				WorkspacePVC:   "test-workspace",
+				PRD:            `{}`,
+			}
+
+			job, err := OrchestratorJob(params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			podSpec := job.Spec.Template.Spec
+			if podSpec.Containers[0].Name != OrchestratorContainerName {
+				t.Errorf("expected container %q, got %q", OrchestratorContainerName, podSpec.Containers[0].Name)
+			}
+
+			// Every container must mount the workspace where WORKSPACE_DIR points.
+			containers := append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+			for _, c := range containers {
+				mountPath := ""
+				for _, m := range c.VolumeMounts {
+					if m.Name == "workspace" {
+						mountPath = m.MountPath
+					}
+				}
+				if mountPath != tt.expected {
+					t.Errorf("%s: expected workspace mounted at %q, got %q", c.Name, tt.expected, mountPath)
+				}
+				envByName := map[string]string{}
+				for _, e := range c.Env {
+					envByName[e.Name] = e.Value
+				}
+				if envByName["WORKSPACE_DIR"] != tt.expected {
+					t.Errorf("%s: expected WORKSPACE_DIR %q, got %q", c.Name, tt.expected, envByName["WORKSPACE_DIR"])
+				}
+				if gitConfig, ok := envByName["GIT_CONFIG_GLOBAL"]; ok && gitConfig != tt.expected+"/.gitconfig" {
+					t.Errorf("%s: expected GIT_CONFIG_GLOBAL under %q, got %q", c.Name, tt.expected, gitConfig)
+				}
+			}
+		})
+	}
+}
+
+func TestOrchestratorJob_WorkerSidecarSecurityContext(t *testing.T) {
+	newParams := func(taskSecurity, workerSecurity *aiv1alpha1.SecurityContextConfig) OrchestratorJobParams {
+		return OrchestratorJobParams{
+			Task: &aiv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default"},
+				Spec:       aiv1alpha1.TaskSpec{SecurityContext: taskSecurity},
+			},
+			OrchestratorAgent: &aiv1alpha1.Agent{Spec: aiv1alpha1.AgentSpec{Image: "orchestrator:v1"}},
+			WorkerAgent: &aiv1alpha1.Agent{
+				ObjectMeta: metav1.ObjectMeta{Name: "code-worker", Namespace: "default"},
+				Spec:       aiv1alpha1.AgentSpec{Image: "worker:v1", SecurityContext: workerSecurity},
+			},
+			WorkerEndpoint: LocalWorkerEndpoint(),
+			WorkspacePVC:   "test-workspace",
+			PRD:            `{}`,
+		}
+	}
+	containers := func(t *testing.T, params OrchestratorJobParams) (orchestrator, worker corev1.Container) {
+		t.Helper()
+		job, err := OrchestratorJob(params)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return job.Spec.Template.Spec.Containers[0], job.Spec.Template.Spec.InitContainers[0]
+	}
+	workerSecurity := &aiv1alpha1.SecurityContextConfig{
+		RunAsUser:              ptr.To(int64(1001)),
+		ReadOnlyRootFilesystem: ptr.To(false),
+	}
+
+	t.Run("worker agent only", func(t *testing.T) {
+		orchestrator, worker := containers(t, newParams(nil, workerSecurity))
+		if sc := worker.SecurityContext; sc.RunAsUser == nil || *sc.RunAsUser != 1001 || *sc.ReadOnlyRootFilesystem {
+			t.Errorf("expected the worker Agent's securityContext on the sidecar, got %+v", sc)
+		}
+		if sc := orchestrator.SecurityContext; sc.RunAsUser != nil || !*sc.ReadOnlyRootFilesystem {
+			t.Errorf("expected the orchestrator to keep the hardened defaults, got %+v", sc)
+		}
+	})
+
+	t.Run("task layered over worker agent", func(t *testing.T) {
+		_, worker := containers(t, newParams(&aiv1alpha1.SecurityContextConfig{RunAsUser: ptr.To(int64(2000))}, workerSecurity))
+		sc := worker.SecurityContext
+		if sc.RunAsUser == nil || *sc.RunAsUser != 2000 {
+			t.Errorf("expected the Task's runAsUser to take precedence, got %v", sc.RunAsUser)
+		}
+		if *sc.ReadOnlyRootFilesystem {
+			t.Error("expected the worker Agent's readOnlyRootFilesystem to apply where the Task sets none")
+		}
+	})
+
+	t.Run("task only", func(t *testing.T) {
+		_, worker := containers(t, newParams(&aiv1alpha1.SecurityContextConfig{RunAsUser: ptr.To(int64(2000))}, nil))
+		if sc := worker.SecurityContext; sc.RunAsUser == nil || *sc.RunAsUser != 2000 || !*sc.ReadOnlyRootFilesystem {
+			t.Errorf("expected the Task's securityContext on the sidecar, got %+v", sc)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package render
+
+import (
+	"fmt"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+const (
+	// OIDCTokenMountPath is where the projected OIDC token is mounted.
+	OIDCTokenMountPath = "/var/run/secrets/fabric.jarsater.ai/oidc"
+
+	// DefaultBedrockOIDCAudience is the token audience AWS STS expects.
+	DefaultBedrockOIDCAudience = "sts.amazonaws.com"
+
+	oidcTokenFileName         = "token"
+	oidcVolumeName            = "oidc-token"
+	defaultOIDCExpirationSecs = int64(3600)
+	modelProviderBedrock      = "bedrock"
+	modelProviderVertex       = "vertex"
+)
+
+// ValidateModelOIDC checks that the fields the provider needs for OIDC token
+// exchange are set. A model without OIDC configured is always valid.
+func ValidateModelOIDC(model aiv1alpha1.ModelConfig) error {
+	oidc := model.OIDC
+	if oidc == nil {
+		return nil
+	}
+	switch model.Provider {
+	case modelProviderBedrock:
+		if oidc.RoleARN == "" {
+			return fmt.Errorf("model.oidc.roleArn is required for provider %q", model.Provider)
+		}
+	case modelProviderVertex:
+		if oidc.Audience == "" {
+			return fmt.Errorf("model.oidc.audience is required for provider %q", model.Provider)
+		}
+		if oidc.RoleARN != "" {
+			return fmt.Errorf("model.oidc.roleArn is not supported for provider %q", model.Provider)
+		}
+	default:
+		return fmt.Errorf("model.oidc is not supported for provider %q", model.Provider)
+	}
+	if oidc.ServiceAccountEmail != "" && model.Provider != modelProviderVertex {
+		return fmt.Errorf("model.oidc.serviceAccountEmail is not supported for provider %q", model.Provider)
+	}
+	return nil
+}
+
+// modelOIDCConfig returns the OIDC section of the agent runtime config, or
+// nil when the model does not use OIDC.
+func modelOIDCConfig(model aiv1alpha1.ModelConfig) *AgentModelOIDCConfig {
+	if model.OIDC == nil {
+		return nil
+	}
+	return &AgentModelOIDCConfig{
+		TokenFile:           oidcTokenPath(),
+		Audience:            oidcAudience(model),
+		RoleARN:             model.OIDC.RoleARN,
+		ServiceAccountEmail: model.OIDC.ServiceAccountEmail,
+	}
+}
+
+// applyModelOIDC mounts a projected ServiceAccount token into the container
+// and sets the provider-specific env the runner uses to exchange it.
+func applyModelOIDC(agent *aiv1alpha1.Agent, podSpec *corev1.PodSpec, container *corev1.Container) {
+	model := agent.Spec.Model
+	if model.OIDC == nil {
+		return
+	}
+
+	expiration := defaultOIDCExpirationSecs
+	if model.OIDC.ExpirationSeconds != nil {
+		expiration = *model.OIDC.ExpirationSeconds
+	}
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: oidcVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Audience:          oidcAudience(model),
+							ExpirationSeconds: &expiration,
+							Path:              oidcTokenFileName,
+						},
+					},
+				},
+			},
+		},
+	})
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      oidcVolumeName,
+		MountPath: OIDCTokenMountPath,
+		ReadOnly:  true,
+	})
+
+	env := []corev1.EnvVar{
+		{Name: "OIDC_TOKEN_FILE", Value: oidcTokenPath()},
+		{Name: "OIDC_AUDIENCE", Value: oidcAudience(model)},
+	}
+	switch model.Provider {
+	case modelProviderBedrock:
+		// Standard AWS SDK web identity variables
+		env = append(env,
+			corev1.EnvVar{Name: "AWS_ROLE_ARN", Value: model.OIDC.RoleARN},
+			corev1.EnvVar{Name: "AWS_WEB_IDENTITY_TOKEN_FILE", Value: oidcTokenPath()},
+			corev1.EnvVar{Name: "AWS_ROLE_SESSION_NAME", Value: agent.Name},
+		)
+	case modelProviderVertex:
+		if model.OIDC.ServiceAccountEmail != "" {
+			env = append(env, corev1.EnvVar{Name: "GOOGLE_SERVICE_ACCOUNT_EMAIL", Value: model.OIDC.ServiceAccountEmail})
+		}
+	}
+	container.Env = append(container.Env, env...)
+}
+
+// oidcAudience returns the configured token audience or the provider default.
+func oidcAudience(model aiv1alpha1.ModelConfig) string {
+	if model.OIDC.Audience != "" {
+		return model.OIDC.Audience
+	}
+	if model.Provider == modelProviderBedrock {
+		return DefaultBedrockOIDCAudience
+	}
+	return ""
+}
+
+func oidcTokenPath() string {
+	return path.Join(OIDCTokenMountPath, oidcTokenFileName)
+}
@@ -7,11 +7,16 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 )
 
 const (
 	// DefaultWorkspaceSize is the default PVC size for task workspaces.
 	DefaultWorkspaceSize = "1Gi"
+
+	// WorkspaceOrphanedLabel marks a workspace PVC kept after its task was
+	// deleted, which the operator no longer manages.
+	WorkspaceOrphanedLabel = "fabric.jarsater.ai/orphaned"
 )
 
 // TaskWorkspacePVC renders a PersistentVolumeClaim for a Task's workspace.
@@ -24,6 +29,11 @@ func TaskWorkspacePVC(task *aiv1alpha1.Task) *corev1.PersistentVolumeClaim {
 		"fabric.jarsater.ai/task":      task.Name,
 	}
 
+	var storageClassName *string
+	if storage := task.Spec.WorkspaceStorage; storage != nil && storage.StorageClassName != nil {
+		storageClassName = ptr.To(*storage.StorageClassName)
+	}
+
 	return &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      WorkspacePVCName(task),
@@ -32,8 +42,9 @@ func TaskWorkspacePVC(task *aiv1alpha1.Task) *corev1.PersistentVolumeClaim {
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes: []corev1.PersistentVolumeAccessMode{
-				corev1.ReadWriteOnce,
+				WorkspaceAccessMode(task),
 			},
+			StorageClassName: storageClassName,
 			Resources: corev1.VolumeResourceRequirements{
 				Requests: corev1.ResourceList{
 					corev1.ResourceStorage: resource.MustParse(DefaultWorkspaceSize),
@@ -42,3 +53,12 @@ func TaskWorkspacePVC(task *aiv1alpha1.Task) *corev1.PersistentVolumeClaim {
 		},
 	}
 }
+
+// WorkspaceAccessMode returns the access mode of a Task's workspace PVC,
+// ReadWriteOnce unless the task's workspace storage asks otherwise.
+func WorkspaceAccessMode(task *aiv1alpha1.Task) corev1.PersistentVolumeAccessMode {
+	if storage := task.Spec.WorkspaceStorage; storage != nil && storage.AccessMode != "" {
+		return storage.AccessMode
+	}
+	return corev1.ReadWriteOnce
+}
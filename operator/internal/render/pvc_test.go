@@ -0,0 +1,57 @@
+package render
+
+import (
+	"testing"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestTaskWorkspacePVC(t *testing.T) {
+	tests := []struct {
+		name             string
+		storage          *aiv1alpha1.WorkspaceStorageConfig
+		wantAccessMode   corev1.PersistentVolumeAccessMode
+		wantStorageClass *string
+	}{
+		{
+			name:           "defaults to read-write-once",
+			wantAccessMode: corev1.ReadWriteOnce,
+		},
+		{
+			name:             "read-write-many with a shared storage class",
+			storage:          &aiv1alpha1.WorkspaceStorageConfig{StorageClassName: ptr.To("efs"), AccessMode: corev1.ReadWriteMany},
+			wantAccessMode:   corev1.ReadWriteMany,
+			wantStorageClass: ptr.To("efs"),
+		},
+		{
+			name:             "storage class without access mode",
+			storage:          &aiv1alpha1.WorkspaceStorageConfig{StorageClassName: ptr.To("fast")},
+			wantAccessMode:   corev1.ReadWriteOnce,
+			wantStorageClass: ptr.To("fast"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &aiv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "default"},
+				Spec:       aiv1alpha1.TaskSpec{WorkspaceStorage: tt.storage},
+			}
+
+			pvc := TaskWorkspacePVC(task)
+
+			if len(pvc.Spec.AccessModes) != 1 || pvc.Spec.AccessModes[0] != tt.wantAccessMode {
+				t.Errorf("expected access modes [%s], got %v", tt.wantAccessMode, pvc.Spec.AccessModes)
+			}
+			if !ptr.Equal(pvc.Spec.StorageClassName, tt.wantStorageClass) {
+				t.Errorf("expected storage class %v, got %v", ptr.Deref(tt.wantStorageClass, "<default>"), ptr.Deref(pvc.Spec.StorageClassName, "<default>"))
+			}
+			if pvc.Name != "build-workspace" {
+				t.Errorf("expected PVC build-workspace, got %s", pvc.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package render
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// toolPackageInitResources returns the default resources of the agent-libs
+// and ToolPackage init containers: enough to copy files, with headroom for
+// pip installs.
+func toolPackageInitResources() corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("50m"),
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+	}
+}
+
+// gitCloneInitResources returns the default resources of the git-clone init
+// container, whose limits allow cloning large repositories.
+func gitCloneInitResources() corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}
+}
+
+// resourcesOrDefault returns a copy of the configured resources, or the
+// defaults when none are configured.
+func resourcesOrDefault(configured *corev1.ResourceRequirements, defaults func() corev1.ResourceRequirements) corev1.ResourceRequirements {
+	if configured == nil {
+		return defaults()
+	}
+	return *configured.DeepCopy()
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+func customInitResources() *corev1.ResourceRequirements {
+	return &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+	}
+}
+
+func TestAgentDeployment_InitContainerResources(t *testing.T) {
+	toolPackages := []ToolPackageInfo{{Name: "aws", Image: "ghcr.io/example/aws-tools:1"}}
+	agent := &aiv1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Name: "helper", Namespace: "default"}}
+
+	initContainers := AgentDeployment(AgentDeploymentParams{Agent: agent, ToolPackages: toolPackages}).Spec.Template.Spec.InitContainers
+	if len(initContainers) != 2 {
+		t.Fatalf("expected 2 init containers, got %d", len(initContainers))
+	}
+	for _, c := range initContainers {
+		if got := c.Resources.Limits.Memory().String(); got != "512Mi" {
+			t.Errorf("expected the default 512Mi memory limit for %s, got %s", c.Name, got)
+		}
+		if got := c.Resources.Requests.Cpu().String(); got != "50m" {
+			t.Errorf("expected the default 50m CPU request for %s, got %s", c.Name, got)
+		}
+	}
+
+	agent.Spec.InitContainerResources = customInitResources()
+	initContainers = AgentDeployment(AgentDeploymentParams{Agent: agent, ToolPackages: toolPackages}).Spec.Template.Spec.InitContainers
+	for _, c := range initContainers {
+		if got := c.Resources.Limits.Memory().String(); got != "2Gi" {
+			t.Errorf("expected the configured 2Gi memory limit for %s, got %s", c.Name, got)
+		}
+		if _, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
+			t.Errorf("expected configured resources to replace the defaults for %s, got %v", c.Name, c.Resources)
+		}
+	}
+}
+
+func TestGitCloneInitContainer_Resources(t *testing.T) {
+	gitConfig := &aiv1alpha1.GitConfig{URL: "https://github.com/example/repo.git"}
+
+	c := gitCloneInitContainer(gitConfig, DefaultWorkspaceDir, "")
+	if got := c.Resources.Limits.Memory().String(); got != "1Gi" {
+		t.Errorf("expected the default 1Gi memory limit, got %s", got)
+	}
+	if got := c.Resources.Requests.Cpu().String(); got != "100m" {
+		t.Errorf("expected the default 100m CPU request, got %s", got)
+	}
+
+	gitConfig.Resources = customInitResources()
+	c = gitCloneInitContainer(gitConfig, DefaultWorkspaceDir, "")
+	if got := c.Resources.Limits.Memory().String(); got != "2Gi" {
+		t.Errorf("expected the configured 2Gi memory limit, got %s", got)
+	}
+
+	// The rendered container must not alias the spec
+	c.Resources.Limits[corev1.ResourceMemory] = resource.MustParse("1Mi")
+	if got := gitConfig.Resources.Limits.Memory().String(); got != "2Gi" {
+		t.Errorf("expected the spec left unchanged, got %s", got)
+	}
+}
@@ -0,0 +1,32 @@
+package render
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+// ExternalSecretName returns the name of the Secret synced from an Agent's
+// external secrets.
+func ExternalSecretName(agent *aiv1alpha1.Agent) string {
+	return agent.Name + "-external-secrets"
+}
+
+// AgentExternalSecret renders the Secret holding values synced from an
+// external secret manager.
+func AgentExternalSecret(agent *aiv1alpha1.Agent, data map[string][]byte, labels map[string]string) *corev1.Secret {
+	if labels == nil {
+		labels = AgentLabels(agent)
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ExternalSecretName(agent),
+			Namespace: agent.Namespace,
+			Labels:    labels,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+}
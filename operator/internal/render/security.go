@@ -0,0 +1,99 @@
+package render
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+// ValidateSecurityContext rejects security context settings that cannot
+// work together. Nil settings are always valid. Setting runAsNonRoot either
+// way requires a uid: a non-root image USER the kubelet cannot verify fails
+// at pod start, and running as root should be explicit.
+func ValidateSecurityContext(security *aiv1alpha1.SecurityContextConfig) error {
+	if security == nil {
+		return nil
+	}
+	runAsNonRoot := getBoolOrDefault(security.RunAsNonRoot, true)
+	if runAsNonRoot && security.RunAsUser != nil && *security.RunAsUser == 0 {
+		return fmt.Errorf("securityContext.runAsUser 0 requires runAsNonRoot to be false")
+	}
+	if security.RunAsNonRoot != nil && security.RunAsUser == nil {
+		return fmt.Errorf("securityContext.runAsNonRoot %t requires runAsUser", runAsNonRoot)
+	}
+	return nil
+}
+
+// podSecurityContext returns hardened pod security context, adjusted by the
+// agent's or task's settings. Unless configured, RunAsUser is not set,
+// allowing each image's USER directive to take effect.
+func podSecurityContext(security *aiv1alpha1.SecurityContextConfig) *corev1.PodSecurityContext {
+	sc := &corev1.PodSecurityContext{
+		RunAsNonRoot: ptr.To(true),
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+	if security != nil {
+		sc.RunAsNonRoot = ptr.To(getBoolOrDefault(security.RunAsNonRoot, true))
+		sc.RunAsUser = copyInt64(security.RunAsUser)
+		sc.FSGroup = copyInt64(security.FSGroup)
+	}
+	return sc
+}
+
+// containerSecurityContext returns hardened container security context,
+// adjusted by the agent's or task's settings. Privilege escalation and all
+// capabilities stay denied regardless.
+func containerSecurityContext(security *aiv1alpha1.SecurityContextConfig) *corev1.SecurityContext {
+	sc := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: ptr.To(false),
+		ReadOnlyRootFilesystem:   ptr.To(true),
+		RunAsNonRoot:             ptr.To(true),
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
+	if security != nil {
+		sc.ReadOnlyRootFilesystem = ptr.To(getBoolOrDefault(security.ReadOnlyRootFilesystem, true))
+		sc.RunAsNonRoot = ptr.To(getBoolOrDefault(security.RunAsNonRoot, true))
+		sc.RunAsUser = copyInt64(security.RunAsUser)
+	}
+	return sc
+}
+
+// LayerSecurityContext returns base with the fields set in override taking
+// precedence, so a Task can adjust its worker Agent's settings field by
+// field. Either may be nil.
+func LayerSecurityContext(base, override *aiv1alpha1.SecurityContextConfig) *aiv1alpha1.SecurityContextConfig {
+	if base == nil {
+		return override
+	}
+	if override == nil {
+		return base
+	}
+	merged := base.DeepCopy()
+	if override.RunAsNonRoot != nil {
+		merged.RunAsNonRoot = ptr.To(*override.RunAsNonRoot)
+	}
+	if override.RunAsUser != nil {
+		merged.RunAsUser = ptr.To(*override.RunAsUser)
+	}
+	if override.FSGroup != nil {
+		merged.FSGroup = ptr.To(*override.FSGroup)
+	}
+	if override.ReadOnlyRootFilesystem != nil {
+		merged.ReadOnlyRootFilesystem = ptr.To(*override.ReadOnlyRootFilesystem)
+	}
+	return merged
+}
+
+func copyInt64(v *int64) *int64 {
+	if v == nil {
+		return nil
+	}
+	return ptr.To(*v)
+}
@@ -0,0 +1,91 @@
+package render
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+func TestAgentDeployment_StrictSecurityContext(t *testing.T) {
+	agent := &aiv1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Name: "helper", Namespace: "default"}}
+
+	podSpec := AgentDeployment(AgentDeploymentParams{Agent: agent}).Spec.Template.Spec
+
+	pod := podSpec.SecurityContext
+	if !*pod.RunAsNonRoot || pod.RunAsUser != nil || pod.FSGroup != nil || pod.SeccompProfile == nil {
+		t.Errorf("expected the hardened pod security context, got %+v", pod)
+	}
+	for _, c := range append(podSpec.InitContainers, podSpec.Containers...) {
+		sc := c.SecurityContext
+		if !*sc.ReadOnlyRootFilesystem || !*sc.RunAsNonRoot || *sc.AllowPrivilegeEscalation || sc.RunAsUser != nil {
+			t.Errorf("expected a hardened security context for %s, got %+v", c.Name, sc)
+		}
+	}
+}
+
+func TestAgentDeployment_RelaxedSecurityContext(t *testing.T) {
+	agent := &aiv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "helper", Namespace: "default"},
+		Spec: aiv1alpha1.AgentSpec{
+			SecurityContext: &aiv1alpha1.SecurityContextConfig{
+				RunAsUser:              ptr.To(int64(1001)),
+				FSGroup:                ptr.To(int64(2000)),
+				ReadOnlyRootFilesystem: ptr.To(false),
+			},
+		},
+	}
+
+	podSpec := AgentDeployment(AgentDeploymentParams{Agent: agent}).Spec.Template.Spec
+
+	pod := podSpec.SecurityContext
+	if !*pod.RunAsNonRoot || *pod.RunAsUser != 1001 || *pod.FSGroup != 2000 {
+		t.Errorf("expected a non-root pod as uid 1001 with fsGroup 2000, got %+v", pod)
+	}
+	for _, c := range append(podSpec.InitContainers, podSpec.Containers...) {
+		sc := c.SecurityContext
+		if *sc.ReadOnlyRootFilesystem || *sc.RunAsUser != 1001 {
+			t.Errorf("expected a writable root filesystem as uid 1001 for %s, got %+v", c.Name, sc)
+		}
+		if *sc.AllowPrivilegeEscalation || len(sc.Capabilities.Drop) != 1 {
+			t.Errorf("expected privilege escalation and capabilities still denied for %s, got %+v", c.Name, sc)
+		}
+	}
+}
+
+func TestContainerSecurityContext_Root(t *testing.T) {
+	sc := containerSecurityContext(&aiv1alpha1.SecurityContextConfig{
+		RunAsNonRoot: ptr.To(false),
+		RunAsUser:    ptr.To(int64(0)),
+	})
+	if *sc.RunAsNonRoot || *sc.RunAsUser != 0 || !*sc.ReadOnlyRootFilesystem {
+		t.Errorf("expected root with a read-only root filesystem, got %+v", sc)
+	}
+}
+
+func TestValidateSecurityContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		security *aiv1alpha1.SecurityContextConfig
+		wantErr  bool
+	}{
+		{name: "unset"},
+		{name: "empty", security: &aiv1alpha1.SecurityContextConfig{}},
+		{name: "non-root uid", security: &aiv1alpha1.SecurityContextConfig{RunAsUser: ptr.To(int64(1000))}},
+		{name: "explicit root", security: &aiv1alpha1.SecurityContextConfig{RunAsNonRoot: ptr.To(false), RunAsUser: ptr.To(int64(0))}},
+		{name: "uid 0 while non-root", security: &aiv1alpha1.SecurityContextConfig{RunAsUser: ptr.To(int64(0))}, wantErr: true},
+		{name: "root without a uid", security: &aiv1alpha1.SecurityContextConfig{RunAsNonRoot: ptr.To(false)}, wantErr: true},
+		{name: "explicit non-root uid", security: &aiv1alpha1.SecurityContextConfig{RunAsNonRoot: ptr.To(true), RunAsUser: ptr.To(int64(1000))}},
+		{name: "non-root without a uid", security: &aiv1alpha1.SecurityContextConfig{RunAsNonRoot: ptr.To(true)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSecurityContext(tt.security)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
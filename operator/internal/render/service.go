@@ -18,9 +18,10 @@ func AgentService(agent *aiv1alpha1.Agent, labels map[string]string) *corev1.Ser
 
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      agent.Name,
-			Namespace: agent.Namespace,
-			Labels:    labels,
+			Name:        agent.Name,
+			Namespace:   agent.Namespace,
+			Labels:      withCustom(labels, agent.Spec.Labels),
+			Annotations: withCustom(nil, agent.Spec.Annotations),
 		},
 		Spec: corev1.ServiceSpec{
 			Type:     corev1.ServiceTypeClusterIP,
@@ -0,0 +1,41 @@
+package render
+
+import (
+	"regexp"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+// MCPToolGroupLabel groups agents under one MCP tool name prefix. It must
+// match the label the gateway reads.
+const MCPToolGroupLabel = "fabric.jarsater.ai/mcp-tool-group"
+
+// MCPToolNamePattern is the character set and length MCP clients accept for
+// tool names; the gateway skips tools whose names do not match.
+var MCPToolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// InvalidMCPToolNames returns the tool names the gateway would generate for
+// agent, "{prefix}_{tool}" or the bare prefix, that clients would reject.
+// The prefix is the agent's tool group, or its name outside of one.
+func InvalidMCPToolNames(agent *aiv1alpha1.Agent) []string {
+	prefix := agent.Name
+	if group := agent.Labels[MCPToolGroupLabel]; group != "" {
+		prefix = group
+	}
+
+	names := []string{prefix}
+	if len(agent.Spec.Tools) > 0 {
+		names = names[:0]
+		for _, tool := range agent.Spec.Tools {
+			names = append(names, prefix+"_"+tool.Name)
+		}
+	}
+
+	var invalid []string
+	for _, name := range names {
+		if !MCPToolNamePattern.MatchString(name) {
+			invalid = append(invalid, name)
+		}
+	}
+	return invalid
+}
@@ -0,0 +1,38 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+)
+
+// ProviderAWSSecretsManager is the provider name for AWS Secrets Manager.
+const ProviderAWSSecretsManager = "aws-secrets-manager"
+
+// ErrProviderNotConfigured is returned by providers that lack a backend client.
+var ErrProviderNotConfigured = errors.New("secret provider is not configured")
+
+// AWSSecretsManager reads secrets from AWS Secrets Manager.
+//
+// The operator does not bundle the AWS SDK, so the manager does not register
+// this provider. To enable it, set GetSecretValue to a client call (e.g.
+// secretsmanager.Client.GetSecretValue) and register it under
+// ProviderAWSSecretsManager.
+type AWSSecretsManager struct {
+	// Region is the AWS region holding the secrets.
+	Region string
+
+	// GetSecretValue fetches the SecretString for a secret name or ARN.
+	GetSecretValue func(ctx context.Context, region, secretID string) (string, error)
+}
+
+// GetSecret implements Provider.
+func (p *AWSSecretsManager) GetSecret(ctx context.Context, remoteRef string) ([]byte, error) {
+	if p.GetSecretValue == nil {
+		return nil, ErrProviderNotConfigured
+	}
+	value, err := p.GetSecretValue(ctx, p.Region, remoteRef)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(value), nil
+}
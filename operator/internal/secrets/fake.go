@@ -0,0 +1,51 @@
+package secrets
+
+import (
+	"context"
+	"sync"
+)
+
+// ProviderFake is the provider name conventionally used for FakeProvider.
+const ProviderFake = "fake"
+
+// FakeProvider is an in-memory Provider for tests and local development.
+type FakeProvider struct {
+	mu      sync.Mutex
+	secrets map[string]string
+	calls   int
+}
+
+// NewFakeProvider creates a FakeProvider serving the given secrets.
+func NewFakeProvider(secrets map[string]string) *FakeProvider {
+	p := &FakeProvider{secrets: make(map[string]string)}
+	for k, v := range secrets {
+		p.secrets[k] = v
+	}
+	return p
+}
+
+// Set adds or replaces a secret value.
+func (p *FakeProvider) Set(remoteRef, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.secrets[remoteRef] = value
+}
+
+// Calls returns how many times GetSecret was called.
+func (p *FakeProvider) Calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+// GetSecret implements Provider.
+func (p *FakeProvider) GetSecret(_ context.Context, remoteRef string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	value, ok := p.secrets[remoteRef]
+	if !ok {
+		return nil, ErrSecretNotFound
+	}
+	return []byte(value), nil
+}
@@ -0,0 +1,100 @@
+// Package secrets resolves agent credentials from external secret managers.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+// ErrSecretNotFound is returned by providers when a remote secret does not exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// ErrUnknownProvider is returned by Resolve for a provider name with no
+// registered implementation.
+var ErrUnknownProvider = errors.New("unknown secret provider")
+
+// IsUnknownProvider reports whether err is or wraps ErrUnknownProvider.
+func IsUnknownProvider(err error) bool {
+	return errors.Is(err, ErrUnknownProvider)
+}
+
+// Provider reads secrets from an external secret manager.
+type Provider interface {
+	// GetSecret returns the raw value of the remote secret.
+	GetSecret(ctx context.Context, remoteRef string) ([]byte, error)
+}
+
+// Registry maps provider names to implementations.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+// NewRegistry creates an empty provider registry.
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]Provider)}
+}
+
+// Register adds or replaces the provider for name.
+func (r *Registry) Register(name string, p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[name] = p
+}
+
+// Get returns the provider registered under name.
+func (r *Registry) Get(name string) (Provider, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Resolve fetches every entry in spec from its provider and returns the
+// Secret data keyed by SecretKey.
+func (r *Registry) Resolve(ctx context.Context, spec *aiv1alpha1.ExternalSecretsSpec) (map[string][]byte, error) {
+	provider, ok := r.Get(spec.Provider)
+	if !ok {
+		return nil, fmt.Errorf("%w %q: the operator has no client for it", ErrUnknownProvider, spec.Provider)
+	}
+
+	data := make(map[string][]byte, len(spec.Data))
+	for _, entry := range spec.Data {
+		value, err := provider.GetSecret(ctx, entry.RemoteRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s for %s: %w", entry.RemoteRef, entry.SecretKey, err)
+		}
+		if entry.Property != "" {
+			value, err = extractProperty(value, entry.Property)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s for %s: %w", entry.RemoteRef, entry.SecretKey, err)
+			}
+		}
+		data[entry.SecretKey] = value
+	}
+	return data, nil
+}
+
+// extractProperty returns a top-level field from a JSON object secret.
+func extractProperty(value []byte, property string) ([]byte, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return nil, fmt.Errorf("property %q requested but secret is not a JSON object", property)
+	}
+	field, ok := fields[property]
+	if !ok {
+		return nil, fmt.Errorf("property %q: %w", property, ErrSecretNotFound)
+	}
+	if s, ok := field.(string); ok {
+		return []byte(s), nil
+	}
+	return json.Marshal(field)
+}
@@ -0,0 +1,116 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	aiv1alpha1 "github.com/jarsater/mcp-fabric/operator/api/v1alpha1"
+)
+
+func TestRegistryResolve(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(ProviderFake, NewFakeProvider(map[string]string{
+		"plain": "value",
+		"json":  `{"user":"app","port":5432}`,
+	}))
+
+	tests := []struct {
+		name        string
+		spec        *aiv1alpha1.ExternalSecretsSpec
+		wantErr     bool
+		errContains string
+		want        map[string]string
+	}{
+		{
+			name: "plain and property values",
+			spec: &aiv1alpha1.ExternalSecretsSpec{
+				Provider: ProviderFake,
+				Data: []aiv1alpha1.ExternalSecretData{
+					{SecretKey: "PLAIN", RemoteRef: "plain"},
+					{SecretKey: "USER", RemoteRef: "json", Property: "user"},
+					{SecretKey: "PORT", RemoteRef: "json", Property: "port"},
+				},
+			},
+			want: map[string]string{"PLAIN": "value", "USER": "app", "PORT": "5432"},
+		},
+		{
+			name:        "unknown provider",
+			spec:        &aiv1alpha1.ExternalSecretsSpec{Provider: "vault"},
+			wantErr:     true,
+			errContains: `unknown secret provider "vault"`,
+		},
+		{
+			name: "missing remote secret",
+			spec: &aiv1alpha1.ExternalSecretsSpec{
+				Provider: ProviderFake,
+				Data:     []aiv1alpha1.ExternalSecretData{{SecretKey: "X", RemoteRef: "nope"}},
+			},
+			wantErr:     true,
+			errContains: "secret not found",
+		},
+		{
+			name: "property on non-JSON secret",
+			spec: &aiv1alpha1.ExternalSecretsSpec{
+				Provider: ProviderFake,
+				Data:     []aiv1alpha1.ExternalSecretData{{SecretKey: "X", RemoteRef: "plain", Property: "user"}},
+			},
+			wantErr:     true,
+			errContains: "not a JSON object",
+		},
+		{
+			name: "missing property",
+			spec: &aiv1alpha1.ExternalSecretsSpec{
+				Provider: ProviderFake,
+				Data:     []aiv1alpha1.ExternalSecretData{{SecretKey: "X", RemoteRef: "json", Property: "password"}},
+			},
+			wantErr:     true,
+			errContains: `property "password"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := registry.Resolve(context.Background(), tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("expected error containing %q, got %q", tt.errContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(data) != len(tt.want) {
+				t.Fatalf("expected %d keys, got %d", len(tt.want), len(data))
+			}
+			for k, v := range tt.want {
+				if string(data[k]) != v {
+					t.Errorf("expected %s=%q, got %q", k, v, data[k])
+				}
+			}
+		})
+	}
+}
+
+func TestAWSSecretsManager_NotConfigured(t *testing.T) {
+	p := &AWSSecretsManager{Region: "eu-west-1"}
+	if _, err := p.GetSecret(context.Background(), "arn"); !errors.Is(err, ErrProviderNotConfigured) {
+		t.Errorf("expected ErrProviderNotConfigured, got %v", err)
+	}
+
+	p.GetSecretValue = func(_ context.Context, region, secretID string) (string, error) {
+		return region + "/" + secretID, nil
+	}
+	value, err := p.GetSecret(context.Background(), "arn")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(value) != "eu-west-1/arn" {
+		t.Errorf("unexpected value %q", value)
+	}
+}